@@ -36,6 +36,7 @@ func AddFlags() *config.Options {
 	flag.StringVar(&o.Username, "username", "", "User for Basic Auth")
 	flag.StringVar(&o.Password, "password", "", "Password for Basic Auth")
 	flag.StringVar(&o.KubeConfig, "kubeconfig", "", "Kubernetes configuration")
+	flag.DurationVar(&o.ReloadInterval, "configreloadinterval", config.DefaultReloadInterval, "Interval to check the config file for changes, 0 only reloads it on SIGHUP")
 	flag.Parse()
 
 	return o
@@ -61,6 +62,9 @@ func Deployer() error {
 		return errors.Wrap(err, "failed to initialize deployer")
 	}
 
+	watcher := config.NewWatcher(localcfg.Logger, c, opts.ReloadInterval, deployer.ApplyConfig)
+	go watcher.Run(make(chan struct{}))
+
 	deployer.Serve()
 
 	return nil
@@ -0,0 +1,73 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// LocalCluster is the name of the cluster the deployer runs in, requests
+// that do not select a cluster target it
+const LocalCluster = "local"
+
+// ForCluster returns a copy of the configuration for the components of the
+// cluster, with the namespace, domain and cluster type of the cluster
+func (d *DeployerSettingsConfig) ForCluster(cluster *Cluster) *DeployerSettingsConfig {
+	cfg := *d
+	cfg.Clusters = nil
+	cfg.Namespace = cluster.Namespace
+	if cluster.Domain != "" {
+		cfg.Domain = cluster.Domain
+	}
+	if cluster.ClusterType != "" {
+		cfg.ClusterType = cluster.ClusterType
+	}
+	return &cfg
+}
+
+// RestConfig returns the configuration of the clients of the cluster
+func (c *Cluster) RestConfig() (*rest.Config, error) {
+	if c.KubeConfig == "" {
+		return rest.InClusterConfig()
+	}
+	return clientcmd.BuildConfigFromFlags("", c.KubeConfig)
+}
+
+func verifyClusters(clusters map[string]*Cluster) error {
+	for name, cluster := range clusters {
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return errors.Errorf("cluster name '%s' not valid: %s", name, strings.Join(errs, ", "))
+		}
+		if name == LocalCluster {
+			return errors.Errorf("cluster name '%s' is reserved for the cluster of the deployer", name)
+		}
+		if cluster == nil || cluster.Namespace == "" {
+			return errors.Errorf("namespace of cluster '%s' is required", name)
+		}
+		if errs := validation.IsDNS1123Label(cluster.Namespace); len(errs) > 0 {
+			return errors.Errorf("namespace '%s' of cluster '%s' not valid: %s", cluster.Namespace, name, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
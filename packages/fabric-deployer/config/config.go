@@ -23,6 +23,8 @@ import (
 	"io/ioutil"
 	"net/url"
 	"os"
+	"reflect"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -30,6 +32,7 @@ import (
 	"go.uber.org/zap/zapcore"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"sigs.k8s.io/yaml"
@@ -48,6 +51,10 @@ type Config struct {
 	Options     *Options
 	Deployer    *DeployerSettingsConfig
 	LocalConfig *LocalConfig
+
+	// hash is the hash of the configuration file that was last read
+	hash     string
+	revision Revision
 }
 
 func New(options *Options) *Config {
@@ -67,6 +74,7 @@ func (c *Config) ReadConfigFile() (*DeployerSettingsConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	c.hash = hash(cfile)
 
 	return deployer, nil
 }
@@ -84,6 +92,48 @@ func (c *Config) Init(deployerConfig *DeployerSettingsConfig) (*DeployerSettings
 
 	log := c.LocalConfig.Logger.Sugar().Named("init")
 
+	if options.KubeConfig != "" {
+		cfg, err := clientcmd.BuildConfigFromFlags("", options.KubeConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		c.LocalConfig.KubeConfig = cfg
+	}
+
+	err = c.load(deployerConfig, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.Deployer = deployerConfig
+	c.LocalConfig.Revision = c.nextRevision()
+
+	return deployerConfig, c.LocalConfig, nil
+}
+
+// Reload reads the configuration file again and validates it the same way
+// Init does. The configuration already in use is not modified.
+func (c *Config) Reload() (*DeployerSettingsConfig, Revision, error) {
+	deployerConfig, err := c.ReadConfigFile()
+	if err != nil {
+		return nil, Revision{}, err
+	}
+
+	err = c.load(deployerConfig, c.LocalConfig.Logger.Sugar().Named("reload"))
+	if err != nil {
+		return nil, Revision{}, err
+	}
+	c.Deployer = deployerConfig
+
+	return deployerConfig, c.nextRevision(), nil
+}
+
+// load applies the defaults and the command line options to the deployer
+// configuration and verifies it
+func (c *Config) load(deployerConfig *DeployerSettingsConfig, log *zap.SugaredLogger) error {
+	var err error
+
+	options := c.Options
+
 	if deployerConfig.Timeouts == nil {
 		deployerConfig.Timeouts = &Timeouts{}
 	}
@@ -108,7 +158,7 @@ func (c *Config) Init(deployerConfig *DeployerSettingsConfig) (*DeployerSettings
 	log.Info("Configuring deployer for cluster type: %s", deployerConfig.ClusterType)
 
 	if deployerConfig.Domain == "" {
-		return nil, nil, errors.New("Domain is not provided")
+		return errors.New("Domain is not provided")
 	}
 
 	if options.DBConnectionURL != "" {
@@ -117,15 +167,24 @@ func (c *Config) Init(deployerConfig *DeployerSettingsConfig) (*DeployerSettings
 
 	_, err = url.ParseRequestURI(deployerConfig.Database.ConnectionURL)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
-	if options.Username == "" && deployerConfig.Auth.Username == "" {
-		return nil, nil, errors.New("Username for basic auth is required")
+	if deployerConfig.Authentication == nil {
+		deployerConfig.Authentication = &Authentication{}
+	}
+	if len(deployerConfig.Authentication.Methods) == 0 {
+		deployerConfig.Authentication.Methods = []string{AuthMethodBasic}
 	}
 
-	if options.Password == "" && deployerConfig.Auth.Password == "" {
-		return nil, nil, errors.New("Password for basic auth is required")
+	if deployerConfig.Authentication.Uses(AuthMethodBasic) {
+		if options.Username == "" && deployerConfig.Auth.Username == "" {
+			return errors.New("Username for basic auth is required")
+		}
+
+		if options.Password == "" && deployerConfig.Auth.Password == "" && deployerConfig.Auth.PasswordHash == "" {
+			return errors.New("Password for basic auth is required")
+		}
 	}
 
 	if options.Username != "" {
@@ -133,33 +192,45 @@ func (c *Config) Init(deployerConfig *DeployerSettingsConfig) (*DeployerSettings
 	}
 	if options.Password != "" {
 		deployerConfig.Auth.Password = options.Password
+		deployerConfig.Auth.PasswordHash = ""
 	}
 
-	if options.KubeConfig != "" {
-		cfg, err := clientcmd.BuildConfigFromFlags("", options.KubeConfig)
-		if err != nil {
-			return nil, nil, err
-		}
-		c.LocalConfig.KubeConfig = cfg
+	if deployerConfig.Auth.Password == "" && deployerConfig.Auth.PasswordHash != "" {
+		log.Warn("Basic auth password is only configured as a hash, mustgather requires the password")
 	}
 
 	namespace, err := GetNamespace()
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 	deployerConfig.Namespace = namespace
 
 	err = VerifyDefaultVersions(deployerConfig.Versions)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
 	err = verifyDefaultStorage(deployerConfig.Defaults.Storage)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
-	return deployerConfig, c.LocalConfig, nil
+	err = verifyProfiles(deployerConfig.Defaults)
+	if err != nil {
+		return err
+	}
+
+	err = verifyInstances(deployerConfig.Instances)
+	if err != nil {
+		return err
+	}
+
+	err = verifyClusters(deployerConfig.Clusters)
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func setupLogging(loglevel string) (*zap.Logger, error) {
@@ -230,6 +301,120 @@ func VerifyDefaultStorageAndResource(defaults *DeployerDefaults) error {
 		return err
 	}
 
+	err = verifyProfiles(defaults)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ForProfile returns a copy of the defaults with the storage and resources of
+// the profile, the default profile if name is empty. The copy can be changed
+// without changing the defaults.
+func (d *DeployerDefaults) ForProfile(name string) (*DeployerDefaults, error) {
+	if name == "" {
+		name = d.DefaultProfile
+	}
+
+	defaults := &DeployerDefaults{
+		Storage:   d.Storage.deepCopy(),
+		Resources: d.Resources.deepCopy(),
+	}
+	if name == "" {
+		return defaults, nil
+	}
+
+	profile, found := d.Profiles[name]
+	if !found || profile == nil {
+		return nil, errors.Errorf("profile '%s' not found", name)
+	}
+
+	if profile.Storage != nil {
+		overlay(defaults.Storage.CA, profile.Storage.CA.DeepCopy())
+		overlay(defaults.Storage.Peer, profile.Storage.Peer.DeepCopy())
+		overlay(defaults.Storage.Orderer, profile.Storage.Orderer.DeepCopy())
+	}
+	if profile.Resources != nil {
+		overlay(defaults.Resources.CA, profile.Resources.CA.DeepCopy())
+		overlay(defaults.Resources.Peer, profile.Resources.Peer.DeepCopy())
+		overlay(defaults.Resources.Orderer, profile.Resources.Orderer.DeepCopy())
+	}
+	return defaults, nil
+}
+
+func (s *Storage) deepCopy() *Storage {
+	if s == nil {
+		return nil
+	}
+	return &Storage{
+		CA:      s.CA.DeepCopy(),
+		Peer:    s.Peer.DeepCopy(),
+		Orderer: s.Orderer.DeepCopy(),
+	}
+}
+
+func (r *Resources) deepCopy() *Resources {
+	if r == nil {
+		return nil
+	}
+	return &Resources{
+		CA:      r.CA.DeepCopy(),
+		Peer:    r.Peer.DeepCopy(),
+		Orderer: r.Orderer.DeepCopy(),
+	}
+}
+
+// overlay sets the fields of dst to the fields of src that are set, dst and
+// src are pointers to structs of the same type
+func overlay(dst, src interface{}) {
+	d := reflect.ValueOf(dst)
+	s := reflect.ValueOf(src)
+	if d.IsNil() || s.IsNil() {
+		return
+	}
+
+	d, s = d.Elem(), s.Elem()
+	for i := 0; i < s.NumField(); i++ {
+		if field := s.Field(i); !field.IsZero() {
+			d.Field(i).Set(field)
+		}
+	}
+}
+
+func verifyProfiles(defaults *DeployerDefaults) error {
+	if defaults.DefaultProfile != "" {
+		if _, found := defaults.Profiles[defaults.DefaultProfile]; !found {
+			return errors.Errorf("default profile '%s' not found", defaults.DefaultProfile)
+		}
+	}
+
+	for name, profile := range defaults.Profiles {
+		if name == "" || profile == nil {
+			return errors.Errorf("profile '%s' not valid", name)
+		}
+	}
+	return nil
+}
+
+func verifyInstances(instances *Instances) error {
+	if instances == nil {
+		return nil
+	}
+
+	for sID, namespace := range instances.Namespaces {
+		if errs := validation.IsValidLabelValue(sID); sID == "" || len(errs) > 0 {
+			return errors.Errorf("service instance ID '%s' not valid", sID)
+		}
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return errors.Errorf("namespace '%s' of service instance '%s' not valid: %s", namespace, sID, strings.Join(errs, ", "))
+		}
+	}
+	if instances.NamespaceLabel != "" {
+		if errs := validation.IsQualifiedName(instances.NamespaceLabel); len(errs) > 0 {
+			return errors.Errorf("namespace label '%s' not valid: %s", instances.NamespaceLabel, strings.Join(errs, ", "))
+		}
+	}
 	return nil
 }
 
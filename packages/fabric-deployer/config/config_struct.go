@@ -20,6 +20,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/IBM-Blockchain/fabric-deployer/offering"
 	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
@@ -45,37 +46,111 @@ type Options struct {
 	Username        string
 	Password        string	// #nosec G117
 	KubeConfig      string
+	// ReloadInterval is the interval at which the configuration file is
+	// checked for changes, zero only reloads it on SIGHUP
+	ReloadInterval time.Duration
 }
 
 type LocalConfig struct {
 	Logger     *zap.Logger `json:"-"`
 	KubeConfig *rest.Config
+	// Revision is the revision of the configuration loaded by Init
+	Revision Revision
 }
 
 type DeployerSettingsConfig struct {
-	ClusterType      offering.Type     `json:"clusterType"`
-	Domain           string            `json:"domain"`
-	DashboardURL     string            `json:"dashboardurl"`
-	Database         Database          `json:"db"`
-	Loglevel         string            `json:"loglevel"`
-	Port             int               `json:"port"`
-	TLS              TLSConfig         `json:"tls"`
-	Auth             BasicAuth         `json:"auth"`
-	Namespace        string            `json:"namespace"`
-	Defaults         *DeployerDefaults `json:"defaults"`
-	Versions         *Versions         `json:"versions"`
-	ImagePullSecrets []string          `json:"imagePullSecrets"`
-	ServiceConfig    ServiceConfig     `json:"serviceConfig"`
-	CRN              *CRN              `json:"crn"`
-	Timeouts         *Timeouts         `json:"timeouts"`
-	OtherImages      *OtherImages      `json:"otherImages"`
-	ServiceAccount   string            `json:"serviceAccount"`
-	UseTags          *bool             `json:"usetags"`
+	ClusterType      offering.Type       `json:"clusterType"`
+	Domain           string              `json:"domain"`
+	DashboardURL     string              `json:"dashboardurl"`
+	Database         Database            `json:"db"`
+	Loglevel         string              `json:"loglevel"`
+	Port             int                 `json:"port"`
+	TLS              TLSConfig           `json:"tls"`
+	Auth             BasicAuth           `json:"auth"`
+	Authentication   *Authentication     `json:"authentication,omitempty"`
+	Authorization    *Authorization      `json:"authorization,omitempty"`
+	Audit            *Audit              `json:"audit,omitempty"`
+	Metrics          *Metrics            `json:"metrics,omitempty"`
+	Tracing          *Tracing            `json:"tracing,omitempty"`
+	Namespace        string              `json:"namespace"`
+	Instances        *Instances          `json:"instances,omitempty"`
+	Clusters         map[string]*Cluster `json:"clusters,omitempty"`
+	Defaults         *DeployerDefaults   `json:"defaults"`
+	Versions         *Versions           `json:"versions"`
+	ImagePullSecrets []string            `json:"imagePullSecrets"`
+	ServiceConfig    ServiceConfig       `json:"serviceConfig"`
+	CRN              *CRN                `json:"crn"`
+	Timeouts         *Timeouts           `json:"timeouts"`
+	OtherImages      *OtherImages        `json:"otherImages"`
+	ServiceAccount   string              `json:"serviceAccount"`
+	UseTags          *bool               `json:"usetags"`
 }
+
+// Instances maps the service instances to the namespaces of their components.
+// Service instances that are not mapped use the namespace of the deployer.
+type Instances struct {
+	// Namespaces maps service instance IDs to namespaces
+	Namespaces map[string]string `json:"namespaces,omitempty"`
+	// NamespaceLabel is the label with the service instance ID as value of
+	// the namespaces of the service instances that are not in Namespaces
+	NamespaceLabel string `json:"namespaceLabel,omitempty"`
+	// Strict rejects the requests of service instances that are not mapped
+	// to a namespace
+	Strict bool `json:"strict,omitempty"`
+}
+
+// Cluster is a cluster the deployer deploys to besides its own
+type Cluster struct {
+	// KubeConfig is the path of the kubeconfig of the cluster, the in-cluster
+	// configuration is used if it is empty
+	KubeConfig string `json:"kubeconfig,omitempty"`
+	// Namespace of the components in the cluster
+	Namespace string `json:"namespace"`
+	// Domain and ClusterType default to the ones of the deployer
+	Domain      string        `json:"domain,omitempty"`
+	ClusterType offering.Type `json:"clusterType,omitempty"`
+}
+
 type Versions struct {
 	CA      map[string]VersionCA      `json:"ca"`
 	Peer    map[string]VersionPeer    `json:"peer"`
 	Orderer map[string]VersionOrderer `json:"orderer"`
+
+	// Transitions restrict the version changes of the components, by
+	// component type
+	Transitions *VersionTransitions `json:"transitions,omitempty"`
+}
+
+type VersionTransitions struct {
+	CA      *TransitionPolicy `json:"ca,omitempty"`
+	Peer    *TransitionPolicy `json:"peer,omitempty"`
+	Orderer *TransitionPolicy `json:"orderer,omitempty"`
+}
+
+// TransitionPolicy restricts the version changes of a component type.
+// Versions are matched by prefix, e.g. "2.2" matches "2.2.10" and "2.2.10-1".
+// Without a policy downgrades must be forced and upgrades cannot skip a
+// major version.
+type TransitionPolicy struct {
+	// Paths are the versions that the versions can be upgraded to, the
+	// upgrades from versions without a path are not restricted
+	Paths []VersionPath `json:"paths,omitempty"`
+
+	// MajorGates are the minimum versions to upgrade from to a major
+	// version, by major version, e.g. "2": "1.4.9"
+	MajorGates map[string]string `json:"majorGates,omitempty"`
+
+	// AllowMajorSkip allows upgrades that skip a major version, e.g. from
+	// 1.x to 3.x
+	AllowMajorSkip bool `json:"allowMajorSkip,omitempty"`
+
+	// AllowDowngrade allows downgrades that are not forced
+	AllowDowngrade bool `json:"allowDowngrade,omitempty"`
+}
+
+type VersionPath struct {
+	From string   `json:"from"`
+	To   []string `json:"to"`
 }
 
 type VersionCA struct {
@@ -301,6 +376,19 @@ type OtherImages struct {
 type DeployerDefaults struct {
 	Storage   *Storage   `json:"storage"`
 	Resources *Resources `json:"resources"`
+
+	// Profiles are named sizing profiles, e.g. dev or production, that the
+	// create requests select with their profile. The volumes and containers
+	// a profile sets replace the defaults, the others keep the defaults.
+	Profiles map[string]*Profile `json:"profiles,omitempty"`
+	// DefaultProfile is used by the create requests without a profile
+	DefaultProfile string `json:"defaultProfile,omitempty"`
+}
+
+// Profile is a sizing profile of the components
+type Profile struct {
+	Storage   *Storage   `json:"storage,omitempty"`
+	Resources *Resources `json:"resources,omitempty"`
 }
 
 type Storage struct {
@@ -332,23 +420,173 @@ type Database struct {
 	CreateDB      bool               `json:"createdb"`
 }
 
+// Metrics configures the Prometheus metrics of the deployer, they are served
+// on /metrics to the authenticated principals allowed to read them
+type Metrics struct {
+	Enabled bool `json:"enabled"`
+}
+
+// Trace exporters
+const (
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+	TracingExporterFile   = "file"
+)
+
+// Tracing configures the OpenTelemetry traces of the requests, tracing is
+// disabled if no exporter is set
+type Tracing struct {
+	// Exporter is either otlp, to an OTLP/HTTP collector, stdout or file
+	Exporter string `json:"exporter"`
+	// Endpoint is the host and port of the collector, defaults to
+	// localhost:4318
+	Endpoint string `json:"endpoint,omitempty"`
+	// Insecure sends the spans to the collector without TLS
+	Insecure bool `json:"insecure,omitempty"`
+	// Headers are sent to the collector, e.g. to authenticate
+	Headers map[string]string `json:"headers,omitempty"`
+	// Path is the file the file exporter appends the spans to, as JSON
+	Path string `json:"path,omitempty"`
+	// SampleRatio is the ratio of the traces started by the deployer that
+	// are sampled, defaults to 1. Incoming sampled traces are always sampled.
+	SampleRatio *float64 `json:"sampleRatio,omitempty"`
+}
+
+// Audit log backends
+const (
+	AuditBackendCouchDB = "couchdb"
+	AuditBackendFile    = "file"
+)
+
+// Audit configures the audit log of the mutating API calls, auditing is
+// disabled if no backend is set
+type Audit struct {
+	// Backend is either couchdb, using the db connection url, or file
+	Backend string `json:"backend"`
+	// Database is the couchdb database, defaults to deployer_audit
+	Database string `json:"database,omitempty"`
+	// Path is the JSONL file of the file backend
+	Path string `json:"path,omitempty"`
+}
+
 // TLSConfig is to configure the tls server
 type TLSConfig struct {
 	Enabled       bool   `json:"enabled"`
 	ListenAddress string `json:"listenaddress"`
 	CertPath      string `json:"certpath"`
 	KeyPath       string `json:"keypath"`
+	// ClientCACertPath is the CA bundle used to verify client certificates
+	// for the mtls authentication method
+	ClientCACertPath string `json:"clientcacertpath,omitempty"`
 }
 
 // BasicAuth provides implementation to store basic auth info
 type BasicAuth struct {
 	Username string `json:"username"`
 	Password string `json:"password"`	// #nosec G117
+	// PasswordHash is the bcrypt hash of the password, used instead of Password
+	PasswordHash string `json:"passwordhash,omitempty"`
+}
+
+// Authentication methods
+const (
+	AuthMethodBasic       = "basic"
+	AuthMethodJWKS        = "jwks"
+	AuthMethodTokenReview = "tokenreview"
+	AuthMethodMTLS        = "mtls"
+)
+
+// Authentication configures the authenticators of the API
+type Authentication struct {
+	// Methods are tried in order until one authenticates the request,
+	// supported methods are basic, jwks, tokenreview and mtls. Defaults to basic.
+	Methods     []string         `json:"methods,omitempty"`
+	JWKS        *JWKSAuth        `json:"jwks,omitempty"`
+	TokenReview *TokenReviewAuth `json:"tokenreview,omitempty"`
+}
+
+// Uses returns true if the authentication method is enabled
+func (a *Authentication) Uses(method string) bool {
+	if a == nil {
+		return false
+	}
+	for _, m := range a.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// JWKSAuth configures the validation of bearer tokens against a local JWKS file
+type JWKSAuth struct {
+	Path     string `json:"path"`
+	Issuer   string `json:"issuer,omitempty"`
+	Audience string `json:"audience,omitempty"`
+	// UsernameClaim defaults to sub
+	UsernameClaim string `json:"usernameClaim,omitempty"`
+	// GroupsClaim defaults to groups
+	GroupsClaim string `json:"groupsClaim,omitempty"`
+}
+
+// TokenReviewAuth configures the validation of bearer tokens with the
+// kubernetes TokenReview API
+type TokenReviewAuth struct {
+	Audiences []string `json:"audiences,omitempty"`
+}
+
+// Predefined authorization roles
+const (
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+)
+
+// Authorization maps the authenticated principals to roles. When configured,
+// a request is only allowed if a rule of one of the roles of the principal
+// matches it, otherwise all authenticated requests are allowed.
+type Authorization struct {
+	// Roles defines custom roles, or replaces the rules of the predefined
+	// viewer, operator and admin roles
+	Roles    map[string][]PolicyRule `json:"roles,omitempty"`
+	Bindings []RoleBinding           `json:"bindings"`
+	// DefaultRole is the role of the principals not matching any binding,
+	// these principals are denied everything if not set
+	DefaultRole string `json:"defaultRole,omitempty"`
+}
+
+// RoleBinding grants a role to users and groups of users authenticated by
+// the method, the same name authenticated by another method is another user
+type RoleBinding struct {
+	Role string `json:"role"`
+	// Method is the authentication method of the users and groups, e.g.
+	// basic or jwks
+	Method string   `json:"method"`
+	Users  []string `json:"users,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+// PolicyRule allows the requests matching all of its fields, an empty field
+// or "*" matches everything
+type PolicyRule struct {
+	// Verbs are HTTP methods, e.g. GET or PATCH
+	Verbs []string `json:"verbs"`
+	// Resources are the first segment of the API path after the service
+	// instance, e.g. component, hsmconfig or mustgather, or metrics
+	Resources []string `json:"resources,omitempty"`
+	// Types are the component types, e.g. ca, peer or orderer
+	Types []string `json:"types,omitempty"`
+	// Sections are the component sections, e.g. actions or resources, the
+	// empty section "" is the whole component
+	Sections []string `json:"sections,omitempty"`
 }
 
 type Timeouts struct {
-	Deployment          int `json:"componentDeploy"`
-	APIServer           int `json:"apiServer"`
+	Deployment int `json:"componentDeploy"`
+	APIServer  int `json:"apiServer"`
+	// OrdererFailureCount is the number of times the CR of the first
+	// orderer node may be found missing before its deployment fails, it is
+	// checked every 500ms
 	OrdererFailureCount int `json:"ordererFailureCount"`
 }
 
@@ -19,6 +19,7 @@
 package config_test
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
 
@@ -31,6 +32,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
 )
 
 var (
@@ -195,6 +197,41 @@ var _ = Describe("Config", func() {
 			Expect(d.Auth.Password).To(Equal("password"))
 		})
 
+		It("returns an error if a service instance is mapped to an invalid namespace", func() {
+			cfg.Deployer.Instances = &config.Instances{Namespaces: map[string]string{"sid1": "Fabric_NS"}}
+			_, _, err := cfg.Init(cfg.Deployer)
+			Expect(err).To(MatchError(ContainSubstring("namespace 'Fabric_NS' of service instance 'sid1' not valid")))
+		})
+
+		It("returns an error if the namespace label is invalid", func() {
+			cfg.Deployer.Instances = &config.Instances{NamespaceLabel: "fabric/instance/id"}
+			_, _, err := cfg.Init(cfg.Deployer)
+			Expect(err).To(MatchError(ContainSubstring("namespace label 'fabric/instance/id' not valid")))
+		})
+
+		It("returns an error if a cluster name is reserved", func() {
+			cfg.Deployer.Clusters = map[string]*config.Cluster{"local": {Namespace: "fabric"}}
+			_, _, err := cfg.Init(cfg.Deployer)
+			Expect(err).To(MatchError(ContainSubstring("cluster name 'local' is reserved")))
+		})
+
+		It("returns an error if the namespace of a cluster is missing", func() {
+			cfg.Deployer.Clusters = map[string]*config.Cluster{"east": {KubeConfig: "/kube/east"}}
+			_, _, err := cfg.Init(cfg.Deployer)
+			Expect(err).To(MatchError(ContainSubstring("namespace of cluster 'east' is required")))
+		})
+
+		It("overrides the namespace, domain and cluster type for a cluster", func() {
+			cluster := &config.Cluster{Namespace: "fabric", Domain: "east.example.com", ClusterType: offering.OPENSHIFT}
+			cfg.Deployer.Clusters = map[string]*config.Cluster{"east": cluster}
+			d := cfg.Deployer.ForCluster(cluster)
+			Expect(d.Namespace).To(Equal("fabric"))
+			Expect(d.Domain).To(Equal("east.example.com"))
+			Expect(d.ClusterType).To(Equal(offering.OPENSHIFT))
+			Expect(d.Clusters).To(BeNil())
+			Expect(cfg.Deployer.Domain).To(Equal("0.0.0.0"))
+		})
+
 		It("overrides configuration file's database string from options", func() {
 			d, _, err := cfg.Init(cfg.Deployer)
 			Expect(err).NotTo(HaveOccurred())
@@ -443,5 +480,145 @@ var _ = Describe("Config", func() {
 			Expect(err).NotTo(BeNil())
 			Expect(err.Error()).To(Equal("no default resources set for Orderer.HSMDaemon"))
 		})
+
+		It("returns an error if the default profile does not exist", func() {
+			defaults.DefaultProfile = "production"
+			err := config.VerifyDefaultStorageAndResource(defaults)
+			Expect(err).To(MatchError("default profile 'production' not found"))
+		})
+	})
+
+	Context("profiles", func() {
+		BeforeEach(func() {
+			defaults.Storage.Peer.Peer.Size = "10Gi"
+			defaults.Storage.Peer.StateDB.Size = "10Gi"
+			defaults.Profiles = map[string]*config.Profile{
+				"production": {
+					Storage: &config.Storage{
+						Peer: &current.PeerStorages{Peer: &current.StorageSpec{Size: "100Gi"}},
+					},
+					Resources: &config.Resources{
+						Peer: &current.PeerResources{Peer: &corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+						}},
+					},
+				},
+			}
+		})
+
+		It("returns a copy of the defaults without a profile", func() {
+			profile, err := defaults.ForProfile("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(profile.Storage.Peer.Peer.Size).To(Equal("10Gi"))
+
+			profile.Storage.Peer.Peer.Size = "1Gi"
+			Expect(defaults.Storage.Peer.Peer.Size).To(Equal("10Gi"))
+		})
+
+		It("replaces the defaults with the volumes and containers of the profile", func() {
+			profile, err := defaults.ForProfile("production")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(profile.Storage.Peer.Peer.Size).To(Equal("100Gi"))
+			Expect(profile.Storage.Peer.StateDB.Size).To(Equal("10Gi"))
+			Expect(profile.Resources.Peer.Peer.Requests.Cpu().String()).To(Equal("2"))
+			Expect(profile.Resources.Peer.Init).To(Equal(defaults.Resources.Peer.Init))
+
+			profile.Storage.Peer.Peer.Size = "1Gi"
+			Expect(defaults.Profiles["production"].Storage.Peer.Peer.Size).To(Equal("100Gi"))
+		})
+
+		It("uses the default profile", func() {
+			defaults.DefaultProfile = "production"
+			profile, err := defaults.ForProfile("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(profile.Storage.Peer.Peer.Size).To(Equal("100Gi"))
+		})
+
+		It("returns an error if the profile does not exist", func() {
+			_, err := defaults.ForProfile("dev")
+			Expect(err).To(MatchError("profile 'dev' not found"))
+		})
+	})
+
+	Context("reload", func() {
+		var (
+			deployerConfig *config.DeployerSettingsConfig
+			watcher        *config.Watcher
+			applied        []config.Revision
+		)
+
+		writeConfig := func() {
+			content, err := yaml.Marshal(deployerConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ioutil.WriteFile(cfg.Options.ConfigPath, content, 0600)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			os.Setenv("DEPLOY_NAMESPACE", "default")
+			deployerConfig = &config.DeployerSettingsConfig{
+				ClusterType: offering.K8S,
+				Domain:      "0.0.0.0",
+				Defaults:    defaults,
+				Versions: &config.Versions{
+					CA:      map[string]config.VersionCA{"1.4.1": config.VersionCA{Default: true}},
+					Peer:    map[string]config.VersionPeer{"1.4.1": config.VersionPeer{Default: true}},
+					Orderer: map[string]config.VersionOrderer{"1.4.1": config.VersionOrderer{Default: true}},
+				},
+			}
+			cfg.Options.ConfigPath = filepath.Join(GinkgoT().TempDir(), "config.yaml")
+			writeConfig()
+
+			read, err := cfg.ReadConfigFile()
+			Expect(err).NotTo(HaveOccurred())
+			_, localConfig, err := cfg.Init(read)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(localConfig.Revision.Number).To(Equal(1))
+			Expect(localConfig.Revision.Hash).NotTo(BeEmpty())
+
+			applied = nil
+			watcher = config.NewWatcher(localConfig.Logger, cfg, 0, func(_ *config.DeployerSettingsConfig, revision config.Revision) {
+				applied = append(applied, revision)
+			})
+		})
+
+		AfterEach(func() {
+			os.Unsetenv("DEPLOY_NAMESPACE")
+		})
+
+		It("does not reload an unchanged file", func() {
+			Expect(watcher.Reload(false)).To(Succeed())
+			Expect(applied).To(BeEmpty())
+			Expect(cfg.Revision().Number).To(Equal(1))
+		})
+
+		It("reloads an unchanged file when forced", func() {
+			Expect(watcher.Reload(true)).To(Succeed())
+			Expect(applied).To(HaveLen(1))
+			Expect(applied[0].Number).To(Equal(2))
+			Expect(applied[0].Hash).To(Equal(cfg.LocalConfig.Revision.Hash))
+		})
+
+		It("reloads a changed file", func() {
+			deployerConfig.Versions.Peer["2.5.4"] = config.VersionPeer{}
+			writeConfig()
+
+			Expect(watcher.Reload(false)).To(Succeed())
+			Expect(applied).To(HaveLen(1))
+			Expect(applied[0].Number).To(Equal(2))
+			Expect(applied[0].Hash).NotTo(Equal(cfg.LocalConfig.Revision.Hash))
+			Expect(cfg.Deployer.Versions.Peer).To(HaveKey("2.5.4"))
+			Expect(cfg.Deployer.Namespace).To(Equal("default"))
+		})
+
+		It("keeps the configuration in use if the file is not valid", func() {
+			deployerConfig.Versions.Peer = map[string]config.VersionPeer{"2.5.4": config.VersionPeer{}}
+			writeConfig()
+
+			err := watcher.Reload(false)
+			Expect(err).To(MatchError("No default version specified for Peer's configuration"))
+			Expect(applied).To(BeEmpty())
+			Expect(cfg.Revision().Number).To(Equal(1))
+			Expect(cfg.Deployer.Versions.Peer).To(HaveKey("1.4.1"))
+		})
 	})
 })
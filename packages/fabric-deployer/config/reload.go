@@ -0,0 +1,144 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// DefaultReloadInterval is the interval at which the configuration file is
+// checked for changes
+const DefaultReloadInterval = 30 * time.Second
+
+// Revision identifies a loaded configuration
+type Revision struct {
+	Number   int       `json:"number"`
+	Hash     string    `json:"hash"`
+	LoadedAt time.Time `json:"loadedAt"`
+}
+
+// Revision returns the revision of the configuration in use
+func (c *Config) Revision() Revision {
+	return c.revision
+}
+
+// Changed returns true if the content of the configuration file differs from
+// the content that was last read
+func (c *Config) Changed() (bool, error) {
+	cfile, err := ioutil.ReadFile(c.Options.ConfigPath)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to read in configuration file from: '%s'", c.Options.ConfigPath)
+	}
+	return hash(cfile) != c.hash, nil
+}
+
+func (c *Config) nextRevision() Revision {
+	c.revision = Revision{
+		Number:   c.revision.Number + 1,
+		Hash:     c.hash,
+		LoadedAt: time.Now().UTC(),
+	}
+	return c.revision
+}
+
+func hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Watcher reloads the configuration file when its content changes or when the
+// process receives SIGHUP. A configuration that fails validation is logged and
+// the configuration in use is kept.
+type Watcher struct {
+	Config   *Config
+	Interval time.Duration
+	// Apply is called with every configuration that is successfully reloaded
+	Apply  func(*DeployerSettingsConfig, Revision)
+	Logger *zap.SugaredLogger
+}
+
+func NewWatcher(logger *zap.Logger, c *Config, interval time.Duration, apply func(*DeployerSettingsConfig, Revision)) *Watcher {
+	return &Watcher{
+		Config:   c,
+		Interval: interval,
+		Apply:    apply,
+		Logger:   logger.Sugar().Named("ConfigWatcher"),
+	}
+}
+
+// Run watches the configuration file until stop is closed. The file is polled
+// every Interval, a zero Interval only reloads it on SIGHUP.
+func (w *Watcher) Run(stop <-chan struct{}) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if w.Interval > 0 {
+		ticker := time.NewTicker(w.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-hup:
+			w.Logger.Infof("Received SIGHUP, reloading configuration file '%s'", w.Config.Options.ConfigPath)
+			_ = w.Reload(true)
+		case <-tick:
+			_ = w.Reload(false)
+		}
+	}
+}
+
+// Reload loads the configuration file and applies it. Unless force is set the
+// file is only loaded if its content changed.
+func (w *Watcher) Reload(force bool) error {
+	if !force {
+		changed, err := w.Config.Changed()
+		if err != nil {
+			w.Logger.Errorw("Error checking configuration file", "error", err)
+			return err
+		}
+		if !changed {
+			return nil
+		}
+	}
+
+	deployerConfig, revision, err := w.Config.Reload()
+	if err != nil {
+		w.Logger.Errorw("Configuration file is not valid, keeping the configuration in use", "revision", w.Config.Revision().Number, "error", err)
+		return err
+	}
+
+	w.Apply(deployerConfig, revision)
+	w.Logger.Infof("Loaded configuration revision %d", revision.Number)
+	return nil
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+)
+
+// accessEntry collects what the handlers know about a request for its access
+// log entry, e.g. the principal is only known once the request is
+// authenticated
+type accessEntry struct {
+	principal string
+	err       error
+	errorKind apierror.Kind
+}
+
+type accessEntryKey struct{}
+
+// accessEntryOf returns the access log entry of the request of the context,
+// nil if the request is not logged
+func accessEntryOf(ctx context.Context) *accessEntry {
+	entry, _ := ctx.Value(accessEntryKey{}).(*accessEntry)
+	return entry
+}
+
+// AccessLogMiddleware logs one structured entry per request once it is
+// served, including the requests that are not authenticated or not routed
+func (d *Deployer) AccessLogMiddleware(next http.Handler) http.Handler {
+	logger := d.LocalConfig.Logger.Sugar().Named("Access")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		entry := &accessEntry{}
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), accessEntryKey{}, entry)))
+		if recorder.statusCode == 0 {
+			recorder.statusCode = http.StatusOK
+		}
+
+		route := routeOf(r)
+
+		fields := []interface{}{
+			"requestID", RequestIDFrom(r.Context()),
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", route,
+			"status", recorder.statusCode,
+			"latency", time.Since(start),
+			"bytes", recorder.bytes,
+			"remoteAddr", r.RemoteAddr,
+			"principal", entry.principal,
+		}
+		if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.IsValid() {
+			fields = append(fields, "traceID", spanContext.TraceID().String())
+		}
+		if entry.err != nil {
+			fields = append(fields, "errorKind", string(entry.errorKind), "error", entry.err.Error())
+		}
+
+		if recorder.statusCode >= http.StatusInternalServerError {
+			logger.Errorw("Request failed", fields...)
+			return
+		}
+		logger.Infow("Request served", fields...)
+	})
+}
@@ -0,0 +1,242 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package apierror defines the errors returned by the deployer APIs. An
+// error has a kind, which sets the HTTP status code of the response, and a
+// machine readable code and field details that are returned to the client.
+package apierror
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Kind is the category of an error
+type Kind string
+
+const (
+	Validation         Kind = "validation"
+	Unauthorized       Kind = "unauthorized"
+	Forbidden          Kind = "forbidden"
+	NotFound           Kind = "not_found"
+	Conflict           Kind = "conflict"
+	PreconditionFailed Kind = "precondition_failed"
+	Unprocessable      Kind = "unprocessable"
+	TooManyRequests    Kind = "too_many_requests"
+	Upstream           Kind = "upstream"
+	UpstreamTimeout    Kind = "upstream_timeout"
+	Unavailable        Kind = "unavailable"
+	Internal           Kind = "internal"
+)
+
+// Codes of the errors returned by more than one API
+const (
+	CodeInvalidRequest           = "invalid_request"
+	CodeInvalidField             = "invalid_field"
+	CodeComponentTypeUnsupported = "component_type_not_supported"
+	CodeSectionUnsupported       = "section_not_supported"
+	CodeNotFound                 = "not_found"
+	CodeAlreadyExists            = "already_exists"
+	CodeConflict                 = "conflict"
+	CodeETagMismatch             = "etag_mismatch"
+	CodeInsufficientCapacity     = "insufficient_capacity"
+	CodeUnschedulable            = "unschedulable"
+	CodeUnauthenticated          = "unauthenticated"
+	CodeForbidden                = "forbidden"
+	CodeTooManyRequests          = "too_many_requests"
+	CodeUpstream                 = "upstream_error"
+	CodeTimeout                  = "timeout"
+	CodeUnavailable              = "unavailable"
+	CodeInternal                 = "internal_error"
+)
+
+var statusCodes = map[Kind]int{
+	Validation:         http.StatusBadRequest,
+	Unauthorized:       http.StatusUnauthorized,
+	Forbidden:          http.StatusForbidden,
+	NotFound:           http.StatusNotFound,
+	Conflict:           http.StatusConflict,
+	PreconditionFailed: http.StatusPreconditionFailed,
+	Unprocessable:      http.StatusUnprocessableEntity,
+	TooManyRequests:    http.StatusTooManyRequests,
+	Upstream:           http.StatusBadGateway,
+	UpstreamTimeout:    http.StatusGatewayTimeout,
+	Unavailable:        http.StatusServiceUnavailable,
+	Internal:           http.StatusInternalServerError,
+}
+
+// StatusCode returns the HTTP status code of errors of the kind
+func (k Kind) StatusCode() int {
+	if code, found := statusCodes[k]; found {
+		return code
+	}
+	return http.StatusInternalServerError
+}
+
+// FieldError is the problem with a field of the request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is an error of a kind, with a machine readable code and the fields of
+// the request that are not valid
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details []FieldError
+
+	// Err is the cause of the error, if any
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the HTTP status code of the error
+func (e *Error) StatusCode() int {
+	return e.Kind.StatusCode()
+}
+
+// WithDetails adds the problems with fields of the request to the error
+func (e *Error) WithDetails(details ...FieldError) *Error {
+	e.Details = append(e.Details, details...)
+	return e
+}
+
+// New returns an error of the kind with the code and formatted message
+func New(kind Kind, code, format string, args ...interface{}) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// Wrap returns an error of the kind caused by err
+func Wrap(err error, kind Kind, code, format string, args ...interface{}) *Error {
+	e := New(kind, code, format, args...)
+	e.Err = err
+	return e
+}
+
+// InvalidField returns a validation error for a field of the request, the
+// message of the error is the message of the field
+func InvalidField(field, format string, args ...interface{}) *Error {
+	e := New(Validation, CodeInvalidField, format, args...)
+	return e.WithDetails(FieldError{Field: field, Message: e.Message})
+}
+
+// UnsupportedComponentType returns the error for requests to a type of
+// component that is not ca, peer or orderer
+func UnsupportedComponentType(componentType string) *Error {
+	return New(Validation, CodeComponentTypeUnsupported, "component type '%s' not supported", componentType)
+}
+
+// UnsupportedSection returns the error for requests to a section of a
+// component that does not exist
+func UnsupportedSection(section string) *Error {
+	return New(Validation, CodeSectionUnsupported, "section '%s' not supported", section)
+}
+
+// From returns the typed error of err. Errors from the Kubernetes API are
+// typed from their reason, timeouts are upstream timeouts and any other error
+// is an internal error.
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	var status k8serrors.APIStatus
+	if errors.As(err, &status) {
+		return fromStatus(err, status.Status())
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Wrap(err, UpstreamTimeout, CodeTimeout, "timed out")
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return Wrap(err, UpstreamTimeout, CodeTimeout, "timed out")
+	}
+
+	return Wrap(err, Internal, CodeInternal, "internal error")
+}
+
+func fromStatus(err error, status metav1.Status) *Error {
+	var e *Error
+	switch status.Reason {
+	case metav1.StatusReasonNotFound:
+		e = Wrap(err, NotFound, CodeNotFound, "not found")
+	case metav1.StatusReasonAlreadyExists:
+		e = Wrap(err, Conflict, CodeAlreadyExists, "already exists")
+	case metav1.StatusReasonConflict:
+		e = Wrap(err, Conflict, CodeConflict, "conflict")
+	case metav1.StatusReasonInvalid, metav1.StatusReasonBadRequest:
+		e = Wrap(err, Validation, CodeInvalidRequest, "invalid request")
+	case metav1.StatusReasonUnauthorized:
+		e = Wrap(err, Unauthorized, CodeUnauthenticated, "unauthorized")
+	case metav1.StatusReasonForbidden:
+		e = Wrap(err, Forbidden, CodeForbidden, "forbidden")
+	case metav1.StatusReasonTimeout, metav1.StatusReasonServerTimeout:
+		e = Wrap(err, UpstreamTimeout, CodeTimeout, "timed out")
+	case metav1.StatusReasonTooManyRequests:
+		e = Wrap(err, TooManyRequests, CodeTooManyRequests, "too many requests")
+	case metav1.StatusReasonServiceUnavailable:
+		e = Wrap(err, Unavailable, CodeUnavailable, "unavailable")
+	default:
+		e = Wrap(err, Upstream, CodeUpstream, "kubernetes API error")
+	}
+
+	if status.Details != nil {
+		for _, cause := range status.Details.Causes {
+			e.Details = append(e.Details, FieldError{Field: cause.Field, Message: cause.Message})
+		}
+	}
+	return e
+}
+
+// StatusCode returns the HTTP status code of the response for err
+func StatusCode(err error) int {
+	return From(err).StatusCode()
+}
+
+// KindOf returns the kind of err
+func KindOf(err error) Kind {
+	return From(err).Kind
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apierror_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+)
+
+var _ = Describe("API errors", func() {
+	var peers = schema.GroupResource{Group: "ibp.com", Resource: "ibppeers"}
+
+	It("returns the message and cause", func() {
+		err := apierror.Wrap(errors.New("unexpected end of JSON input"), apierror.Validation, apierror.CodeInvalidRequest, "failed to unmarshal, invalid request")
+		Expect(err.Error()).To(Equal("failed to unmarshal, invalid request: unexpected end of JSON input"))
+		Expect(err.StatusCode()).To(Equal(http.StatusBadRequest))
+	})
+
+	It("returns the field of invalid field errors", func() {
+		err := apierror.InvalidField("version", "version not valid")
+		Expect(err.Error()).To(Equal("version not valid"))
+		Expect(err.Code).To(Equal(apierror.CodeInvalidField))
+		Expect(err.Details).To(Equal([]apierror.FieldError{{Field: "version", Message: "version not valid"}}))
+	})
+
+	It("keeps the typed error when wrapped", func() {
+		err := pkgerrors.Wrap(apierror.UnsupportedSection("bad"), "failed to update")
+		Expect(apierror.From(err).Code).To(Equal(apierror.CodeSectionUnsupported))
+		Expect(apierror.StatusCode(err)).To(Equal(http.StatusBadRequest))
+	})
+
+	It("returns an internal error for untyped errors", func() {
+		err := errors.New("update failed")
+		Expect(apierror.KindOf(err)).To(Equal(apierror.Internal))
+		Expect(apierror.StatusCode(err)).To(Equal(http.StatusInternalServerError))
+	})
+
+	It("returns an upstream timeout if the deadline is exceeded", func() {
+		err := pkgerrors.Wrap(context.DeadlineExceeded, "failed waiting for cr")
+		Expect(apierror.StatusCode(err)).To(Equal(http.StatusGatewayTimeout))
+	})
+
+	DescribeTable("maps the reasons of kubernetes errors",
+		func(err error, kind apierror.Kind, status int) {
+			err = pkgerrors.Wrap(err, "failed to get cr")
+			Expect(apierror.KindOf(err)).To(Equal(kind))
+			Expect(apierror.StatusCode(err)).To(Equal(status))
+		},
+		Entry("not found", k8serrors.NewNotFound(peers, "peer1"), apierror.NotFound, http.StatusNotFound),
+		Entry("already exists", k8serrors.NewAlreadyExists(peers, "peer1"), apierror.Conflict, http.StatusConflict),
+		Entry("conflict", k8serrors.NewConflict(peers, "peer1", errors.New("object has been modified")), apierror.Conflict, http.StatusConflict),
+		Entry("bad request", k8serrors.NewBadRequest("bad"), apierror.Validation, http.StatusBadRequest),
+		Entry("forbidden", k8serrors.NewForbidden(peers, "peer1", errors.New("denied")), apierror.Forbidden, http.StatusForbidden),
+		Entry("unauthorized", k8serrors.NewUnauthorized("no token"), apierror.Unauthorized, http.StatusUnauthorized),
+		Entry("timeout", k8serrors.NewTimeoutError("timed out", 1), apierror.UpstreamTimeout, http.StatusGatewayTimeout),
+		Entry("server timeout", k8serrors.NewServerTimeout(peers, "get", 1), apierror.UpstreamTimeout, http.StatusGatewayTimeout),
+		Entry("too many requests", k8serrors.NewTooManyRequests("slow down", 1), apierror.TooManyRequests, http.StatusTooManyRequests),
+		Entry("service unavailable", k8serrors.NewServiceUnavailable("down"), apierror.Unavailable, http.StatusServiceUnavailable),
+		Entry("internal error", k8serrors.NewInternalError(errors.New("boom")), apierror.Upstream, http.StatusBadGateway),
+	)
+
+	It("returns the causes of invalid kubernetes errors as field details", func() {
+		err := k8serrors.NewInvalid(schema.GroupKind{Group: "ibp.com", Kind: "IBPPeer"}, "peer1", field.ErrorList{
+			field.Invalid(field.NewPath("spec", "replicas"), 2, "must be 0 or 1"),
+		})
+
+		apiErr := apierror.From(err)
+		Expect(apiErr.Kind).To(Equal(apierror.Validation))
+		Expect(apiErr.Details).To(Equal([]apierror.FieldError{
+			{Field: "spec.replicas", Message: "Invalid value: 2: must be 0 or 1"},
+		}))
+	})
+})
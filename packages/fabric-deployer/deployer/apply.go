@@ -0,0 +1,209 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apply"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/ca"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/peer"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/operations"
+)
+
+// ApplyEndpoint returns an endpoint type that is responsible for handling
+// applying a manifest of components
+func (d *Deployer) ApplyEndpoint() func(http.ResponseWriter, *http.Request) {
+	return NewEndpoint(d.Apply, d.LocalConfig.Logger).ServeHTTP
+}
+
+// Apply creates, updates and deletes the components of the service instance
+// to match the manifest of the request body, in YAML or JSON. On dry runs the
+// plan is returned without being executed.
+func (d *Deployer) Apply(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	sID := chi.URLParam(r, "serviceInstanceID")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, 0, errors.New("failed to ready request body")
+	}
+
+	manifest := &apply.Manifest{}
+	err = yaml.Unmarshal(body, manifest)
+	if err != nil {
+		return nil, 0, apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "failed to unmarshal manifest")
+	}
+
+	applier := apply.New(d.LocalConfig.Logger, applyTargets(d.clusterComponents(r), d.namespace(r)))
+	plan, err := applier.Plan(sID, manifest)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if isDryRun(r) {
+		plan.DryRun = true
+		return plan, http.StatusOK, nil
+	}
+
+	if !isAsyncRequest(r) {
+		return d.apply(applier, sID, plan, nil)
+	}
+
+	op := d.Operations.Start(sID, operations.APPLY, "manifest", "")
+	go func() {
+		resp, statusCode, err := d.apply(applier, sID, plan, op.UpdateNode)
+		if err != nil {
+			d.Logger.Errorf("Operation '%s' to apply manifest failed: %s", op.ID, err)
+		}
+		op.Finish(resp, statusCode, err)
+	}()
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v3/instance/%s/operations/%s", sID, op.ID))
+	w.Header().Set("Preference-Applied", "respond-async")
+	return op.Snapshot(), http.StatusAccepted, nil
+}
+
+// apply executes the plan, the results of the actions are returned with the
+// status of the first action that failed
+func (d *Deployer) apply(applier *apply.Applier, sID string, plan *apply.Response, progress common.NodeProgressFunc) (interface{}, int, error) {
+	err := applier.Execute(sID, plan, progress)
+	if err != nil {
+		return plan, apierror.StatusCode(err), nil
+	}
+
+	return plan, http.StatusOK, nil
+}
+
+// applyTargets returns the targets of the components of the namespace
+func applyTargets(components *Components, namespace string) map[string]apply.Target {
+	domain := components.Config.Domain
+	return map[string]apply.Target{
+		apply.TypeCA:      &caTarget{domain: domain, namespace: namespace, ca: components.CA},
+		apply.TypePeer:    &peerTarget{domain: domain, namespace: namespace, peer: components.Peer},
+		apply.TypeOrderer: &ordererTarget{domain: domain, namespace: namespace, orderer: components.Orderer},
+	}
+}
+
+type caTarget struct {
+	domain    string
+	namespace string
+	ca        *ca.CA
+}
+
+func (t *caTarget) List(sID string) ([]string, error) {
+	return t.ca.ListLabelled(sID, t.namespace)
+}
+
+func (t *caTarget) Plan(sID, name string, spec []byte) (bool, []common.Change, error) {
+	return t.ca.PlanApply(sID, name, t.namespace, spec)
+}
+
+func (t *caTarget) Create(sID, name string, spec []byte) error {
+	_, _, err := t.ca.CreateCR(t.domain, sID, name, t.namespace, spec)
+	return err
+}
+
+func (t *caTarget) Update(sID, name string, spec []byte) error {
+	return t.ca.ApplyCR(sID, name, t.namespace, spec)
+}
+
+func (t *caTarget) Delete(sID, name string) error {
+	_, _, err := t.ca.DeleteCR(sID, name, t.namespace, nil)
+	return err
+}
+
+type peerTarget struct {
+	domain    string
+	namespace string
+	peer      *peer.Peer
+}
+
+func (t *peerTarget) List(sID string) ([]string, error) {
+	return t.peer.ListLabelled(sID, t.namespace)
+}
+
+func (t *peerTarget) Plan(sID, name string, spec []byte) (bool, []common.Change, error) {
+	return t.peer.PlanApply(sID, name, t.namespace, spec)
+}
+
+func (t *peerTarget) Create(sID, name string, spec []byte) error {
+	_, _, err := t.peer.CreateCR(t.domain, sID, name, t.namespace, spec)
+	return err
+}
+
+func (t *peerTarget) Update(sID, name string, spec []byte) error {
+	return t.peer.ApplyCR(sID, name, t.namespace, spec)
+}
+
+func (t *peerTarget) Delete(sID, name string) error {
+	_, _, err := t.peer.DeleteCR(sID, name, t.namespace, nil)
+	return err
+}
+
+// ordererTarget manages orderer clusters, the nodes are deleted with their
+// cluster and are not listed
+type ordererTarget struct {
+	domain    string
+	namespace string
+	orderer   *orderer.Orderer
+}
+
+func (t *ordererTarget) List(sID string) ([]string, error) {
+	return t.orderer.ListLabelled(sID, t.namespace)
+}
+
+func (t *ordererTarget) Plan(sID, name string, spec []byte) (bool, []common.Change, error) {
+	return t.orderer.PlanApply(sID, name, t.namespace, spec)
+}
+
+func (t *ordererTarget) Create(sID, name string, spec []byte) error {
+	_, _, err := t.orderer.CreateCR(t.domain, sID, name, t.namespace, spec)
+	return err
+}
+
+func (t *ordererTarget) Update(sID, name string, spec []byte) error {
+	return t.orderer.ApplyCR(sID, name, t.namespace, spec)
+}
+
+func (t *ordererTarget) Delete(sID, name string) error {
+	orderers, _, err := t.orderer.GetAllCR(sID, t.namespace)
+	if err != nil {
+		return err
+	}
+	for _, node := range orderers {
+		if node.Parent != nil && node.Parent.Name == name {
+			_, _, err = t.orderer.DeleteCR(sID, node.Name, t.namespace, nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, _, err = t.orderer.DeleteCR(sID, name, t.namespace, nil)
+	return err
+}
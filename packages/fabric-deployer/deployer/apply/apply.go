@@ -0,0 +1,316 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package apply reconciles the components of a service instance with a
+// declarative manifest of the desired CAs, peers and orderers.
+package apply
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	caapi "github.com/IBM-Blockchain/fabric-deployer/deployer/components/ca/api"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	ordererapi "github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer/api"
+	peerapi "github.com/IBM-Blockchain/fabric-deployer/deployer/components/peer/api"
+)
+
+// Component types, in the order they are created and updated. They are
+// deleted in the reverse order.
+const (
+	TypeCA      = "ca"
+	TypePeer    = "peer"
+	TypeOrderer = "orderer"
+)
+
+var typeOrder = []string{TypeCA, TypePeer, TypeOrderer}
+
+// Actions of a plan
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+	ActionNone   = "none"
+)
+
+// Statuses of the executed actions
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped"
+)
+
+// Manifest is the desired state of the components of a service instance. The
+// specs are the bodies of the create requests of the components.
+type Manifest struct {
+	CAs      []CA      `json:"cas,omitempty"`
+	Peers    []Peer    `json:"peers,omitempty"`
+	Orderers []Orderer `json:"orderers,omitempty"`
+
+	// Prune deletes the components that are not in the manifest
+	Prune bool `json:"prune,omitempty"`
+}
+
+type CA struct {
+	Name string               `json:"name"`
+	Spec *caapi.CreateRequest `json:"spec,omitempty"`
+}
+
+type Peer struct {
+	Name string                 `json:"name"`
+	Spec *peerapi.CreateRequest `json:"spec,omitempty"`
+}
+
+// Orderer is an orderer cluster, its nodes are named after the cluster
+type Orderer struct {
+	Name string                    `json:"name"`
+	Spec *ordererapi.CreateRequest `json:"spec,omitempty"`
+}
+
+// Action is a step of the plan and its result once executed. Diff lists the
+// changes of the CR of an update.
+type Action struct {
+	Type   string          `json:"type"`
+	Name   string          `json:"name"`
+	Action string          `json:"action"`
+	Diff   []common.Change `json:"diff,omitempty"`
+	Status string          `json:"status,omitempty"`
+	Error  string          `json:"error,omitempty"`
+
+	spec []byte
+}
+
+// Response is the plan of a manifest with the results of its actions, the
+// actions are not executed on dry runs
+type Response struct {
+	DryRun  bool      `json:"dryRun,omitempty"`
+	Actions []*Action `json:"actions"`
+}
+
+// Changes returns the number of actions that change a component
+func (r *Response) Changes() int {
+	changes := 0
+	for _, action := range r.Actions {
+		if action.Action != ActionNone {
+			changes++
+		}
+	}
+	return changes
+}
+
+//go:generate counterfeiter -o mocks/target.go -fake-name Target . Target
+
+// Target manages the components of a type
+type Target interface {
+	// List returns the names of the components labelled with the service
+	// instance, the components that can be pruned
+	List(sID string) ([]string, error)
+	// Plan returns the changes that the spec would make to the component,
+	// found is false if the component does not exist
+	Plan(sID, name string, spec []byte) (found bool, diff []common.Change, err error)
+	Create(sID, name string, spec []byte) error
+	Update(sID, name string, spec []byte) error
+	Delete(sID, name string) error
+}
+
+type Applier struct {
+	Logger  *zap.SugaredLogger
+	Targets map[string]Target
+}
+
+func New(logger *zap.Logger, targets map[string]Target) *Applier {
+	return &Applier{
+		Logger:  logger.Sugar().Named("Apply"),
+		Targets: targets,
+	}
+}
+
+type component struct {
+	Type string
+	Name string
+	Spec interface{}
+}
+
+// components returns the components of the manifest in the order they are
+// applied
+func (m *Manifest) components() ([]component, error) {
+	components := []component{}
+	names := map[string]bool{}
+	add := func(field string, i int, componentType, name string, spec interface{}) error {
+		if name == "" {
+			return apierror.InvalidField(fmt.Sprintf("%s[%d].name", field, i), "%s[%d].name is required", field, i)
+		}
+		if names[componentType+"/"+name] {
+			return apierror.InvalidField(fmt.Sprintf("%s[%d].name", field, i), "%s '%s' is listed more than once", componentType, name)
+		}
+		names[componentType+"/"+name] = true
+		components = append(components, component{Type: componentType, Name: name, Spec: spec})
+		return nil
+	}
+
+	for i, ca := range m.CAs {
+		if err := add("cas", i, TypeCA, ca.Name, ca.Spec); err != nil {
+			return nil, err
+		}
+	}
+	for i, peer := range m.Peers {
+		if err := add("peers", i, TypePeer, peer.Name, peer.Spec); err != nil {
+			return nil, err
+		}
+	}
+	for i, orderer := range m.Orderers {
+		if err := add("orderers", i, TypeOrderer, orderer.Name, orderer.Spec); err != nil {
+			return nil, err
+		}
+	}
+
+	return components, nil
+}
+
+// Plan returns the actions that reconcile the components of the service
+// instance with the manifest. Components that are up to date have no action,
+// so planning the manifest again once applied only returns ActionNone.
+func (a *Applier) Plan(sID string, manifest *Manifest) (*Response, error) {
+	components, err := manifest.components()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &Response{Actions: []*Action{}}
+	for _, c := range components {
+		target, found := a.Targets[c.Type]
+		if !found {
+			return nil, apierror.UnsupportedComponentType(c.Type)
+		}
+
+		spec := []byte{}
+		if c.Spec != nil && !reflect.ValueOf(c.Spec).IsNil() {
+			spec, err = json.Marshal(c.Spec)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to marshal spec of %s '%s'", c.Type, c.Name)
+			}
+		}
+
+		exists, diff, err := target.Plan(sID, c.Name, spec)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to plan %s '%s'", c.Type, c.Name)
+		}
+
+		action := &Action{Type: c.Type, Name: c.Name, Action: ActionNone, spec: spec}
+		switch {
+		case !exists:
+			action.Action = ActionCreate
+		case len(diff) > 0:
+			action.Action = ActionUpdate
+			action.Diff = diff
+		}
+		response.Actions = append(response.Actions, action)
+	}
+
+	if manifest.Prune {
+		for i := len(typeOrder) - 1; i >= 0; i-- {
+			componentType := typeOrder[i]
+			names, err := a.Targets[componentType].List(sID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to list the %s components", componentType)
+			}
+			for _, name := range names {
+				if !contains(components, componentType, name) {
+					response.Actions = append(response.Actions, &Action{Type: componentType, Name: name, Action: ActionDelete})
+				}
+			}
+		}
+	}
+
+	return response, nil
+}
+
+// Execute executes the actions of the plan in order, progress is called with
+// the state of each component that is changed. Nodes are skipped if a CA
+// failed to apply, and deletes are skipped if any action failed. The error
+// of the first action that failed is returned.
+func (a *Applier) Execute(sID string, plan *Response, progress common.NodeProgressFunc) error {
+	if progress == nil {
+		progress = func(string, int, string, error) {}
+	}
+
+	var firstErr error
+	caFailed := false
+	total := plan.Changes()
+	for _, action := range plan.Actions {
+		if action.Action == ActionNone {
+			continue
+		}
+
+		if (caFailed && action.Type != TypeCA) || (firstErr != nil && action.Action == ActionDelete) {
+			action.Status = StatusSkipped
+			continue
+		}
+
+		// components of different types can have the same name
+		node := action.Type + "/" + action.Name
+		progress(node, total, common.NodeStateDeploying, nil)
+		err := a.execute(sID, action)
+		if err != nil {
+			a.Logger.Errorf("Failed to %s %s '%s': %s", action.Action, action.Type, action.Name, err)
+			progress(node, total, common.NodeStateFailed, err)
+			action.Status = StatusFailed
+			action.Error = err.Error()
+			if action.Type == TypeCA {
+				caFailed = true
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		progress(node, total, common.NodeStateDeployed, nil)
+		action.Status = StatusSucceeded
+	}
+
+	return firstErr
+}
+
+func (a *Applier) execute(sID string, action *Action) error {
+	target := a.Targets[action.Type]
+
+	switch action.Action {
+	case ActionCreate:
+		return target.Create(sID, action.Name, action.spec)
+	case ActionUpdate:
+		return target.Update(sID, action.Name, action.spec)
+	case ActionDelete:
+		return target.Delete(sID, action.Name)
+	}
+	return nil
+}
+
+func contains(components []component, componentType, name string) bool {
+	for _, c := range components {
+		if c.Type == componentType && c.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apply"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apply/mocks"
+	caapi "github.com/IBM-Blockchain/fabric-deployer/deployer/components/ca/api"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	peerapi "github.com/IBM-Blockchain/fabric-deployer/deployer/components/peer/api"
+)
+
+var _ = Describe("Apply", func() {
+	var (
+		applier  *apply.Applier
+		cas      *mocks.Target
+		peers    *mocks.Target
+		orderers *mocks.Target
+		manifest *apply.Manifest
+	)
+
+	BeforeEach(func() {
+		cas = &mocks.Target{}
+		peers = &mocks.Target{}
+		orderers = &mocks.Target{}
+		applier = apply.New(zap.NewNop(), map[string]apply.Target{
+			apply.TypeCA:      cas,
+			apply.TypePeer:    peers,
+			apply.TypeOrderer: orderers,
+		})
+
+		manifest = &apply.Manifest{
+			CAs:   []apply.CA{{Name: "ca1", Spec: &caapi.CreateRequest{}}},
+			Peers: []apply.Peer{{Name: "peer1", Spec: &peerapi.CreateRequest{}}, {Name: "peer2"}},
+		}
+
+		peers.PlanStub = func(sID, name string, spec []byte) (bool, []common.Change, error) {
+			if name == "peer2" {
+				return true, []common.Change{{Op: "replace", Path: "/spec/version"}}, nil
+			}
+			return true, nil, nil
+		}
+	})
+
+	Context("plan", func() {
+		It("creates missing components, updates changed ones and leaves the others", func() {
+			plan, err := applier.Plan("sid", manifest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(plan.Actions).To(HaveLen(3))
+			Expect(*plan.Actions[0]).To(MatchFields(apply.TypeCA, "ca1", apply.ActionCreate))
+			Expect(*plan.Actions[1]).To(MatchFields(apply.TypePeer, "peer1", apply.ActionNone))
+			Expect(*plan.Actions[2]).To(MatchFields(apply.TypePeer, "peer2", apply.ActionUpdate))
+			Expect(plan.Actions[2].Diff).To(HaveLen(1))
+			Expect(plan.Changes()).To(Equal(2))
+			Expect(cas.ListCallCount()).To(Equal(0))
+
+			sID, name, spec := peers.PlanArgsForCall(0)
+			Expect(sID).To(Equal("sid"))
+			Expect(name).To(Equal("peer1"))
+			Expect(string(spec)).To(Equal("{}"))
+			_, _, spec = peers.PlanArgsForCall(1)
+			Expect(spec).To(BeEmpty())
+		})
+
+		It("deletes the components missing from the manifest when pruning", func() {
+			manifest.Prune = true
+			cas.ListReturns([]string{"ca1", "ca2"}, nil)
+			orderers.ListReturns([]string{"os1"}, nil)
+
+			plan, err := applier.Plan("sid", manifest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(plan.Actions).To(HaveLen(5))
+			Expect(*plan.Actions[3]).To(MatchFields(apply.TypeOrderer, "os1", apply.ActionDelete))
+			Expect(*plan.Actions[4]).To(MatchFields(apply.TypeCA, "ca2", apply.ActionDelete))
+		})
+
+		It("rejects components without a name", func() {
+			manifest.Peers[1].Name = ""
+			_, err := applier.Plan("sid", manifest)
+			Expect(apierror.From(err).Code).To(Equal(apierror.CodeInvalidField))
+			Expect(apierror.From(err).Details[0].Field).To(Equal("peers[1].name"))
+		})
+
+		It("rejects components listed twice", func() {
+			manifest.Peers[1].Name = "peer1"
+			_, err := applier.Plan("sid", manifest)
+			Expect(err).To(MatchError("peer 'peer1' is listed more than once"))
+		})
+
+		It("returns the errors of the targets", func() {
+			cas.PlanReturns(false, nil, errors.New("plan error"))
+			_, err := applier.Plan("sid", manifest)
+			Expect(err).To(MatchError("failed to plan ca 'ca1': plan error"))
+		})
+	})
+
+	Context("execute", func() {
+		var plan *apply.Response
+
+		BeforeEach(func() {
+			manifest.Prune = true
+			cas.ListReturns([]string{"ca1", "ca2"}, nil)
+
+			var err error
+			plan, err = applier.Plan("sid", manifest)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("executes the actions in order and reports progress", func() {
+			nodes := []string{}
+			progress := func(nodeName string, total int, state string, err error) {
+				Expect(total).To(Equal(3))
+				nodes = append(nodes, nodeName+" "+state)
+			}
+
+			Expect(applier.Execute("sid", plan, progress)).To(Succeed())
+			Expect(cas.CreateCallCount()).To(Equal(1))
+			Expect(peers.UpdateCallCount()).To(Equal(1))
+			Expect(cas.DeleteCallCount()).To(Equal(1))
+			Expect(nodes).To(Equal([]string{
+				"ca/ca1 deploying", "ca/ca1 deployed",
+				"peer/peer2 deploying", "peer/peer2 deployed",
+				"ca/ca2 deploying", "ca/ca2 deployed",
+			}))
+
+			sID, name, _ := cas.CreateArgsForCall(0)
+			Expect(sID).To(Equal("sid"))
+			Expect(name).To(Equal("ca1"))
+			Expect(plan.Actions[0].Status).To(Equal(apply.StatusSucceeded))
+			Expect(plan.Actions[1].Status).To(BeEmpty())
+		})
+
+		It("skips the nodes and deletes when a CA fails", func() {
+			cas.CreateReturns(apierror.New(apierror.Conflict, apierror.CodeAlreadyExists, "exists"))
+
+			err := applier.Execute("sid", plan, nil)
+			Expect(apierror.StatusCode(err)).To(Equal(409))
+			Expect(plan.Actions[0].Status).To(Equal(apply.StatusFailed))
+			Expect(plan.Actions[0].Error).To(Equal("exists"))
+			Expect(plan.Actions[2].Status).To(Equal(apply.StatusSkipped))
+			Expect(plan.Actions[3].Status).To(Equal(apply.StatusSkipped))
+			Expect(peers.UpdateCallCount()).To(Equal(0))
+			Expect(cas.DeleteCallCount()).To(Equal(0))
+		})
+
+		It("skips the deletes when a node fails", func() {
+			peers.UpdateReturns(errors.New("update error"))
+
+			Expect(applier.Execute("sid", plan, nil)).To(MatchError("update error"))
+			Expect(plan.Actions[0].Status).To(Equal(apply.StatusSucceeded))
+			Expect(plan.Actions[2].Status).To(Equal(apply.StatusFailed))
+			Expect(plan.Actions[3].Status).To(Equal(apply.StatusSkipped))
+		})
+	})
+})
+
+// MatchFields matches the type, name and action of an action
+func MatchFields(componentType, name, action string) OmegaMatcher {
+	return And(
+		WithTransform(func(a apply.Action) string { return a.Type }, Equal(componentType)),
+		WithTransform(func(a apply.Action) string { return a.Name }, Equal(name)),
+		WithTransform(func(a apply.Action) string { return a.Action }, Equal(action)),
+	)
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/audit"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/auth"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
+)
+
+const auditRecordTimeout = 10 * time.Second
+
+// statusRecorder keeps the status code and the size of the body written by
+// the handler
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	// bytes is the size of the body written by the handler
+	bytes int
+}
+
+func (s *statusRecorder) WriteHeader(statusCode int) {
+	if s.statusCode == 0 {
+		s.statusCode = statusCode
+	}
+	s.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.statusCode == 0 {
+		s.statusCode = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += n
+	return n, err
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// Flush flushes the underlying writer, so that the streams, e.g. of events,
+// work through the middlewares recording the status
+func (s *statusRecorder) Flush() {
+	if s.statusCode == 0 {
+		s.statusCode = http.StatusOK
+	}
+	_ = http.NewResponseController(s.ResponseWriter).Flush()
+}
+
+// routeOf returns the route pattern matched by the router, or "unmatched".
+// The route context is filled in by the router once the request is served.
+func routeOf(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return "unmatched"
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// AuditMiddleware records the mutating requests in the audit log, including
+// the ones denied by the authorization. It must be used on routed requests.
+func (d *Deployer) AuditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if d.Audit == nil || !isMutating(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var body []byte
+		if r.Body != nil {
+			var err error
+			body, err = io.ReadAll(r.Body)
+			if err != nil {
+				d.Logger.Errorf("Failed to read request body of %s %s: %s", r.Method, r.URL.Path, err)
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+		if recorder.statusCode == 0 {
+			recorder.statusCode = http.StatusOK
+		}
+
+		request := accessRequest(r)
+		entry := &audit.Entry{
+			ID:                util.NewID(),
+			RequestID:         RequestIDFrom(r.Context()),
+			Timestamp:         time.Now().UTC(),
+			Verb:              r.Method,
+			Path:              r.URL.Path,
+			ServiceInstanceID: chi.URLParam(r, "serviceInstanceID"),
+			Resource:          request.Resource,
+			ComponentType:     request.Type,
+			ComponentName:     chi.URLParam(r, "componentName"),
+			Section:           request.Section,
+			Body:              audit.Redact(body),
+			StatusCode:        recorder.statusCode,
+		}
+		if principal, found := auth.PrincipalFrom(r.Context()); found {
+			entry.Principal = principal.Name
+			entry.AuthMethod = principal.Method
+		}
+
+		// the request context may be canceled once the response is written
+		ctx, cancel := context.WithTimeout(context.Background(), auditRecordTimeout)
+		defer cancel()
+		err := d.Audit.Record(ctx, entry)
+		if err != nil {
+			d.Logger.Errorf("Failed to record audit entry of %s %s by '%s': %s", r.Method, r.URL.Path, entry.Principal, err)
+		}
+	})
+}
+
+// AuditEndpoint returns an endpoint type that is responsible for handling
+// querying the audit log
+func (d *Deployer) AuditEndpoint() func(http.ResponseWriter, *http.Request) {
+	return NewEndpoint(d.GetAudit, d.LocalConfig.Logger).ServeHTTP
+}
+
+// GetAudit returns the audit entries of the service instance, newest first
+func (d *Deployer) GetAudit(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	if d.Audit == nil {
+		return nil, http.StatusNotFound, apierror.New(apierror.NotFound, apierror.CodeNotFound, "audit log not found, auditing is not enabled")
+	}
+
+	query := r.URL.Query()
+	filter := &audit.Filter{
+		ServiceInstanceID: chi.URLParam(r, "serviceInstanceID"),
+		Principal:         query.Get("principal"),
+		Verb:              query.Get("verb"),
+		Resource:          query.Get("resource"),
+		ComponentType:     query.Get("type"),
+		ComponentName:     query.Get("component"),
+	}
+
+	var err error
+	if since := query.Get("since"); since != "" {
+		filter.Since, err = time.Parse(time.RFC3339, since)
+		if err != nil {
+			return nil, http.StatusBadRequest, apierror.InvalidField("since", "invalid since timestamp '%s'", since)
+		}
+	}
+	if until := query.Get("until"); until != "" {
+		filter.Until, err = time.Parse(time.RFC3339, until)
+		if err != nil {
+			return nil, http.StatusBadRequest, apierror.InvalidField("until", "invalid until timestamp '%s'", until)
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit <= 0 {
+			return nil, http.StatusBadRequest, apierror.InvalidField("limit", "invalid limit '%s'", limit)
+		}
+	}
+
+	entries, err := d.Audit.Query(r.Context(), filter)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return entries, http.StatusOK, nil
+}
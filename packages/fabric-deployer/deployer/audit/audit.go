@@ -0,0 +1,206 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+)
+
+// DefaultLimit is the number of entries returned by a query without limit
+const DefaultLimit = 100
+
+// Redacted replaces the sensitive values of the recorded request bodies
+const Redacted = "[REDACTED]"
+
+// Entry records a mutating API call
+type Entry struct {
+	ID                string          `json:"id"`
+	RequestID         string          `json:"requestId,omitempty"`
+	Timestamp         time.Time       `json:"timestamp"`
+	Principal         string          `json:"principal"`
+	AuthMethod        string          `json:"authMethod,omitempty"`
+	Verb              string          `json:"verb"`
+	Path              string          `json:"path"`
+	ServiceInstanceID string          `json:"serviceInstanceID"`
+	Resource          string          `json:"resource"`
+	ComponentType     string          `json:"componentType,omitempty"`
+	ComponentName     string          `json:"componentName,omitempty"`
+	Section           string          `json:"section,omitempty"`
+	Body              json.RawMessage `json:"body,omitempty"`
+	StatusCode        int             `json:"statusCode"`
+}
+
+// Filter selects the entries returned by a query, empty fields match all
+// entries
+type Filter struct {
+	ServiceInstanceID string
+	Principal         string
+	Verb              string
+	Resource          string
+	ComponentType     string
+	ComponentName     string
+	Since             time.Time
+	Until             time.Time
+	Limit             int
+}
+
+// Matches returns true if the entry is selected by the filter
+func (f *Filter) Matches(e *Entry) bool {
+	switch {
+	case f.ServiceInstanceID != "" && e.ServiceInstanceID != f.ServiceInstanceID:
+		return false
+	case f.Principal != "" && e.Principal != f.Principal:
+		return false
+	case f.Verb != "" && !strings.EqualFold(e.Verb, f.Verb):
+		return false
+	case f.Resource != "" && e.Resource != f.Resource:
+		return false
+	case f.ComponentType != "" && e.ComponentType != f.ComponentType:
+		return false
+	case f.ComponentName != "" && e.ComponentName != f.ComponentName:
+		return false
+	case !f.Since.IsZero() && e.Timestamp.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && e.Timestamp.After(f.Until):
+		return false
+	}
+	return true
+}
+
+func (f *Filter) limit() int {
+	if f.Limit <= 0 {
+		return DefaultLimit
+	}
+	return f.Limit
+}
+
+//go:generate counterfeiter -o mocks/store.go -fake-name Store . Store
+
+// Store persists the audit log
+type Store interface {
+	Record(ctx context.Context, entry *Entry) error
+	// Query returns the entries selected by the filter, newest first
+	Query(ctx context.Context, filter *Filter) ([]*Entry, error)
+}
+
+// New returns the store of the configured backend, nil is returned when
+// auditing is disabled
+func New(logger *zap.Logger, cfg *config.DeployerSettingsConfig, client HTTPClient) (Store, error) {
+	if cfg.Audit == nil || cfg.Audit.Backend == "" {
+		return nil, nil
+	}
+
+	switch cfg.Audit.Backend {
+	case config.AuditBackendCouchDB:
+		return NewCouchDB(logger, client, cfg.Database.ConnectionURL, cfg.Audit.Database)
+	case config.AuditBackendFile:
+		return NewFile(cfg.Audit.Path)
+	}
+
+	return nil, errors.Errorf("audit backend '%s' not supported", cfg.Audit.Backend)
+}
+
+// sensitiveKeys are the keys whose values are always redacted, e.g. the
+// passwords of the CA identities, keys containing one of sensitiveSubstrings
+// are redacted as well, e.g. the database connection strings
+var (
+	sensitiveKeys       = []string{"pin", "key", "pass"}
+	sensitiveSubstrings = []string{"password", "secret", "token", "privatekey", "keystore", "credential", "datasource"}
+)
+
+// Redact returns the JSON body with the values of sensitive keys, e.g.
+// enrollment secrets and private keys, replaced. Bodies that are not JSON
+// are not recorded.
+func Redact(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var value interface{}
+	err := json.Unmarshal(body, &value)
+	if err != nil {
+		return nil
+	}
+
+	redacted, err := json.Marshal(redact(value))
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+func redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			if IsSensitive(key) && nested != nil {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = redact(nested)
+		}
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = redact(nested)
+		}
+	case string:
+		if IsSensitiveValue(v) {
+			u, _ := url.Parse(v)
+			u.User = nil
+			return u.String()
+		}
+	}
+	return value
+}
+
+// IsSensitive returns true if the values of the key are redacted
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range sensitiveKeys {
+		if key == k {
+			return true
+		}
+	}
+	for _, s := range sensitiveSubstrings {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSensitiveValue returns true if the value holds credentials whatever its
+// key, i.e. a URL with userinfo such as the LDAP URL of a CA
+func IsSensitiveValue(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok || !strings.Contains(s, "@") {
+		return false
+	}
+	u, err := url.Parse(s)
+	return err == nil && u.Host != "" && u.User != nil
+}
@@ -0,0 +1,216 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// DefaultDatabase is the CouchDB database of the audit log
+const DefaultDatabase = "deployer_audit"
+
+//go:generate counterfeiter -o mocks/http_client.go -fake-name HTTPClient . HTTPClient
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// CouchDB stores the audit log in a CouchDB database, entries are queried
+// with mango queries sorted on an index of the time
+type CouchDB struct {
+	Logger   *zap.SugaredLogger
+	Client   HTTPClient
+	URL      *url.URL
+	Database string
+}
+
+type couchDocument struct {
+	ID string `json:"_id"`
+	// Time is the timestamp in unix milliseconds, RFC 3339 timestamps do not
+	// sort lexically
+	Time int64 `json:"time"`
+	*Entry
+}
+
+type couchFindResponse struct {
+	Docs []*Entry `json:"docs"`
+}
+
+// NewCouchDB creates the database and the time index if they do not
+// exist yet
+func NewCouchDB(logger *zap.Logger, client HTTPClient, connectionURL, database string) (*CouchDB, error) {
+	u, err := url.ParseRequestURI(connectionURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid db connection url")
+	}
+	if database == "" {
+		database = DefaultDatabase
+	}
+
+	c := &CouchDB{
+		Logger:   logger.Sugar().Named("AuditCouchDB"),
+		Client:   client,
+		URL:      u,
+		Database: database,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	statusCode, _, err := c.do(ctx, http.MethodPut, "", nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create audit database '%s'", database)
+	}
+	// 412 is returned if the database already exists
+	if statusCode != http.StatusCreated && statusCode != http.StatusPreconditionFailed {
+		return nil, errors.Errorf("failed to create audit database '%s', status code %d", database, statusCode)
+	}
+
+	index := map[string]interface{}{
+		"index": map[string]interface{}{
+			"fields": []string{"time"},
+		},
+		"name": "time",
+		"type": "json",
+	}
+	statusCode, _, err = c.do(ctx, http.MethodPost, "_index", index)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create audit time index")
+	}
+	if statusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to create audit time index, status code %d", statusCode)
+	}
+
+	return c, nil
+}
+
+func (c *CouchDB) Record(ctx context.Context, entry *Entry) error {
+	statusCode, _, err := c.do(ctx, http.MethodPost, "", &couchDocument{
+		ID:    entry.ID,
+		Time:  entry.Timestamp.UnixMilli(),
+		Entry: entry,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to record audit entry")
+	}
+	if statusCode != http.StatusCreated && statusCode != http.StatusAccepted {
+		return errors.Errorf("failed to record audit entry, status code %d", statusCode)
+	}
+	return nil
+}
+
+func (c *CouchDB) Query(ctx context.Context, filter *Filter) ([]*Entry, error) {
+	// the sort field must be part of the selector to use the index
+	timeRange := map[string]interface{}{
+		"$gte": int64(0),
+	}
+	if !filter.Since.IsZero() {
+		timeRange["$gte"] = filter.Since.UnixMilli()
+	}
+	if !filter.Until.IsZero() {
+		timeRange["$lte"] = filter.Until.UnixMilli()
+	}
+
+	selector := map[string]interface{}{
+		"time": timeRange,
+	}
+	fields := map[string]string{
+		"serviceInstanceID": filter.ServiceInstanceID,
+		"principal":         filter.Principal,
+		"verb":              strings.ToUpper(filter.Verb),
+		"resource":          filter.Resource,
+		"componentType":     filter.ComponentType,
+		"componentName":     filter.ComponentName,
+	}
+	for field, value := range fields {
+		if value != "" {
+			selector[field] = value
+		}
+	}
+
+	query := map[string]interface{}{
+		"selector": selector,
+		"sort":     []map[string]string{{"time": "desc"}},
+		"limit":    filter.limit(),
+	}
+	statusCode, body, err := c.do(ctx, http.MethodPost, "_find", query)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query audit log")
+	}
+	if statusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to query audit log, status code %d", statusCode)
+	}
+
+	resp := &couchFindResponse{}
+	err = json.Unmarshal(body, resp)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse audit log query response")
+	}
+	return resp.Docs, nil
+}
+
+// do sends the request to the path of the database and returns the status
+// code and body of the response
+func (c *CouchDB) do(ctx context.Context, method, path string, obj interface{}) (int, []byte, error) {
+	u := *c.URL
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + url.PathEscape(c.Database)
+	if path != "" {
+		u.Path += "/" + path
+	}
+
+	var reqBody io.Reader
+	if obj != nil {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return 0, nil, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		return 0, nil, err
+	}
+	if obj != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read response: %s", err)
+	}
+	return resp.StatusCode, body, nil
+}
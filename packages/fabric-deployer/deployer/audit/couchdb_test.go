@@ -0,0 +1,145 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/audit"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/audit/mocks"
+)
+
+var _ = Describe("CouchDB", func() {
+	var (
+		client *mocks.HTTPClient
+		store  *audit.CouchDB
+	)
+
+	response := func(statusCode int, body string) *http.Response {
+		return &http.Response{
+			StatusCode: statusCode,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+	}
+
+	requestBody := func(req *http.Request) map[string]interface{} {
+		body := map[string]interface{}{}
+		data, err := io.ReadAll(req.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(json.Unmarshal(data, &body)).To(Succeed())
+		return body
+	}
+
+	BeforeEach(func() {
+		client = &mocks.HTTPClient{}
+		client.DoReturnsOnCall(0, response(http.StatusPreconditionFailed, `{"error":"file_exists"}`), nil)
+		client.DoReturnsOnCall(1, response(http.StatusOK, `{"result":"exists"}`), nil)
+
+		var err error
+		store, err = audit.NewCouchDB(zap.NewNop(), client, "http://couchdb:5984", "")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("creates the database and the time index", func() {
+		Expect(client.DoCallCount()).To(Equal(2))
+
+		req := client.DoArgsForCall(0)
+		Expect(req.Method).To(Equal(http.MethodPut))
+		Expect(req.URL.String()).To(Equal("http://couchdb:5984/" + audit.DefaultDatabase))
+
+		req = client.DoArgsForCall(1)
+		Expect(req.Method).To(Equal(http.MethodPost))
+		Expect(req.URL.Path).To(Equal("/" + audit.DefaultDatabase + "/_index"))
+	})
+
+	It("returns an error if the database cannot be created", func() {
+		client.DoReturnsOnCall(2, response(http.StatusUnauthorized, `{"error":"unauthorized"}`), nil)
+		_, err := audit.NewCouchDB(zap.NewNop(), client, "http://couchdb:5984", "audit")
+		Expect(err).To(MatchError("failed to create audit database 'audit', status code 401"))
+	})
+
+	It("records the entries as documents", func() {
+		client.DoReturns(response(http.StatusCreated, `{"ok":true}`), nil)
+		timestamp := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		err := store.Record(context.TODO(), &audit.Entry{
+			ID:                "id1",
+			Timestamp:         timestamp,
+			Principal:         "admin",
+			ServiceInstanceID: "sid",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		req := client.DoArgsForCall(2)
+		Expect(req.Method).To(Equal(http.MethodPost))
+		Expect(req.URL.Path).To(Equal("/" + audit.DefaultDatabase))
+		body := requestBody(req)
+		Expect(body["_id"]).To(Equal("id1"))
+		Expect(body["principal"]).To(Equal("admin"))
+		Expect(body["time"]).To(BeEquivalentTo(timestamp.UnixMilli()))
+	})
+
+	It("returns an error if the entry is not recorded", func() {
+		client.DoReturns(nil, errors.New("connection refused"))
+		err := store.Record(context.TODO(), &audit.Entry{ID: "id1"})
+		Expect(err).To(MatchError(ContainSubstring("connection refused")))
+	})
+
+	It("queries the entries with a mango query", func() {
+		client.DoReturns(response(http.StatusOK, `{"docs": [{"_id": "id2", "id": "id2", "principal": "admin"}, {"_id": "id1", "id": "id1", "principal": "admin"}]}`), nil)
+		since := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		entries, err := store.Query(context.TODO(), &audit.Filter{
+			ServiceInstanceID: "sid",
+			Principal:         "admin",
+			Verb:              "delete",
+			Since:             since,
+			Limit:             10,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].ID).To(Equal("id2"))
+
+		req := client.DoArgsForCall(2)
+		Expect(req.URL.Path).To(Equal("/" + audit.DefaultDatabase + "/_find"))
+		body := requestBody(req)
+		Expect(body["limit"]).To(BeEquivalentTo(10))
+		Expect(body["sort"]).To(Equal([]interface{}{map[string]interface{}{"time": "desc"}}))
+		selector := body["selector"].(map[string]interface{})
+		Expect(selector["serviceInstanceID"]).To(Equal("sid"))
+		Expect(selector["principal"]).To(Equal("admin"))
+		Expect(selector["verb"]).To(Equal("DELETE"))
+		Expect(selector).NotTo(HaveKey("componentType"))
+		Expect(selector["time"]).To(HaveKeyWithValue("$gte", BeEquivalentTo(since.UnixMilli())))
+	})
+
+	It("returns an error if the query fails", func() {
+		client.DoReturns(response(http.StatusBadRequest, `{"error":"bad_request"}`), nil)
+		_, err := store.Query(context.TODO(), &audit.Filter{})
+		Expect(err).To(MatchError("failed to query audit log, status code 400"))
+	})
+})
@@ -0,0 +1,116 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// File stores the audit log as a local JSONL file, one entry per line
+type File struct {
+	Path string
+
+	mutex sync.Mutex
+}
+
+func NewFile(path string) (*File, error) {
+	if path == "" {
+		return nil, errors.New("file audit backend requires a path")
+	}
+
+	// #nosec G302 G304
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open audit log '%s'", path)
+	}
+	_ = f.Close()
+
+	return &File{
+		Path: path,
+	}, nil
+}
+
+func (f *File) Record(ctx context.Context, entry *Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal audit entry")
+	}
+	line = append(line, '\n')
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	// #nosec G302 G304
+	file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to open audit log")
+	}
+	defer file.Close()
+
+	_, err = file.Write(line)
+	if err != nil {
+		return errors.Wrap(err, "failed to write audit entry")
+	}
+	return nil
+}
+
+// Query scans the whole file, the log is expected to be rotated externally
+func (f *File) Query(ctx context.Context, filter *Filter) ([]*Entry, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	// #nosec G304
+	file, err := os.Open(f.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open audit log")
+	}
+	defer file.Close()
+
+	entries := []*Entry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		entry := &Entry{}
+		err := json.Unmarshal(scanner.Bytes(), entry)
+		if err != nil {
+			// skips lines truncated by a crash
+			continue
+		}
+		if filter.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read audit log")
+	}
+
+	// entries are appended in order, the newest are at the end
+	limit := filter.limit()
+	result := []*Entry{}
+	for i := len(entries) - 1; i >= 0 && len(result) < limit; i-- {
+		result = append(result, entries[i])
+	}
+	return result, nil
+}
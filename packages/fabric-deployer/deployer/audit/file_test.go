@@ -0,0 +1,111 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/audit"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
+)
+
+var _ = Describe("File", func() {
+	var (
+		path  string
+		store *audit.File
+		start time.Time
+	)
+
+	record := func(sID, principal string, at time.Time) {
+		err := store.Record(context.TODO(), &audit.Entry{
+			ID:                util.NewID(),
+			Timestamp:         at,
+			Principal:         principal,
+			Verb:              "PATCH",
+			ServiceInstanceID: sID,
+			StatusCode:        200,
+		})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "audit.jsonl")
+
+		var err error
+		store, err = audit.NewFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		start = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		record("sid", "admin", start)
+		record("othersid", "admin", start.Add(time.Minute))
+		record("sid", "user", start.Add(2*time.Minute))
+		record("sid", "admin", start.Add(3*time.Minute))
+	})
+
+	It("returns an error without a path", func() {
+		_, err := audit.NewFile("")
+		Expect(err).To(MatchError("file audit backend requires a path"))
+	})
+
+	It("appends one entry per line", func() {
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(HaveSuffix("\n"))
+		Expect(string(data)).To(ContainSubstring(`"serviceInstanceID":"othersid"`))
+	})
+
+	It("returns the matching entries, newest first", func() {
+		entries, err := store.Query(context.TODO(), &audit.Filter{ServiceInstanceID: "sid"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(3))
+		Expect(entries[0].Timestamp).To(Equal(start.Add(3 * time.Minute)))
+		Expect(entries[2].Timestamp).To(Equal(start))
+
+		entries, err = store.Query(context.TODO(), &audit.Filter{ServiceInstanceID: "sid", Principal: "admin", Since: start.Add(time.Second)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Timestamp).To(Equal(start.Add(3 * time.Minute)))
+	})
+
+	It("limits the number of entries", func() {
+		entries, err := store.Query(context.TODO(), &audit.Filter{ServiceInstanceID: "sid", Limit: 2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[1].Principal).To(Equal("user"))
+	})
+
+	It("skips truncated lines", func() {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = f.WriteString(`{"id": "trunc` + "\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Close()).To(Succeed())
+
+		record("sid", "admin", start.Add(4*time.Minute))
+		entries, err := store.Query(context.TODO(), &audit.Filter{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(5))
+	})
+})
@@ -0,0 +1,195 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/audit"
+)
+
+type Store struct {
+	QueryStub        func(context.Context, *audit.Filter) ([]*audit.Entry, error)
+	queryMutex       sync.RWMutex
+	queryArgsForCall []struct {
+		arg1 context.Context
+		arg2 *audit.Filter
+	}
+	queryReturns struct {
+		result1 []*audit.Entry
+		result2 error
+	}
+	queryReturnsOnCall map[int]struct {
+		result1 []*audit.Entry
+		result2 error
+	}
+	RecordStub        func(context.Context, *audit.Entry) error
+	recordMutex       sync.RWMutex
+	recordArgsForCall []struct {
+		arg1 context.Context
+		arg2 *audit.Entry
+	}
+	recordReturns struct {
+		result1 error
+	}
+	recordReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *Store) Query(arg1 context.Context, arg2 *audit.Filter) ([]*audit.Entry, error) {
+	fake.queryMutex.Lock()
+	ret, specificReturn := fake.queryReturnsOnCall[len(fake.queryArgsForCall)]
+	fake.queryArgsForCall = append(fake.queryArgsForCall, struct {
+		arg1 context.Context
+		arg2 *audit.Filter
+	}{arg1, arg2})
+	stub := fake.QueryStub
+	fakeReturns := fake.queryReturns
+	fake.recordInvocation("Query", []interface{}{arg1, arg2})
+	fake.queryMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Store) QueryCallCount() int {
+	fake.queryMutex.RLock()
+	defer fake.queryMutex.RUnlock()
+	return len(fake.queryArgsForCall)
+}
+
+func (fake *Store) QueryCalls(stub func(context.Context, *audit.Filter) ([]*audit.Entry, error)) {
+	fake.queryMutex.Lock()
+	defer fake.queryMutex.Unlock()
+	fake.QueryStub = stub
+}
+
+func (fake *Store) QueryArgsForCall(i int) (context.Context, *audit.Filter) {
+	fake.queryMutex.RLock()
+	defer fake.queryMutex.RUnlock()
+	argsForCall := fake.queryArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Store) QueryReturns(result1 []*audit.Entry, result2 error) {
+	fake.queryMutex.Lock()
+	defer fake.queryMutex.Unlock()
+	fake.QueryStub = nil
+	fake.queryReturns = struct {
+		result1 []*audit.Entry
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Store) QueryReturnsOnCall(i int, result1 []*audit.Entry, result2 error) {
+	fake.queryMutex.Lock()
+	defer fake.queryMutex.Unlock()
+	fake.QueryStub = nil
+	if fake.queryReturnsOnCall == nil {
+		fake.queryReturnsOnCall = make(map[int]struct {
+			result1 []*audit.Entry
+			result2 error
+		})
+	}
+	fake.queryReturnsOnCall[i] = struct {
+		result1 []*audit.Entry
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Store) Record(arg1 context.Context, arg2 *audit.Entry) error {
+	fake.recordMutex.Lock()
+	ret, specificReturn := fake.recordReturnsOnCall[len(fake.recordArgsForCall)]
+	fake.recordArgsForCall = append(fake.recordArgsForCall, struct {
+		arg1 context.Context
+		arg2 *audit.Entry
+	}{arg1, arg2})
+	stub := fake.RecordStub
+	fakeReturns := fake.recordReturns
+	fake.recordInvocation("Record", []interface{}{arg1, arg2})
+	fake.recordMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Store) RecordCallCount() int {
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	return len(fake.recordArgsForCall)
+}
+
+func (fake *Store) RecordCalls(stub func(context.Context, *audit.Entry) error) {
+	fake.recordMutex.Lock()
+	defer fake.recordMutex.Unlock()
+	fake.RecordStub = stub
+}
+
+func (fake *Store) RecordArgsForCall(i int) (context.Context, *audit.Entry) {
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	argsForCall := fake.recordArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Store) RecordReturns(result1 error) {
+	fake.recordMutex.Lock()
+	defer fake.recordMutex.Unlock()
+	fake.RecordStub = nil
+	fake.recordReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Store) RecordReturnsOnCall(i int, result1 error) {
+	fake.recordMutex.Lock()
+	defer fake.recordMutex.Unlock()
+	fake.RecordStub = nil
+	if fake.recordReturnsOnCall == nil {
+		fake.recordReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.recordReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Store) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.queryMutex.RLock()
+	defer fake.queryMutex.RUnlock()
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *Store) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ audit.Store = new(Store)
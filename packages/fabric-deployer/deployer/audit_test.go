@@ -0,0 +1,145 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-chi/chi"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/audit"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/audit/mocks"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/auth"
+)
+
+var _ = Describe("Audit", func() {
+	var (
+		d      *deployer.Deployer
+		store  *mocks.Store
+		router *chi.Mux
+		body   string
+	)
+
+	BeforeEach(func() {
+		store = &mocks.Store{}
+		d = deployer.New(&config.DeployerSettingsConfig{}, &config.LocalConfig{Logger: zap.NewNop()}, false)
+		d.Audit = store
+
+		router = chi.NewRouter()
+		router.With(d.AuditMiddleware).HandleFunc("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}/{section}", func(w http.ResponseWriter, r *http.Request) {
+			data, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			body = string(data)
+			w.WriteHeader(http.StatusAccepted)
+		})
+	})
+
+	serve := func(method, reqBody string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v3/instance/sid/type/peer/component/peer1/actions", strings.NewReader(reqBody))
+		req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Name: "admin", Method: config.AuthMethodBasic}))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	Context("middleware", func() {
+		It("records the mutating requests", func() {
+			w := serve(http.MethodPatch, `{"reenroll": {"ecert": true}, "enrollsecret": "adminpw"}`)
+			Expect(w.Code).To(Equal(http.StatusAccepted))
+			Expect(body).To(ContainSubstring("adminpw"))
+
+			Expect(store.RecordCallCount()).To(Equal(1))
+			_, entry := store.RecordArgsForCall(0)
+			Expect(entry.ID).NotTo(BeEmpty())
+			Expect(entry.Principal).To(Equal("admin"))
+			Expect(entry.AuthMethod).To(Equal(config.AuthMethodBasic))
+			Expect(entry.Verb).To(Equal(http.MethodPatch))
+			Expect(entry.ServiceInstanceID).To(Equal("sid"))
+			Expect(entry.Resource).To(Equal(deployer.ResourceComponent))
+			Expect(entry.ComponentType).To(Equal("peer"))
+			Expect(entry.ComponentName).To(Equal("peer1"))
+			Expect(entry.Section).To(Equal("actions"))
+			Expect(entry.StatusCode).To(Equal(http.StatusAccepted))
+			Expect(string(entry.Body)).To(ContainSubstring(`"enrollsecret":"[REDACTED]"`))
+		})
+
+		It("does not record reads", func() {
+			serve(http.MethodGet, "")
+			Expect(store.RecordCallCount()).To(Equal(0))
+		})
+
+		It("does not fail the request if the entry is not recorded", func() {
+			store.RecordReturns(errors.New("disk full"))
+			w := serve(http.MethodDelete, "")
+			Expect(w.Code).To(Equal(http.StatusAccepted))
+		})
+	})
+
+	Context("get", func() {
+		request := func(url string) *http.Request {
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("serviceInstanceID", "sid")
+			return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		}
+
+		It("returns the entries of the instance matching the query", func() {
+			store.QueryReturns([]*audit.Entry{{ID: "id1", Principal: "admin"}}, nil)
+			resp, code, err := d.GetAudit(nil, request("/api/v3/instance/sid/audit?principal=admin&type=peer&since=2024-01-01T00:00:00Z&limit=5"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(resp).To(HaveLen(1))
+
+			_, filter := store.QueryArgsForCall(0)
+			Expect(filter.ServiceInstanceID).To(Equal("sid"))
+			Expect(filter.Principal).To(Equal("admin"))
+			Expect(filter.ComponentType).To(Equal("peer"))
+			Expect(filter.Since.IsZero()).To(BeFalse())
+			Expect(filter.Limit).To(Equal(5))
+		})
+
+		It("returns a bad request for invalid query parameters", func() {
+			_, code, err := d.GetAudit(nil, request("/api/v3/instance/sid/audit?since=yesterday"))
+			Expect(err).To(MatchError("invalid since timestamp 'yesterday'"))
+			Expect(apierror.KindOf(err)).To(Equal(apierror.Validation))
+			Expect(code).To(Equal(http.StatusBadRequest))
+
+			_, code, _ = d.GetAudit(nil, request("/api/v3/instance/sid/audit?limit=-1"))
+			Expect(code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("returns a 404 if auditing is disabled", func() {
+			d.Audit = nil
+			_, code, err := d.GetAudit(nil, request("/api/v3/instance/sid/audit"))
+			Expect(err).To(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
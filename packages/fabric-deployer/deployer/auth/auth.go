@@ -0,0 +1,136 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+)
+
+// ErrNoCredentials is returned by an authenticator when the request does not
+// carry the kind of credentials it handles
+var ErrNoCredentials = errors.New("no credentials")
+
+// Principal is the authenticated identity of a request
+type Principal struct {
+	Name   string   `json:"name"`
+	Groups []string `json:"groups,omitempty"`
+	// Method is the authentication method that authenticated the principal
+	Method string `json:"method"`
+}
+
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Chain tries the authenticators in order and returns the first principal
+// authenticated
+type Chain []Authenticator
+
+func (c Chain) Authenticate(r *http.Request) (*Principal, error) {
+	reasons := []string{}
+	for _, a := range c {
+		principal, err := a.Authenticate(r)
+		if err == nil {
+			return principal, nil
+		}
+		if err != ErrNoCredentials {
+			reasons = append(reasons, err.Error())
+		}
+	}
+
+	if len(reasons) == 0 {
+		return nil, apierror.New(apierror.Unauthorized, apierror.CodeUnauthenticated, "unauthorized, no credentials provided")
+	}
+	return nil, apierror.New(apierror.Unauthorized, apierror.CodeUnauthenticated, "unauthorized, %s", strings.Join(reasons, "; "))
+}
+
+// New returns the chain of authenticators enabled in the configuration,
+// basic auth is used if no method is configured
+func New(logger *zap.Logger, cfg *config.DeployerSettingsConfig, reviewer TokenReviewer) (Chain, error) {
+	methods := []string{config.AuthMethodBasic}
+	if cfg.Authentication != nil && len(cfg.Authentication.Methods) != 0 {
+		methods = cfg.Authentication.Methods
+	}
+
+	chain := Chain{}
+	for _, method := range methods {
+		switch method {
+		case config.AuthMethodBasic:
+			basic, err := NewBasic(cfg.Auth)
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, basic)
+		case config.AuthMethodJWKS:
+			if cfg.Authentication.JWKS == nil {
+				return nil, errors.New("jwks authentication requires the jwks configuration")
+			}
+			jwks, err := NewJWKS(cfg.Authentication.JWKS)
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, jwks)
+		case config.AuthMethodTokenReview:
+			audiences := []string{}
+			if cfg.Authentication.TokenReview != nil {
+				audiences = cfg.Authentication.TokenReview.Audiences
+			}
+			chain = append(chain, NewTokenReview(logger, reviewer, audiences))
+		case config.AuthMethodMTLS:
+			if !cfg.TLS.Enabled || cfg.TLS.ClientCACertPath == "" {
+				return nil, errors.New("mtls authentication requires TLS to be enabled with a client CA cert path")
+			}
+			chain = append(chain, &MTLS{})
+		default:
+			return nil, errors.Errorf("authentication method '%s' not supported", method)
+		}
+	}
+
+	return chain, nil
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx holding the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the principal stored in ctx by WithPrincipal
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// bearerToken returns the token of the Authorization header
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "bearer ") {
+		return "", false
+	}
+	token := strings.TrimSpace(header[7:])
+	return token, token != ""
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/auth"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/auth/mocks"
+)
+
+var _ = Describe("Auth", func() {
+	var (
+		cfg     *config.DeployerSettingsConfig
+		request *http.Request
+	)
+
+	BeforeEach(func() {
+		cfg = &config.DeployerSettingsConfig{
+			Auth: config.BasicAuth{
+				Username: "user",
+				Password: "pass",
+			},
+		}
+		request = httptest.NewRequest(http.MethodGet, "/api/v3/instance/sid/ca/ca1", nil)
+	})
+
+	Context("new", func() {
+		It("uses basic auth if no method is configured", func() {
+			chain, err := auth.New(zap.NewNop(), cfg, &mocks.TokenReviewer{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(chain).To(HaveLen(1))
+			Expect(chain[0]).To(BeAssignableToTypeOf(&auth.Basic{}))
+		})
+
+		It("returns the configured methods in order", func() {
+			cfg.Authentication = &config.Authentication{
+				Methods: []string{config.AuthMethodTokenReview, config.AuthMethodBasic},
+			}
+			chain, err := auth.New(zap.NewNop(), cfg, &mocks.TokenReviewer{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(chain).To(HaveLen(2))
+			Expect(chain[0]).To(BeAssignableToTypeOf(&auth.TokenReview{}))
+			Expect(chain[1]).To(BeAssignableToTypeOf(&auth.Basic{}))
+		})
+
+		It("returns an error for unsupported methods", func() {
+			cfg.Authentication = &config.Authentication{
+				Methods: []string{"ldap"},
+			}
+			_, err := auth.New(zap.NewNop(), cfg, &mocks.TokenReviewer{})
+			Expect(err).To(MatchError("authentication method 'ldap' not supported"))
+		})
+
+		It("returns an error if jwks is not configured", func() {
+			cfg.Authentication = &config.Authentication{
+				Methods: []string{config.AuthMethodJWKS},
+			}
+			_, err := auth.New(zap.NewNop(), cfg, &mocks.TokenReviewer{})
+			Expect(err).To(MatchError("jwks authentication requires the jwks configuration"))
+		})
+
+		It("returns an error if mtls is used without a client CA", func() {
+			cfg.Authentication = &config.Authentication{
+				Methods: []string{config.AuthMethodMTLS},
+			}
+			cfg.TLS.Enabled = true
+			_, err := auth.New(zap.NewNop(), cfg, &mocks.TokenReviewer{})
+			Expect(err).To(MatchError(ContainSubstring("requires TLS to be enabled with a client CA cert path")))
+		})
+	})
+
+	Context("chain", func() {
+		var chain auth.Chain
+
+		BeforeEach(func() {
+			basic, err := auth.NewBasic(cfg.Auth)
+			Expect(err).NotTo(HaveOccurred())
+			chain = auth.Chain{&auth.MTLS{}, basic}
+		})
+
+		It("returns the principal of the first authenticator accepting the request", func() {
+			request.SetBasicAuth("user", "pass")
+			principal, err := chain.Authenticate(request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(principal.Name).To(Equal("user"))
+			Expect(principal.Method).To(Equal(config.AuthMethodBasic))
+		})
+
+		It("returns an unauthorized error if no credentials are provided", func() {
+			_, err := chain.Authenticate(request)
+			Expect(err).To(MatchError("unauthorized, no credentials provided"))
+		})
+
+		It("returns the reason the credentials were rejected", func() {
+			request.SetBasicAuth("user", "wrong")
+			_, err := chain.Authenticate(request)
+			Expect(err).To(MatchError("unauthorized, invalid basic auth credentials"))
+		})
+	})
+
+	Context("principal", func() {
+		It("is stored in the request context", func() {
+			_, found := auth.PrincipalFrom(request.Context())
+			Expect(found).To(BeFalse())
+
+			ctx := auth.WithPrincipal(request.Context(), &auth.Principal{Name: "user"})
+			principal, found := auth.PrincipalFrom(ctx)
+			Expect(found).To(BeTrue())
+			Expect(principal.Name).To(Equal("user"))
+		})
+	})
+
+	Context("basic", func() {
+		It("requires a password", func() {
+			_, err := auth.NewBasic(config.BasicAuth{Username: "user"})
+			Expect(err).To(MatchError("basic authentication requires a password or password hash"))
+		})
+
+		It("rejects an invalid username", func() {
+			basic, err := auth.NewBasic(cfg.Auth)
+			Expect(err).NotTo(HaveOccurred())
+
+			request.SetBasicAuth("other", "pass")
+			_, err = basic.Authenticate(request)
+			Expect(err).To(MatchError("invalid basic auth credentials"))
+		})
+
+		It("verifies the password against the bcrypt hash", func() {
+			hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+			Expect(err).NotTo(HaveOccurred())
+			basic, err := auth.NewBasic(config.BasicAuth{Username: "user", PasswordHash: string(hash)})
+			Expect(err).NotTo(HaveOccurred())
+
+			request.SetBasicAuth("user", "secret")
+			principal, err := basic.Authenticate(request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(principal.Name).To(Equal("user"))
+
+			request.SetBasicAuth("user", "pass")
+			_, err = basic.Authenticate(request)
+			Expect(err).To(MatchError("invalid basic auth credentials"))
+		})
+
+		It("returns an error for an invalid bcrypt hash", func() {
+			_, err := auth.NewBasic(config.BasicAuth{Username: "user", PasswordHash: "invalid"})
+			Expect(err).To(MatchError(ContainSubstring("invalid bcrypt password hash")))
+		})
+	})
+
+	Context("mtls", func() {
+		var cert *x509.Certificate
+
+		BeforeEach(func() {
+			cert = &x509.Certificate{
+				Subject: pkix.Name{
+					CommonName:   "console",
+					Organization: []string{"operators"},
+				},
+			}
+		})
+
+		It("returns no credentials without a client certificate", func() {
+			_, err := (&auth.MTLS{}).Authenticate(request)
+			Expect(err).To(Equal(auth.ErrNoCredentials))
+		})
+
+		It("returns the common name and organizations of the verified certificate", func() {
+			request.TLS = &tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{cert},
+				VerifiedChains:   [][]*x509.Certificate{{cert}},
+			}
+			principal, err := (&auth.MTLS{}).Authenticate(request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(principal.Name).To(Equal("console"))
+			Expect(principal.Groups).To(Equal([]string{"operators"}))
+			Expect(principal.Method).To(Equal(config.AuthMethodMTLS))
+		})
+
+		It("rejects unverified certificates", func() {
+			request.TLS = &tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{cert},
+			}
+			_, err := (&auth.MTLS{}).Authenticate(request)
+			Expect(err).To(MatchError("client certificate not verified"))
+		})
+	})
+})
@@ -0,0 +1,90 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+)
+
+// Basic authenticates the static basic auth user of the configuration. The
+// credentials are compared in constant time, the configured password is
+// either a bcrypt hash or only kept as a SHA-256 digest.
+type Basic struct {
+	username     [sha256.Size]byte
+	password     [sha256.Size]byte
+	passwordHash []byte
+}
+
+func NewBasic(cfg config.BasicAuth) (*Basic, error) {
+	if cfg.Username == "" {
+		return nil, errors.New("basic authentication requires a username")
+	}
+
+	b := &Basic{
+		username: sha256.Sum256([]byte(cfg.Username)),
+	}
+
+	switch {
+	case cfg.PasswordHash != "":
+		_, err := bcrypt.Cost([]byte(cfg.PasswordHash))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid bcrypt password hash")
+		}
+		b.passwordHash = []byte(cfg.PasswordHash)
+	case cfg.Password != "":
+		b.password = sha256.Sum256([]byte(cfg.Password))
+	default:
+		return nil, errors.New("basic authentication requires a password or password hash")
+	}
+
+	return b, nil
+}
+
+func (b *Basic) Authenticate(r *http.Request) (*Principal, error) {
+	user, pass, ok := r.BasicAuth()
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	username := sha256.Sum256([]byte(user))
+	userOK := subtle.ConstantTimeCompare(username[:], b.username[:]) == 1
+
+	var passOK bool
+	if b.passwordHash != nil {
+		passOK = bcrypt.CompareHashAndPassword(b.passwordHash, []byte(pass)) == nil
+	} else {
+		password := sha256.Sum256([]byte(pass))
+		passOK = subtle.ConstantTimeCompare(password[:], b.password[:]) == 1
+	}
+
+	if !userOK || !passOK {
+		return nil, errors.New("invalid basic auth credentials")
+	}
+
+	return &Principal{
+		Name:   user,
+		Method: config.AuthMethodBasic,
+	}, nil
+}
@@ -0,0 +1,253 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+)
+
+var jwtSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// JWKS authenticates bearer tokens (JWTs) signed by one of the keys of a
+// local JWKS file, e.g. the keys of an OIDC provider
+type JWKS struct {
+	Issuer        string
+	Audience      string
+	UsernameClaim string
+	GroupsClaim   string
+
+	keys map[string]crypto.PublicKey
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func NewJWKS(cfg *config.JWKSAuth) (*JWKS, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("jwks authentication requires the path of the jwks file")
+	}
+
+	data, err := os.ReadFile(cfg.Path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read jwks file '%s'", cfg.Path)
+	}
+
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid jwks file '%s'", cfg.Path)
+	}
+
+	j := &JWKS{
+		Issuer:        cfg.Issuer,
+		Audience:      cfg.Audience,
+		UsernameClaim: cfg.UsernameClaim,
+		GroupsClaim:   cfg.GroupsClaim,
+		keys:          keys,
+	}
+	if j.UsernameClaim == "" {
+		j.UsernameClaim = "sub"
+	}
+	if j.GroupsClaim == "" {
+		j.GroupsClaim = "groups"
+	}
+
+	return j, nil
+}
+
+// ParseJWKS returns the signature verification keys of the JWKS by key id
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	set := &jsonWebKeySet{}
+	err := json.Unmarshal(data, set)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key %d", i)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no signature keys found")
+	}
+
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("curve '%s' not supported", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		// rejects points that are not on the curve
+		_, err = key.ECDH()
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid EC key")
+		}
+		return key, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, errors.Errorf("curve '%s' not supported", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, errors.Errorf("key type '%s' not supported", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func (j *JWKS) Authenticate(r *http.Request) (*Principal, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(jwtSigningMethods),
+		jwt.WithExpirationRequired(),
+	}
+	if j.Issuer != "" {
+		options = append(options, jwt.WithIssuer(j.Issuer))
+	}
+	if j.Audience != "" {
+		options = append(options, jwt.WithAudience(j.Audience))
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, j.key, options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid bearer token")
+	}
+
+	name, _ := claims[j.UsernameClaim].(string)
+	if name == "" {
+		return nil, errors.Errorf("invalid bearer token, claim '%s' not set", j.UsernameClaim)
+	}
+
+	return &Principal{
+		Name:   name,
+		Groups: stringsClaim(claims[j.GroupsClaim]),
+		Method: config.AuthMethodJWKS,
+	}, nil
+}
+
+// key returns the key the token is signed with, tokens without a key id
+// are accepted if the JWKS has a single key
+func (j *JWKS) key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if key, found := j.keys[kid]; found {
+		return key, nil
+	}
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("key '%s' not found in jwks", kid)
+}
+
+func stringsClaim(claim interface{}) []string {
+	switch c := claim.(type) {
+	case string:
+		return []string{c}
+	case []interface{}:
+		values := []string{}
+		for _, v := range c {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/auth"
+)
+
+var _ = Describe("JWKS", func() {
+	var (
+		rsaKey  *rsa.PrivateKey
+		ecKey   *ecdsa.PrivateKey
+		cfg     *config.JWKSAuth
+		jwks    *auth.JWKS
+		request *http.Request
+	)
+
+	encode := func(b []byte) string {
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		Expect(err).NotTo(HaveOccurred())
+		return signed
+	}
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":    "operator@example.com",
+			"groups": []string{"admins", "operators"},
+			"iss":    "https://issuer.example.com",
+			"aud":    "deployer",
+			"exp":    time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		set := fmt.Sprintf(`{"keys": [
+			{"kid": "rsa1", "kty": "RSA", "use": "sig", "n": "%s", "e": "%s"},
+			{"kid": "ec1", "kty": "EC", "crv": "P-256", "x": "%s", "y": "%s"},
+			{"kid": "enc1", "kty": "RSA", "use": "enc", "n": "%s", "e": "%s"}
+		]}`,
+			encode(rsaKey.N.Bytes()), encode(big.NewInt(int64(rsaKey.E)).Bytes()),
+			encode(ecKey.X.Bytes()), encode(ecKey.Y.Bytes()),
+			encode(rsaKey.N.Bytes()), encode(big.NewInt(int64(rsaKey.E)).Bytes()),
+		)
+		path := filepath.Join(GinkgoT().TempDir(), "jwks.json")
+		Expect(os.WriteFile(path, []byte(set), 0600)).To(Succeed())
+
+		cfg = &config.JWKSAuth{
+			Path:     path,
+			Issuer:   "https://issuer.example.com",
+			Audience: "deployer",
+		}
+		jwks, err = auth.NewJWKS(cfg)
+		Expect(err).NotTo(HaveOccurred())
+
+		request = httptest.NewRequest(http.MethodGet, "/api/v3/instance/sid/ca/ca1", nil)
+	})
+
+	It("returns no credentials without a bearer token", func() {
+		_, err := jwks.Authenticate(request)
+		Expect(err).To(Equal(auth.ErrNoCredentials))
+	})
+
+	It("authenticates tokens signed with an RSA key", func() {
+		request.Header.Set("Authorization", "Bearer "+sign(jwt.SigningMethodRS256, "rsa1", rsaKey, validClaims()))
+		principal, err := jwks.Authenticate(request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(principal.Name).To(Equal("operator@example.com"))
+		Expect(principal.Groups).To(Equal([]string{"admins", "operators"}))
+		Expect(principal.Method).To(Equal(config.AuthMethodJWKS))
+	})
+
+	It("authenticates tokens signed with an EC key", func() {
+		request.Header.Set("Authorization", "Bearer "+sign(jwt.SigningMethodES256, "ec1", ecKey, validClaims()))
+		principal, err := jwks.Authenticate(request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(principal.Name).To(Equal("operator@example.com"))
+	})
+
+	It("uses the configured claims", func() {
+		cfg.UsernameClaim = "email"
+		cfg.GroupsClaim = "roles"
+		jwks, err := auth.NewJWKS(cfg)
+		Expect(err).NotTo(HaveOccurred())
+
+		claims := validClaims()
+		claims["email"] = "user@example.com"
+		claims["roles"] = "viewer"
+		request.Header.Set("Authorization", "Bearer "+sign(jwt.SigningMethodRS256, "rsa1", rsaKey, claims))
+		principal, err := jwks.Authenticate(request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(principal.Name).To(Equal("user@example.com"))
+		Expect(principal.Groups).To(Equal([]string{"viewer"}))
+	})
+
+	It("ignores keys that are not used for signatures", func() {
+		request.Header.Set("Authorization", "Bearer "+sign(jwt.SigningMethodRS256, "enc1", rsaKey, validClaims()))
+		_, err := jwks.Authenticate(request)
+		Expect(err).To(MatchError(ContainSubstring("key 'enc1' not found in jwks")))
+	})
+
+	It("rejects tokens signed by another key", func() {
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		request.Header.Set("Authorization", "Bearer "+sign(jwt.SigningMethodRS256, "rsa1", otherKey, validClaims()))
+		_, err = jwks.Authenticate(request)
+		Expect(err).To(MatchError(ContainSubstring("invalid bearer token")))
+	})
+
+	It("rejects unsigned tokens", func() {
+		request.Header.Set("Authorization", "Bearer "+sign(jwt.SigningMethodNone, "rsa1", jwt.UnsafeAllowNoneSignatureType, validClaims()))
+		_, err := jwks.Authenticate(request)
+		Expect(err).To(MatchError(ContainSubstring("invalid bearer token")))
+	})
+
+	It("rejects expired tokens", func() {
+		claims := validClaims()
+		claims["exp"] = time.Now().Add(-time.Minute).Unix()
+		request.Header.Set("Authorization", "Bearer "+sign(jwt.SigningMethodRS256, "rsa1", rsaKey, claims))
+		_, err := jwks.Authenticate(request)
+		Expect(err).To(MatchError(ContainSubstring("token is expired")))
+	})
+
+	It("rejects tokens without an expiration", func() {
+		claims := validClaims()
+		delete(claims, "exp")
+		request.Header.Set("Authorization", "Bearer "+sign(jwt.SigningMethodRS256, "rsa1", rsaKey, claims))
+		_, err := jwks.Authenticate(request)
+		Expect(err).To(MatchError(ContainSubstring("invalid bearer token")))
+	})
+
+	It("rejects tokens of another issuer or audience", func() {
+		claims := validClaims()
+		claims["iss"] = "https://other.example.com"
+		request.Header.Set("Authorization", "Bearer "+sign(jwt.SigningMethodRS256, "rsa1", rsaKey, claims))
+		_, err := jwks.Authenticate(request)
+		Expect(err).To(MatchError(ContainSubstring("invalid bearer token")))
+
+		claims = validClaims()
+		claims["aud"] = "other"
+		request.Header.Set("Authorization", "Bearer "+sign(jwt.SigningMethodRS256, "rsa1", rsaKey, claims))
+		_, err = jwks.Authenticate(request)
+		Expect(err).To(MatchError(ContainSubstring("invalid bearer token")))
+	})
+
+	Context("parse", func() {
+		It("returns an error for keys not on the curve", func() {
+			_, err := auth.ParseJWKS([]byte(`{"keys": [{"kid": "ec1", "kty": "EC", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`))
+			Expect(err).To(MatchError(ContainSubstring("invalid EC key")))
+		})
+
+		It("returns an error if there are no signature keys", func() {
+			_, err := auth.ParseJWKS([]byte(`{"keys": []}`))
+			Expect(err).To(MatchError("no signature keys found"))
+		})
+	})
+})
@@ -0,0 +1,127 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/auth"
+	v1 "k8s.io/api/authentication/v1"
+)
+
+type TokenReviewer struct {
+	ReviewTokenStub        func(context.Context, string, []string) (*v1.TokenReviewStatus, error)
+	reviewTokenMutex       sync.RWMutex
+	reviewTokenArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []string
+	}
+	reviewTokenReturns struct {
+		result1 *v1.TokenReviewStatus
+		result2 error
+	}
+	reviewTokenReturnsOnCall map[int]struct {
+		result1 *v1.TokenReviewStatus
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *TokenReviewer) ReviewToken(arg1 context.Context, arg2 string, arg3 []string) (*v1.TokenReviewStatus, error) {
+	var arg3Copy []string
+	if arg3 != nil {
+		arg3Copy = make([]string, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.reviewTokenMutex.Lock()
+	ret, specificReturn := fake.reviewTokenReturnsOnCall[len(fake.reviewTokenArgsForCall)]
+	fake.reviewTokenArgsForCall = append(fake.reviewTokenArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []string
+	}{arg1, arg2, arg3Copy})
+	stub := fake.ReviewTokenStub
+	fakeReturns := fake.reviewTokenReturns
+	fake.recordInvocation("ReviewToken", []interface{}{arg1, arg2, arg3Copy})
+	fake.reviewTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *TokenReviewer) ReviewTokenCallCount() int {
+	fake.reviewTokenMutex.RLock()
+	defer fake.reviewTokenMutex.RUnlock()
+	return len(fake.reviewTokenArgsForCall)
+}
+
+func (fake *TokenReviewer) ReviewTokenCalls(stub func(context.Context, string, []string) (*v1.TokenReviewStatus, error)) {
+	fake.reviewTokenMutex.Lock()
+	defer fake.reviewTokenMutex.Unlock()
+	fake.ReviewTokenStub = stub
+}
+
+func (fake *TokenReviewer) ReviewTokenArgsForCall(i int) (context.Context, string, []string) {
+	fake.reviewTokenMutex.RLock()
+	defer fake.reviewTokenMutex.RUnlock()
+	argsForCall := fake.reviewTokenArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *TokenReviewer) ReviewTokenReturns(result1 *v1.TokenReviewStatus, result2 error) {
+	fake.reviewTokenMutex.Lock()
+	defer fake.reviewTokenMutex.Unlock()
+	fake.ReviewTokenStub = nil
+	fake.reviewTokenReturns = struct {
+		result1 *v1.TokenReviewStatus
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *TokenReviewer) ReviewTokenReturnsOnCall(i int, result1 *v1.TokenReviewStatus, result2 error) {
+	fake.reviewTokenMutex.Lock()
+	defer fake.reviewTokenMutex.Unlock()
+	fake.ReviewTokenStub = nil
+	if fake.reviewTokenReturnsOnCall == nil {
+		fake.reviewTokenReturnsOnCall = make(map[int]struct {
+			result1 *v1.TokenReviewStatus
+			result2 error
+		})
+	}
+	fake.reviewTokenReturnsOnCall[i] = struct {
+		result1 *v1.TokenReviewStatus
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *TokenReviewer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.reviewTokenMutex.RLock()
+	defer fake.reviewTokenMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *TokenReviewer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ auth.TokenReviewer = new(TokenReviewer)
@@ -0,0 +1,54 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package auth
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+)
+
+// MTLS authenticates the client certificate verified during the TLS
+// handshake. The principal is the common name of the certificate and its
+// groups are the organizations, like kubernetes does for client certificates.
+type MTLS struct{}
+
+func (m *MTLS) Authenticate(r *http.Request) (*Principal, error) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil, ErrNoCredentials
+	}
+
+	// the listener only accepts certificates signed by the client CA, so
+	// only verified certificates get here
+	if len(r.TLS.VerifiedChains) == 0 {
+		return nil, errors.New("client certificate not verified")
+	}
+
+	cert := r.TLS.PeerCertificates[0]
+	if cert.Subject.CommonName == "" {
+		return nil, errors.New("client certificate has no common name")
+	}
+
+	return &Principal{
+		Name:   cert.Subject.CommonName,
+		Groups: cert.Subject.Organization,
+		Method: config.AuthMethodMTLS,
+	}, nil
+}
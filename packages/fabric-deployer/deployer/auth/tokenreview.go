@@ -0,0 +1,131 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	authenticationv1 "k8s.io/api/authentication/v1"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+)
+
+const (
+	// tokenReviewCacheTTL limits how often the same token is reviewed
+	tokenReviewCacheTTL = 10 * time.Second
+	tokenReviewTimeout  = 10 * time.Second
+)
+
+//go:generate counterfeiter -o mocks/token_reviewer.go -fake-name TokenReviewer . TokenReviewer
+
+type TokenReviewer interface {
+	ReviewToken(ctx context.Context, token string, audiences []string) (*authenticationv1.TokenReviewStatus, error)
+}
+
+// TokenReview authenticates bearer tokens with the kubernetes TokenReview API,
+// e.g. service account tokens
+type TokenReview struct {
+	Logger    *zap.SugaredLogger
+	Reviewer  TokenReviewer
+	Audiences []string
+
+	cache map[[sha256.Size]byte]cachedReview
+	mutex sync.Mutex
+}
+
+type cachedReview struct {
+	principal *Principal
+	expires   time.Time
+}
+
+func NewTokenReview(logger *zap.Logger, reviewer TokenReviewer, audiences []string) *TokenReview {
+	return &TokenReview{
+		Logger:    logger.Sugar().Named("TokenReview"),
+		Reviewer:  reviewer,
+		Audiences: audiences,
+		cache:     map[[sha256.Size]byte]cachedReview{},
+	}
+}
+
+func (t *TokenReview) Authenticate(r *http.Request) (*Principal, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	key := sha256.Sum256([]byte(token))
+	if principal, found := t.cached(key); found {
+		return principal, nil
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), tokenReviewTimeout)
+	defer cancel()
+	status, err := t.Reviewer.ReviewToken(ctx, token, t.Audiences)
+	if err != nil {
+		t.Logger.Errorf("Failed to review token: %s", err)
+		return nil, errors.New("failed to review token")
+	}
+	if !status.Authenticated {
+		if status.Error != "" {
+			return nil, errors.Errorf("token not authenticated: %s", status.Error)
+		}
+		return nil, errors.New("token not authenticated")
+	}
+
+	principal := &Principal{
+		Name:   status.User.Username,
+		Groups: status.User.Groups,
+		Method: config.AuthMethodTokenReview,
+	}
+	t.store(key, principal)
+
+	return principal, nil
+}
+
+func (t *TokenReview) cached(key [sha256.Size]byte) (*Principal, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	review, found := t.cache[key]
+	if !found || time.Now().After(review.expires) {
+		return nil, false
+	}
+	return review.principal, true
+}
+
+func (t *TokenReview) store(key [sha256.Size]byte, principal *Principal) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	for k, review := range t.cache {
+		if now.After(review.expires) {
+			delete(t.cache, k)
+		}
+	}
+	t.cache[key] = cachedReview{
+		principal: principal,
+		expires:   now.Add(tokenReviewCacheTTL),
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	authenticationv1 "k8s.io/api/authentication/v1"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/auth"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/auth/mocks"
+)
+
+var _ = Describe("TokenReview", func() {
+	var (
+		reviewer    *mocks.TokenReviewer
+		tokenReview *auth.TokenReview
+		request     *http.Request
+	)
+
+	BeforeEach(func() {
+		reviewer = &mocks.TokenReviewer{}
+		reviewer.ReviewTokenReturns(&authenticationv1.TokenReviewStatus{
+			Authenticated: true,
+			User: authenticationv1.UserInfo{
+				Username: "system:serviceaccount:ns:console",
+				Groups:   []string{"system:serviceaccounts"},
+			},
+		}, nil)
+		tokenReview = auth.NewTokenReview(zap.NewNop(), reviewer, []string{"deployer"})
+
+		request = httptest.NewRequest(http.MethodGet, "/api/v3/instance/sid/ca/ca1", nil)
+		request.Header.Set("Authorization", "Bearer token")
+	})
+
+	It("returns no credentials without a bearer token", func() {
+		request.Header.Del("Authorization")
+		_, err := tokenReview.Authenticate(request)
+		Expect(err).To(Equal(auth.ErrNoCredentials))
+		Expect(reviewer.ReviewTokenCallCount()).To(Equal(0))
+	})
+
+	It("returns the user of the token review", func() {
+		principal, err := tokenReview.Authenticate(request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(principal.Name).To(Equal("system:serviceaccount:ns:console"))
+		Expect(principal.Groups).To(Equal([]string{"system:serviceaccounts"}))
+		Expect(principal.Method).To(Equal(config.AuthMethodTokenReview))
+
+		_, token, audiences := reviewer.ReviewTokenArgsForCall(0)
+		Expect(token).To(Equal("token"))
+		Expect(audiences).To(Equal([]string{"deployer"}))
+	})
+
+	It("caches authenticated tokens", func() {
+		_, err := tokenReview.Authenticate(request)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = tokenReview.Authenticate(request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reviewer.ReviewTokenCallCount()).To(Equal(1))
+	})
+
+	It("rejects tokens that are not authenticated", func() {
+		reviewer.ReviewTokenReturns(&authenticationv1.TokenReviewStatus{
+			Error: "token expired",
+		}, nil)
+		_, err := tokenReview.Authenticate(request)
+		Expect(err).To(MatchError("token not authenticated: token expired"))
+
+		_, err = tokenReview.Authenticate(request)
+		Expect(err).To(HaveOccurred())
+		Expect(reviewer.ReviewTokenCallCount()).To(Equal(2))
+	})
+
+	It("returns an error if the review fails", func() {
+		reviewer.ReviewTokenReturns(nil, errors.New("connection refused"))
+		_, err := tokenReview.Authenticate(request)
+		Expect(err).To(MatchError("failed to review token"))
+	})
+})
@@ -0,0 +1,258 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/auth"
+)
+
+const (
+	// ResourceComponent is the resource of the component apis, under
+	// /type/{type} and /precreate/type/{type}
+	ResourceComponent = "component"
+
+	anyValue = "*"
+)
+
+// DefaultRoles are the rules of the predefined roles. Viewers can read
+// everything but the mustgather, operators can also create and update
+// components, e.g. run actions or take snapshots, and admins are allowed
+// everything, including restoring snapshots.
+var DefaultRoles = map[string][]config.PolicyRule{
+	config.RoleViewer: {
+		{
+			Verbs:     []string{http.MethodGet},
+			Resources: []string{ResourceComponent, "operations", "events", "hsmconfig", "k8s", "resources", "config", "metrics"},
+		},
+	},
+	config.RoleOperator: {
+		{
+			Verbs:     []string{http.MethodGet},
+			Resources: []string{ResourceComponent, "operations", "events", "hsmconfig", "k8s", "resources", "config", "metrics"},
+		},
+		{
+			Verbs:     []string{http.MethodPost, http.MethodPut, http.MethodPatch},
+			Resources: []string{ResourceComponent},
+			// all the sections but restore, which replaces the volumes
+			Sections: []string{"", "actions", "admincerts", "config", "crypto", "endpoints", "genesis", "hsm", "nodeou",
+				"replicas", "resources", "snapshot", "status", "storage", "version"},
+		},
+	},
+	config.RoleAdmin: {
+		{
+			Verbs: []string{anyValue},
+		},
+	},
+}
+
+// authMethods are the authentication methods the bindings can be of
+var authMethods = []string{config.AuthMethodBasic, config.AuthMethodJWKS, config.AuthMethodTokenReview, config.AuthMethodMTLS}
+
+// Authorizer decides whether a principal is allowed to make a request based
+// on the roles bound to it
+type Authorizer struct {
+	roles       map[string][]config.PolicyRule
+	bindings    []config.RoleBinding
+	defaultRole string
+}
+
+// AccessRequest is the part of a request the policy rules are matched against
+type AccessRequest struct {
+	Verb     string
+	Resource string
+	Type     string
+	Section  string
+}
+
+func (a AccessRequest) String() string {
+	s := fmt.Sprintf("%s %s", a.Verb, a.Resource)
+	if a.Type != "" {
+		s += " of type " + a.Type
+	}
+	if a.Section != "" {
+		s += " section " + a.Section
+	}
+	return s
+}
+
+// NewAuthorizer returns the authorizer of the configuration, nil is returned
+// when authorization is not configured
+func NewAuthorizer(cfg *config.Authorization) (*Authorizer, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	roles := map[string][]config.PolicyRule{}
+	for name, rules := range DefaultRoles {
+		roles[name] = rules
+	}
+	for name, rules := range cfg.Roles {
+		roles[name] = rules
+	}
+
+	for _, binding := range cfg.Bindings {
+		if _, found := roles[binding.Role]; !found {
+			return nil, errors.Errorf("role binding references unknown role '%s'", binding.Role)
+		}
+		if !contains(authMethods, binding.Method) {
+			return nil, errors.Errorf("role binding of role '%s' has unknown authentication method '%s'", binding.Role, binding.Method)
+		}
+	}
+	if cfg.DefaultRole != "" {
+		if _, found := roles[cfg.DefaultRole]; !found {
+			return nil, errors.Errorf("default role '%s' is unknown", cfg.DefaultRole)
+		}
+	}
+
+	return &Authorizer{
+		roles:       roles,
+		bindings:    cfg.Bindings,
+		defaultRole: cfg.DefaultRole,
+	}, nil
+}
+
+// Roles returns the roles bound to the principal by the bindings of its
+// authentication method
+func (a *Authorizer) Roles(principal *auth.Principal) []string {
+	roles := []string{}
+	for _, binding := range a.bindings {
+		if binding.Method != principal.Method {
+			continue
+		}
+		if contains(binding.Users, principal.Name) || containsAny(binding.Groups, principal.Groups) {
+			roles = append(roles, binding.Role)
+		}
+	}
+	if len(roles) == 0 && a.defaultRole != "" {
+		roles = append(roles, a.defaultRole)
+	}
+	return roles
+}
+
+// Authorize returns true if a rule of a role of the principal allows the request
+func (a *Authorizer) Authorize(principal *auth.Principal, request AccessRequest) bool {
+	for _, role := range a.Roles(principal) {
+		for _, rule := range a.roles[role] {
+			if ruleMatches(rule, request) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func ruleMatches(rule config.PolicyRule, request AccessRequest) bool {
+	verbs := []string{}
+	for _, verb := range rule.Verbs {
+		verbs = append(verbs, strings.ToUpper(verb))
+	}
+
+	return matches(verbs, request.Verb) &&
+		matches(rule.Resources, request.Resource) &&
+		matches(rule.Types, request.Type) &&
+		matches(rule.Sections, request.Section)
+}
+
+func matches(values []string, value string) bool {
+	return len(values) == 0 || contains(values, anyValue) || contains(values, value)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(values []string, others []string) bool {
+	for _, other := range others {
+		if contains(values, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessRequest returns the access request of a routed request, the resource
+// is the first segment of the route after the service instance, or of the
+// route for the routes outside of the service instances, e.g. metrics
+func accessRequest(r *http.Request) AccessRequest {
+	request := AccessRequest{
+		Verb:    r.Method,
+		Type:    chi.URLParam(r, "type"),
+		Section: chi.URLParam(r, "section"),
+	}
+
+	pattern := chi.RouteContext(r.Context()).RoutePattern()
+	segments := strings.Split(strings.TrimPrefix(strings.TrimPrefix(pattern, "/api/v3/instance/{serviceInstanceID}"), "/"), "/")
+	switch segments[0] {
+	case "type":
+		request.Resource = ResourceComponent
+		// the sections with their own route, e.g. restore
+		if request.Section == "" && len(segments) == 5 {
+			request.Section = segments[4]
+		}
+	case "precreate":
+		request.Resource = ResourceComponent
+		request.Type = segments[2]
+	default:
+		request.Resource = segments[0]
+	}
+
+	return request
+}
+
+// AuthorizationMiddleware rejects the requests not allowed by the roles of
+// the principal. It must be used on routed requests, after the AuthMiddleware.
+func (d *Deployer) AuthorizationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if d.Authorizer == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		request := accessRequest(r)
+		principal, found := auth.PrincipalFrom(r.Context())
+		if found && d.Authorizer.Authorize(principal, request) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		name := ""
+		if found {
+			name = principal.Name
+		}
+		d.Logger.Infof("Request of '%s' to %s %s denied", name, r.Method, r.URL.Path)
+
+		NewEndpoint(func(http.ResponseWriter, *http.Request) (interface{}, int, error) {
+			return nil, 0, apierror.New(apierror.Forbidden, apierror.CodeForbidden, "forbidden, '%s' is not allowed to %s", name, request)
+		}, d.LocalConfig.Logger).ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,250 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/auth"
+)
+
+var _ = Describe("Authorization", func() {
+	var (
+		cfg        *config.Authorization
+		authorizer *deployer.Authorizer
+		alice      *auth.Principal
+		bob        *auth.Principal
+	)
+
+	BeforeEach(func() {
+		cfg = &config.Authorization{
+			Roles: map[string][]config.PolicyRule{
+				"reenroller": {
+					{
+						Verbs:     []string{"patch"},
+						Resources: []string{deployer.ResourceComponent},
+						Types:     []string{"peer", "orderer"},
+						Sections:  []string{"actions"},
+					},
+				},
+			},
+			Bindings: []config.RoleBinding{
+				{Role: config.RoleAdmin, Method: config.AuthMethodBasic, Users: []string{"alice"}},
+				{Role: config.RoleViewer, Method: config.AuthMethodJWKS, Groups: []string{"auditors"}},
+				{Role: "reenroller", Method: config.AuthMethodJWKS, Groups: []string{"auditors"}},
+			},
+		}
+		alice = &auth.Principal{Name: "alice", Method: config.AuthMethodBasic}
+		bob = &auth.Principal{Name: "bob", Groups: []string{"auditors"}, Method: config.AuthMethodJWKS}
+
+		var err error
+		authorizer, err = deployer.NewAuthorizer(cfg)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Context("new", func() {
+		It("returns nil if authorization is not configured", func() {
+			authorizer, err := deployer.NewAuthorizer(nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authorizer).To(BeNil())
+		})
+
+		It("returns an error if a binding references an unknown role", func() {
+			cfg.Bindings = append(cfg.Bindings, config.RoleBinding{Role: "unknown", Method: config.AuthMethodBasic, Users: []string{"bob"}})
+			_, err := deployer.NewAuthorizer(cfg)
+			Expect(err).To(MatchError("role binding references unknown role 'unknown'"))
+		})
+
+		It("returns an error if a binding has no authentication method", func() {
+			cfg.Bindings = append(cfg.Bindings, config.RoleBinding{Role: config.RoleAdmin, Users: []string{"bob"}})
+			_, err := deployer.NewAuthorizer(cfg)
+			Expect(err).To(MatchError("role binding of role 'admin' has unknown authentication method ''"))
+		})
+
+		It("returns an error if the default role is unknown", func() {
+			cfg.DefaultRole = "unknown"
+			_, err := deployer.NewAuthorizer(cfg)
+			Expect(err).To(MatchError("default role 'unknown' is unknown"))
+		})
+	})
+
+	Context("roles", func() {
+		It("returns the roles bound to the user and its groups", func() {
+			Expect(authorizer.Roles(alice)).To(Equal([]string{config.RoleAdmin}))
+			Expect(authorizer.Roles(bob)).To(Equal([]string{config.RoleViewer, "reenroller"}))
+		})
+
+		It("only binds the users and groups of the authentication method of the binding", func() {
+			jwtAlice := &auth.Principal{Name: "alice", Method: config.AuthMethodJWKS}
+			Expect(authorizer.Roles(jwtAlice)).To(BeEmpty())
+			basicBob := &auth.Principal{Name: "bob", Groups: []string{"auditors"}, Method: config.AuthMethodBasic}
+			Expect(authorizer.Roles(basicBob)).To(BeEmpty())
+		})
+
+		It("returns the default role for principals without bindings", func() {
+			carol := &auth.Principal{Name: "carol"}
+			Expect(authorizer.Roles(carol)).To(BeEmpty())
+
+			cfg.DefaultRole = config.RoleViewer
+			authorizer, err := deployer.NewAuthorizer(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authorizer.Roles(carol)).To(Equal([]string{config.RoleViewer}))
+		})
+	})
+
+	Context("authorize", func() {
+		It("allows everything to admins", func() {
+			Expect(authorizer.Authorize(alice, deployer.AccessRequest{Verb: http.MethodDelete, Resource: deployer.ResourceComponent, Type: "orderer"})).To(BeTrue())
+			Expect(authorizer.Authorize(alice, deployer.AccessRequest{Verb: http.MethodPost, Resource: "mustgather"})).To(BeTrue())
+		})
+
+		It("allows the requests matching a rule of the roles", func() {
+			Expect(authorizer.Authorize(bob, deployer.AccessRequest{Verb: http.MethodGet, Resource: deployer.ResourceComponent, Type: "ca"})).To(BeTrue())
+			Expect(authorizer.Authorize(bob, deployer.AccessRequest{Verb: http.MethodPatch, Resource: deployer.ResourceComponent, Type: "peer", Section: "actions"})).To(BeTrue())
+		})
+
+		It("denies the requests not matching any rule", func() {
+			Expect(authorizer.Authorize(bob, deployer.AccessRequest{Verb: http.MethodDelete, Resource: deployer.ResourceComponent, Type: "peer"})).To(BeFalse())
+			Expect(authorizer.Authorize(bob, deployer.AccessRequest{Verb: http.MethodPatch, Resource: deployer.ResourceComponent, Type: "peer", Section: "resources"})).To(BeFalse())
+			Expect(authorizer.Authorize(bob, deployer.AccessRequest{Verb: http.MethodPatch, Resource: deployer.ResourceComponent, Type: "ca", Section: "actions"})).To(BeFalse())
+			Expect(authorizer.Authorize(bob, deployer.AccessRequest{Verb: http.MethodGet, Resource: "mustgather"})).To(BeFalse())
+		})
+
+		It("allows operators to update but not delete components", func() {
+			operator := &auth.Principal{Name: "operator"}
+			cfg.DefaultRole = config.RoleOperator
+			authorizer, err := deployer.NewAuthorizer(cfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(authorizer.Authorize(operator, deployer.AccessRequest{Verb: http.MethodPatch, Resource: deployer.ResourceComponent, Type: "peer", Section: "actions"})).To(BeTrue())
+			Expect(authorizer.Authorize(operator, deployer.AccessRequest{Verb: http.MethodDelete, Resource: deployer.ResourceComponent, Type: "peer"})).To(BeFalse())
+		})
+
+		It("only allows admins to restore snapshots", func() {
+			operator := &auth.Principal{Name: "operator"}
+			cfg.DefaultRole = config.RoleOperator
+			authorizer, err := deployer.NewAuthorizer(cfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(authorizer.Authorize(operator, deployer.AccessRequest{Verb: http.MethodPut, Resource: deployer.ResourceComponent, Type: "peer", Section: "snapshot"})).To(BeTrue())
+			Expect(authorizer.Authorize(operator, deployer.AccessRequest{Verb: http.MethodPut, Resource: deployer.ResourceComponent, Type: "peer", Section: "restore"})).To(BeFalse())
+			Expect(authorizer.Authorize(alice, deployer.AccessRequest{Verb: http.MethodPut, Resource: deployer.ResourceComponent, Type: "peer", Section: "restore"})).To(BeTrue())
+		})
+	})
+
+	Context("middleware", func() {
+		var (
+			d       *deployer.Deployer
+			handled bool
+		)
+
+		serve := func(principal *auth.Principal, method, pattern, path string) *httptest.ResponseRecorder {
+			handled = false
+			router := d.Router
+			router.With(d.RequestIDMiddleware, d.AuthorizationMiddleware).MethodFunc(method, pattern, func(w http.ResponseWriter, r *http.Request) {
+				handled = true
+			})
+
+			req := httptest.NewRequest(method, path, nil)
+			req.Header.Set(deployer.RequestIDHeader, "req-1")
+			req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		BeforeEach(func() {
+			d = deployer.New(&config.DeployerSettingsConfig{}, &config.LocalConfig{Logger: zap.NewNop()}, false)
+			d.Authorizer = authorizer
+		})
+
+		It("allows all requests if authorization is not configured", func() {
+			d.Authorizer = nil
+			w := serve(bob, http.MethodDelete, "/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}", "/api/v3/instance/sid/type/peer/component/peer1")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(handled).To(BeTrue())
+		})
+
+		It("allows the requests matching the route parameters", func() {
+			w := serve(bob, http.MethodPatch, "/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}/{section}", "/api/v3/instance/sid/type/peer/component/peer1/actions")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(handled).To(BeTrue())
+		})
+
+		It("returns a 403 error for denied requests", func() {
+			w := serve(bob, http.MethodDelete, "/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}", "/api/v3/instance/sid/type/peer/component/peer1")
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			Expect(handled).To(BeFalse())
+
+			errs := &deployer.Errors{}
+			Expect(json.Unmarshal(w.Body.Bytes(), errs)).To(Succeed())
+			Expect(errs.Status).To(Equal(http.StatusForbidden))
+			Expect(errs.Message).To(Equal("forbidden, 'bob' is not allowed to DELETE component of type peer"))
+			Expect(errs.Kind).To(Equal(apierror.Forbidden))
+			Expect(errs.RequestID).To(Equal("req-1"))
+		})
+
+		It("uses the last path segment of the component routes as section", func() {
+			cfg.DefaultRole = config.RoleOperator
+			authorizer, err := deployer.NewAuthorizer(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			d.Authorizer = authorizer
+			carol := &auth.Principal{Name: "carol"}
+
+			w := serve(carol, http.MethodPut, "/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}/restore", "/api/v3/instance/sid/type/peer/component/peer1/restore")
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			Expect(w.Body.String()).To(ContainSubstring("not allowed to PUT component of type peer section restore"))
+		})
+
+		It("uses the first path segment as resource", func() {
+			w := serve(bob, http.MethodPost, "/api/v3/instance/{serviceInstanceID}/mustgather", "/api/v3/instance/sid/mustgather")
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			Expect(w.Body.String()).To(ContainSubstring("not allowed to POST mustgather"))
+
+			w = serve(alice, http.MethodPost, "/api/v3/instance/{serviceInstanceID}/mustgather", "/api/v3/instance/sid/mustgather")
+			Expect(w.Code).To(Equal(http.StatusOK))
+		})
+
+		It("uses the first path segment of the routes outside of the service instances as resource", func() {
+			w := serve(bob, http.MethodGet, "/metrics", "/metrics")
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			d.Authorizer, _ = deployer.NewAuthorizer(&config.Authorization{
+				Bindings: []config.RoleBinding{{Role: "reenroller", Method: config.AuthMethodJWKS, Groups: []string{"auditors"}}},
+				Roles:    cfg.Roles,
+			})
+			w = serve(bob, http.MethodGet, "/metrics", "/metrics")
+			Expect(w.Body.String()).To(ContainSubstring("not allowed to GET metrics"))
+		})
+
+		It("uses the orderer type for precreate requests", func() {
+			w := serve(bob, http.MethodPost, "/api/v3/instance/{serviceInstanceID}/precreate/type/orderer/component/{componentName}", "/api/v3/instance/sid/precreate/type/orderer/component/os1")
+			Expect(w.Body.String()).To(ContainSubstring("not allowed to POST component of type orderer"))
+		})
+	})
+})
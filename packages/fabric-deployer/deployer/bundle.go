@@ -0,0 +1,107 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/bundle"
+)
+
+// PassphraseHeader is the header of the passphrase that encrypts the
+// sensitive values of exported archives
+const PassphraseHeader = "X-Bundle-Passphrase"
+
+// ExportHandler writes the archive of the component definitions of the
+// service instance
+func (d *Deployer) ExportHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sID := chi.URLParam(r, "serviceInstanceID")
+
+		namespace := d.namespace(r)
+
+		archive := &bytes.Buffer{}
+		b, err := d.target(r).Bundle.Export(sID, namespace, d.clusterComponents(r).Config.Domain)
+		if err == nil {
+			err = bundle.Write(archive, b, r.Header.Get(PassphraseHeader))
+		}
+		if err != nil {
+			NewEndpoint(func(http.ResponseWriter, *http.Request) (interface{}, int, error) {
+				return nil, 0, err
+			}, d.LocalConfig.Logger).ServeHTTP(w, r)
+			return
+		}
+
+		d.Logger.Infof("Exported %d cas, %d peers, %d orderers and %d secrets of namespace '%s'",
+			len(b.CAs), len(b.Peers), len(b.Orderers), len(b.Secrets), namespace)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s-export.tar.gz", sID))
+		w.Header().Set("Content-Type", "application/x-gzip")
+		w.Header().Set("Content-Length", strconv.Itoa(archive.Len()))
+		w.WriteHeader(http.StatusOK)
+		_, err = archive.WriteTo(w)
+		if err != nil {
+			d.Logger.Errorf("Failed to write export archive: %s", err)
+		}
+	}
+}
+
+// ImportEndpoint returns an endpoint type that is responsible for handling
+// importing an archive of component definitions
+func (d *Deployer) ImportEndpoint() func(http.ResponseWriter, *http.Request) {
+	return NewEndpoint(d.Import, d.LocalConfig.Logger).ServeHTTP
+}
+
+// Import recreates the components of the archive of the request body in the
+// namespace of the service instance. The domain, registry and storageClass query
+// parameters remap the components to this cluster. If any component already
+// exists, nothing is imported and the conflicts are returned.
+func (d *Deployer) Import(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	sID := chi.URLParam(r, "serviceInstanceID")
+
+	b, err := bundle.Read(http.MaxBytesReader(w, r.Body, bundle.MaxArchiveSize), r.Header.Get(PassphraseHeader))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := r.URL.Query()
+	mapping := bundle.Mapping{
+		Domain:       query.Get("domain"),
+		Registry:     query.Get("registry"),
+		StorageClass: query.Get("storageClass"),
+	}
+
+	resp, err := d.target(r).Bundle.Import(sID, d.namespace(r), b, mapping, isDryRun(r))
+	if resp == nil {
+		return nil, 0, err
+	}
+	if err != nil {
+		return resp, apierror.StatusCode(err), nil
+	}
+	if resp.Conflicts > 0 {
+		return resp, http.StatusConflict, nil
+	}
+
+	return resp, http.StatusOK, nil
+}
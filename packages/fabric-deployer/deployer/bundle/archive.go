@@ -0,0 +1,297 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+)
+
+// metadataFile is the name of the file of the metadata in the archive, the
+// objects are in a directory per kind, e.g. peers/peer1.json
+const metadataFile = "bundle.json"
+
+// Limits of the archives that are read, so that a small compressed archive
+// cannot expand into files that exhaust the memory of the deployer
+const (
+	// MaxArchiveSize is the maximum size of a compressed archive
+	MaxArchiveSize = 32 << 20
+	// MaxFileSize is the maximum size of a file of the archive
+	MaxFileSize = 4 << 20
+	// maxFilesSize is the maximum size of all the files of the archive
+	maxFilesSize = 128 << 20
+)
+
+const (
+	casDir      = "cas"
+	peersDir    = "peers"
+	orderersDir = "orderers"
+	secretsDir  = "secrets"
+)
+
+// Write writes the bundle as a gzipped tar archive. If passphrase is not
+// empty, the sensitive values of the objects are encrypted with a key derived
+// from it.
+func Write(w io.Writer, b *Bundle, passphrase string) error {
+	metadata := b.Metadata
+	metadata.Version = Version
+	metadata.Encrypted = passphrase != ""
+
+	var key *cipherKey
+	if metadata.Encrypted {
+		salt, err := newSalt()
+		if err != nil {
+			return err
+		}
+		metadata.Salt = base64.StdEncoding.EncodeToString(salt)
+		key, err = newCipherKey(passphrase, salt)
+		if err != nil {
+			return err
+		}
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	err := writeFile(tw, metadataFile, metadata)
+	if err != nil {
+		return err
+	}
+	for _, cr := range b.CAs {
+		err = writeObject(tw, key, casDir, cr.Name, cr, false)
+		if err != nil {
+			return err
+		}
+	}
+	for _, cr := range b.Peers {
+		err = writeObject(tw, key, peersDir, cr.Name, cr, false)
+		if err != nil {
+			return err
+		}
+	}
+	for _, cr := range b.Orderers {
+		err = writeObject(tw, key, orderersDir, cr.Name, cr, false)
+		if err != nil {
+			return err
+		}
+	}
+	for _, secret := range b.Secrets {
+		err = writeObject(tw, key, secretsDir, secret.Name, secret, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return errors.Wrap(err, "failed to write archive")
+	}
+	return gz.Close()
+}
+
+func writeObject(tw *tar.Writer, key *cipherKey, dir, name string, obj interface{}, secret bool) error {
+	if key == nil {
+		return writeFile(tw, path.Join(dir, name+".json"), obj)
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal '%s'", name)
+	}
+	object := map[string]interface{}{}
+	err = json.Unmarshal(data, &object)
+	if err != nil {
+		return errors.Wrapf(err, "failed to unmarshal '%s'", name)
+	}
+	err = key.encryptObject(object, secret)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encrypt '%s'", name)
+	}
+	return writeFile(tw, path.Join(dir, name+".json"), object)
+}
+
+func writeFile(tw *tar.Writer, name string, obj interface{}) error {
+	data, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal '%s'", name)
+	}
+
+	err = tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	})
+	if err == nil {
+		_, err = tw.Write(data)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to write '%s' to archive", name)
+	}
+	return nil
+}
+
+// Read reads a bundle written by Write, passphrase is required if the
+// archive is encrypted. The objects are sorted by name. The files of the
+// archive are limited to MaxFileSize, callers limit the size of r to
+// MaxArchiveSize.
+func Read(r io.Reader, passphrase string) (*Bundle, error) {
+	invalid := func(err error) error {
+		return apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "invalid archive")
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	tr := tar.NewReader(gz)
+
+	files := map[string][]byte{}
+	size := 0
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, invalid(err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, MaxFileSize+1))
+		if err != nil {
+			return nil, invalid(err)
+		}
+		if len(data) > MaxFileSize {
+			return nil, apierror.New(apierror.Validation, apierror.CodeInvalidRequest, "invalid archive: %s is larger than %d bytes", header.Name, MaxFileSize)
+		}
+		size += len(data)
+		if size > maxFilesSize {
+			return nil, apierror.New(apierror.Validation, apierror.CodeInvalidRequest, "invalid archive: the files are larger than %d bytes", maxFilesSize)
+		}
+		files[path.Clean(header.Name)] = data
+	}
+
+	b := &Bundle{}
+	data, found := files[metadataFile]
+	if !found {
+		return nil, apierror.New(apierror.Validation, apierror.CodeInvalidRequest, "invalid archive: %s not found", metadataFile)
+	}
+	err = json.Unmarshal(data, &b.Metadata)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	if b.Metadata.Version != Version {
+		return nil, apierror.New(apierror.Validation, apierror.CodeInvalidRequest, "invalid archive: version '%s' is not supported", b.Metadata.Version)
+	}
+
+	var key *cipherKey
+	if b.Metadata.Encrypted {
+		if passphrase == "" {
+			return nil, apierror.New(apierror.Validation, apierror.CodeInvalidRequest, "the archive is encrypted, a passphrase is required")
+		}
+		salt, err := base64.StdEncoding.DecodeString(b.Metadata.Salt)
+		if err != nil {
+			return nil, invalid(err)
+		}
+		key, err = newCipherKey(passphrase, salt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == metadataFile {
+			continue
+		}
+		dir, file := path.Split(name)
+		if !strings.HasSuffix(file, ".json") {
+			continue
+		}
+
+		var obj interface{}
+		switch strings.TrimSuffix(dir, "/") {
+		case casDir:
+			b.CAs = append(b.CAs, current.IBPCA{})
+			obj = &b.CAs[len(b.CAs)-1]
+		case peersDir:
+			b.Peers = append(b.Peers, current.IBPPeer{})
+			obj = &b.Peers[len(b.Peers)-1]
+		case orderersDir:
+			b.Orderers = append(b.Orderers, current.IBPOrderer{})
+			obj = &b.Orderers[len(b.Orderers)-1]
+		case secretsDir:
+			b.Secrets = append(b.Secrets, corev1.Secret{})
+			obj = &b.Secrets[len(b.Secrets)-1]
+		default:
+			continue
+		}
+
+		err = readObject(key, files[name], obj)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to read '%s'", name)
+		}
+	}
+
+	return b, nil
+}
+
+func readObject(key *cipherKey, data []byte, obj interface{}) error {
+	if key != nil {
+		object := map[string]interface{}{}
+		err := json.Unmarshal(data, &object)
+		if err != nil {
+			return apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "invalid archive")
+		}
+		err = key.decryptObject(object)
+		if err != nil {
+			return err
+		}
+		data, err = json.Marshal(object)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := json.Unmarshal(data, obj)
+	if err != nil {
+		return apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "invalid archive")
+	}
+	return nil
+}
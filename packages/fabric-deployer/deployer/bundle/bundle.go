@@ -0,0 +1,466 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package bundle exports the component definitions of a service instance as
+// a portable archive, and imports them in another namespace or cluster.
+package bundle
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer"
+)
+
+// Version of the archive format
+const Version = "v1"
+
+// Kinds of the objects of a bundle
+const (
+	KindCA      = "ca"
+	KindPeer    = "peer"
+	KindOrderer = "orderer"
+	KindSecret  = "secret"
+)
+
+// Results of the import of an object
+const (
+	StatusCreated  = "created"
+	StatusConflict = "conflict"
+	StatusFailed   = "failed"
+	StatusSkipped  = "skipped"
+)
+
+// Bundle is the definition of the components of a service instance: the
+// specs of their CRs and the secrets the specs reference
+type Bundle struct {
+	Metadata Metadata
+	CAs      []current.IBPCA
+	Peers    []current.IBPPeer
+	Orderers []current.IBPOrderer
+	Secrets  []corev1.Secret
+}
+
+type Metadata struct {
+	Version    string    `json:"version"`
+	ExportedAt time.Time `json:"exportedAt"`
+	Namespace  string    `json:"namespace"`
+	Domain     string    `json:"domain,omitempty"`
+	Encrypted  bool      `json:"encrypted,omitempty"`
+	// Salt of the key derived from the passphrase, base64 encoded
+	Salt string `json:"salt,omitempty"`
+}
+
+// Mapping remaps the components of a bundle to the target cluster, the empty
+// fields are not remapped
+type Mapping struct {
+	Domain       string `json:"domain,omitempty"`
+	Registry     string `json:"registry,omitempty"`
+	StorageClass string `json:"storageClass,omitempty"`
+}
+
+// Result is the result of the import of an object
+type Result struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type ImportResponse struct {
+	DryRun    bool      `json:"dryRun,omitempty"`
+	Conflicts int       `json:"conflicts"`
+	Results   []*Result `json:"results"`
+}
+
+//go:generate counterfeiter -o mocks/kube.go -fake-name Kube . Kube
+
+type Kube interface {
+	GetSecret(namespace string, name string) (*corev1.Secret, error)
+	CreateSecret(namespace string, secret *corev1.Secret) (*corev1.Secret, error)
+}
+
+//go:generate counterfeiter -o mocks/ibp_client.go -fake-name IBPOperatorClient . IBPOperatorClient
+
+type IBPOperatorClient interface {
+	GetCR(namespace string, kind string, name string, cr runtime.Object) error
+	GetAllCR(namespace string, kind string, crList runtime.Object) error
+	CreateCR(namespace string, kind string, cr interface{}) error
+}
+
+type Bundler struct {
+	Kube              Kube
+	Logger            *zap.SugaredLogger
+	IBPOperatorClient IBPOperatorClient
+}
+
+func New(logger *zap.Logger, kube Kube, ibpClient IBPOperatorClient) *Bundler {
+	return &Bundler{
+		Kube:              kube,
+		Logger:            logger.Sugar().Named("Bundle"),
+		IBPOperatorClient: ibpClient,
+	}
+}
+
+// Export returns the CRs of the service instance in the namespace and the
+// secrets they reference, the image pull secrets and the MSP secrets of the
+// peers, and the crypto secrets the operator created for them, so that the
+// imported components keep their identities. The status and the server set
+// metadata of the objects are removed.
+func (b *Bundler) Export(sID, namespace, domain string) (*Bundle, error) {
+	bundle := &Bundle{
+		Metadata: Metadata{
+			Version:    Version,
+			ExportedAt: time.Now().UTC(),
+			Namespace:  namespace,
+			Domain:     domain,
+		},
+	}
+	secrets := map[string]bool{}
+	// secrets that only exist for some components, they are not reported
+	// when not found
+	optional := map[string]bool{}
+
+	caList := &current.IBPCAList{}
+	err := b.IBPOperatorClient.GetAllCR(namespace, "ibpcas", caList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the ca crs in namespace '%s'", namespace)
+	}
+	for _, cr := range caList.Items {
+		if !common.BelongsTo(cr.Labels, sID) {
+			continue
+		}
+		cr.ObjectMeta = portableMeta(cr.ObjectMeta)
+		cr.Status = current.IBPCAStatus{}
+		bundle.CAs = append(bundle.CAs, cr)
+		addNames(secrets, cr.Spec.ImagePullSecrets...)
+		addNames(secrets, caCryptoSecrets(cr.Name)...)
+	}
+
+	peerList := &current.IBPPeerList{}
+	err = b.IBPOperatorClient.GetAllCR(namespace, "ibppeers", peerList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the peer crs in namespace '%s'", namespace)
+	}
+	for _, cr := range peerList.Items {
+		if !common.BelongsTo(cr.Labels, sID) {
+			continue
+		}
+		cr.ObjectMeta = portableMeta(cr.ObjectMeta)
+		cr.Status = current.IBPPeerStatus{}
+		bundle.Peers = append(bundle.Peers, cr)
+		addNames(secrets, cr.Spec.ImagePullSecrets...)
+		addNames(secrets, cr.Spec.MSPSecret)
+		addNodeCryptoSecrets(secrets, optional, cr.Name)
+	}
+
+	ordererList := &current.IBPOrdererList{}
+	err = b.IBPOperatorClient.GetAllCR(namespace, "ibporderers", ordererList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the orderer crs in namespace '%s'", namespace)
+	}
+	parents := map[string]*current.IBPOrderer{}
+	clusters := map[string]bool{}
+	for i := range ordererList.Items {
+		parents[ordererList.Items[i].Name] = &ordererList.Items[i]
+		addNames(clusters, ordererList.Items[i].Labels["parent"])
+	}
+	for _, cr := range ordererList.Items {
+		if !common.BelongsTo(orderer.ServiceInstanceLabels(&cr, parents[cr.Labels["parent"]]), sID) {
+			continue
+		}
+		cr.ObjectMeta = portableMeta(cr.ObjectMeta)
+		cr.Status = current.IBPOrdererStatus{}
+		bundle.Orderers = append(bundle.Orderers, cr)
+		addNames(secrets, cr.Spec.ImagePullSecrets...)
+		// the parents of the clusters run no node and have no crypto
+		if !clusters[cr.Name] {
+			addNodeCryptoSecrets(secrets, optional, cr.Name)
+		}
+	}
+
+	for _, name := range sortedNames(secrets) {
+		secret, err := b.Kube.GetSecret(namespace, name)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				if !optional[name] {
+					b.Logger.Warnf("Secret '%s' referenced by the components is not found in namespace '%s', it is not exported", name, namespace)
+				}
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to get secret '%s' in namespace '%s'", name, namespace)
+		}
+		bundle.Secrets = append(bundle.Secrets, corev1.Secret{
+			ObjectMeta: portableMeta(secret.ObjectMeta),
+			Type:       secret.Type,
+			Data:       secret.Data,
+		})
+	}
+
+	return bundle, nil
+}
+
+// portableMeta returns the metadata of the object without the fields set by
+// the API server and the namespace
+func portableMeta(meta metav1.ObjectMeta) metav1.ObjectMeta {
+	annotations := map[string]string{}
+	for key, value := range meta.Annotations {
+		if key != corev1.LastAppliedConfigAnnotation {
+			annotations[key] = value
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+
+	return metav1.ObjectMeta{
+		Name:        meta.Name,
+		Labels:      meta.Labels,
+		Annotations: annotations,
+	}
+}
+
+// Import creates the objects of the bundle in the namespace for the service
+// instance, after remapping them. The secrets are created first, then the
+// CAs, peers and orderers.
+// Nothing is created if any object already exists, the conflicts are
+// reported with the status of each object. On dry runs the objects are only
+// checked for conflicts.
+func (b *Bundler) Import(sID, namespace string, bundle *Bundle, mapping Mapping, dryRun bool) (*ImportResponse, error) {
+	Remap(bundle, mapping)
+
+	objects := []*importObject{}
+
+	for i := range bundle.Secrets {
+		secret := &bundle.Secrets[i]
+		secret.Namespace = namespace
+		objects = append(objects, &importObject{
+			result: &Result{Kind: KindSecret, Name: secret.Name},
+			exists: func() (bool, error) {
+				_, err := b.Kube.GetSecret(namespace, secret.Name)
+				return exists(err)
+			},
+			create: func() error {
+				_, err := b.Kube.CreateSecret(namespace, secret)
+				return err
+			},
+		})
+	}
+	for i := range bundle.CAs {
+		cr := &bundle.CAs[i]
+		cr.Namespace = namespace
+		common.SetServiceInstance(cr, sID)
+		objects = append(objects, b.crObject(namespace, "ibpcas", KindCA, cr.Name, cr, &current.IBPCA{}))
+	}
+	for i := range bundle.Peers {
+		cr := &bundle.Peers[i]
+		cr.Namespace = namespace
+		common.SetServiceInstance(cr, sID)
+		objects = append(objects, b.crObject(namespace, "ibppeers", KindPeer, cr.Name, cr, &current.IBPPeer{}))
+	}
+	// the nodes of orderer clusters are created after their parent
+	orderers := append([]current.IBPOrderer{}, bundle.Orderers...)
+	sort.SliceStable(orderers, func(i, j int) bool {
+		return orderers[i].Labels["parent"] == "" && orderers[j].Labels["parent"] != ""
+	})
+	for i := range orderers {
+		cr := &orderers[i]
+		cr.Namespace = namespace
+		common.SetServiceInstance(cr, sID)
+		objects = append(objects, b.crObject(namespace, "ibporderers", KindOrderer, cr.Name, cr, &current.IBPOrderer{}))
+	}
+
+	response := &ImportResponse{DryRun: dryRun, Results: []*Result{}}
+	for _, obj := range objects {
+		found, err := obj.exists()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to check if %s '%s' exists", obj.result.Kind, obj.result.Name)
+		}
+		if found {
+			obj.result.Status = StatusConflict
+			obj.result.Error = fmt.Sprintf("%s '%s' already exists in namespace '%s'", obj.result.Kind, obj.result.Name, namespace)
+			response.Conflicts++
+		}
+		response.Results = append(response.Results, obj.result)
+	}
+	if dryRun || response.Conflicts > 0 {
+		return response, nil
+	}
+
+	var firstErr error
+	for _, obj := range objects {
+		if firstErr != nil {
+			obj.result.Status = StatusSkipped
+			continue
+		}
+
+		err := obj.create()
+		if err != nil {
+			b.Logger.Errorf("Failed to import %s '%s': %s", obj.result.Kind, obj.result.Name, err)
+			obj.result.Status = StatusFailed
+			obj.result.Error = err.Error()
+			firstErr = err
+			continue
+		}
+		obj.result.Status = StatusCreated
+	}
+
+	return response, firstErr
+}
+
+// importObject is an object of the bundle to import
+type importObject struct {
+	result *Result
+	exists func() (bool, error)
+	create func() error
+}
+
+func (b *Bundler) crObject(namespace, kind, resultKind, name string, cr interface{}, existing runtime.Object) *importObject {
+	return &importObject{
+		result: &Result{Kind: resultKind, Name: name},
+		exists: func() (bool, error) {
+			return exists(b.IBPOperatorClient.GetCR(namespace, kind, name, existing))
+		},
+		create: func() error {
+			return b.IBPOperatorClient.CreateCR(namespace, kind, cr)
+		},
+	}
+}
+
+func exists(err error) (bool, error) {
+	if err == nil {
+		return true, nil
+	}
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// Remap sets the domain, the registry of the images and the storage class of
+// the CRs of the bundle
+func Remap(bundle *Bundle, mapping Mapping) {
+	for i := range bundle.CAs {
+		spec := &bundle.CAs[i].Spec
+		remap(mapping, &spec.Domain, &spec.RegistryURL, spec.Images, spec.Storage)
+	}
+	for i := range bundle.Peers {
+		spec := &bundle.Peers[i].Spec
+		remap(mapping, &spec.Domain, &spec.RegistryURL, spec.Images, spec.Storage)
+	}
+	for i := range bundle.Orderers {
+		spec := &bundle.Orderers[i].Spec
+		remap(mapping, &spec.Domain, &spec.RegistryURL, spec.Images, spec.Storage)
+	}
+}
+
+func remap(mapping Mapping, domain, registryURL *string, images, storage interface{}) {
+	if mapping.Domain != "" {
+		*domain = mapping.Domain
+	}
+
+	if mapping.Registry != "" {
+		registry := strings.TrimSuffix(mapping.Registry, "/")
+		*registryURL = registry
+		// the image fields of the images structs end with Image, e.g. PeerImage
+		forEachField(images, func(name string, field reflect.Value) {
+			if field.Kind() == reflect.String && strings.HasSuffix(name, "Image") && field.String() != "" {
+				field.SetString(replaceRegistry(field.String(), registry))
+			}
+		})
+	}
+
+	if mapping.StorageClass != "" {
+		forEachField(storage, func(name string, field reflect.Value) {
+			if spec, ok := field.Interface().(*current.StorageSpec); ok && spec != nil {
+				spec.Class = mapping.StorageClass
+			}
+		})
+	}
+}
+
+// forEachField calls f with the fields of the struct pointed to by v, if it
+// is not nil
+func forEachField(v interface{}, f func(name string, field reflect.Value)) {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return
+	}
+	value = value.Elem()
+	for i := 0; i < value.NumField(); i++ {
+		f(value.Type().Field(i).Name, value.Field(i))
+	}
+}
+
+// replaceRegistry replaces the registry of the image, the first element of
+// its name if it is a host, e.g. icr.io/cp/peer becomes registry/cp/peer
+func replaceRegistry(image, registry string) string {
+	elements := strings.SplitN(image, "/", 2)
+	if len(elements) == 2 && (strings.ContainsAny(elements[0], ".:") || elements[0] == "localhost") {
+		return registry + "/" + elements[1]
+	}
+	return registry + "/" + image
+}
+
+// caCryptoSecrets returns the names of the secrets of the CA and TLS CA
+// crypto that the operator creates for a CA
+func caCryptoSecrets(name string) []string {
+	return []string{name + "-ca-crypto", name + "-tlsca-crypto"}
+}
+
+// addNodeCryptoSecrets adds the names of the secrets of the enrollment and
+// TLS certificates and keys that the operator creates for a peer or orderer
+// node. The intermediate and admin certs are optional.
+func addNodeCryptoSecrets(names, optional map[string]bool, name string) {
+	for _, prefix := range []string{"ecert", "tls"} {
+		addNames(names, prefix+"-"+name+"-signcert", prefix+"-"+name+"-keystore", prefix+"-"+name+"-cacerts")
+		addNames(names, prefix+"-"+name+"-intercerts")
+		addNames(optional, prefix+"-"+name+"-intercerts")
+	}
+	addNames(names, "ecert-"+name+"-admincerts")
+	addNames(optional, "ecert-"+name+"-admincerts")
+}
+
+func addNames(names map[string]bool, values ...string) {
+	for _, value := range values {
+		if value != "" {
+			names[value] = true
+		}
+	}
+}
+
+func sortedNames(names map[string]bool) []string {
+	sorted := []string{}
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
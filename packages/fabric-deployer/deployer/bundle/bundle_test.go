@@ -0,0 +1,255 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bundle_test
+
+import (
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/bundle"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/bundle/mocks"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+)
+
+var _ = Describe("Bundle", func() {
+	var (
+		bundler       *bundle.Bundler
+		mockKube      *mocks.Kube
+		mockIBPClient *mocks.IBPOperatorClient
+		notFound      error
+	)
+
+	BeforeEach(func() {
+		mockKube = &mocks.Kube{}
+		mockIBPClient = &mocks.IBPOperatorClient{}
+		bundler = bundle.New(zap.NewNop(), mockKube, mockIBPClient)
+		notFound = k8serrors.NewNotFound(schema.GroupResource{}, "")
+	})
+
+	Context("export", func() {
+		BeforeEach(func() {
+			mockIBPClient.GetAllCRStub = func(namespace, kind string, list runtime.Object) error {
+				switch l := list.(type) {
+				case *current.IBPCAList:
+					l.Items = []current.IBPCA{{
+						ObjectMeta: metav1.ObjectMeta{Name: "ca1", Namespace: namespace, ResourceVersion: "12", UID: "uid"},
+						Spec:       current.IBPCASpec{ImagePullSecrets: []string{"pull"}},
+						Status:     current.IBPCAStatus{CRStatus: current.CRStatus{Type: current.Deployed}},
+					}}
+				case *current.IBPPeerList:
+					l.Items = []current.IBPPeer{{
+						ObjectMeta: metav1.ObjectMeta{Name: "peer1", Labels: map[string]string{"app": "peer1"}},
+						Spec:       current.IBPPeerSpec{ImagePullSecrets: []string{"pull"}, MSPSecret: "peer1-msp"},
+					}}
+				}
+				return nil
+			}
+			mockKube.GetSecretStub = func(namespace, name string) (*corev1.Secret, error) {
+				switch name {
+				case "pull", "ca1-ca-crypto", "ca1-tlsca-crypto", "ecert-peer1-signcert", "ecert-peer1-keystore", "tls-peer1-keystore":
+					return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: "3"}, Data: map[string][]byte{"k": []byte("v")}}, nil
+				}
+				return nil, notFound
+			}
+		})
+
+		It("exports the crs and their secrets without server set fields", func() {
+			b, err := bundler.Export("sid", "ns1", "example.com")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(b.Metadata.Namespace).To(Equal("ns1"))
+			Expect(b.Metadata.Domain).To(Equal("example.com"))
+
+			Expect(b.CAs).To(HaveLen(1))
+			Expect(b.CAs[0].ObjectMeta).To(Equal(metav1.ObjectMeta{Name: "ca1"}))
+			Expect(b.CAs[0].Status).To(Equal(current.IBPCAStatus{}))
+			Expect(b.Peers[0].Labels).To(HaveKeyWithValue("app", "peer1"))
+			Expect(b.Orderers).To(BeEmpty())
+
+			names := []string{}
+			for _, secret := range b.Secrets {
+				Expect(secret.ResourceVersion).To(BeEmpty())
+				names = append(names, secret.Name)
+			}
+			Expect(names).To(Equal([]string{"ca1-ca-crypto", "ca1-tlsca-crypto", "ecert-peer1-keystore", "ecert-peer1-signcert", "pull", "tls-peer1-keystore"}))
+		})
+
+		It("exports the crypto secrets of the orderer nodes but not of the clusters", func() {
+			mockIBPClient.GetAllCRStub = func(namespace, kind string, list runtime.Object) error {
+				if l, ok := list.(*current.IBPOrdererList); ok {
+					l.Items = []current.IBPOrderer{
+						{ObjectMeta: metav1.ObjectMeta{Name: "os1"}},
+						{ObjectMeta: metav1.ObjectMeta{Name: "os1node1", Labels: map[string]string{"parent": "os1"}}},
+					}
+				}
+				return nil
+			}
+
+			_, err := bundler.Export("sid", "ns1", "")
+			Expect(err).NotTo(HaveOccurred())
+			requested := []string{}
+			for i := 0; i < mockKube.GetSecretCallCount(); i++ {
+				_, name := mockKube.GetSecretArgsForCall(i)
+				requested = append(requested, name)
+			}
+			Expect(requested).To(ContainElement("tls-os1node1-signcert"))
+			Expect(requested).NotTo(ContainElement(ContainSubstring("-os1-")))
+		})
+
+		It("only exports the crs of the service instance", func() {
+			mockIBPClient.GetAllCRStub = func(namespace, kind string, list runtime.Object) error {
+				if l, ok := list.(*current.IBPOrdererList); ok {
+					other := map[string]string{common.ServiceInstanceLabel: "other"}
+					l.Items = []current.IBPOrderer{
+						{ObjectMeta: metav1.ObjectMeta{Name: "os1", Labels: other}},
+						{ObjectMeta: metav1.ObjectMeta{Name: "os1node1", Labels: map[string]string{"parent": "os1"}}},
+						{ObjectMeta: metav1.ObjectMeta{Name: "os2"}},
+					}
+				}
+				return nil
+			}
+
+			b, err := bundler.Export("sid", "ns1", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(b.Orderers).To(HaveLen(1))
+			Expect(b.Orderers[0].Name).To(Equal("os2"))
+		})
+
+		It("returns the errors of the operator client", func() {
+			mockIBPClient.GetAllCRStub = nil
+			mockIBPClient.GetAllCRReturns(errors.New("list error"))
+			_, err := bundler.Export("sid", "ns1", "")
+			Expect(err).To(MatchError("failed to get the ca crs in namespace 'ns1': list error"))
+		})
+	})
+
+	Context("import", func() {
+		var b *bundle.Bundle
+
+		BeforeEach(func() {
+			b = &bundle.Bundle{
+				CAs: []current.IBPCA{{ObjectMeta: metav1.ObjectMeta{Name: "ca1", Namespace: "ns1"}}},
+				Orderers: []current.IBPOrderer{
+					{ObjectMeta: metav1.ObjectMeta{Name: "osnode1", Labels: map[string]string{"parent": "os"}}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "os"}},
+				},
+				Secrets: []corev1.Secret{{ObjectMeta: metav1.ObjectMeta{Name: "pull"}}},
+			}
+			mockIBPClient.GetCRReturns(notFound)
+			mockKube.GetSecretReturns(nil, notFound)
+		})
+
+		It("creates the secrets then the crs in the namespace", func() {
+			resp, err := bundler.Import("sid", "ns2", b, bundle.Mapping{}, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Conflicts).To(Equal(0))
+			Expect(resp.Results).To(HaveLen(4))
+			for _, result := range resp.Results {
+				Expect(result.Status).To(Equal(bundle.StatusCreated))
+			}
+			Expect(resp.Results[0].Kind).To(Equal(bundle.KindSecret))
+			Expect(resp.Results[2].Name).To(Equal("os"))
+			Expect(resp.Results[3].Name).To(Equal("osnode1"))
+
+			Expect(mockKube.CreateSecretCallCount()).To(Equal(1))
+			Expect(mockIBPClient.CreateCRCallCount()).To(Equal(3))
+			namespace, kind, cr := mockIBPClient.CreateCRArgsForCall(0)
+			Expect(namespace).To(Equal("ns2"))
+			Expect(kind).To(Equal("ibpcas"))
+			Expect(cr.(*current.IBPCA).Namespace).To(Equal("ns2"))
+			Expect(cr.(*current.IBPCA).Labels).To(HaveKeyWithValue(common.ServiceInstanceLabel, "sid"))
+		})
+
+		It("reports the conflicts and creates nothing", func() {
+			mockIBPClient.GetCRStub = func(namespace, kind, name string, cr runtime.Object) error {
+				if name == "ca1" {
+					return nil
+				}
+				return notFound
+			}
+
+			resp, err := bundler.Import("sid", "ns2", b, bundle.Mapping{}, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Conflicts).To(Equal(1))
+			Expect(resp.Results[1].Status).To(Equal(bundle.StatusConflict))
+			Expect(resp.Results[1].Error).To(Equal("ca 'ca1' already exists in namespace 'ns2'"))
+			Expect(resp.Results[0].Status).To(BeEmpty())
+			Expect(mockIBPClient.CreateCRCallCount()).To(Equal(0))
+			Expect(mockKube.CreateSecretCallCount()).To(Equal(0))
+		})
+
+		It("only checks for conflicts on dry runs", func() {
+			resp, err := bundler.Import("sid", "ns2", b, bundle.Mapping{}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.DryRun).To(BeTrue())
+			Expect(mockIBPClient.CreateCRCallCount()).To(Equal(0))
+		})
+
+		It("skips the remaining objects after a failure", func() {
+			mockIBPClient.CreateCRReturns(errors.New("create error"))
+
+			resp, err := bundler.Import("sid", "ns2", b, bundle.Mapping{}, false)
+			Expect(err).To(MatchError("create error"))
+			Expect(resp.Results[0].Status).To(Equal(bundle.StatusCreated))
+			Expect(resp.Results[1].Status).To(Equal(bundle.StatusFailed))
+			Expect(resp.Results[2].Status).To(Equal(bundle.StatusSkipped))
+		})
+	})
+
+	Context("remap", func() {
+		It("sets the domain, registry and storage class", func() {
+			b := &bundle.Bundle{
+				Peers: []current.IBPPeer{{
+					Spec: current.IBPPeerSpec{
+						Domain:      "old.example.com",
+						RegistryURL: "icr.io/cpopen",
+						Images: &current.PeerImages{
+							PeerImage:    "icr.io/cpopen/ibp-peer",
+							CouchDBImage: "couchdb",
+							PeerTag:      "2.5.4",
+						},
+						Storage: &current.PeerStorages{
+							Peer:    &current.StorageSpec{Size: "1Gi", Class: "old"},
+							StateDB: &current.StorageSpec{Size: "1Gi"},
+						},
+					},
+				}},
+				CAs: []current.IBPCA{{}},
+			}
+
+			bundle.Remap(b, bundle.Mapping{Domain: "new.example.com", Registry: "registry.local:5000/", StorageClass: "fast"})
+			spec := b.Peers[0].Spec
+			Expect(spec.Domain).To(Equal("new.example.com"))
+			Expect(spec.RegistryURL).To(Equal("registry.local:5000"))
+			Expect(spec.Images.PeerImage).To(Equal("registry.local:5000/cpopen/ibp-peer"))
+			Expect(spec.Images.CouchDBImage).To(Equal("registry.local:5000/couchdb"))
+			Expect(spec.Images.PeerTag).To(Equal("2.5.4"))
+			Expect(spec.Storage.Peer.Class).To(Equal("fast"))
+			Expect(spec.Storage.StateDB.Class).To(Equal("fast"))
+			Expect(b.CAs[0].Spec.Domain).To(Equal("new.example.com"))
+		})
+	})
+})
@@ -0,0 +1,172 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bundle
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/audit"
+)
+
+// encryptedPrefix marks the values that are encrypted in the archive
+const encryptedPrefix = "encrypted:v1:"
+
+// cipherKey encrypts the sensitive values of the archive with AES-GCM, the
+// key is derived from a passphrase with scrypt
+type cipherKey struct {
+	aead cipher.AEAD
+}
+
+func newSalt() ([]byte, error) {
+	salt := make([]byte, 16)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate salt")
+	}
+	return salt, nil
+}
+
+func newCipherKey(passphrase string, salt []byte) (*cipherKey, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to derive key")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cipher")
+	}
+	return &cipherKey{aead: aead}, nil
+}
+
+func (k *cipherKey) encrypt(value interface{}) (string, error) {
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, k.aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate nonce")
+	}
+	ciphertext := k.aead.Seal(nonce, nonce, plaintext, nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+func (k *cipherKey) decrypt(value string) (interface{}, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil || len(ciphertext) < k.aead.NonceSize() {
+		return nil, apierror.New(apierror.Validation, apierror.CodeInvalidRequest, "invalid encrypted value in archive")
+	}
+	nonce := ciphertext[:k.aead.NonceSize()]
+	plaintext, err := k.aead.Open(nil, nonce, ciphertext[k.aead.NonceSize():], nil)
+	if err != nil {
+		return nil, apierror.New(apierror.Validation, apierror.CodeInvalidRequest, "failed to decrypt archive, the passphrase is not valid")
+	}
+
+	var decrypted interface{}
+	err = json.Unmarshal(plaintext, &decrypted)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal decrypted value")
+	}
+	return decrypted, nil
+}
+
+// encryptObject encrypts the values of the sensitive keys of the JSON object,
+// the same keys that are redacted from the audit log. The data of secrets is
+// always encrypted.
+func (k *cipherKey) encryptObject(object map[string]interface{}, secret bool) error {
+	if secret {
+		for _, key := range []string{"data", "stringData"} {
+			if object[key] == nil {
+				continue
+			}
+			encrypted, err := k.encrypt(object[key])
+			if err != nil {
+				return err
+			}
+			object[key] = encrypted
+		}
+	}
+
+	_, err := k.walk(object, func(key string, value interface{}) (interface{}, bool, error) {
+		if value == nil || !(audit.IsSensitive(key) || audit.IsSensitiveValue(value)) {
+			return nil, false, nil
+		}
+		encrypted, err := k.encrypt(value)
+		return encrypted, true, err
+	})
+	return err
+}
+
+// decryptObject replaces the encrypted values of the JSON object
+func (k *cipherKey) decryptObject(object map[string]interface{}) error {
+	_, err := k.walk(object, func(key string, value interface{}) (interface{}, bool, error) {
+		s, ok := value.(string)
+		if !ok || !strings.HasPrefix(s, encryptedPrefix) {
+			return nil, false, nil
+		}
+		decrypted, err := k.decrypt(s)
+		return decrypted, true, err
+	})
+	return err
+}
+
+// walk replaces the values for which replace returns true, the replaced
+// values are not walked
+func (k *cipherKey) walk(value interface{}, replace func(key string, value interface{}) (interface{}, bool, error)) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			replaced, ok, err := replace(key, nested)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				v[key] = replaced
+				continue
+			}
+			v[key], err = k.walk(nested, replace)
+			if err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		for i, nested := range v {
+			var err error
+			v[i], err = k.walk(nested, replace)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,274 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"sync"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/bundle"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type IBPOperatorClient struct {
+	CreateCRStub        func(string, string, interface{}) error
+	createCRMutex       sync.RWMutex
+	createCRArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 interface{}
+	}
+	createCRReturns struct {
+		result1 error
+	}
+	createCRReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetAllCRStub        func(string, string, runtime.Object) error
+	getAllCRMutex       sync.RWMutex
+	getAllCRArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 runtime.Object
+	}
+	getAllCRReturns struct {
+		result1 error
+	}
+	getAllCRReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetCRStub        func(string, string, string, runtime.Object) error
+	getCRMutex       sync.RWMutex
+	getCRArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 runtime.Object
+	}
+	getCRReturns struct {
+		result1 error
+	}
+	getCRReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *IBPOperatorClient) CreateCR(arg1 string, arg2 string, arg3 interface{}) error {
+	fake.createCRMutex.Lock()
+	ret, specificReturn := fake.createCRReturnsOnCall[len(fake.createCRArgsForCall)]
+	fake.createCRArgsForCall = append(fake.createCRArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 interface{}
+	}{arg1, arg2, arg3})
+	stub := fake.CreateCRStub
+	fakeReturns := fake.createCRReturns
+	fake.recordInvocation("CreateCR", []interface{}{arg1, arg2, arg3})
+	fake.createCRMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *IBPOperatorClient) CreateCRCallCount() int {
+	fake.createCRMutex.RLock()
+	defer fake.createCRMutex.RUnlock()
+	return len(fake.createCRArgsForCall)
+}
+
+func (fake *IBPOperatorClient) CreateCRCalls(stub func(string, string, interface{}) error) {
+	fake.createCRMutex.Lock()
+	defer fake.createCRMutex.Unlock()
+	fake.CreateCRStub = stub
+}
+
+func (fake *IBPOperatorClient) CreateCRArgsForCall(i int) (string, string, interface{}) {
+	fake.createCRMutex.RLock()
+	defer fake.createCRMutex.RUnlock()
+	argsForCall := fake.createCRArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *IBPOperatorClient) CreateCRReturns(result1 error) {
+	fake.createCRMutex.Lock()
+	defer fake.createCRMutex.Unlock()
+	fake.CreateCRStub = nil
+	fake.createCRReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *IBPOperatorClient) CreateCRReturnsOnCall(i int, result1 error) {
+	fake.createCRMutex.Lock()
+	defer fake.createCRMutex.Unlock()
+	fake.CreateCRStub = nil
+	if fake.createCRReturnsOnCall == nil {
+		fake.createCRReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createCRReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *IBPOperatorClient) GetAllCR(arg1 string, arg2 string, arg3 runtime.Object) error {
+	fake.getAllCRMutex.Lock()
+	ret, specificReturn := fake.getAllCRReturnsOnCall[len(fake.getAllCRArgsForCall)]
+	fake.getAllCRArgsForCall = append(fake.getAllCRArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 runtime.Object
+	}{arg1, arg2, arg3})
+	stub := fake.GetAllCRStub
+	fakeReturns := fake.getAllCRReturns
+	fake.recordInvocation("GetAllCR", []interface{}{arg1, arg2, arg3})
+	fake.getAllCRMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *IBPOperatorClient) GetAllCRCallCount() int {
+	fake.getAllCRMutex.RLock()
+	defer fake.getAllCRMutex.RUnlock()
+	return len(fake.getAllCRArgsForCall)
+}
+
+func (fake *IBPOperatorClient) GetAllCRCalls(stub func(string, string, runtime.Object) error) {
+	fake.getAllCRMutex.Lock()
+	defer fake.getAllCRMutex.Unlock()
+	fake.GetAllCRStub = stub
+}
+
+func (fake *IBPOperatorClient) GetAllCRArgsForCall(i int) (string, string, runtime.Object) {
+	fake.getAllCRMutex.RLock()
+	defer fake.getAllCRMutex.RUnlock()
+	argsForCall := fake.getAllCRArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *IBPOperatorClient) GetAllCRReturns(result1 error) {
+	fake.getAllCRMutex.Lock()
+	defer fake.getAllCRMutex.Unlock()
+	fake.GetAllCRStub = nil
+	fake.getAllCRReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *IBPOperatorClient) GetAllCRReturnsOnCall(i int, result1 error) {
+	fake.getAllCRMutex.Lock()
+	defer fake.getAllCRMutex.Unlock()
+	fake.GetAllCRStub = nil
+	if fake.getAllCRReturnsOnCall == nil {
+		fake.getAllCRReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.getAllCRReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *IBPOperatorClient) GetCR(arg1 string, arg2 string, arg3 string, arg4 runtime.Object) error {
+	fake.getCRMutex.Lock()
+	ret, specificReturn := fake.getCRReturnsOnCall[len(fake.getCRArgsForCall)]
+	fake.getCRArgsForCall = append(fake.getCRArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 runtime.Object
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.GetCRStub
+	fakeReturns := fake.getCRReturns
+	fake.recordInvocation("GetCR", []interface{}{arg1, arg2, arg3, arg4})
+	fake.getCRMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *IBPOperatorClient) GetCRCallCount() int {
+	fake.getCRMutex.RLock()
+	defer fake.getCRMutex.RUnlock()
+	return len(fake.getCRArgsForCall)
+}
+
+func (fake *IBPOperatorClient) GetCRCalls(stub func(string, string, string, runtime.Object) error) {
+	fake.getCRMutex.Lock()
+	defer fake.getCRMutex.Unlock()
+	fake.GetCRStub = stub
+}
+
+func (fake *IBPOperatorClient) GetCRArgsForCall(i int) (string, string, string, runtime.Object) {
+	fake.getCRMutex.RLock()
+	defer fake.getCRMutex.RUnlock()
+	argsForCall := fake.getCRArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *IBPOperatorClient) GetCRReturns(result1 error) {
+	fake.getCRMutex.Lock()
+	defer fake.getCRMutex.Unlock()
+	fake.GetCRStub = nil
+	fake.getCRReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *IBPOperatorClient) GetCRReturnsOnCall(i int, result1 error) {
+	fake.getCRMutex.Lock()
+	defer fake.getCRMutex.Unlock()
+	fake.GetCRStub = nil
+	if fake.getCRReturnsOnCall == nil {
+		fake.getCRReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.getCRReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *IBPOperatorClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.createCRMutex.RLock()
+	defer fake.createCRMutex.RUnlock()
+	fake.getAllCRMutex.RLock()
+	defer fake.getAllCRMutex.RUnlock()
+	fake.getCRMutex.RLock()
+	defer fake.getCRMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *IBPOperatorClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ bundle.IBPOperatorClient = new(IBPOperatorClient)
@@ -0,0 +1,95 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/capacity"
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+)
+
+// CapacityEndpoint returns an endpoint type that is responsible for handling
+// checking the capacity for a create request
+func (d *Deployer) CapacityEndpoint() func(http.ResponseWriter, *http.Request) {
+	return NewEndpoint(d.Capacity, d.LocalConfig.Logger).ServeHTTP
+}
+
+// Capacity returns the resources that the create request in the body, or the
+// defaults if there is no body, requires and whether they fit the namespace
+// and the nodes
+func (d *Deployer) Capacity(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	typeOfComponent := chi.URLParam(r, "type")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, 0, errors.New("failed to ready request body")
+	}
+
+	compName := r.URL.Query().Get("componentName")
+	if compName == "" {
+		compName = typeOfComponent
+	}
+
+	component, err := d.capacityComponent(r, typeOfComponent)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	report, err := d.target(r).CapacityChecker.CheckComponent(component, compName, d.namespace(r), body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return report, http.StatusOK, nil
+}
+
+// checkCapacity rejects a create request whose pods do not fit the namespace
+// or the nodes
+func (d *Deployer) checkCapacity(r *http.Request, typeOfComponent, compName, namespace string, body []byte) error {
+	component, err := d.capacityComponent(r, typeOfComponent)
+	if err != nil {
+		return err
+	}
+
+	report, err := d.target(r).CapacityChecker.CheckComponent(component, compName, namespace, body)
+	if err != nil {
+		return err
+	}
+	if err := report.Err(); err != nil {
+		d.Logger.Errorf("Not enough capacity to create %s '%s': %s", typeOfComponent, compName, err)
+		return err
+	}
+	return nil
+}
+
+func (d *Deployer) capacityComponent(r *http.Request, typeOfComponent string) (capacity.Component, error) {
+	components := d.clusterComponents(r)
+	switch typeOfComponent {
+	case "ca":
+		return components.CA, nil
+	case "peer":
+		return components.Peer, nil
+	case "orderer":
+		return components.Orderer, nil
+	}
+	return nil, apierror.UnsupportedComponentType(typeOfComponent)
+}
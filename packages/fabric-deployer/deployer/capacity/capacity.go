@@ -0,0 +1,493 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package capacity checks that the pods of a component fit the resource
+// quotas of the namespace and the free capacity of the nodes before it is
+// created.
+package capacity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
+)
+
+// checkedResources are the resources of the nodes that the pods are fitted in
+var checkedResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+
+// Pod is a pod of a component and the resources of its containers
+type Pod struct {
+	Name       string                                 `json:"name"`
+	Containers map[string]corev1.ResourceRequirements `json:"containers"`
+	Requests   corev1.ResourceList                    `json:"requests"`
+	Limits     corev1.ResourceList                    `json:"limits"`
+}
+
+// NewPod returns the pod with the containers of the roles, the resources of
+// the containers that the component runs by role
+func NewPod(name string, roles map[string]*corev1.ResourceRequirements) Pod {
+	pod := Pod{
+		Name:       name,
+		Containers: map[string]corev1.ResourceRequirements{},
+	}
+	for role, requirements := range roles {
+		if requirements != nil {
+			pod.Containers[role] = *requirements
+		}
+	}
+
+	pod.Requests, pod.Limits = util.EffectiveResources(roles)
+	return pod
+}
+
+// NewPods returns the replicas of the pod with the containers of the roles,
+// named after the component and the number of the replica when there is more
+// than one
+func NewPods(name string, replicas int, roles map[string]*corev1.ResourceRequirements) []Pod {
+	if replicas < 1 {
+		replicas = 1
+	}
+	pods := []Pod{}
+	for i := 1; i <= replicas; i++ {
+		podName := name
+		if replicas > 1 {
+			podName = fmt.Sprintf("%s-%d", name, i)
+		}
+		pods = append(pods, NewPod(podName, roles))
+	}
+	return pods
+}
+
+// effective returns the resources of a pod following the rules of the
+// scheduler, the containers named after the roles of util.InitRoles are init
+// containers
+func effective(containers map[string]corev1.ResourceRequirements) (requests, limits corev1.ResourceList) {
+	roles := map[string]*corev1.ResourceRequirements{}
+	for name := range containers {
+		container := containers[name]
+		roles[name] = &container
+	}
+	return util.EffectiveResources(roles)
+}
+
+func add(total, list corev1.ResourceList) {
+	for name, quantity := range list {
+		value := total[name]
+		value.Add(quantity)
+		total[name] = value
+	}
+}
+
+// raise sets each resource of total to the larger of total and list
+func raise(total, list corev1.ResourceList) {
+	for name, quantity := range list {
+		if value, ok := total[name]; !ok || quantity.Cmp(value) > 0 {
+			total[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// Sum returns the total resources of the pods
+func Sum(pods []Pod) (requests, limits corev1.ResourceList) {
+	requests = corev1.ResourceList{}
+	limits = corev1.ResourceList{}
+	for _, pod := range pods {
+		add(requests, pod.Requests)
+		add(limits, pod.Limits)
+	}
+	return requests, limits
+}
+
+// Quota is the headroom of a resource of a resource quota of the namespace
+type Quota struct {
+	Name      string              `json:"name"`
+	Resource  corev1.ResourceName `json:"resource"`
+	Hard      resource.Quantity   `json:"hard"`
+	Used      resource.Quantity   `json:"used"`
+	Available resource.Quantity   `json:"available"`
+	Required  resource.Quantity   `json:"required"`
+	Fits      bool                `json:"fits"`
+}
+
+// Nodes is the capacity of the schedulable nodes of the cluster. Unschedulable
+// are the pods that request more than any node can allocate, Unplaced are the
+// pods that do not fit the capacity that is free on the nodes right now
+type Nodes struct {
+	Count         int                 `json:"count"`
+	Allocatable   corev1.ResourceList `json:"allocatable"`
+	Requested     corev1.ResourceList `json:"requested"`
+	Available     corev1.ResourceList `json:"available"`
+	Fits          bool                `json:"fits"`
+	Unschedulable []string            `json:"unschedulable,omitempty"`
+	Unplaced      []string            `json:"unplaced,omitempty"`
+}
+
+// Report is the result of the check of the pods of a component. Quotas or
+// Nodes that could not be read are left out and listed in Warnings
+type Report struct {
+	Namespace string              `json:"namespace"`
+	Fits      bool                `json:"fits"`
+	Pods      []Pod               `json:"pods"`
+	Requests  corev1.ResourceList `json:"requests"`
+	Limits    corev1.ResourceList `json:"limits"`
+	Quotas    []Quota             `json:"quotas"`
+	Nodes     *Nodes              `json:"nodes,omitempty"`
+	Warnings  []string            `json:"warnings,omitempty"`
+}
+
+// Err returns the error of a report that does not fit, Unprocessable if a pod
+// can never be scheduled and Conflict if the namespace or nodes are short of
+// capacity right now
+func (r *Report) Err() error {
+	if r.Fits {
+		return nil
+	}
+
+	if r.Nodes != nil && len(r.Nodes.Unschedulable) > 0 {
+		err := apierror.New(apierror.Unprocessable, apierror.CodeUnschedulable, "pods request more resources than any node can allocate")
+		for _, name := range r.Nodes.Unschedulable {
+			err.WithDetails(apierror.FieldError{
+				Field:   "pods." + name,
+				Message: fmt.Sprintf("requests %s", formatList(r.pod(name).Requests)),
+			})
+		}
+		return err
+	}
+
+	err := apierror.New(apierror.Conflict, apierror.CodeInsufficientCapacity, "insufficient capacity in namespace '%s'", r.Namespace)
+	for _, quota := range r.Quotas {
+		if quota.Fits {
+			continue
+		}
+		err.WithDetails(apierror.FieldError{
+			Field:   fmt.Sprintf("quotas.%s.%s", quota.Name, quota.Resource),
+			Message: fmt.Sprintf("requires %s, %s available", quota.Required.String(), quota.Available.String()),
+		})
+	}
+	if r.Nodes != nil && len(r.Nodes.Unplaced) > 0 {
+		err.WithDetails(apierror.FieldError{
+			Field:   "nodes",
+			Message: fmt.Sprintf("pods %s do not fit the free capacity of the nodes, %s available", strings.Join(r.Nodes.Unplaced, ", "), formatList(r.Nodes.Available)),
+		})
+	}
+	return err
+}
+
+func (r *Report) pod(name string) Pod {
+	for _, pod := range r.Pods {
+		if pod.Name == name {
+			return pod
+		}
+	}
+	return Pod{}
+}
+
+func formatList(list corev1.ResourceList) string {
+	parts := []string{}
+	for _, name := range checkedResources {
+		if quantity, ok := list[name]; ok {
+			parts = append(parts, fmt.Sprintf("%s %s", name, quantity.String()))
+		}
+	}
+	if len(parts) == 0 {
+		return "nothing"
+	}
+	return strings.Join(parts, ", ")
+}
+
+//go:generate counterfeiter -o mocks/kube.go -fake-name Kube . Kube
+
+type Kube interface {
+	ListResourceQuotas(namespace string) ([]corev1.ResourceQuota, error)
+	ListNodes() ([]corev1.Node, error)
+	ListPods() ([]corev1.Pod, error)
+}
+
+//go:generate counterfeiter -o mocks/component.go -fake-name Component . Component
+
+// Component is a component type that can tell the pods of a create request
+type Component interface {
+	// Requirements returns the pods the component of the create request runs
+	Requirements(compName string, body []byte) ([]Pod, error)
+}
+
+type Checker struct {
+	Kube   Kube
+	Logger *zap.SugaredLogger
+}
+
+func New(logger *zap.Logger, kube Kube) *Checker {
+	return &Checker{
+		Kube:   kube,
+		Logger: logger.Sugar().Named("Capacity"),
+	}
+}
+
+// CheckComponent checks the pods of the create request of the component
+func (c *Checker) CheckComponent(component Component, compName, namespace string, body []byte) (*Report, error) {
+	pods, err := component.Requirements(compName, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.Check(namespace, pods), nil
+}
+
+// Check compares the resources of the pods with the headroom of the resource
+// quotas of the namespace and the allocatable capacity of the nodes. Quotas
+// and nodes that cannot be listed, e.g. because the deployer is not allowed
+// to, are not checked
+func (c *Checker) Check(namespace string, pods []Pod) *Report {
+	requests, limits := Sum(pods)
+	report := &Report{
+		Namespace: namespace,
+		Fits:      true,
+		Pods:      pods,
+		Requests:  requests,
+		Limits:    limits,
+		Quotas:    []Quota{},
+	}
+
+	quotas, err := c.Kube.ListResourceQuotas(namespace)
+	if err != nil {
+		c.Logger.Warnf("Failed to list resource quotas of namespace '%s': %s", namespace, err)
+		report.Warnings = append(report.Warnings, fmt.Sprintf("resource quotas not checked: %s", err))
+	} else {
+		report.Quotas = checkQuotas(quotas, len(pods), requests, limits)
+		for _, quota := range report.Quotas {
+			report.Fits = report.Fits && quota.Fits
+		}
+	}
+
+	nodes, err := c.Kube.ListNodes()
+	if err == nil {
+		var running []corev1.Pod
+		running, err = c.Kube.ListPods()
+		if err == nil {
+			report.Nodes = checkNodes(nodes, running, pods)
+			if report.Nodes.Count == 0 {
+				report.Nodes = nil
+				err = errors.New("no schedulable nodes found")
+			} else {
+				report.Fits = report.Fits && report.Nodes.Fits
+			}
+		}
+	}
+	if err != nil {
+		c.Logger.Warnf("Failed to get the capacity of the nodes: %s", err)
+		report.Warnings = append(report.Warnings, fmt.Sprintf("nodes not checked: %s", err))
+	}
+
+	return report
+}
+
+// checkQuotas returns the headroom of the resources of the quotas that the
+// pods use, quotas with scopes are not checked
+func checkQuotas(quotas []corev1.ResourceQuota, count int, requests, limits corev1.ResourceList) []Quota {
+	result := []Quota{}
+	for _, quota := range quotas {
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+
+		names := []string{}
+		for name := range quota.Status.Hard {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			resourceName := corev1.ResourceName(name)
+			required, ok := quotaRequirement(resourceName, count, requests, limits)
+			if !ok {
+				continue
+			}
+			hard := quota.Status.Hard[resourceName]
+			used := quota.Status.Used[resourceName]
+			available := hard.DeepCopy()
+			available.Sub(used)
+			result = append(result, Quota{
+				Name:      quota.Name,
+				Resource:  resourceName,
+				Hard:      hard,
+				Used:      used,
+				Available: available,
+				Required:  required,
+				Fits:      required.Cmp(available) <= 0,
+			})
+		}
+	}
+	return result
+}
+
+// quotaRequirement returns how much of a quota resource the pods use
+func quotaRequirement(name corev1.ResourceName, count int, requests, limits corev1.ResourceList) (resource.Quantity, bool) {
+	switch name {
+	case corev1.ResourcePods:
+		return *resource.NewQuantity(int64(count), resource.DecimalSI), true
+	case corev1.ResourceCPU, corev1.ResourceRequestsCPU:
+		return requests[corev1.ResourceCPU], true
+	case corev1.ResourceMemory, corev1.ResourceRequestsMemory:
+		return requests[corev1.ResourceMemory], true
+	case corev1.ResourceLimitsCPU:
+		return limits[corev1.ResourceCPU], true
+	case corev1.ResourceLimitsMemory:
+		return limits[corev1.ResourceMemory], true
+	}
+	return resource.Quantity{}, false
+}
+
+// checkNodes fits the pods, largest first, in the capacity left on the ready
+// and schedulable nodes by the pods running on them
+func checkNodes(nodes []corev1.Node, running []corev1.Pod, pods []Pod) *Nodes {
+	result := &Nodes{
+		Allocatable: corev1.ResourceList{},
+		Requested:   corev1.ResourceList{},
+		Available:   corev1.ResourceList{},
+		Fits:        true,
+	}
+
+	free := map[string]corev1.ResourceList{}
+	allocatable := map[string]corev1.ResourceList{}
+	names := []string{}
+	for _, node := range nodes {
+		if node.Spec.Unschedulable || !isReady(node) {
+			continue
+		}
+		names = append(names, node.Name)
+		allocatable[node.Name] = node.Status.Allocatable
+		free[node.Name] = node.Status.Allocatable.DeepCopy()
+		add(result.Allocatable, node.Status.Allocatable)
+	}
+	sort.Strings(names)
+	result.Count = len(names)
+
+	for _, pod := range running {
+		nodeFree, ok := free[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+		requests := podRequests(pod)
+		sub(nodeFree, requests)
+		add(result.Requested, requests)
+	}
+	for _, name := range names {
+		add(result.Available, free[name])
+	}
+
+	sorted := append([]Pod{}, pods...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return larger(sorted[i].Requests, sorted[j].Requests)
+	})
+
+	for _, pod := range sorted {
+		schedulable := false
+		for _, name := range names {
+			if fits(pod.Requests, allocatable[name]) {
+				schedulable = true
+				break
+			}
+		}
+		if !schedulable {
+			result.Unschedulable = append(result.Unschedulable, pod.Name)
+			result.Fits = false
+			continue
+		}
+
+		placed := false
+		for _, name := range names {
+			if fits(pod.Requests, free[name]) {
+				sub(free[name], pod.Requests)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			result.Unplaced = append(result.Unplaced, pod.Name)
+			result.Fits = false
+		}
+	}
+
+	return result
+}
+
+// podRequests returns the requests of a running pod
+func podRequests(pod corev1.Pod) corev1.ResourceList {
+	containers := map[string]corev1.ResourceRequirements{}
+	for _, container := range pod.Spec.Containers {
+		containers[container.Name] = container.Resources
+	}
+	requests, _ := effective(containers)
+	initRequests := corev1.ResourceList{}
+	for _, container := range pod.Spec.InitContainers {
+		raise(initRequests, container.Resources.Requests)
+	}
+	raise(requests, initRequests)
+	return requests
+}
+
+func isReady(node corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func sub(total, list corev1.ResourceList) {
+	for name, quantity := range list {
+		if value, ok := total[name]; ok {
+			value.Sub(quantity)
+			total[name] = value
+		}
+	}
+}
+
+// fits returns true if the requests fit the capacity for the resources the
+// nodes are checked for
+func fits(requests, capacity corev1.ResourceList) bool {
+	for _, name := range checkedResources {
+		request, ok := requests[name]
+		if !ok {
+			continue
+		}
+		available := capacity[name]
+		if request.Cmp(available) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func larger(a, b corev1.ResourceList) bool {
+	for _, name := range checkedResources {
+		x, y := a[name], b[name]
+		if c := x.Cmp(y); c != 0 {
+			return c > 0
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package capacity_test
+
+import (
+	"errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/capacity"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/capacity/mocks"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
+)
+
+func requirements(cpu, memory string) *corev1.ResourceRequirements {
+	return &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		},
+	}
+}
+
+func node(name, cpu, memory string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func quota(resourceName corev1.ResourceName, hard, used string) corev1.ResourceQuota {
+	return corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "quota"},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{resourceName: resource.MustParse(hard)},
+			Used: corev1.ResourceList{resourceName: resource.MustParse(used)},
+		},
+	}
+}
+
+var _ = Describe("Capacity", func() {
+	var (
+		kube    *mocks.Kube
+		checker *capacity.Checker
+		pods    []capacity.Pod
+	)
+
+	BeforeEach(func() {
+		kube = &mocks.Kube{}
+		kube.ListNodesReturns([]corev1.Node{node("node1", "4", "8Gi")}, nil)
+		checker = capacity.New(zap.NewNop(), kube)
+
+		roles := map[string]*corev1.ResourceRequirements{
+			util.RoleInit:  requirements("1", "1Gi"),
+			util.RolePeer:  requirements("500m", "1Gi"),
+			util.RoleProxy: requirements("100m", "200M"),
+		}
+		pods = capacity.NewPods("org1peer", 2, roles)
+	})
+
+	Context("pods", func() {
+		It("sums the containers and takes the largest init container", func() {
+			Expect(pods).To(HaveLen(2))
+			Expect(pods[0].Name).To(Equal("org1peer-1"))
+			Expect(pods[0].Containers).To(HaveKey("proxy"))
+
+			cpu := pods[0].Requests[corev1.ResourceCPU]
+			Expect(cpu.String()).To(Equal("1"))
+			memory := pods[0].Requests[corev1.ResourceMemory]
+			Expect(memory.Cmp(resource.MustParse("1273741824"))).To(Equal(0))
+
+			requests, _ := capacity.Sum(pods)
+			cpu = requests[corev1.ResourceCPU]
+			Expect(cpu.String()).To(Equal("2"))
+		})
+	})
+
+	Context("check", func() {
+		It("fits when there is enough capacity", func() {
+			kube.ListResourceQuotasReturns([]corev1.ResourceQuota{quota(corev1.ResourceRequestsCPU, "4", "1")}, nil)
+
+			report := checker.Check("ns", pods)
+			Expect(report.Fits).To(BeTrue())
+			Expect(report.Quotas).To(HaveLen(1))
+			Expect(report.Quotas[0].Available.String()).To(Equal("3"))
+			Expect(report.Nodes.Count).To(Equal(1))
+			Expect(report.Err()).NotTo(HaveOccurred())
+		})
+
+		It("returns a conflict when the quota is short", func() {
+			kube.ListResourceQuotasReturns([]corev1.ResourceQuota{quota(corev1.ResourceRequestsCPU, "2", "1")}, nil)
+
+			report := checker.Check("ns", pods)
+			Expect(report.Fits).To(BeFalse())
+
+			err := report.Err()
+			Expect(apierror.StatusCode(err)).To(Equal(http.StatusConflict))
+			Expect(apierror.From(err).Code).To(Equal(apierror.CodeInsufficientCapacity))
+			Expect(apierror.From(err).Details[0].Field).To(Equal("quotas.quota.requests.cpu"))
+		})
+
+		It("returns a conflict when the nodes are short of free capacity", func() {
+			kube.ListPodsReturns([]corev1.Pod{{
+				Spec: corev1.PodSpec{
+					NodeName:   "node1",
+					Containers: []corev1.Container{{Name: "app", Resources: *requirements("3", "1Gi")}},
+				},
+			}}, nil)
+
+			report := checker.Check("ns", pods)
+			Expect(report.Nodes.Unplaced).To(Equal([]string{"org1peer-2"}))
+			Expect(apierror.StatusCode(report.Err())).To(Equal(http.StatusConflict))
+		})
+
+		It("returns unprocessable when a pod fits no node", func() {
+			kube.ListNodesReturns([]corev1.Node{node("node1", "500m", "8Gi")}, nil)
+
+			report := checker.Check("ns", pods)
+			Expect(report.Nodes.Unschedulable).To(ConsistOf("org1peer-1", "org1peer-2"))
+
+			err := report.Err()
+			Expect(apierror.StatusCode(err)).To(Equal(http.StatusUnprocessableEntity))
+			Expect(apierror.From(err).Details).To(HaveLen(2))
+		})
+
+		It("checks the pods of the create request of a component", func() {
+			component := &mocks.Component{}
+			component.RequirementsReturns(pods, nil)
+
+			report, err := checker.CheckComponent(component, "org1peer", "ns", []byte(`{}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Pods).To(HaveLen(2))
+			compName, body := component.RequirementsArgsForCall(0)
+			Expect(compName).To(Equal("org1peer"))
+			Expect(string(body)).To(Equal(`{}`))
+		})
+
+		It("returns the error of a request that is not valid", func() {
+			component := &mocks.Component{}
+			component.RequirementsReturns(nil, apierror.InvalidField("version", "version not valid"))
+
+			_, err := checker.CheckComponent(component, "org1peer", "ns", nil)
+			Expect(apierror.StatusCode(err)).To(Equal(http.StatusBadRequest))
+		})
+
+		It("skips the checks it is not allowed to make", func() {
+			kube.ListResourceQuotasReturns(nil, errors.New("forbidden"))
+			kube.ListNodesReturns(nil, errors.New("forbidden"))
+
+			report := checker.Check("ns", pods)
+			Expect(report.Fits).To(BeTrue())
+			Expect(report.Nodes).To(BeNil())
+			Expect(report.Warnings).To(HaveLen(2))
+		})
+	})
+})
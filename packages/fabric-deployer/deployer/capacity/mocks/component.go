@@ -0,0 +1,123 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"sync"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/capacity"
+)
+
+type Component struct {
+	RequirementsStub        func(string, []byte) ([]capacity.Pod, error)
+	requirementsMutex       sync.RWMutex
+	requirementsArgsForCall []struct {
+		arg1 string
+		arg2 []byte
+	}
+	requirementsReturns struct {
+		result1 []capacity.Pod
+		result2 error
+	}
+	requirementsReturnsOnCall map[int]struct {
+		result1 []capacity.Pod
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *Component) Requirements(arg1 string, arg2 []byte) ([]capacity.Pod, error) {
+	var arg2Copy []byte
+	if arg2 != nil {
+		arg2Copy = make([]byte, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.requirementsMutex.Lock()
+	ret, specificReturn := fake.requirementsReturnsOnCall[len(fake.requirementsArgsForCall)]
+	fake.requirementsArgsForCall = append(fake.requirementsArgsForCall, struct {
+		arg1 string
+		arg2 []byte
+	}{arg1, arg2Copy})
+	stub := fake.RequirementsStub
+	fakeReturns := fake.requirementsReturns
+	fake.recordInvocation("Requirements", []interface{}{arg1, arg2Copy})
+	fake.requirementsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Component) RequirementsCallCount() int {
+	fake.requirementsMutex.RLock()
+	defer fake.requirementsMutex.RUnlock()
+	return len(fake.requirementsArgsForCall)
+}
+
+func (fake *Component) RequirementsCalls(stub func(string, []byte) ([]capacity.Pod, error)) {
+	fake.requirementsMutex.Lock()
+	defer fake.requirementsMutex.Unlock()
+	fake.RequirementsStub = stub
+}
+
+func (fake *Component) RequirementsArgsForCall(i int) (string, []byte) {
+	fake.requirementsMutex.RLock()
+	defer fake.requirementsMutex.RUnlock()
+	argsForCall := fake.requirementsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Component) RequirementsReturns(result1 []capacity.Pod, result2 error) {
+	fake.requirementsMutex.Lock()
+	defer fake.requirementsMutex.Unlock()
+	fake.RequirementsStub = nil
+	fake.requirementsReturns = struct {
+		result1 []capacity.Pod
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Component) RequirementsReturnsOnCall(i int, result1 []capacity.Pod, result2 error) {
+	fake.requirementsMutex.Lock()
+	defer fake.requirementsMutex.Unlock()
+	fake.RequirementsStub = nil
+	if fake.requirementsReturnsOnCall == nil {
+		fake.requirementsReturnsOnCall = make(map[int]struct {
+			result1 []capacity.Pod
+			result2 error
+		})
+	}
+	fake.requirementsReturnsOnCall[i] = struct {
+		result1 []capacity.Pod
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Component) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.requirementsMutex.RLock()
+	defer fake.requirementsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *Component) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ capacity.Component = new(Component)
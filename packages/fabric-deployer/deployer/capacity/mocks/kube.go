@@ -0,0 +1,257 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"sync"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/capacity"
+	v1 "k8s.io/api/core/v1"
+)
+
+type Kube struct {
+	ListNodesStub        func() ([]v1.Node, error)
+	listNodesMutex       sync.RWMutex
+	listNodesArgsForCall []struct {
+	}
+	listNodesReturns struct {
+		result1 []v1.Node
+		result2 error
+	}
+	listNodesReturnsOnCall map[int]struct {
+		result1 []v1.Node
+		result2 error
+	}
+	ListPodsStub        func() ([]v1.Pod, error)
+	listPodsMutex       sync.RWMutex
+	listPodsArgsForCall []struct {
+	}
+	listPodsReturns struct {
+		result1 []v1.Pod
+		result2 error
+	}
+	listPodsReturnsOnCall map[int]struct {
+		result1 []v1.Pod
+		result2 error
+	}
+	ListResourceQuotasStub        func(string) ([]v1.ResourceQuota, error)
+	listResourceQuotasMutex       sync.RWMutex
+	listResourceQuotasArgsForCall []struct {
+		arg1 string
+	}
+	listResourceQuotasReturns struct {
+		result1 []v1.ResourceQuota
+		result2 error
+	}
+	listResourceQuotasReturnsOnCall map[int]struct {
+		result1 []v1.ResourceQuota
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *Kube) ListNodes() ([]v1.Node, error) {
+	fake.listNodesMutex.Lock()
+	ret, specificReturn := fake.listNodesReturnsOnCall[len(fake.listNodesArgsForCall)]
+	fake.listNodesArgsForCall = append(fake.listNodesArgsForCall, struct {
+	}{})
+	stub := fake.ListNodesStub
+	fakeReturns := fake.listNodesReturns
+	fake.recordInvocation("ListNodes", []interface{}{})
+	fake.listNodesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Kube) ListNodesCallCount() int {
+	fake.listNodesMutex.RLock()
+	defer fake.listNodesMutex.RUnlock()
+	return len(fake.listNodesArgsForCall)
+}
+
+func (fake *Kube) ListNodesCalls(stub func() ([]v1.Node, error)) {
+	fake.listNodesMutex.Lock()
+	defer fake.listNodesMutex.Unlock()
+	fake.ListNodesStub = stub
+}
+
+func (fake *Kube) ListNodesReturns(result1 []v1.Node, result2 error) {
+	fake.listNodesMutex.Lock()
+	defer fake.listNodesMutex.Unlock()
+	fake.ListNodesStub = nil
+	fake.listNodesReturns = struct {
+		result1 []v1.Node
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) ListNodesReturnsOnCall(i int, result1 []v1.Node, result2 error) {
+	fake.listNodesMutex.Lock()
+	defer fake.listNodesMutex.Unlock()
+	fake.ListNodesStub = nil
+	if fake.listNodesReturnsOnCall == nil {
+		fake.listNodesReturnsOnCall = make(map[int]struct {
+			result1 []v1.Node
+			result2 error
+		})
+	}
+	fake.listNodesReturnsOnCall[i] = struct {
+		result1 []v1.Node
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) ListPods() ([]v1.Pod, error) {
+	fake.listPodsMutex.Lock()
+	ret, specificReturn := fake.listPodsReturnsOnCall[len(fake.listPodsArgsForCall)]
+	fake.listPodsArgsForCall = append(fake.listPodsArgsForCall, struct {
+	}{})
+	stub := fake.ListPodsStub
+	fakeReturns := fake.listPodsReturns
+	fake.recordInvocation("ListPods", []interface{}{})
+	fake.listPodsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Kube) ListPodsCallCount() int {
+	fake.listPodsMutex.RLock()
+	defer fake.listPodsMutex.RUnlock()
+	return len(fake.listPodsArgsForCall)
+}
+
+func (fake *Kube) ListPodsCalls(stub func() ([]v1.Pod, error)) {
+	fake.listPodsMutex.Lock()
+	defer fake.listPodsMutex.Unlock()
+	fake.ListPodsStub = stub
+}
+
+func (fake *Kube) ListPodsReturns(result1 []v1.Pod, result2 error) {
+	fake.listPodsMutex.Lock()
+	defer fake.listPodsMutex.Unlock()
+	fake.ListPodsStub = nil
+	fake.listPodsReturns = struct {
+		result1 []v1.Pod
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) ListPodsReturnsOnCall(i int, result1 []v1.Pod, result2 error) {
+	fake.listPodsMutex.Lock()
+	defer fake.listPodsMutex.Unlock()
+	fake.ListPodsStub = nil
+	if fake.listPodsReturnsOnCall == nil {
+		fake.listPodsReturnsOnCall = make(map[int]struct {
+			result1 []v1.Pod
+			result2 error
+		})
+	}
+	fake.listPodsReturnsOnCall[i] = struct {
+		result1 []v1.Pod
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) ListResourceQuotas(arg1 string) ([]v1.ResourceQuota, error) {
+	fake.listResourceQuotasMutex.Lock()
+	ret, specificReturn := fake.listResourceQuotasReturnsOnCall[len(fake.listResourceQuotasArgsForCall)]
+	fake.listResourceQuotasArgsForCall = append(fake.listResourceQuotasArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ListResourceQuotasStub
+	fakeReturns := fake.listResourceQuotasReturns
+	fake.recordInvocation("ListResourceQuotas", []interface{}{arg1})
+	fake.listResourceQuotasMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Kube) ListResourceQuotasCallCount() int {
+	fake.listResourceQuotasMutex.RLock()
+	defer fake.listResourceQuotasMutex.RUnlock()
+	return len(fake.listResourceQuotasArgsForCall)
+}
+
+func (fake *Kube) ListResourceQuotasCalls(stub func(string) ([]v1.ResourceQuota, error)) {
+	fake.listResourceQuotasMutex.Lock()
+	defer fake.listResourceQuotasMutex.Unlock()
+	fake.ListResourceQuotasStub = stub
+}
+
+func (fake *Kube) ListResourceQuotasArgsForCall(i int) string {
+	fake.listResourceQuotasMutex.RLock()
+	defer fake.listResourceQuotasMutex.RUnlock()
+	argsForCall := fake.listResourceQuotasArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Kube) ListResourceQuotasReturns(result1 []v1.ResourceQuota, result2 error) {
+	fake.listResourceQuotasMutex.Lock()
+	defer fake.listResourceQuotasMutex.Unlock()
+	fake.ListResourceQuotasStub = nil
+	fake.listResourceQuotasReturns = struct {
+		result1 []v1.ResourceQuota
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) ListResourceQuotasReturnsOnCall(i int, result1 []v1.ResourceQuota, result2 error) {
+	fake.listResourceQuotasMutex.Lock()
+	defer fake.listResourceQuotasMutex.Unlock()
+	fake.ListResourceQuotasStub = nil
+	if fake.listResourceQuotasReturnsOnCall == nil {
+		fake.listResourceQuotasReturnsOnCall = make(map[int]struct {
+			result1 []v1.ResourceQuota
+			result2 error
+		})
+	}
+	fake.listResourceQuotasReturnsOnCall[i] = struct {
+		result1 []v1.ResourceQuota
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.listNodesMutex.RLock()
+	defer fake.listNodesMutex.RUnlock()
+	fake.listPodsMutex.RLock()
+	defer fake.listPodsMutex.RUnlock()
+	fake.listResourceQuotasMutex.RLock()
+	defer fake.listResourceQuotasMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *Kube) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ capacity.Kube = new(Kube)
@@ -0,0 +1,296 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/client-go/rest"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/bundle"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/capacity"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/mustgather"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/operator"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/ibpoperator"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/instance"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/kube"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/snapshot"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/upgrade"
+)
+
+// ClusterHeader selects the cluster of a request, requests without it target
+// the cluster of the deployer. The cluster can also be selected with the
+// /api/v3/cluster/{cluster} prefix of the path.
+const ClusterHeader = "X-Fabric-Cluster"
+
+const clusterPrefix = "/api/v3/cluster/"
+
+type clusterKey struct{}
+
+// Target is a cluster the components are deployed to, with the clients and
+// the services of the cluster
+type Target struct {
+	Name string
+	// Config is the configuration of the deployer with the namespace, domain
+	// and cluster type of the cluster
+	Config            *config.DeployerSettingsConfig
+	Cluster           *config.Cluster
+	K8SClient         *kube.Kube
+	IBPOperatorClient *ibpoperator.Client
+
+	Operator        *operator.Operator
+	Bundle          *bundle.Bundler
+	Snapshots       *snapshot.Snapshotter
+	Upgrader        *upgrade.Upgrader
+	CapacityChecker *capacity.Checker
+	Instances       *instance.Resolver
+}
+
+// newTarget connects to the cluster, the clients only fail once they are used
+// if the cluster is not reachable
+func (d *Deployer) newTarget(name string, cluster *config.Cluster) (*Target, error) {
+	restConfig, err := cluster.RestConfig()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error loading the kubeconfig of cluster '%s'", name)
+	}
+	ibpOperatorClient, k8sClient, err := d.newClients(restConfig, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating the clients of cluster '%s'", name)
+	}
+
+	cfg := d.Config.ForCluster(cluster)
+	deployment := time.Duration(cfg.Timeouts.Deployment) * time.Millisecond
+	logger := d.LocalConfig.Logger.Named(name)
+	return &Target{
+		Name:              name,
+		Config:            cfg,
+		Cluster:           cluster,
+		K8SClient:         k8sClient,
+		IBPOperatorClient: ibpOperatorClient,
+		Operator:          operator.New(logger, k8sClient),
+		Bundle:            bundle.New(logger, k8sClient, ibpOperatorClient),
+		Snapshots:         snapshot.New(logger, k8sClient, deployment),
+		Upgrader:          upgrade.New(logger, k8sClient, d.upgradeTargets(name), deployment),
+		CapacityChecker:   capacity.New(logger, k8sClient),
+		Instances:         instance.New(logger, k8sClient, ibpOperatorClient, cfg.Namespace),
+	}, nil
+}
+
+// newClients returns the clients of the cluster, an empty name is the cluster
+// of the deployer. The clients are observed by the metrics and traced if
+// enabled.
+func (d *Deployer) newClients(restConfig *rest.Config, name string) (*ibpoperator.Client, *kube.Kube, error) {
+	if name == "" {
+		name = config.LocalCluster
+	}
+
+	ibpOperatorConfig, kubeConfig := restConfig, restConfig
+	if d.Metrics != nil {
+		ibpOperatorConfig = d.Metrics.InstrumentConfig(ibpOperatorConfig, name, "ibpoperator")
+		kubeConfig = d.Metrics.InstrumentConfig(kubeConfig, name, "kube")
+	}
+	if d.Tracing != nil {
+		ibpOperatorConfig = d.Tracing.InstrumentConfig(ibpOperatorConfig, name, "ibpoperator")
+		kubeConfig = d.Tracing.InstrumentConfig(kubeConfig, name, "kube")
+	}
+
+	ibpOperatorClient, err := ibpoperator.New(ibpOperatorConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	k8sClient, err := kube.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if d.Metrics != nil {
+		observer := d.Metrics.Cluster(name)
+		ibpOperatorClient.SetObserver(observer)
+		k8sClient.SetObserver(observer)
+	}
+	return ibpOperatorClient, k8sClient, nil
+}
+
+// ClusterMiddleware selects the cluster of the request from the path prefix or
+// the ClusterHeader and strips the prefix for the request to be routed
+func (d *Deployer) ClusterMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.Header.Get(ClusterHeader)
+		if path := strings.TrimPrefix(r.URL.Path, clusterPrefix); path != r.URL.Path {
+			name = path
+			path = ""
+			if i := strings.Index(name, "/"); i >= 0 {
+				name, path = name[:i], name[i:]
+			}
+			// the URL of the copy of the request is replaced, not the
+			// URL shared with the original request
+			u := *r.URL
+			u.Path = "/api/v3" + path
+			u.RawPath = ""
+			r = r.WithContext(r.Context())
+			r.URL = &u
+		}
+
+		if name == "" || name == config.LocalCluster {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if _, found := d.Targets[name]; !found {
+			NewEndpoint(func(http.ResponseWriter, *http.Request) (interface{}, int, error) {
+				return nil, 0, apierror.New(apierror.NotFound, apierror.CodeNotFound, "cluster '%s' not found", name)
+			}, d.LocalConfig.Logger).ServeHTTP(w, r)
+			return
+		}
+
+		trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("deployer.cluster", name))
+		ctx := context.WithValue(r.Context(), clusterKey{}, name)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// clusterOf returns the name of the cluster selected by the request, empty
+// for the cluster of the deployer
+func clusterOf(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+	name, _ := r.Context().Value(clusterKey{}).(string)
+	return name
+}
+
+// target returns the cluster of the request, with the clients bound to the
+// span of the request if tracing is enabled
+func (d *Deployer) target(r *http.Request) *Target {
+	target, found := d.Targets[clusterOf(r)]
+	if !found {
+		target = d.localTarget()
+	}
+	if ctx := d.spanContext(r); ctx != nil {
+		return target.WithContext(ctx)
+	}
+	return target
+}
+
+// localTarget returns the cluster of the deployer, with the services of the
+// deployer
+func (d *Deployer) localTarget() *Target {
+	return &Target{
+		Name:              config.LocalCluster,
+		Config:            d.Config,
+		K8SClient:         d.K8SClient,
+		IBPOperatorClient: d.IBPOperatorClient,
+		Operator:          d.Operator,
+		Bundle:            d.Bundle,
+		Snapshots:         d.Snapshots,
+		Upgrader:          d.Upgrader,
+		CapacityChecker:   d.CapacityChecker,
+		Instances:         d.Instances,
+	}
+}
+
+// WithContext returns a copy of the cluster whose clients, including the
+// clients of its services, make their calls to the API server with ctx
+func (t *Target) WithContext(ctx context.Context) *Target {
+	bound := *t
+	bound.K8SClient = withContext(t.K8SClient, ctx)
+	bound.IBPOperatorClient = withContext(t.IBPOperatorClient, ctx)
+	if t.Operator != nil {
+		o := *t.Operator
+		o.Kube = withContext(o.Kube, ctx)
+		bound.Operator = &o
+	}
+	if t.Bundle != nil {
+		b := *t.Bundle
+		b.Kube = withContext(b.Kube, ctx)
+		b.IBPOperatorClient = withContext(b.IBPOperatorClient, ctx)
+		bound.Bundle = &b
+	}
+	if t.Snapshots != nil {
+		s := *t.Snapshots
+		s.Kube = withContext(s.Kube, ctx)
+		bound.Snapshots = &s
+	}
+	if t.Upgrader != nil {
+		u := *t.Upgrader
+		u.Kube = withContext(u.Kube, ctx)
+		bound.Upgrader = &u
+	}
+	if t.CapacityChecker != nil {
+		c := *t.CapacityChecker
+		c.Kube = withContext(c.Kube, ctx)
+		bound.CapacityChecker = &c
+	}
+	if t.Instances != nil {
+		i := *t.Instances
+		i.Kube = withContext(i.Kube, ctx)
+		i.IBPOperatorClient = withContext(i.IBPOperatorClient, ctx)
+		bound.Instances = &i
+	}
+	return &bound
+}
+
+// clusterComponents returns the components of the cluster of the request,
+// with the clients bound to the span of the request if tracing is enabled and
+// the loggers carrying the ID of the request
+func (d *Deployer) clusterComponents(r *http.Request) *Components {
+	components := d.Components().For(clusterOf(r))
+	if ctx := d.spanContext(r); ctx != nil {
+		components = components.WithContext(ctx)
+	}
+	if r != nil {
+		if id := RequestIDFrom(r.Context()); id != "" {
+			components = components.WithRequestID(id)
+		}
+	}
+	return components
+}
+
+// allTargets returns the cluster of the deployer then the other clusters, by
+// name
+func (d *Deployer) allTargets() []*Target {
+	names := make([]string, 0, len(d.Targets))
+	for name := range d.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	targets := []*Target{d.localTarget()}
+	for _, name := range names {
+		targets = append(targets, d.Targets[name])
+	}
+	return targets
+}
+
+// mustgather returns the mustgather of the cluster of the request, it only
+// runs in the cluster of the deployer
+func (d *Deployer) mustgather(r *http.Request) (*mustgather.Mustgather, error) {
+	if name := clusterOf(r); name != "" {
+		return nil, apierror.New(apierror.Validation, apierror.CodeInvalidRequest, "mustgather is not supported on cluster '%s'", name)
+	}
+	return d.clusterComponents(r).Mustgather, nil
+}
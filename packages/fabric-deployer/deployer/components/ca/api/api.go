@@ -37,6 +37,8 @@ type CreateRequest struct {
 	HSM            *current.HSM            `json:"hsm,omitempty"` // DEPRECATED
 	Zone           string                  `json:"zone,omitempty"`
 	Region         string                  `json:"region,omitempty"`
+	// Profile is the sizing profile of the defaults, e.g. production
+	Profile string `json:"profile,omitempty"`
 }
 
 type DeleteRequest struct {
@@ -46,7 +48,9 @@ type DeleteRequest struct {
 }
 
 type UpdateRequest struct {
-	Version        string                  `json:"version,omitempty"`
+	Version string `json:"version,omitempty"`
+	// ForceVersion allows the version to be downgraded
+	ForceVersion   bool                    `json:"forceVersion,omitempty"`
 	Resources      *current.CAResources    `json:"resources,omitempty"`
 	Replicas       *int32                  `json:"replicas,omitempty"`
 	ConfigOverride *current.ConfigOverride `json:"configoverride,omitempty"`
@@ -56,6 +60,7 @@ type UpdateRequest struct {
 
 type Component struct {
 	Name                 string                  `json:"name,omitempty"`
+	Cluster              string                  `json:"cluster,omitempty"`
 	CAName               string                  `json:"ca_name,omitempty"`
 	TLSCAName            string                  `json:"tlsca_name,omitempty"`
 	Endpoints            interface{}             `json:"endpoints,omitempty"`
@@ -80,6 +85,14 @@ type Component struct {
 
 type Response struct {
 	Component `json:",inline"`
+
+	// ResourceVersion of the CR, returned in the ETag header
+	ResourceVersion string `json:"-"`
+}
+
+// GetResourceVersion returns the resource version of the CR
+func (r *Response) GetResourceVersion() string {
+	return r.ResourceVersion
 }
 
 type Deleted struct {
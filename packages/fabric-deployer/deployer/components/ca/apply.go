@@ -0,0 +1,134 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ca
+
+import (
+	"encoding/json"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// PlanApply returns the changes that applying the create request would make
+// to the CR of the component, found is false if the component does not exist
+func (ca *CA) PlanApply(sID, compName, namespace string, body []byte) (bool, []common.Change, error) {
+	unchangedCR, updatedCR, err := ca.renderApply(sID, compName, namespace, body)
+	if err != nil || unchangedCR == nil {
+		return false, nil, err
+	}
+
+	diff, err := common.Diff(unchangedCR, updatedCR)
+	if err != nil {
+		return true, nil, err
+	}
+	return true, diff, nil
+}
+
+// ApplyCR updates the CR of an existing component with the fields of the
+// create request that can be changed after the component is created
+func (ca *CA) ApplyCR(sID, compName, namespace string, body []byte) error {
+	unchangedCR, updatedCR, err := ca.renderApply(sID, compName, namespace, body)
+	if err != nil {
+		return err
+	}
+	if unchangedCR == nil {
+		return apierror.New(apierror.NotFound, apierror.CodeNotFound, "cr '%s' not found in namespace '%s'", compName, namespace)
+	}
+
+	crBytes, err := json.Marshal(updatedCR)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal, invalid request")
+	}
+
+	err = ca.IBPOperatorClient.UpdateCR(namespace, "ibpcas", compName, crBytes)
+	if err != nil {
+		return errors.Wrapf(err, "failed update cr '%s' in namespace '%s'", compName, namespace)
+	}
+
+	return nil
+}
+
+// renderApply returns the current CR and the CR updated with the create
+// request, both nil if the CR does not exist. Storage, zone and region are
+// only set on create, the config override, HSM and replicas are kept if the
+// request does not set them. CRs of other service instances are forbidden and
+// the version must follow the transition policy.
+func (ca *CA) renderApply(sID, compName, namespace string, body []byte) (*current.IBPCA, *current.IBPCA, error) {
+	desiredCR, _, err := ca.renderCR(compName, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	originalCR := &current.IBPCA{}
+	err = ca.IBPOperatorClient.GetCR(namespace, "ibpcas", compName, originalCR)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+	}
+	if !common.BelongsTo(originalCR.Labels, sID) {
+		return nil, nil, apierror.New(apierror.Forbidden, apierror.CodeForbidden, "ca '%s' belongs to another service instance", compName)
+	}
+
+	err = util.CheckVersionTransition("ca", originalCR.Spec.FabricVersion, desiredCR.Spec.FabricVersion, false, ca.Config.Versions)
+	if err != nil {
+		return nil, nil, apierror.InvalidField("version", "%s", err.Error())
+	}
+
+	unchangedCR := originalCR.DeepCopy()
+
+	originalCR.Spec.FabricVersion = desiredCR.Spec.FabricVersion
+	originalCR.Spec.Images = desiredCR.Spec.Images
+	originalCR.Spec.Resources = desiredCR.Spec.Resources
+	if desiredCR.Spec.ConfigOverride != nil {
+		originalCR.Spec.ConfigOverride = desiredCR.Spec.ConfigOverride
+	}
+	if desiredCR.Spec.HSM != nil {
+		originalCR.Spec.HSM = desiredCR.Spec.HSM
+	}
+	if desiredCR.Spec.Replicas != nil {
+		originalCR.Spec.Replicas = desiredCR.Spec.Replicas
+	}
+
+	return unchangedCR, originalCR, nil
+}
+
+// ListLabelled returns the names of the cas labelled with the service
+// instance, the cas that apply can prune. CAs without the label, created
+// before the CRs were labelled, are left out.
+func (ca *CA) ListLabelled(sID, namespace string) ([]string, error) {
+	list := &current.IBPCAList{}
+	err := ca.IBPOperatorClient.GetAllCR(namespace, "ibpcas", list)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get all ca cr in namespace '%s'", namespace)
+	}
+
+	names := []string{}
+	for _, cr := range list.Items {
+		if common.LabelledWith(cr.Labels, sID) {
+			names = append(names, cr.Name)
+		}
+	}
+	return names, nil
+}
@@ -19,14 +19,18 @@
 package ca
 
 import (
+	"context"
 	"encoding/json"
+	"net/http"
 	"time"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
 
 	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/ca/api"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
 	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
 	ibpca "github.com/IBM-Blockchain/fabric-operator/pkg/apis/ca/v1"
@@ -34,8 +38,8 @@ import (
 	"go.uber.org/zap"
 
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 const (
@@ -67,6 +71,7 @@ type Kube interface {
 	GetPort(namespace, name string) (int32, error)
 	GetPorts(namespace, name string) ([]corev1.ServicePort, error)
 	ClusterType(namespace string) string
+	WaitForConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error)
 }
 
 //go:generate counterfeiter -o mocks/ibp_client.go -fake-name IBPOperatorClient . IBPOperatorClient
@@ -78,6 +83,7 @@ type IBPOperatorClient interface {
 	DeleteCR(namespace string, kind string, name string) error
 	UpdateCR(namespace string, kind string, name string, bytes []byte) error
 	PatchCR(namespace string, kind string, name string, bytes []byte) error
+	WaitForCRStatus(ctx context.Context, namespace string, kind string, name string, cond func(status *current.CRStatus) (bool, error)) (*current.CRStatus, error)
 }
 
 type CA struct {
@@ -97,23 +103,102 @@ func New(logger *zap.Logger, k8sClient Kube, ibpClient IBPOperatorClient, config
 }
 
 func (ca *CA) CreateCR(domain, sID, compName, namespace string, body []byte) (*api.Response, int, error) {
-	var err error
 	statusCode := 0
 
+	ca.Logger.Debugf("Received request to create ca cr '%s' in namespace '%s'", compName, namespace)
+
+	cr, version, err := ca.renderCR(compName, body)
+	if err != nil {
+		return nil, statusCode, err
+	}
+	common.SetServiceInstance(cr, sID)
+
+	err = ca.IBPOperatorClient.CreateCR(namespace, "ibpcas", cr)
+	if err != nil {
+		ca.Logger.Error(errors.Wrapf(err, "Failed to create cr '%s' in namespace '%s", compName, namespace))
+		return nil, statusCode, err
+	}
+
+	ca.Logger.Debugf("Created cr '%s'", cr.Name)
+	// get cr status
+	// if status is deployed -> get connection profile
+	// if status is error -> we are done
+	ca.Logger.Debugf("Waiting for cr spec status for :'%s'", compName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(ca.Config.Timeouts.Deployment)*time.Millisecond)
+	defer cancel()
+	crStatus, err := common.WaitForDeployment(ctx, ca.IBPOperatorClient, ca.Kube, namespace, "ibpcas", compName, 0)
+
+	// cr status did not change to deployed/error before timeout
+	if err != nil {
+		ca.Logger.Warnf("Status not set after timeout or got an error: %s", err)
+		// return error immediately, something went wrong
+		statusCode = 500
+		if crStatus != nil && crStatus.Status == current.True && crStatus.Type == current.Error {
+			// dont error out
+		} else {
+			return nil, statusCode, err
+		}
+	}
+
+	// build the response
+	response, statusCodeNew, err := ca.GetCRResponse(ALL, compName, namespace, sID)
+	if err != nil {
+		ca.Logger.Error(errors.Wrapf(err, "Failed to build response object '%s'", compName))
+		return nil, statusCode, err
+	}
+	if statusCode == 0 && statusCodeNew != 0 {
+		statusCode = statusCodeNew
+	}
+	response.Version = version
+	timestamp := time.Now().Unix()
+	response.CreationTimestamp = timestamp
+	response.LastUpdatedTimestamp = timestamp
+
+	return response, statusCode, nil
+}
+
+// DryRunCreateCR returns the CR that would be created for the request,
+// nothing is created
+func (ca *CA) DryRunCreateCR(compName, namespace string, body []byte) (*common.DryRunResponse, int, error) {
+	ca.Logger.Debugf("Received dry run request to create ca cr '%s' in namespace '%s'", compName, namespace)
+
+	cr, _, err := ca.renderCR(compName, body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = ca.IBPOperatorClient.GetCR(namespace, "ibpcas", compName, &current.IBPCA{})
+	if err == nil {
+		return nil, http.StatusConflict, apierror.New(apierror.Conflict, apierror.CodeAlreadyExists, "cr '%s' already exists", compName)
+	}
+	if !k8serrors.IsNotFound(err) {
+		return nil, 0, errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+	}
+
+	response, err := common.NewDryRunResponse(nil, cr)
+	if err != nil {
+		return nil, 0, err
+	}
+	return response, http.StatusOK, nil
+}
+
+// renderCR returns the CR created for the request and its version, merging
+// the request with the defaults of the configuration
+func (ca *CA) renderCR(compName string, body []byte) (*current.IBPCA, string, error) {
+	var err error
+
 	// if comp name is not passed, return error as comp name is required
 	if compName == "" {
 		ca.Logger.Error("Component name not valid, cannot be empty")
-		return nil, statusCode, errors.New("component name not valid, cannot be empty")
+		return nil, "", apierror.InvalidField("componentName", "component name not valid, cannot be empty")
 	}
 
-	ca.Logger.Debugf("Received request to create ca cr '%s' in namespace '%s'", compName, namespace)
-
 	request := &api.CreateRequest{}
 	if len(body) != 0 {
 		err = json.Unmarshal(body, request)
 		if err != nil {
 			ca.Logger.Error(errors.Wrapf(err, "failed to unmarshal configuration, configuration is not valid"))
-			return nil, statusCode, errors.Wrapf(err, "failed to unmarshal configuration, configuration is not valid")
+			return nil, "", apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "failed to unmarshal configuration, configuration is not valid")
 		}
 	}
 
@@ -124,17 +209,23 @@ func (ca *CA) CreateCR(domain, sID, compName, namespace string, body []byte) (*a
 	} else if !util.IsValidVersion("ca", version, ca.Config.Versions) {
 		// version is not valid
 		ca.Logger.Error("Version not valid")
-		return nil, statusCode, errors.Errorf("version not valid")
+		return nil, "", apierror.InvalidField("version", "version not valid")
+	}
+
+	defaults, err := ca.Config.Defaults.ForProfile(request.Profile)
+	if err != nil {
+		ca.Logger.Error(err)
+		return nil, "", apierror.InvalidField("profile", "%s", err.Error())
 	}
 
 	// merge storage and resources
-	storage := ca.GetStorage(ca.Config.Defaults, request.Storage)
-	resources := ca.GetResources(ca.Config.Defaults, request.Resources)
+	storage := ca.GetStorage(defaults, request.Storage)
+	resources := ca.GetResources(defaults, request.Resources)
 
 	err = ca.checkCreateReplicas(request)
 	if err != nil {
 		ca.Logger.Error(errors.Wrap(err, "Failed to check create replicas"))
-		return nil, statusCode, err
+		return nil, "", err
 	}
 
 	zone, region := util.GetZoneAndRegion(request.Zone, request.Region)
@@ -163,68 +254,7 @@ func (ca *CA) CreateCR(domain, sID, compName, namespace string, body []byte) (*a
 	}
 	cr.Name = compName
 
-	err = ca.IBPOperatorClient.CreateCR(namespace, "ibpcas", cr)
-	if err != nil {
-		ca.Logger.Error(errors.Wrapf(err, "Failed to create cr '%s' in namespace '%s", compName, namespace))
-		return nil, statusCode, err
-	}
-
-	ca.Logger.Debugf("Created cr '%s'", cr.Name)
-	// get cr status
-	// if status is deployed -> get connection profile
-	// if status is error -> we are done
-	ca.Logger.Debugf("Polling for cr spec status for :'%s'", compName)
-	originalCR := &current.IBPCA{}
-	err = wait.Poll(500*time.Millisecond, time.Duration(ca.Config.Timeouts.Deployment)*time.Millisecond, func() (bool, error) {
-		err = ca.IBPOperatorClient.GetCR(namespace, "ibpcas", compName, originalCR)
-		if err == nil {
-			// check the status field
-			if originalCR.Status.Status == current.True {
-				// if error then get out
-				if originalCR.Status.Type == current.Error {
-					// if cr status is error, no need to get configs
-					return true, errors.New("CR status is set to error")
-				}
-				cmName := compName + "-connection-profile"
-				cm, err := ca.Kube.GetConfigMap(namespace, cmName)
-				if err == nil && cm != nil {
-					return true, nil
-				}
-			}
-			return false, nil
-		} else {
-			// immediately fail on error
-			return true, err
-		}
-	})
-
-	// cr status did not change to deployed/error before timeout
-	if err != nil {
-		ca.Logger.Warnf("Status not set after timeout or got an error: %s", err)
-		// return error immediately, something went wrong
-		statusCode = 500
-		if originalCR.Status.Status == current.True && originalCR.Status.Type == current.Error {
-			// dont error out
-		} else {
-			return nil, statusCode, err
-		}
-	}
-
-	// build the response
-	response, statusCodeNew, err := ca.GetCRResponse(ALL, compName, namespace, sID)
-	if err != nil {
-		ca.Logger.Error(errors.Wrapf(err, "Failed to build response object '%s'", compName))
-		return nil, statusCode, err
-	}
-	if statusCode == 0 && statusCodeNew != 0 {
-		statusCode = statusCodeNew
-	}
-	response.Version = version
-	timestamp := time.Now().Unix()
-	response.CreationTimestamp = timestamp
-	response.LastUpdatedTimestamp = timestamp
-
-	return response, statusCode, nil
+	return cr, version, nil
 }
 
 func (ca *CA) Images(version string) *current.CAImages {
@@ -263,7 +293,7 @@ func (ca *CA) DeleteCR(sID, compName, namespace string, body []byte) (*api.Delet
 		err = json.Unmarshal(body, request)
 		if err != nil {
 			ca.Logger.Error(errors.Wrapf(err, "Failed to unmarshal configuration"))
-			return nil, statusCode, errors.Wrapf(err, "failed to unmarshal configuration, configuration is not valid")
+			return nil, statusCode, apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "failed to unmarshal configuration, configuration is not valid")
 		}
 	}
 
@@ -288,7 +318,7 @@ func (ca *CA) GetConnectionProfile(compName, namespace string) (*api.ConnectionP
 	}
 	binaryData := cm.BinaryData
 	if binaryData["profile.json"] == nil {
-		return nil, errors.New("profile.json not found in configmap")
+		return nil, apierror.New(apierror.NotFound, apierror.CodeNotFound, "profile.json not found in configmap")
 	}
 	data := binaryData["profile.json"]
 
@@ -311,7 +341,7 @@ func (ca *CA) GetConfig(compName, namespace, caType string) (*ibpca.ServerConfig
 	}
 	binaryData := cm.BinaryData
 	if binaryData["fabric-ca-server-config.yaml"] == nil {
-		return nil, errors.New("fabric-ca-server-config.yaml not found in configmap")
+		return nil, apierror.New(apierror.NotFound, apierror.CodeNotFound, "fabric-ca-server-config.yaml not found in configmap")
 	}
 	data := binaryData["fabric-ca-server-config.yaml"]
 
@@ -327,50 +357,53 @@ func (ca *CA) checkCreateReplicas(request *api.CreateRequest) error {
 	return ca.CheckReplicas(request.Replicas, request.ConfigOverride)
 }
 
+// CheckReplicas returns a validation error if the config override does not
+// allow the replicas, more than one replica requires postgres databases for
+// the CA and the TLS CA
 func (ca *CA) CheckReplicas(replicas *int32, configOverride *current.ConfigOverride) error {
 	if replicas != nil && *replicas > 1 {
 		if configOverride == nil {
-			return errors.New("CA & TLSCA config override should be passed to allow replicas > 1")
+			return apierror.InvalidField("configoverride", "CA & TLSCA config override should be passed to allow replicas > 1")
 		}
 		if configOverride.CA == nil && configOverride.TLSCA == nil {
-			return errors.New("CA & TLSCA config override should be passed to allow replicas > 1")
+			return apierror.InvalidField("configoverride", "CA & TLSCA config override should be passed to allow replicas > 1")
 		} else if configOverride.CA == nil {
-			return errors.New("CA config override missing to allow replicas > 1")
+			return apierror.InvalidField("configoverride", "CA config override missing to allow replicas > 1")
 		} else if configOverride.TLSCA == nil {
-			return errors.New("TLSCA config override missing to allow replicas > 1")
+			return apierror.InvalidField("configoverride", "TLSCA config override missing to allow replicas > 1")
 		}
 
 		configoverrideCA := &ibpca.ServerConfig{}
 		err := json.Unmarshal(configOverride.CA.Raw, configoverrideCA)
 		if err != nil {
-			return errors.New("[checkReplicas] Failed to unmarshal CA configoverride")
+			return apierror.InvalidField("configoverride", "[checkReplicas] Failed to unmarshal CA configoverride")
 		}
 
 		configoverrideTLSCA := &ibpca.ServerConfig{}
 		err = json.Unmarshal(configOverride.TLSCA.Raw, configoverrideTLSCA)
 		if err != nil {
-			return errors.New("[checkReplicas] Failed to unmarshal TLSCA configoverride")
+			return apierror.InvalidField("configoverride", "[checkReplicas] Failed to unmarshal TLSCA configoverride")
 		}
 
 		if configoverrideCA.CAConfig.DB == nil || configoverrideTLSCA.CAConfig.DB == nil {
-			return errors.New("DB Type in CA & TLSCA config override should be `postgres` to allow replicas > 1")
+			return apierror.InvalidField("configoverride", "DB Type in CA & TLSCA config override should be `postgres` to allow replicas > 1")
 		}
 
 		if configoverrideCA.CAConfig.DB != nil {
 			if configoverrideCA.CAConfig.DB.Type != "postgres" {
-				return errors.New("DB Type in CA config override should be `postgres` to allow replicas > 1")
+				return apierror.InvalidField("configoverride", "DB Type in CA config override should be `postgres` to allow replicas > 1")
 			}
 			if configoverrideCA.CAConfig.DB.Datasource == "" {
-				return errors.New("Datasource in CA config override should not be empty to allow replicas > 1")
+				return apierror.InvalidField("configoverride", "Datasource in CA config override should not be empty to allow replicas > 1")
 			}
 		}
 
 		if configoverrideTLSCA.CAConfig.DB != nil {
 			if configoverrideTLSCA.CAConfig.DB.Type != "postgres" {
-				return errors.New("DB Type in TLSCA config override should be `postgres` to allow replicas > 1")
+				return apierror.InvalidField("configoverride", "DB Type in TLSCA config override should be `postgres` to allow replicas > 1")
 			}
 			if configoverrideTLSCA.CAConfig.DB.Datasource == "" {
-				return errors.New("Datasource in TLSCA config override should not be empty to allow replicas > 1")
+				return apierror.InvalidField("configoverride", "Datasource in TLSCA config override should not be empty to allow replicas > 1")
 			}
 		}
 	}
@@ -383,14 +416,28 @@ func (ca *CA) GetDefaultVersion() string {
 }
 
 func (ca *CA) GetResourceForResponse(resources *current.CAResources) (*util.ResourceReturn, *current.CAResources) {
-	caResources := []*corev1.ResourceRequirements{resources.CA}
-	totalResources := util.GetTotalDeploymentResources(caResources)
+	totalResources := util.GetResourcesByRole(roleResources(resources))
 	individualResources := ca.GetIndividualResources(resources)
 	return totalResources, individualResources
 }
 
+// roleResources returns the resources of the containers the CA runs, by role
+func roleResources(resources *current.CAResources) map[string]*corev1.ResourceRequirements {
+	roles := map[string]*corev1.ResourceRequirements{}
+	if resources == nil {
+		return roles
+	}
+
+	roles[util.RoleCA] = resources.CA
+	roles[util.RoleInit] = resources.Init
+	roles[util.RoleHSMDaemon] = resources.HSMDaemon
+	return roles
+}
+
 func (ca *CA) GetStorage(defaults *config.DeployerDefaults, override *current.CAStorages) *current.CAStorages {
-	storage := defaults.Storage.CA
+	// the defaults are shared by all the requests, they are copied so the
+	// overrides do not change them
+	storage := defaults.Storage.CA.DeepCopy()
 
 	if override != nil {
 		if override.CA != nil && override.CA.Size != "" {
@@ -404,7 +451,7 @@ func (ca *CA) GetStorage(defaults *config.DeployerDefaults, override *current.CA
 }
 
 func (ca *CA) GetResources(defaults *config.DeployerDefaults, override *current.CAResources) *current.CAResources {
-	resources := defaults.Resources.CA
+	resources := defaults.Resources.CA.DeepCopy()
 
 	if override != nil {
 		if override.CA != nil {
@@ -19,6 +19,7 @@
 package ca_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 
@@ -26,18 +27,22 @@ import (
 	. "github.com/onsi/gomega"
 
 	cfg "github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/ca"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/ca/api"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/ca/mocks"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
 	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
 	ibpca "github.com/IBM-Blockchain/fabric-operator/pkg/apis/ca/v1"
 
 	"go.uber.org/zap"
 
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 var _ = Describe("CA", func() {
@@ -134,6 +139,9 @@ var _ = Describe("CA", func() {
 			return nil
 		}
 
+		mockIBPClient.WaitForCRStatusReturns(&current.CRStatus{Status: current.True, Type: current.Deployed}, nil)
+		mockKube.WaitForConfigMapReturns(&corev1.ConfigMap{}, nil)
+
 		mockKube.GetConfigMapStub = func(namespace, name string) (*corev1.ConfigMap, error) {
 			type tls struct {
 				Cert string
@@ -267,17 +275,31 @@ var _ = Describe("CA", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("waits for the CR status and the connection profile", func() {
+			_, _, err := testCA.CreateCR("0.0.0.0", "sID1", "ca1", "default", body)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(mockIBPClient.WaitForCRStatusCallCount()).To(Equal(1))
+			_, namespace, kind, name, _ := mockIBPClient.WaitForCRStatusArgsForCall(0)
+			Expect(namespace).To(Equal("default"))
+			Expect(kind).To(Equal("ibpcas"))
+			Expect(name).To(Equal("ca1"))
+
+			Expect(mockKube.WaitForConfigMapCallCount()).To(Equal(1))
+			_, namespace, name = mockKube.WaitForConfigMapArgsForCall(0)
+			Expect(namespace).To(Equal("default"))
+			Expect(name).To(Equal("ca1-connection-profile"))
+		})
+
+		It("returns error if the connection profile is not created before timeout", func() {
+			mockKube.WaitForConfigMapReturns(nil, context.DeadlineExceeded)
+			_, statusCode, err := testCA.CreateCR("0.0.0.0", "sID1", "ca1", "default", body)
+			Expect(err).To(HaveOccurred())
+			Expect(statusCode).Should(Equal(500))
+		})
+
 		It("returns error if get CR timesout", func() {
-			mockIBPClient.GetCRStub = func(namespace string, kind string, name string, caCR runtime.Object) error {
-				c := caCR.(*current.IBPCA)
-				c.Spec = current.IBPCASpec{}
-				c.Status = current.IBPCAStatus{
-					CRStatus: current.CRStatus{
-						Status: current.False,
-					},
-				}
-				return nil
-			}
+			mockIBPClient.WaitForCRStatusReturns(&current.CRStatus{Status: current.False}, context.DeadlineExceeded)
 			testCA.Config.Timeouts = &cfg.Timeouts{
 				Deployment: 1 * 100,
 			}
@@ -287,6 +309,11 @@ var _ = Describe("CA", func() {
 		})
 
 		It("returns 500 if CR status is error", func() {
+			mockIBPClient.WaitForCRStatusStub = func(_ context.Context, _, _, _ string, cond func(*current.CRStatus) (bool, error)) (*current.CRStatus, error) {
+				status := &current.CRStatus{Status: current.True, Type: current.Error}
+				_, err := cond(status)
+				return status, err
+			}
 			mockIBPClient.GetCRStub = func(namespace string, kind string, name string, caCR runtime.Object) error {
 				c := caCR.(*current.IBPCA)
 				c.Spec = current.IBPCASpec{}
@@ -358,6 +385,126 @@ var _ = Describe("CA", func() {
 		})
 	})
 
+	Context("Dry run", func() {
+		It("returns a conflict if the cr to create already exists", func() {
+			_, statusCode, err := testCA.DryRunCreateCR("ca1", "default", []byte{})
+			Expect(err).To(HaveOccurred())
+			Expect(statusCode).To(Equal(409))
+		})
+
+		It("returns the cr to create without creating it", func() {
+			mockIBPClient.GetCRReturns(k8serrors.NewNotFound(schema.GroupResource{}, "ca1"))
+			resp, statusCode, err := testCA.DryRunCreateCR("ca1", "default", []byte{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(statusCode).To(Equal(200))
+			Expect(mockIBPClient.CreateCRCallCount()).To(Equal(0))
+			Expect(resp.CR.(*current.IBPCA).Name).To(Equal("ca1"))
+		})
+
+		It("returns the updated cr and the diff without updating it", func() {
+			replicas := int32(0)
+			body, err := json.Marshal(&api.UpdateRequest{Replicas: &replicas})
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, statusCode, err := testCA.DryRunUpdateCR(ca.REPLICAS, "ca1", "default", "", body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(statusCode).To(Equal(200))
+			Expect(mockIBPClient.UpdateCRCallCount()).To(Equal(0))
+			Expect(*resp.CR.(*current.IBPCA).Spec.Replicas).To(Equal(int32(0)))
+			Expect(resp.Diff).To(Equal([]common.Change{
+				{Op: common.OpReplace, Path: "/spec/replicas", Value: float64(0), OldValue: float64(1)},
+			}))
+		})
+	})
+
+	Context("Apply", func() {
+		It("plans to create the cr if it does not exist", func() {
+			mockIBPClient.GetCRReturns(k8serrors.NewNotFound(schema.GroupResource{}, "ca1"))
+			found, diff, err := testCA.PlanApply("sID1", "ca1", "default", []byte{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+			Expect(diff).To(BeEmpty())
+		})
+
+		It("plans the changes to the existing cr and keeps its storage and replicas", func() {
+			found, diff, err := testCA.PlanApply("sID1", "ca1", "default", []byte(`{"version": "1.4.1"}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(diff).To(ContainElement(common.Change{Op: common.OpReplace, Path: "/spec/version", Value: "1.4.1", OldValue: ""}))
+			for _, change := range diff {
+				Expect(change.Path).NotTo(HavePrefix("/spec/storage"))
+				Expect(change.Path).NotTo(HavePrefix("/spec/replicas"))
+			}
+			Expect(mockIBPClient.UpdateCRCallCount()).To(Equal(0))
+		})
+
+		It("updates the existing cr", func() {
+			err := testCA.ApplyCR("sID1", "ca1", "default", []byte(`{"version": "1.4.1"}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockIBPClient.UpdateCRCallCount()).To(Equal(1))
+
+			_, kind, name, crBytes := mockIBPClient.UpdateCRArgsForCall(0)
+			Expect(kind).To(Equal("ibpcas"))
+			Expect(name).To(Equal("ca1"))
+			updatedCR := &current.IBPCA{}
+			Expect(json.Unmarshal(crBytes, updatedCR)).To(Succeed())
+			Expect(updatedCR.Spec.FabricVersion).To(Equal("1.4.1"))
+			Expect(*updatedCR.Spec.Replicas).To(Equal(int32(1)))
+		})
+
+		It("returns a not found error if the cr does not exist", func() {
+			mockIBPClient.GetCRReturns(k8serrors.NewNotFound(schema.GroupResource{}, "ca1"))
+			err := testCA.ApplyCR("sID1", "ca1", "default", []byte(`{"version": "1.4.1"}`))
+			Expect(apierror.KindOf(err)).To(Equal(apierror.NotFound))
+		})
+
+		It("does not apply the cr of another service instance", func() {
+			getCR := mockIBPClient.GetCRStub
+			mockIBPClient.GetCRStub = func(namespace string, kind string, name string, caCR runtime.Object) error {
+				err := getCR(namespace, kind, name, caCR)
+				caCR.(*current.IBPCA).Labels = map[string]string{common.ServiceInstanceLabel: "sID2"}
+				return err
+			}
+
+			_, _, err := testCA.PlanApply("sID1", "ca1", "default", []byte(`{"version": "1.4.1"}`))
+			Expect(apierror.KindOf(err)).To(Equal(apierror.Forbidden))
+			err = testCA.ApplyCR("sID1", "ca1", "default", []byte(`{"version": "1.4.1"}`))
+			Expect(apierror.KindOf(err)).To(Equal(apierror.Forbidden))
+			Expect(mockIBPClient.UpdateCRCallCount()).To(Equal(0))
+		})
+
+		It("rejects versions that the transition policy does not allow", func() {
+			getCR := mockIBPClient.GetCRStub
+			mockIBPClient.GetCRStub = func(namespace string, kind string, name string, caCR runtime.Object) error {
+				err := getCR(namespace, kind, name, caCR)
+				caCR.(*current.IBPCA).Spec.FabricVersion = "1.4.2"
+				return err
+			}
+
+			_, _, err := testCA.PlanApply("sID1", "ca1", "default", []byte(`{"version": "1.4.1"}`))
+			Expect(apierror.KindOf(err)).To(Equal(apierror.Validation))
+			Expect(err.Error()).To(ContainSubstring("downgrades must be forced"))
+			err = testCA.ApplyCR("sID1", "ca1", "default", []byte(`{"version": "1.4.1"}`))
+			Expect(apierror.KindOf(err)).To(Equal(apierror.Validation))
+			Expect(mockIBPClient.UpdateCRCallCount()).To(Equal(0))
+		})
+
+		It("lists only the crs labelled with the service instance", func() {
+			mockIBPClient.GetAllCRStub = func(namespace string, kind string, list runtime.Object) error {
+				list.(*current.IBPCAList).Items = []current.IBPCA{
+					{ObjectMeta: metav1.ObjectMeta{Name: "ca1", Labels: map[string]string{common.ServiceInstanceLabel: "sID1"}}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "ca2", Labels: map[string]string{common.ServiceInstanceLabel: "sID2"}}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "legacy"}},
+				}
+				return nil
+			}
+
+			names, err := testCA.ListLabelled("sID1", "default")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal([]string{"ca1"}))
+		})
+	})
+
 	Context("Delete Custom Resource", func() {
 		It("returns an error if it fails delete custom resource", func() {
 			mockIBPClient.DeleteCRReturns(errors.New("delete CR error"))
@@ -533,6 +680,8 @@ var _ = Describe("CA", func() {
 				err := testCA.CheckReplicas(replicas, configOverride)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("CA & TLSCA config override should be passed to allow replicas > 1"))
+				Expect(apierror.KindOf(err)).To(Equal(apierror.Validation))
+				Expect(apierror.From(err).Details[0].Field).To(Equal("configoverride"))
 			})
 
 			It("gives an error if replicas > 1 and Override has nil objects", func() {
@@ -0,0 +1,38 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ca
+
+import (
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/capacity"
+)
+
+// Requirements returns the pods of the replicas of the CA of the create
+// request, with the resources merged with the defaults
+func (ca *CA) Requirements(compName string, body []byte) ([]capacity.Pod, error) {
+	cr, _, err := ca.renderCR(compName, body)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := 1
+	if cr.Spec.Replicas != nil {
+		replicas = int(*cr.Spec.Replicas)
+	}
+	return capacity.NewPods(compName, replicas, roleResources(cr.Spec.Resources)), nil
+}
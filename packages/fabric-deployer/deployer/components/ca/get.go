@@ -24,6 +24,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/ca/api"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
@@ -54,6 +55,9 @@ func (ca *CA) GetAllCR(sID, namespace string) ([]api.Response, int, error) {
 		return nil, 500, err
 	}
 	for _, caCR := range caList.Items {
+		if !common.BelongsTo(caCR.Labels, sID) {
+			continue
+		}
 		compName := caCR.Name
 		response, _, err := ca.GetCRResponse(ALL, compName, namespace, sID)
 		if err != nil {
@@ -66,6 +70,25 @@ func (ca *CA) GetAllCR(sID, namespace string) ([]api.Response, int, error) {
 	return allresponses, 200, nil
 }
 
+// ResourceUsage returns the resources of all the CAs of the service instance
+// in the namespace
+func (ca *CA) ResourceUsage(sID, namespace string) ([]util.ComponentResources, error) {
+	caList := &current.IBPCAList{}
+	err := ca.IBPOperatorClient.GetAllCR(namespace, "ibpcas", caList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get all ca cr in namespace '%s'", namespace)
+	}
+
+	usage := []util.ComponentResources{}
+	for _, cr := range caList.Items {
+		if !common.BelongsTo(cr.Labels, sID) {
+			continue
+		}
+		usage = append(usage, util.NewComponentResources("ca", cr.Name, cr.Spec.Replicas, roleResources(cr.Spec.Resources)))
+	}
+	return usage, nil
+}
+
 func (ca *CA) GetCRResponse(section, compName, namespace, sID string) (*api.Response, int, error) {
 	ca.Logger.Debugf("Received get request for '%s'", compName)
 
@@ -82,6 +105,8 @@ func (ca *CA) GetCRResponse(section, compName, namespace, sID string) (*api.Resp
 		return nil, *statusCode, errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
 	}
 
+	response.ResourceVersion = originalCR.ResourceVersion
+
 	switch section {
 	case RESOURCES:
 		ca.getResources(originalCR, response)
@@ -113,7 +138,7 @@ func (ca *CA) GetCRResponse(section, compName, namespace, sID string) (*api.Resp
 		ca.getCrypto(originalCR, response, statusCode)
 		ca.getOther(originalCR, response)
 	default:
-		return nil, http.StatusBadRequest, errors.Errorf("section %s not supported: %d", section, http.StatusBadRequest)
+		return nil, http.StatusBadRequest, apierror.UnsupportedSection(section)
 	}
 
 	return response, *statusCode, nil
@@ -2,9 +2,11 @@
 package mocks
 
 import (
+	"context"
 	"sync"
 
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/ca"
+	"github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -90,6 +92,23 @@ type IBPOperatorClient struct {
 	updateCRReturnsOnCall map[int]struct {
 		result1 error
 	}
+	WaitForCRStatusStub        func(context.Context, string, string, string, func(status *v1beta1.CRStatus) (bool, error)) (*v1beta1.CRStatus, error)
+	waitForCRStatusMutex       sync.RWMutex
+	waitForCRStatusArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 func(status *v1beta1.CRStatus) (bool, error)
+	}
+	waitForCRStatusReturns struct {
+		result1 *v1beta1.CRStatus
+		result2 error
+	}
+	waitForCRStatusReturnsOnCall map[int]struct {
+		result1 *v1beta1.CRStatus
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -485,6 +504,74 @@ func (fake *IBPOperatorClient) UpdateCRReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *IBPOperatorClient) WaitForCRStatus(arg1 context.Context, arg2 string, arg3 string, arg4 string, arg5 func(status *v1beta1.CRStatus) (bool, error)) (*v1beta1.CRStatus, error) {
+	fake.waitForCRStatusMutex.Lock()
+	ret, specificReturn := fake.waitForCRStatusReturnsOnCall[len(fake.waitForCRStatusArgsForCall)]
+	fake.waitForCRStatusArgsForCall = append(fake.waitForCRStatusArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 func(status *v1beta1.CRStatus) (bool, error)
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.WaitForCRStatusStub
+	fakeReturns := fake.waitForCRStatusReturns
+	fake.recordInvocation("WaitForCRStatus", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.waitForCRStatusMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *IBPOperatorClient) WaitForCRStatusCallCount() int {
+	fake.waitForCRStatusMutex.RLock()
+	defer fake.waitForCRStatusMutex.RUnlock()
+	return len(fake.waitForCRStatusArgsForCall)
+}
+
+func (fake *IBPOperatorClient) WaitForCRStatusCalls(stub func(context.Context, string, string, string, func(status *v1beta1.CRStatus) (bool, error)) (*v1beta1.CRStatus, error)) {
+	fake.waitForCRStatusMutex.Lock()
+	defer fake.waitForCRStatusMutex.Unlock()
+	fake.WaitForCRStatusStub = stub
+}
+
+func (fake *IBPOperatorClient) WaitForCRStatusArgsForCall(i int) (context.Context, string, string, string, func(status *v1beta1.CRStatus) (bool, error)) {
+	fake.waitForCRStatusMutex.RLock()
+	defer fake.waitForCRStatusMutex.RUnlock()
+	argsForCall := fake.waitForCRStatusArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *IBPOperatorClient) WaitForCRStatusReturns(result1 *v1beta1.CRStatus, result2 error) {
+	fake.waitForCRStatusMutex.Lock()
+	defer fake.waitForCRStatusMutex.Unlock()
+	fake.WaitForCRStatusStub = nil
+	fake.waitForCRStatusReturns = struct {
+		result1 *v1beta1.CRStatus
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *IBPOperatorClient) WaitForCRStatusReturnsOnCall(i int, result1 *v1beta1.CRStatus, result2 error) {
+	fake.waitForCRStatusMutex.Lock()
+	defer fake.waitForCRStatusMutex.Unlock()
+	fake.WaitForCRStatusStub = nil
+	if fake.waitForCRStatusReturnsOnCall == nil {
+		fake.waitForCRStatusReturnsOnCall = make(map[int]struct {
+			result1 *v1beta1.CRStatus
+			result2 error
+		})
+	}
+	fake.waitForCRStatusReturnsOnCall[i] = struct {
+		result1 *v1beta1.CRStatus
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *IBPOperatorClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -500,6 +587,8 @@ func (fake *IBPOperatorClient) Invocations() map[string][][]interface{} {
 	defer fake.patchCRMutex.RUnlock()
 	fake.updateCRMutex.RLock()
 	defer fake.updateCRMutex.RUnlock()
+	fake.waitForCRStatusMutex.RLock()
+	defer fake.waitForCRStatusMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -22,13 +22,21 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/ca/api"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
 	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
 	"github.com/pkg/errors"
 )
 
 func (ca *CA) PatchCR(section, compName, namespace, sID string, body []byte) (*api.Response, int, error) {
-	err := ca.patchCR(section, compName, namespace, sID, body)
+	return ca.PatchCRIfMatch(section, compName, namespace, sID, "", body)
+}
+
+// PatchCRIfMatch patches the CR if its ETag matches ifMatch, the value of the
+// If-Match header of the request
+func (ca *CA) PatchCRIfMatch(section, compName, namespace, sID, ifMatch string, body []byte) (*api.Response, int, error) {
+	err := ca.patchCR(section, compName, namespace, sID, ifMatch, body)
 	if err != nil {
 		ca.Logger.Error(errors.Wrapf(err, "patch err for %s", compName))
 		return nil, 500, err
@@ -43,20 +51,62 @@ func (ca *CA) PatchCR(section, compName, namespace, sID string, body []byte) (*a
 	return response, 200, nil
 }
 
-func (ca *CA) patchCR(section, compName, namespace, sID string, body []byte) error {
+// DryRunPatchCR returns the CR that would be patched for the request,
+// nothing is patched
+func (ca *CA) DryRunPatchCR(section, compName, namespace, ifMatch string, body []byte) (*common.DryRunResponse, int, error) {
+	unchangedCR, updatedCR, err := ca.renderPatch(section, compName, namespace, ifMatch, body)
+	if err != nil {
+		ca.Logger.Error(errors.Wrapf(err, "dry run patch err for %s", compName))
+		return nil, 0, err
+	}
+
+	response, err := common.NewDryRunResponse(unchangedCR, updatedCR)
+	if err != nil {
+		return nil, 0, err
+	}
+	return response, http.StatusOK, nil
+}
+
+func (ca *CA) patchCR(section, compName, namespace, sID, ifMatch string, body []byte) error {
+	_, updatedCR, err := ca.renderPatch(section, compName, namespace, ifMatch, body)
+	if err != nil {
+		return err
+	}
+
+	crBytes, err := json.Marshal(updatedCR)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal, invalid request")
+	}
+
+	err = ca.IBPOperatorClient.PatchCR(namespace, "ibpcas", compName, crBytes)
+	if err != nil {
+		return errors.Wrapf(err, "failed patch cr '%s' in namespace '%s'", compName, namespace)
+	}
+
+	return nil
+}
+
+// renderPatch returns the current CR and the CR patched with the request
+func (ca *CA) renderPatch(section, compName, namespace, ifMatch string, body []byte) (*current.IBPCA, *current.IBPCA, error) {
 	ca.Logger.Debugf("Received patch request for '%s'", compName)
 
 	originalCR := &current.IBPCA{}
 	err := ca.IBPOperatorClient.GetCR(namespace, "ibpcas", compName, originalCR)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+		return nil, nil, errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+	}
+	unchangedCR := originalCR.DeepCopy()
+
+	err = common.CheckIfMatch(ifMatch, originalCR.ResourceVersion)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	request := &api.UpdateRequest{}
 	if len(body) != 0 {
 		err = json.Unmarshal(body, request)
 		if err != nil {
-			return errors.Wrapf(err, "failed to unmarshal, invalid request")
+			return nil, nil, apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "failed to unmarshal, invalid request")
 		}
 	}
 
@@ -67,24 +117,18 @@ func (ca *CA) patchCR(section, compName, namespace, sID string, body []byte) err
 		ca.patchConfig(originalCR, request.ConfigOverride)
 	case ACTIONS:
 		ca.patchActions(originalCR, request.Actions)
+	case VERSION:
+		err = ca.updateVersion(originalCR, request.Version, request.ForceVersion)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to patch version")
+		}
 	case ALL:
 		ca.patchAll(originalCR, request)
 	default:
-		return errors.Errorf("section %s not supported: %d", section, http.StatusBadRequest)
-	}
-
-	crBytes, err := json.Marshal(originalCR)
-	if err != nil {
-		return errors.Wrapf(err, "failed to marshal, invalid request")
-	}
-
-	err = ca.IBPOperatorClient.PatchCR(namespace, "ibpcas", compName, crBytes)
-	if err != nil {
-		return errors.Wrapf(err, "failed patch cr '%s' in namespace '%s'", compName, namespace)
+		return nil, nil, apierror.UnsupportedSection(section)
 	}
 
-	return nil
-
+	return unchangedCR, originalCR, nil
 }
 
 func (ca *CA) patchResources(originalCR *current.IBPCA, resources *current.CAResources) {
@@ -22,14 +22,22 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/ca/api"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
 	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
 	"github.com/pkg/errors"
 )
 
 func (ca *CA) UpdateCR(section, compName, namespace, sID string, body []byte) (*api.Response, int, error) {
-	err := ca.updateCR(section, compName, namespace, sID, body)
+	return ca.UpdateCRIfMatch(section, compName, namespace, sID, "", body)
+}
+
+// UpdateCRIfMatch updates the CR if its ETag matches ifMatch, the value of the
+// If-Match header of the request
+func (ca *CA) UpdateCRIfMatch(section, compName, namespace, sID, ifMatch string, body []byte) (*api.Response, int, error) {
+	err := ca.updateCR(section, compName, namespace, sID, ifMatch, body)
 	if err != nil {
 		ca.Logger.Error(errors.Wrapf(err, "update err for %s", compName))
 		return nil, 500, err
@@ -44,20 +52,62 @@ func (ca *CA) UpdateCR(section, compName, namespace, sID string, body []byte) (*
 	return response, 200, nil
 }
 
-func (ca *CA) updateCR(section, compName, namespace, sID string, body []byte) error {
+// DryRunUpdateCR returns the CR that would be updated for the request,
+// nothing is updated
+func (ca *CA) DryRunUpdateCR(section, compName, namespace, ifMatch string, body []byte) (*common.DryRunResponse, int, error) {
+	unchangedCR, updatedCR, err := ca.renderUpdate(section, compName, namespace, ifMatch, body)
+	if err != nil {
+		ca.Logger.Error(errors.Wrapf(err, "dry run update err for %s", compName))
+		return nil, 0, err
+	}
+
+	response, err := common.NewDryRunResponse(unchangedCR, updatedCR)
+	if err != nil {
+		return nil, 0, err
+	}
+	return response, http.StatusOK, nil
+}
+
+func (ca *CA) updateCR(section, compName, namespace, sID, ifMatch string, body []byte) error {
+	_, updatedCR, err := ca.renderUpdate(section, compName, namespace, ifMatch, body)
+	if err != nil {
+		return err
+	}
+
+	crBytes, err := json.Marshal(updatedCR)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal, invalid request")
+	}
+
+	err = ca.IBPOperatorClient.UpdateCR(namespace, "ibpcas", compName, crBytes)
+	if err != nil {
+		return errors.Wrapf(err, "failed update cr '%s' in namespace '%s'", compName, namespace)
+	}
+
+	return nil
+}
+
+// renderUpdate returns the current CR and the CR updated with the request
+func (ca *CA) renderUpdate(section, compName, namespace, ifMatch string, body []byte) (*current.IBPCA, *current.IBPCA, error) {
 	ca.Logger.Debugf("Received update request for '%s'", compName)
 
 	originalCR := &current.IBPCA{}
 	err := ca.IBPOperatorClient.GetCR(namespace, "ibpcas", compName, originalCR)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+		return nil, nil, errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+	}
+	unchangedCR := originalCR.DeepCopy()
+
+	err = common.CheckIfMatch(ifMatch, originalCR.ResourceVersion)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	request := &api.UpdateRequest{}
 	if len(body) != 0 {
 		err = json.Unmarshal(body, request)
 		if err != nil {
-			return errors.Wrapf(err, "failed to unmarshal, invalid request")
+			return nil, nil, apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "failed to unmarshal, invalid request")
 		}
 	}
 
@@ -65,37 +115,27 @@ func (ca *CA) updateCR(section, compName, namespace, sID string, body []byte) er
 	case CONFIG:
 		ca.updateConfig(originalCR, request.ConfigOverride)
 	case VERSION:
-		err := ca.updateVersion(originalCR, request.Version)
+		err := ca.updateVersion(originalCR, request.Version, request.ForceVersion)
 		if err != nil {
-			return errors.Wrap(err, "failed to update version")
+			return nil, nil, errors.Wrap(err, "failed to update version")
 		}
 	case REPLICAS:
 		err := ca.updateReplicas(originalCR, request.Replicas)
 		if err != nil {
-			return errors.Wrap(err, "failed to update replicas")
+			return nil, nil, errors.Wrap(err, "failed to update replicas")
 		}
 	case HSM:
 		ca.updateHSM(originalCR, request.HSM)
 	case ALL:
 		err := ca.updateAll(originalCR, request)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 	default:
-		return errors.Errorf("section %s not supported: %d", section, http.StatusBadRequest)
-	}
-
-	crBytes, err := json.Marshal(originalCR)
-	if err != nil {
-		return errors.Wrapf(err, "failed to marshal, invalid request")
-	}
-
-	err = ca.IBPOperatorClient.UpdateCR(namespace, "ibpcas", compName, crBytes)
-	if err != nil {
-		return errors.Wrapf(err, "failed update cr '%s' in namespace '%s'", compName, namespace)
+		return nil, nil, apierror.UnsupportedSection(section)
 	}
 
-	return nil
+	return unchangedCR, originalCR, nil
 }
 
 func (ca *CA) updateConfig(originalCR *current.IBPCA, config *current.ConfigOverride) {
@@ -107,14 +147,20 @@ func (ca *CA) updateConfig(originalCR *current.IBPCA, config *current.ConfigOver
 	originalCR.Spec.ConfigOverride = config
 }
 
-func (ca *CA) updateVersion(originalCR *current.IBPCA, version string) error {
+func (ca *CA) updateVersion(originalCR *current.IBPCA, version string, force bool) error {
 	if version == "" {
 		return nil
 	}
 
 	if !util.IsValidVersion("ca", version, ca.Config.Versions) {
 		ca.Logger.Error("Version not valid")
-		return errors.New("version not valid")
+		return apierror.InvalidField("version", "version not valid")
+	}
+
+	err := util.CheckVersionTransition("ca", originalCR.Spec.FabricVersion, version, force, ca.Config.Versions)
+	if err != nil {
+		ca.Logger.Error(err)
+		return apierror.InvalidField("version", "%s", err.Error())
 	}
 
 	image := ca.Config.Versions.CA[version].Image
@@ -148,7 +194,7 @@ func (ca *CA) updateHSM(originalCR *current.IBPCA, hsm *current.HSM) {
 
 func (ca *CA) updateAll(originalCR *current.IBPCA, request *api.UpdateRequest) error {
 	ca.updateConfig(originalCR, request.ConfigOverride)
-	err := ca.updateVersion(originalCR, request.Version)
+	err := ca.updateVersion(originalCR, request.Version, request.ForceVersion)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,144 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// JSON patch operations of the changes
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+// DryRunResponse is returned instead of the component when a create, update
+// or patch request is a dry run. CR is the custom resource that would have
+// been submitted and Diff its changes from the current custom resource.
+type DryRunResponse struct {
+	DryRun bool        `json:"dryRun"`
+	CR     interface{} `json:"cr"`
+	Diff   []Change    `json:"diff"`
+}
+
+// Change is a difference between two JSON documents, in the format of a
+// JSON patch (RFC 6902) operation with the previous value
+type Change struct {
+	Op       string      `json:"op"`
+	Path     string      `json:"path"`
+	Value    interface{} `json:"value,omitempty"`
+	OldValue interface{} `json:"oldValue,omitempty"`
+}
+
+// NewDryRunResponse returns the response of a dry run that would change the
+// current CR, which is nil for creates, into the updated CR
+func NewDryRunResponse(current, updated interface{}) (*DryRunResponse, error) {
+	diff, err := Diff(current, updated)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DryRunResponse{
+		DryRun: true,
+		CR:     updated,
+		Diff:   diff,
+	}, nil
+}
+
+// Diff returns the changes between the JSON representations of original and
+// updated, ordered by path. Objects are compared field by field, other values
+// including arrays are replaced as a whole.
+func Diff(original, updated interface{}) ([]Change, error) {
+	o, err := toJSONValue(original)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal original")
+	}
+	u, err := toJSONValue(updated)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal updated")
+	}
+
+	changes := []Change{}
+	diff("", o, u, &changes)
+	return changes, nil
+}
+
+func toJSONValue(obj interface{}) (interface{}, error) {
+	if obj == nil || reflect.ValueOf(obj).Kind() == reflect.Ptr && reflect.ValueOf(obj).IsNil() {
+		return map[string]interface{}{}, nil
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	err = json.Unmarshal(data, &value)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func diff(path string, original, updated interface{}, changes *[]Change) {
+	originalMap, originalIsMap := original.(map[string]interface{})
+	updatedMap, updatedIsMap := updated.(map[string]interface{})
+	if !originalIsMap || !updatedIsMap {
+		if !reflect.DeepEqual(original, updated) {
+			*changes = append(*changes, Change{Op: OpReplace, Path: path, Value: updated, OldValue: original})
+		}
+		return
+	}
+
+	keys := []string{}
+	for key := range originalMap {
+		keys = append(keys, key)
+	}
+	for key := range updatedMap {
+		if _, found := originalMap[key]; !found {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := path + "/" + escapePointer(key)
+		o, inOriginal := originalMap[key]
+		u, inUpdated := updatedMap[key]
+		switch {
+		case !inOriginal:
+			*changes = append(*changes, Change{Op: OpAdd, Path: keyPath, Value: u})
+		case !inUpdated:
+			*changes = append(*changes, Change{Op: OpRemove, Path: keyPath, OldValue: o})
+		default:
+			diff(keyPath, o, u, changes)
+		}
+	}
+}
+
+// escapePointer escapes the key as a JSON pointer (RFC 6901) token
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	common "github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+)
+
+var _ = Describe("Dry run", func() {
+	Context("diff", func() {
+		It("returns no changes for equal documents", func() {
+			changes, err := common.Diff(map[string]interface{}{"a": 1}, map[string]interface{}{"a": 1})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(BeEmpty())
+		})
+
+		It("returns added, removed and replaced fields ordered by path", func() {
+			original := map[string]interface{}{
+				"kept":    "value",
+				"removed": "old",
+				"nested":  map[string]interface{}{"size": "1Gi"},
+				"list":    []string{"a"},
+			}
+			updated := map[string]interface{}{
+				"kept":   "value",
+				"added":  true,
+				"nested": map[string]interface{}{"size": "2Gi"},
+				"list":   []string{"a", "b"},
+			}
+
+			changes, err := common.Diff(original, updated)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(Equal([]common.Change{
+				{Op: common.OpAdd, Path: "/added", Value: true},
+				{Op: common.OpReplace, Path: "/list", Value: []interface{}{"a", "b"}, OldValue: []interface{}{"a"}},
+				{Op: common.OpReplace, Path: "/nested/size", Value: "2Gi", OldValue: "1Gi"},
+				{Op: common.OpRemove, Path: "/removed", OldValue: "old"},
+			}))
+		})
+
+		It("escapes the keys of the paths", func() {
+			changes, err := common.Diff(nil, map[string]interface{}{"a/b~c": 1})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(HaveLen(1))
+			Expect(changes[0].Path).To(Equal("/a~1b~0c"))
+		})
+	})
+
+	Context("response", func() {
+		It("adds the whole CR when there is no current CR", func() {
+			cr := &current.IBPCA{}
+			cr.Name = "ca1"
+
+			var original *current.IBPCA
+			response, err := common.NewDryRunResponse(original, cr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.DryRun).To(Equal(true))
+			Expect(response.CR).To(Equal(cr))
+			Expect(response.Diff).To(ContainElement(common.Change{
+				Op:    common.OpAdd,
+				Path:  "/metadata",
+				Value: map[string]interface{}{"name": "ca1", "creationTimestamp": nil},
+			}))
+		})
+	})
+})
@@ -0,0 +1,52 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"strings"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+)
+
+// ETag returns the entity tag of a CR, which is its quoted resource version
+func ETag(resourceVersion string) string {
+	if resourceVersion == "" {
+		return ""
+	}
+	return `"` + resourceVersion + `"`
+}
+
+// CheckIfMatch returns an error if the value of an If-Match header does not
+// match the entity tag of the CR with the resource version. An empty value
+// matches any CR.
+func CheckIfMatch(ifMatch, resourceVersion string) error {
+	if ifMatch == "" {
+		return nil
+	}
+
+	etag := ETag(resourceVersion)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || (etag != "" && tag == etag) {
+			return nil
+		}
+	}
+
+	return apierror.New(apierror.PreconditionFailed, apierror.CodeETagMismatch, "precondition failed, If-Match %s does not match the current ETag %s", ifMatch, etag)
+}
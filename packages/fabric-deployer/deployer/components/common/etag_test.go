@@ -0,0 +1,54 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	common "github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+)
+
+var _ = Describe("ETag", func() {
+	It("quotes the resource version", func() {
+		Expect(common.ETag("123")).To(Equal(`"123"`))
+		Expect(common.ETag("")).To(Equal(""))
+	})
+
+	Context("If-Match", func() {
+		It("matches any resource version if empty or *", func() {
+			Expect(common.CheckIfMatch("", "123")).To(Succeed())
+			Expect(common.CheckIfMatch("*", "123")).To(Succeed())
+		})
+
+		It("matches one of the entity tags", func() {
+			Expect(common.CheckIfMatch(`"122", "123"`, "123")).To(Succeed())
+		})
+
+		It("returns a precondition failed error if no entity tag matches", func() {
+			err := common.CheckIfMatch(`"122"`, "123")
+			Expect(err).To(MatchError(`precondition failed, If-Match "122" does not match the current ETag "123"`))
+		})
+
+		It("does not match unquoted or weak entity tags", func() {
+			Expect(common.CheckIfMatch("123", "123")).NotTo(Succeed())
+			Expect(common.CheckIfMatch(`W/"123"`, "123")).NotTo(Succeed())
+		})
+	})
+})
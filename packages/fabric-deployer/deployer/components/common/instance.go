@@ -0,0 +1,52 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ServiceInstanceLabel is the label of the CRs with the ID of the service
+// instance they were created for
+const ServiceInstanceLabel = "fabric-deployer/service-instance-id"
+
+// SetServiceInstance labels the CR with the ID of its service instance
+func SetServiceInstance(cr metav1.Object, sID string) {
+	labels := cr.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[ServiceInstanceLabel] = sID
+	cr.SetLabels(labels)
+}
+
+// BelongsTo returns true if the labels of a CR do not assign it to another
+// service instance. CRs without the label, e.g. created before the CRs were
+// labelled, belong to the service instances of their namespace.
+func BelongsTo(labels map[string]string, sID string) bool {
+	owner, found := labels[ServiceInstanceLabel]
+	return !found || owner == sID
+}
+
+// LabelledWith returns true if the CR is labelled with the service instance.
+// Unlike BelongsTo, CRs without the label are not assigned to it.
+func LabelledWith(labels map[string]string, sID string) bool {
+	owner, found := labels[ServiceInstanceLabel]
+	return found && owner == sID
+}
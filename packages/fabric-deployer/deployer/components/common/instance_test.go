@@ -0,0 +1,46 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	common "github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+)
+
+var _ = Describe("Service instance", func() {
+	It("labels the CR with the service instance", func() {
+		cr := &current.IBPPeer{}
+		common.SetServiceInstance(cr, "sid1")
+		Expect(cr.Labels).To(HaveKeyWithValue(common.ServiceInstanceLabel, "sid1"))
+	})
+
+	It("assigns the CRs to the service instance of their label", func() {
+		labels := map[string]string{common.ServiceInstanceLabel: "sid1"}
+		Expect(common.BelongsTo(labels, "sid1")).To(BeTrue())
+		Expect(common.BelongsTo(labels, "sid2")).To(BeFalse())
+	})
+
+	It("assigns the CRs without label to every service instance", func() {
+		Expect(common.BelongsTo(nil, "sid1")).To(BeTrue())
+		Expect(common.BelongsTo(map[string]string{"parent": "os1"}, "sid2")).To(BeTrue())
+	})
+})
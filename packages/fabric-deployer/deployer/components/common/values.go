@@ -29,6 +29,17 @@ const (
 	NODE = "node"
 )
 
+// Deployment states reported for the individual nodes of a component
+const (
+	NodeStateDeploying = "deploying"
+	NodeStateDeployed  = "deployed"
+	NodeStateFailed    = "failed"
+)
+
+// NodeProgressFunc is called when the deployment state of a node changes,
+// total is the number of nodes being deployed for the component
+type NodeProgressFunc func(nodeName string, total int, state string, err error)
+
 type ConnectionProfile struct {
 	Endpoints interface{} `json:"endpoints"`
 	TLS       interface{} `json:"tls"`
@@ -0,0 +1,125 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"context"
+	"time"
+
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// CRWatcher gets and watches the CRs of the components
+type CRWatcher interface {
+	GetCR(namespace string, kind string, name string, cr runtime.Object) error
+	WaitForCRStatus(ctx context.Context, namespace string, kind string, name string, cond func(status *current.CRStatus) (bool, error)) (*current.CRStatus, error)
+}
+
+// ConfigMapWatcher watches the config maps of the components
+type ConfigMapWatcher interface {
+	WaitForConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error)
+}
+
+// MissInterval is how often the CR of a component is checked again while it
+// is missing, a CR that may be missed n times may be missing for n intervals
+const MissInterval = 500 * time.Millisecond
+
+// WaitForDeployment blocks until the operator has deployed the component,
+// that is until its connection profile exists or its CR is Deployed, or until
+// the operator sets the status of the CR to Error. The CR is watched the whole
+// time, so that an Error set after Deploying is not missed. The CR is checked
+// on every change and every MissInterval, the wait fails once the CR has been
+// found missing more than misses times. The last status of the CR is
+// returned, nil if it has not been seen.
+func WaitForDeployment(ctx context.Context, crs CRWatcher, configMaps ConfigMapWatcher, namespace, kind, name string, misses int) (*current.CRStatus, error) {
+	crCtx, cancelCR := context.WithCancel(ctx)
+	defer cancelCR()
+	profileCtx, cancelProfile := context.WithCancel(ctx)
+	defer cancelProfile()
+
+	profile := make(chan error, 1)
+	go func() {
+		_, err := configMaps.WaitForConfigMap(profileCtx, namespace, name+"-connection-profile")
+		if err == nil {
+			// the component is deployed, no need to watch its CR any longer
+			cancelCR()
+		}
+		profile <- err
+	}()
+
+	cond := func(status *current.CRStatus) (bool, error) {
+		if status == nil {
+			// the watch may not have caught up with the creation of the CR
+			// yet, so the API server is asked before counting it as missing
+			err := crs.GetCR(namespace, kind, name, &unstructured.Unstructured{})
+			if err == nil {
+				return false, nil
+			}
+			misses--
+			if misses < 0 {
+				return false, err
+			}
+			return false, nil
+		}
+		if status.Status != current.True {
+			return false, nil
+		}
+		switch status.Type {
+		case current.Error:
+			// if cr status is error, no need to get configs
+			return false, errors.New("CR status is set to error")
+		case current.Deployed:
+			return true, nil
+		}
+		return false, nil
+	}
+
+	var crStatus *current.CRStatus
+	var err error
+	for {
+		// a missing CR does not change, the wait is restarted every interval
+		// so that the misses are counted over time
+		missCtx, cancelMiss := context.WithTimeout(crCtx, MissInterval)
+		crStatus, err = crs.WaitForCRStatus(missCtx, namespace, kind, name, cond)
+		cancelMiss()
+		if err == nil || !errors.Is(err, context.DeadlineExceeded) || crCtx.Err() != nil {
+			break
+		}
+	}
+	if err != nil {
+		if errors.Is(err, context.Canceled) && ctx.Err() == nil {
+			// the wait on the CR is only canceled once the connection
+			// profile exists
+			return crStatus, nil
+		}
+		return crStatus, err
+	}
+
+	// the operator creates the connection profile of deployed components
+	err = <-profile
+	if err != nil {
+		return crStatus, err
+	}
+
+	return crStatus, nil
+}
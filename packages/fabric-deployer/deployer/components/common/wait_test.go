@@ -0,0 +1,173 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	common "github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// fakeCRs evaluates the condition against each of the statuses in turn, as
+// the watch would for every change of the CR, then waits for ctx
+type fakeCRs struct {
+	statuses []*current.CRStatus
+	getErr   error
+}
+
+func (f *fakeCRs) GetCR(namespace string, kind string, name string, cr runtime.Object) error {
+	return f.getErr
+}
+
+func (f *fakeCRs) WaitForCRStatus(ctx context.Context, namespace string, kind string, name string, cond func(status *current.CRStatus) (bool, error)) (*current.CRStatus, error) {
+	var last *current.CRStatus
+	for _, status := range f.statuses {
+		if status != nil {
+			last = status
+		}
+		done, err := cond(status)
+		if err != nil || done {
+			return last, err
+		}
+	}
+	<-ctx.Done()
+	return last, ctx.Err()
+}
+
+// fakeConfigMaps returns the config map once created is closed
+type fakeConfigMaps struct {
+	created chan struct{}
+}
+
+func (f *fakeConfigMaps) WaitForConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	select {
+	case <-f.created:
+		return &corev1.ConfigMap{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+var _ = Describe("WaitForDeployment", func() {
+	var (
+		crs        *fakeCRs
+		configMaps *fakeConfigMaps
+		ctx        context.Context
+		cancel     context.CancelFunc
+	)
+
+	deploying := &current.CRStatus{Status: current.True, Type: current.Deploying}
+	deployed := &current.CRStatus{Status: current.True, Type: current.Deployed}
+	failed := &current.CRStatus{Status: current.True, Type: current.Error}
+	notFound := k8serrors.NewNotFound(schema.GroupResource{Resource: "ibppeers"}, "peer1")
+
+	BeforeEach(func() {
+		crs = &fakeCRs{}
+		configMaps = &fakeConfigMaps{created: make(chan struct{})}
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("returns once the CR is deployed and the connection profile exists", func() {
+		crs.statuses = []*current.CRStatus{deploying, deployed}
+		close(configMaps.created)
+
+		status, err := common.WaitForDeployment(ctx, crs, configMaps, "default", "ibppeers", "peer1", 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(Equal(deployed))
+	})
+
+	It("returns once the connection profile exists while the CR is deploying", func() {
+		crs.statuses = []*current.CRStatus{deploying}
+		close(configMaps.created)
+
+		status, err := common.WaitForDeployment(ctx, crs, configMaps, "default", "ibppeers", "peer1", 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(Equal(deploying))
+	})
+
+	It("keeps watching the CR after it is deploying and returns its error", func() {
+		crs.statuses = []*current.CRStatus{deploying, failed}
+
+		status, err := common.WaitForDeployment(ctx, crs, configMaps, "default", "ibppeers", "peer1", 0)
+		Expect(err).To(MatchError("CR status is set to error"))
+		Expect(status).To(Equal(failed))
+	})
+
+	It("fails as soon as the CR is missing", func() {
+		crs.statuses = []*current.CRStatus{nil}
+		crs.getErr = notFound
+
+		status, err := common.WaitForDeployment(ctx, crs, configMaps, "default", "ibppeers", "peer1", 0)
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		Expect(status).To(BeNil())
+	})
+
+	It("does not count the CR as missing if the API server has it", func() {
+		crs.statuses = []*current.CRStatus{nil, deployed}
+		close(configMaps.created)
+
+		_, err := common.WaitForDeployment(ctx, crs, configMaps, "default", "ibppeers", "peer1", 0)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("allows the CR to be missing up to misses times", func() {
+		crs.statuses = []*current.CRStatus{nil, nil, deployed}
+		crs.getErr = notFound
+		close(configMaps.created)
+
+		_, err := common.WaitForDeployment(ctx, crs, configMaps, "default", "ibporderers", "orderer1node1", 2)
+		Expect(err).NotTo(HaveOccurred())
+
+		crs.statuses = []*current.CRStatus{nil, nil, deployed}
+		_, err = common.WaitForDeployment(ctx, crs, configMaps, "default", "ibporderers", "orderer1node1", 1)
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("counts the misses of a CR that stays missing every interval", func() {
+		crs.statuses = []*current.CRStatus{nil}
+		crs.getErr = notFound
+
+		start := time.Now()
+		_, err := common.WaitForDeployment(ctx, crs, configMaps, "default", "ibporderers", "orderer1node1", 2)
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		Expect(time.Since(start)).To(BeNumerically(">=", 2*common.MissInterval))
+	})
+
+	It("returns an error if the component is not deployed before timeout", func() {
+		crs.statuses = []*current.CRStatus{deploying}
+		ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+
+		status, err := common.WaitForDeployment(ctx, crs, configMaps, "default", "ibppeers", "peer1", 0)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(status).To(Equal(deploying))
+	})
+})
@@ -1,13 +1,117 @@
 package api
 
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
 type CreateRequest struct {
-	HSMConfig interface{} `json:"hsmconfig,omitempty"`
+	HSMConfig *HSMConfig `json:"hsmconfig,omitempty"`
 }
 
 type UpdateRequest struct {
-	HSMConfig interface{} `json:"hsmconfig,omitempty"`
+	// HSMConfig is applied to the existing config as a JSON merge patch
+	HSMConfig *runtime.RawExtension `json:"hsmconfig,omitempty"`
 }
 
 type Response struct {
+	// HSMConfig is the config as stored in the configmap, with the fields
+	// that HSMConfig does not model
 	HSMConfig interface{} `json:"hsmconfig,omitempty"`
 }
+
+type DeleteResponse struct {
+	Message string `json:"message,omitempty"`
+}
+
+// HSMConfig is the content of the ibm-hlfsupport-hsm-config configmap that is
+// read by the operator when deploying components configured to use an HSM
+type HSMConfig struct {
+	// Type of the config, should be "hsm"
+	Type string `json:"type,omitempty"`
+
+	// Version of the config format
+	Version string `json:"version,omitempty"`
+
+	// Library is the PKCS11 library used to connect to the HSM
+	Library Library `json:"library"`
+
+	// Slot is the HSM slot the components connect to
+	Slot *int `json:"slot,omitempty"`
+
+	// Label is the HSM token label, alternative to Slot
+	Label string `json:"label,omitempty"`
+
+	// PIN is a reference to the secret key holding the HSM PIN
+	PIN *SecretKeyRef `json:"pin,omitempty"`
+
+	// Envs are environment variables set on the HSM client containers
+	Envs []corev1.EnvVar `json:"envs,omitempty"`
+
+	// MountPaths are the volumes mounted into the HSM client containers
+	MountPaths []MountPath `json:"mountpaths,omitempty"`
+
+	// Daemon configures the HSM daemon sidecar, if the HSM requires one
+	Daemon *Daemon `json:"daemon,omitempty"`
+}
+
+type Library struct {
+	// FilePath is the absolute path of the PKCS11 library within the image
+	FilePath string `json:"filepath"`
+
+	// Image containing the PKCS11 library
+	Image string `json:"image,omitempty"`
+
+	// AutoUpdateDisabled prevents the operator from updating the library image
+	AutoUpdateDisabled bool `json:"autoUpdateDisabled,omitempty"`
+
+	// Auth holds the credentials to pull the library image
+	Auth *Auth `json:"auth,omitempty"`
+}
+
+type Auth struct {
+	ImagePullSecret string `json:"imagePullSecret,omitempty"`
+}
+
+type SecretKeyRef struct {
+	// Name of the secret
+	Name string `json:"name"`
+
+	// Key within the secret
+	Key string `json:"key"`
+}
+
+type MountPath struct {
+	// Name of the volume
+	Name string `json:"name"`
+
+	// Secret is the name of the secret backing the volume
+	Secret string `json:"secret,omitempty"`
+
+	// MountPath is the absolute path the volume is mounted at
+	MountPath string `json:"mountpath"`
+
+	// UsePathAsKey mounts each of the paths at its own path instead of the
+	// whole volume at the mount path
+	UsePathAsKey bool `json:"usePathAsKey,omitempty"`
+
+	// Paths are the secret keys to project into the volume
+	Paths []Path `json:"paths,omitempty"`
+
+	// VolumeSource is used instead of Secret for non secret volumes
+	VolumeSource *corev1.VolumeSource `json:"volumeSource,omitempty"`
+}
+
+type Path struct {
+	Key  string `json:"key"`
+	Path string `json:"path"`
+}
+
+type Daemon struct {
+	Image           string                       `json:"image"`
+	Envs            []corev1.EnvVar              `json:"envs,omitempty"`
+	MountPaths      []MountPath                  `json:"mountpaths,omitempty"`
+	Auth            *Auth                        `json:"auth,omitempty"`
+	SecurityContext *corev1.SecurityContext      `json:"securityContext,omitempty"`
+	Resources       *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
@@ -21,6 +21,7 @@ package operator
 import (
 	"net/http"
 
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/operator/api"
 	"github.com/pkg/errors"
@@ -46,7 +47,7 @@ func (o *Operator) getResponse(section, namespace string) (*api.Response, int, e
 	case HSMCONFIG:
 		o.getHSMConfig(namespace, response, statusCode)
 	default:
-		return nil, http.StatusBadRequest, errors.Errorf("section %s not supported: %d", section, http.StatusBadRequest)
+		return nil, http.StatusBadRequest, apierror.UnsupportedSection(section)
 	}
 
 	return response, *statusCode, nil
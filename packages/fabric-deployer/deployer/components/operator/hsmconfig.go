@@ -0,0 +1,289 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/operator/api"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// ValidateHSMConfig checks that the hsm config has all the fields required
+// by the operator before it is written to the configmap
+func ValidateHSMConfig(hsmConfig *api.HSMConfig) error {
+	if hsmConfig == nil {
+		return apierror.InvalidField("hsmconfig", "hsm config is required")
+	}
+
+	problems := []apierror.FieldError{}
+	if hsmConfig.Type != "" && hsmConfig.Type != "hsm" {
+		problems = append(problems, problem("type", "type must be 'hsm', got '%s'", hsmConfig.Type))
+	}
+
+	if hsmConfig.Library.FilePath == "" {
+		problems = append(problems, problem("library.filepath", "library.filepath is required"))
+	} else if !filepath.IsAbs(hsmConfig.Library.FilePath) {
+		problems = append(problems, problem("library.filepath", "library.filepath must be an absolute path"))
+	}
+
+	if hsmConfig.Slot != nil && *hsmConfig.Slot < 0 {
+		problems = append(problems, problem("slot", "slot must not be negative"))
+	}
+	if hsmConfig.Slot != nil && hsmConfig.Label != "" {
+		problems = append(problems, problem("slot", "only one of slot or label can be set"))
+	}
+
+	if hsmConfig.PIN != nil {
+		problems = append(problems, validateSecretKeyRef("pin", hsmConfig.PIN)...)
+	}
+
+	problems = append(problems, validateEnvs("envs", hsmConfig.Envs)...)
+	problems = append(problems, validateMountPaths("mountpaths", hsmConfig.MountPaths)...)
+
+	if hsmConfig.Daemon != nil {
+		if hsmConfig.Daemon.Image == "" {
+			problems = append(problems, problem("daemon.image", "daemon.image is required"))
+		}
+		problems = append(problems, validateEnvs("daemon.envs", hsmConfig.Daemon.Envs)...)
+		problems = append(problems, validateMountPaths("daemon.mountpaths", hsmConfig.Daemon.MountPaths)...)
+	}
+
+	if len(problems) > 0 {
+		messages := []string{}
+		for _, fieldErr := range problems {
+			messages = append(messages, fieldErr.Message)
+		}
+		return apierror.New(apierror.Validation, apierror.CodeInvalidRequest, "invalid hsm config: %s", strings.Join(messages, "; ")).
+			WithDetails(problems...)
+	}
+
+	return nil
+}
+
+func validateSecretKeyRef(field string, ref *api.SecretKeyRef) []apierror.FieldError {
+	problems := []apierror.FieldError{}
+	if ref.Name == "" {
+		problems = append(problems, problem(field+".name", "%s.name is required", field))
+	}
+	if ref.Key == "" {
+		problems = append(problems, problem(field+".key", "%s.key is required", field))
+	}
+	return problems
+}
+
+func validateEnvs(field string, envs []corev1.EnvVar) []apierror.FieldError {
+	problems := []apierror.FieldError{}
+	names := map[string]bool{}
+	for i, env := range envs {
+		if env.Name == "" {
+			problems = append(problems, problem(fmt.Sprintf("%s[%d].name", field, i), "%s[%d].name is required", field, i))
+			continue
+		}
+		if names[env.Name] {
+			problems = append(problems, problem(fmt.Sprintf("%s[%d].name", field, i), "%s[%d].name '%s' is duplicated", field, i, env.Name))
+		}
+		names[env.Name] = true
+
+		if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+			ref := env.ValueFrom.SecretKeyRef
+			problems = append(problems, validateSecretKeyRef(fmt.Sprintf("%s[%d].valueFrom.secretKeyRef", field, i), &api.SecretKeyRef{
+				Name: ref.Name,
+				Key:  ref.Key,
+			})...)
+		}
+	}
+	return problems
+}
+
+func validateMountPaths(field string, mountPaths []api.MountPath) []apierror.FieldError {
+	problems := []apierror.FieldError{}
+	names := map[string]bool{}
+	for i, mount := range mountPaths {
+		prefix := fmt.Sprintf("%s[%d]", field, i)
+		if mount.Name == "" {
+			problems = append(problems, problem(prefix+".name", "%s.name is required", prefix))
+		} else if names[mount.Name] {
+			problems = append(problems, problem(prefix+".name", "%s.name '%s' is duplicated", prefix, mount.Name))
+		}
+		names[mount.Name] = true
+
+		if mount.MountPath == "" {
+			problems = append(problems, problem(prefix+".mountpath", "%s.mountpath is required", prefix))
+		} else if !filepath.IsAbs(mount.MountPath) {
+			problems = append(problems, problem(prefix+".mountpath", "%s.mountpath must be an absolute path", prefix))
+		}
+
+		if mount.Secret == "" && mount.VolumeSource == nil {
+			problems = append(problems, problem(prefix, "%s requires either secret or volumeSource", prefix))
+		}
+		if mount.Secret != "" && mount.VolumeSource != nil {
+			problems = append(problems, problem(prefix, "%s can only set one of secret or volumeSource", prefix))
+		}
+
+		for j, path := range mount.Paths {
+			if path.Key == "" || path.Path == "" {
+				problems = append(problems, problem(fmt.Sprintf("%s.paths[%d]", prefix, j), "%s.paths[%d] requires key and path", prefix, j))
+			}
+		}
+	}
+	return problems
+}
+
+func problem(field, format string, args ...interface{}) apierror.FieldError {
+	return apierror.FieldError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// PutHSMConfig validates the hsm config and writes it to the hsm configmap,
+// creating the configmap if it does not exist. Returns true if the configmap
+// was created.
+func (o *Operator) PutHSMConfig(namespace string, hsmConfig *api.HSMConfig) (bool, error) {
+	err := ValidateHSMConfig(hsmConfig)
+	if err != nil {
+		return false, err
+	}
+
+	data, err := yaml.Marshal(hsmConfig)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to marshal hsm config")
+	}
+
+	return o.putHSMConfigData(namespace, data)
+}
+
+// putHSMConfigData writes the hsm config to the hsm configmap, creating the
+// configmap if it does not exist. Returns true if the configmap was created.
+func (o *Operator) putHSMConfigData(namespace string, data []byte) (bool, error) {
+	cm, err := o.Kube.GetConfigMap(namespace, HSMConfigMapName)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return false, errors.Wrap(err, "failed to get hsm configmap")
+		}
+
+		o.Logger.Debugf("Creating hsm configmap in namespace %s", namespace)
+		_, err = o.Kube.CreateConfigMap(namespace, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      HSMConfigMapName,
+				Namespace: namespace,
+			},
+			Data: map[string]string{
+				HSMConfigKey: string(data),
+			},
+		})
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	o.Logger.Debugf("Replacing hsm config in configmap in namespace %s", namespace)
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[HSMConfigKey] = string(data)
+	_, err = o.Kube.UpdateConfigMap(namespace, cm)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to update hsm configmap")
+	}
+
+	return false, nil
+}
+
+// PatchHSMConfig applies a JSON merge patch (RFC 7386) to the existing hsm
+// config, validates the result and writes it back to the configmap. The patch
+// is rejected if it has fields unknown to the hsm config, while the fields of
+// the stored config that the hsm config does not model are kept as they are.
+// Returns the config that was written.
+func (o *Operator) PatchHSMConfig(namespace string, patch []byte) (map[string]interface{}, error) {
+	data, err := o.getHSMConfigData(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	originalMap := map[string]interface{}{}
+	err = yaml.Unmarshal([]byte(data), &originalMap)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal hsm config")
+	}
+
+	patchMap := map[string]interface{}{}
+	err = yaml.Unmarshal(patch, &patchMap)
+	if err != nil {
+		return nil, apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "hsm config patch is not a valid object")
+	}
+	err = yaml.UnmarshalStrict(patch, &api.HSMConfig{})
+	if err != nil {
+		return nil, apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "invalid hsm config patch")
+	}
+
+	patchedMap := mergePatch(originalMap, patchMap)
+	merged, err := yaml.Marshal(patchedMap)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal patched hsm config")
+	}
+
+	patched := &api.HSMConfig{}
+	err = yaml.Unmarshal(merged, patched)
+	if err != nil {
+		return nil, apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "invalid hsm config")
+	}
+	err = ValidateHSMConfig(patched)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = o.putHSMConfigData(namespace, merged)
+	if err != nil {
+		return nil, err
+	}
+
+	return patchedMap, nil
+}
+
+// mergePatch merges patch into original following JSON merge patch rules:
+// null values remove keys, objects are merged recursively and all other
+// values replace the original value
+func mergePatch(original, patch map[string]interface{}) map[string]interface{} {
+	for key, value := range patch {
+		if value == nil {
+			delete(original, key)
+			continue
+		}
+
+		patchObj, ok := value.(map[string]interface{})
+		if !ok {
+			original[key] = value
+			continue
+		}
+
+		originalObj, ok := original[key].(map[string]interface{})
+		if !ok {
+			originalObj = map[string]interface{}{}
+		}
+		original[key] = mergePatch(originalObj, patchObj)
+	}
+	return original
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operator_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/operator"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/operator/api"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("HSM config validation", func() {
+
+	var (
+		hsmConfig *api.HSMConfig
+	)
+
+	BeforeEach(func() {
+		slot := 0
+		hsmConfig = &api.HSMConfig{
+			Type:    "hsm",
+			Version: "v1",
+			Library: api.Library{
+				FilePath: "/usr/local/lib/libpkcs11.so",
+				Image:    "registry/hsm-client:latest",
+			},
+			Slot: &slot,
+			PIN: &api.SecretKeyRef{
+				Name: "hsm-pin",
+				Key:  "pin",
+			},
+			Envs: []corev1.EnvVar{
+				{Name: "HSM_CONFIG", Value: "/etc/hsm/config"},
+			},
+			MountPaths: []api.MountPath{
+				{
+					Name:      "hsmcrypto",
+					Secret:    "hsmcrypto",
+					MountPath: "/hsm",
+					Paths: []api.Path{
+						{Key: "cafile.pem", Path: "cafile.pem"},
+					},
+				},
+			},
+		}
+	})
+
+	It("accepts a valid config", func() {
+		Expect(operator.ValidateHSMConfig(hsmConfig)).To(Succeed())
+	})
+
+	It("returns error if config is missing", func() {
+		err := operator.ValidateHSMConfig(nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("hsm config is required"))
+	})
+
+	It("returns error if library path is missing or relative", func() {
+		hsmConfig.Library.FilePath = ""
+		err := operator.ValidateHSMConfig(hsmConfig)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("library.filepath is required"))
+
+		hsmConfig.Library.FilePath = "lib/libpkcs11.so"
+		err = operator.ValidateHSMConfig(hsmConfig)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("library.filepath must be an absolute path"))
+	})
+
+	It("returns error if slot is negative or set with label", func() {
+		slot := -1
+		hsmConfig.Slot = &slot
+		hsmConfig.Label = "token"
+		err := operator.ValidateHSMConfig(hsmConfig)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("slot must not be negative"))
+		Expect(err.Error()).To(ContainSubstring("only one of slot or label can be set"))
+	})
+
+	It("returns error if pin secret reference is incomplete", func() {
+		hsmConfig.PIN.Key = ""
+		err := operator.ValidateHSMConfig(hsmConfig)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("pin.key is required"))
+	})
+
+	It("returns error if envs are duplicated", func() {
+		hsmConfig.Envs = append(hsmConfig.Envs, corev1.EnvVar{Name: "HSM_CONFIG"})
+		err := operator.ValidateHSMConfig(hsmConfig)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("envs[1].name 'HSM_CONFIG' is duplicated"))
+	})
+
+	It("returns error if mount paths are invalid", func() {
+		hsmConfig.MountPaths[0].MountPath = "hsm"
+		hsmConfig.MountPaths[0].Secret = ""
+		err := operator.ValidateHSMConfig(hsmConfig)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("mountpaths[0].mountpath must be an absolute path"))
+		Expect(err.Error()).To(ContainSubstring("mountpaths[0] requires either secret or volumeSource"))
+	})
+
+	It("flags validation errors as bad requests with field details", func() {
+		hsmConfig.Library.FilePath = ""
+		err := operator.ValidateHSMConfig(hsmConfig)
+		Expect(apierror.StatusCode(err)).To(Equal(http.StatusBadRequest))
+
+		apiErr := apierror.From(err)
+		Expect(apiErr.Code).To(Equal(apierror.CodeInvalidRequest))
+		Expect(apiErr.Details).To(ContainElement(apierror.FieldError{Field: "library.filepath", Message: "library.filepath is required"}))
+	})
+})
@@ -9,6 +9,32 @@ import (
 )
 
 type Kube struct {
+	CreateConfigMapStub        func(string, *v1.ConfigMap) (*v1.ConfigMap, error)
+	createConfigMapMutex       sync.RWMutex
+	createConfigMapArgsForCall []struct {
+		arg1 string
+		arg2 *v1.ConfigMap
+	}
+	createConfigMapReturns struct {
+		result1 *v1.ConfigMap
+		result2 error
+	}
+	createConfigMapReturnsOnCall map[int]struct {
+		result1 *v1.ConfigMap
+		result2 error
+	}
+	DeleteConfigMapStub        func(string, string) error
+	deleteConfigMapMutex       sync.RWMutex
+	deleteConfigMapArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	deleteConfigMapReturns struct {
+		result1 error
+	}
+	deleteConfigMapReturnsOnCall map[int]struct {
+		result1 error
+	}
 	GetConfigMapStub        func(string, string) (*v1.ConfigMap, error)
 	getConfigMapMutex       sync.RWMutex
 	getConfigMapArgsForCall []struct {
@@ -65,10 +91,151 @@ type Kube struct {
 		result1 *v1.Service
 		result2 error
 	}
+	UpdateConfigMapStub        func(string, *v1.ConfigMap) (*v1.ConfigMap, error)
+	updateConfigMapMutex       sync.RWMutex
+	updateConfigMapArgsForCall []struct {
+		arg1 string
+		arg2 *v1.ConfigMap
+	}
+	updateConfigMapReturns struct {
+		result1 *v1.ConfigMap
+		result2 error
+	}
+	updateConfigMapReturnsOnCall map[int]struct {
+		result1 *v1.ConfigMap
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *Kube) CreateConfigMap(arg1 string, arg2 *v1.ConfigMap) (*v1.ConfigMap, error) {
+	fake.createConfigMapMutex.Lock()
+	ret, specificReturn := fake.createConfigMapReturnsOnCall[len(fake.createConfigMapArgsForCall)]
+	fake.createConfigMapArgsForCall = append(fake.createConfigMapArgsForCall, struct {
+		arg1 string
+		arg2 *v1.ConfigMap
+	}{arg1, arg2})
+	stub := fake.CreateConfigMapStub
+	fakeReturns := fake.createConfigMapReturns
+	fake.recordInvocation("CreateConfigMap", []interface{}{arg1, arg2})
+	fake.createConfigMapMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Kube) CreateConfigMapCallCount() int {
+	fake.createConfigMapMutex.RLock()
+	defer fake.createConfigMapMutex.RUnlock()
+	return len(fake.createConfigMapArgsForCall)
+}
+
+func (fake *Kube) CreateConfigMapCalls(stub func(string, *v1.ConfigMap) (*v1.ConfigMap, error)) {
+	fake.createConfigMapMutex.Lock()
+	defer fake.createConfigMapMutex.Unlock()
+	fake.CreateConfigMapStub = stub
+}
+
+func (fake *Kube) CreateConfigMapArgsForCall(i int) (string, *v1.ConfigMap) {
+	fake.createConfigMapMutex.RLock()
+	defer fake.createConfigMapMutex.RUnlock()
+	argsForCall := fake.createConfigMapArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Kube) CreateConfigMapReturns(result1 *v1.ConfigMap, result2 error) {
+	fake.createConfigMapMutex.Lock()
+	defer fake.createConfigMapMutex.Unlock()
+	fake.CreateConfigMapStub = nil
+	fake.createConfigMapReturns = struct {
+		result1 *v1.ConfigMap
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) CreateConfigMapReturnsOnCall(i int, result1 *v1.ConfigMap, result2 error) {
+	fake.createConfigMapMutex.Lock()
+	defer fake.createConfigMapMutex.Unlock()
+	fake.CreateConfigMapStub = nil
+	if fake.createConfigMapReturnsOnCall == nil {
+		fake.createConfigMapReturnsOnCall = make(map[int]struct {
+			result1 *v1.ConfigMap
+			result2 error
+		})
+	}
+	fake.createConfigMapReturnsOnCall[i] = struct {
+		result1 *v1.ConfigMap
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) DeleteConfigMap(arg1 string, arg2 string) error {
+	fake.deleteConfigMapMutex.Lock()
+	ret, specificReturn := fake.deleteConfigMapReturnsOnCall[len(fake.deleteConfigMapArgsForCall)]
+	fake.deleteConfigMapArgsForCall = append(fake.deleteConfigMapArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeleteConfigMapStub
+	fakeReturns := fake.deleteConfigMapReturns
+	fake.recordInvocation("DeleteConfigMap", []interface{}{arg1, arg2})
+	fake.deleteConfigMapMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Kube) DeleteConfigMapCallCount() int {
+	fake.deleteConfigMapMutex.RLock()
+	defer fake.deleteConfigMapMutex.RUnlock()
+	return len(fake.deleteConfigMapArgsForCall)
+}
+
+func (fake *Kube) DeleteConfigMapCalls(stub func(string, string) error) {
+	fake.deleteConfigMapMutex.Lock()
+	defer fake.deleteConfigMapMutex.Unlock()
+	fake.DeleteConfigMapStub = stub
+}
+
+func (fake *Kube) DeleteConfigMapArgsForCall(i int) (string, string) {
+	fake.deleteConfigMapMutex.RLock()
+	defer fake.deleteConfigMapMutex.RUnlock()
+	argsForCall := fake.deleteConfigMapArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Kube) DeleteConfigMapReturns(result1 error) {
+	fake.deleteConfigMapMutex.Lock()
+	defer fake.deleteConfigMapMutex.Unlock()
+	fake.DeleteConfigMapStub = nil
+	fake.deleteConfigMapReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Kube) DeleteConfigMapReturnsOnCall(i int, result1 error) {
+	fake.deleteConfigMapMutex.Lock()
+	defer fake.deleteConfigMapMutex.Unlock()
+	fake.DeleteConfigMapStub = nil
+	if fake.deleteConfigMapReturnsOnCall == nil {
+		fake.deleteConfigMapReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteConfigMapReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *Kube) GetConfigMap(arg1 string, arg2 string) (*v1.ConfigMap, error) {
 	fake.getConfigMapMutex.Lock()
 	ret, specificReturn := fake.getConfigMapReturnsOnCall[len(fake.getConfigMapArgsForCall)]
@@ -329,9 +496,78 @@ func (fake *Kube) GetServiceReturnsOnCall(i int, result1 *v1.Service, result2 er
 	}{result1, result2}
 }
 
+func (fake *Kube) UpdateConfigMap(arg1 string, arg2 *v1.ConfigMap) (*v1.ConfigMap, error) {
+	fake.updateConfigMapMutex.Lock()
+	ret, specificReturn := fake.updateConfigMapReturnsOnCall[len(fake.updateConfigMapArgsForCall)]
+	fake.updateConfigMapArgsForCall = append(fake.updateConfigMapArgsForCall, struct {
+		arg1 string
+		arg2 *v1.ConfigMap
+	}{arg1, arg2})
+	stub := fake.UpdateConfigMapStub
+	fakeReturns := fake.updateConfigMapReturns
+	fake.recordInvocation("UpdateConfigMap", []interface{}{arg1, arg2})
+	fake.updateConfigMapMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Kube) UpdateConfigMapCallCount() int {
+	fake.updateConfigMapMutex.RLock()
+	defer fake.updateConfigMapMutex.RUnlock()
+	return len(fake.updateConfigMapArgsForCall)
+}
+
+func (fake *Kube) UpdateConfigMapCalls(stub func(string, *v1.ConfigMap) (*v1.ConfigMap, error)) {
+	fake.updateConfigMapMutex.Lock()
+	defer fake.updateConfigMapMutex.Unlock()
+	fake.UpdateConfigMapStub = stub
+}
+
+func (fake *Kube) UpdateConfigMapArgsForCall(i int) (string, *v1.ConfigMap) {
+	fake.updateConfigMapMutex.RLock()
+	defer fake.updateConfigMapMutex.RUnlock()
+	argsForCall := fake.updateConfigMapArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Kube) UpdateConfigMapReturns(result1 *v1.ConfigMap, result2 error) {
+	fake.updateConfigMapMutex.Lock()
+	defer fake.updateConfigMapMutex.Unlock()
+	fake.UpdateConfigMapStub = nil
+	fake.updateConfigMapReturns = struct {
+		result1 *v1.ConfigMap
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) UpdateConfigMapReturnsOnCall(i int, result1 *v1.ConfigMap, result2 error) {
+	fake.updateConfigMapMutex.Lock()
+	defer fake.updateConfigMapMutex.Unlock()
+	fake.UpdateConfigMapStub = nil
+	if fake.updateConfigMapReturnsOnCall == nil {
+		fake.updateConfigMapReturnsOnCall = make(map[int]struct {
+			result1 *v1.ConfigMap
+			result2 error
+		})
+	}
+	fake.updateConfigMapReturnsOnCall[i] = struct {
+		result1 *v1.ConfigMap
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Kube) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.createConfigMapMutex.RLock()
+	defer fake.createConfigMapMutex.RUnlock()
+	fake.deleteConfigMapMutex.RLock()
+	defer fake.deleteConfigMapMutex.RUnlock()
 	fake.getConfigMapMutex.RLock()
 	defer fake.getConfigMapMutex.RUnlock()
 	fake.getPortMutex.RLock()
@@ -340,6 +576,8 @@ func (fake *Kube) Invocations() map[string][][]interface{} {
 	defer fake.getPortsMutex.RUnlock()
 	fake.getServiceMutex.RLock()
 	defer fake.getServiceMutex.RUnlock()
+	fake.updateConfigMapMutex.RLock()
+	defer fake.updateConfigMapMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
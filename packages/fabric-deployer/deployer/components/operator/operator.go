@@ -19,11 +19,13 @@
 package operator
 
 import (
-	"errors"
-
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/operator/api"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
 )
 
 // Supported actions for Operator-related requests
@@ -32,11 +34,19 @@ const (
 	ALL       = "all"
 )
 
+const (
+	HSMConfigMapName = "ibm-hlfsupport-hsm-config"
+	HSMConfigKey     = "ibm-hlfsupport-hsm-config.yaml"
+)
+
 //go:generate counterfeiter -o mocks/kube.go -fake-name Kube . Kube
 
 type Kube interface {
 	GetService(namespace, name string) (*corev1.Service, error)
 	GetConfigMap(namespace, name string) (*corev1.ConfigMap, error)
+	CreateConfigMap(namespace string, cm *corev1.ConfigMap) (*corev1.ConfigMap, error)
+	UpdateConfigMap(namespace string, cm *corev1.ConfigMap) (*corev1.ConfigMap, error)
+	DeleteConfigMap(namespace, name string) error
 	GetPort(namespace, name string) (int32, error)
 	GetPorts(namespace, name string) ([]corev1.ServicePort, error)
 }
@@ -64,16 +74,59 @@ func New(logger *zap.Logger, k8sClient Kube) *Operator {
 	}
 }
 
-func (o *Operator) GetHSMConfig(namespace string) (interface{}, error) {
-	cmName := "ibm-hlfsupport-hsm-config"
-	cm, err := o.Kube.GetConfigMap(namespace, cmName)
+// GetHSMConfig returns the content of the hsm configmap in the namespace. It
+// is not decoded into an api.HSMConfig so that the fields it does not model
+// are returned too.
+func (o *Operator) GetHSMConfig(namespace string) (map[string]interface{}, error) {
+	data, err := o.getHSMConfigData(namespace)
 	if err != nil {
 		return nil, err
 	}
-	data := cm.Data["ibm-hlfsupport-hsm-config.yaml"]
+
+	hsmConfig := map[string]interface{}{}
+	err = yaml.Unmarshal([]byte(data), &hsmConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal hsm config")
+	}
+
+	return hsmConfig, nil
+}
+
+// getHSMConfigData returns the hsm config as stored in the hsm configmap
+func (o *Operator) getHSMConfigData(namespace string) (string, error) {
+	cm, err := o.Kube.GetConfigMap(namespace, HSMConfigMapName)
+	if err != nil {
+		return "", err
+	}
+	data := cm.Data[HSMConfigKey]
 	if data == "" {
-		return nil, errors.New("ibm-hlfsupport-hsm-config.yaml not found in configmap")
+		return "", apierror.New(apierror.NotFound, apierror.CodeNotFound, "ibm-hlfsupport-hsm-config.yaml not found in configmap")
 	}
 
 	return data, nil
 }
+
+// DeleteHSMConfig removes the hsm configmap from the namespace
+func (o *Operator) DeleteHSMConfig(namespace string) error {
+	return o.Kube.DeleteConfigMap(namespace, HSMConfigMapName)
+}
+
+// Delete removes the operator config for the section in the namespace
+func (o *Operator) Delete(section, namespace string) (*api.DeleteResponse, int, error) {
+	o.Logger.Debugf("Received request to delete %s in namespace %s", section, namespace)
+
+	switch section {
+	case HSMCONFIG:
+		err := o.DeleteHSMConfig(namespace)
+		if err != nil {
+			o.Logger.Error(errors.Wrapf(err, "failed to delete hsm config in namespace %s", namespace))
+			return nil, 0, err
+		}
+	default:
+		return nil, 0, apierror.UnsupportedSection(section)
+	}
+
+	return &api.DeleteResponse{
+		Message: "ok",
+	}, 0, nil
+}
@@ -80,6 +80,19 @@ var _ = Describe("Operator", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(config).NotTo(BeNil())
 		})
+
+		It("returns the fields unknown to the hsm config", func() {
+			mockKube.GetConfigMapStub = nil
+			mockKube.GetConfigMapReturns(&corev1.ConfigMap{
+				Data: map[string]string{
+					operator.HSMConfigKey: "type: hsm\nlibrary:\n  filepath: /usr/lib/libpkcs11.so\n  certs: /etc/hsm/certs\n",
+				},
+			}, nil)
+
+			config, err := testOperator.GetHSMConfig("test-ns")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config["library"]).To(HaveKeyWithValue("certs", "/etc/hsm/certs"))
+		})
 	})
 
 })
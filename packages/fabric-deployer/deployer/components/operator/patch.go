@@ -0,0 +1,61 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operator
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/operator/api"
+	"github.com/pkg/errors"
+)
+
+// Patch merges the request into the existing operator config for the section
+// in the namespace
+func (o *Operator) Patch(section, namespace string, body []byte) (*api.Response, int, error) {
+	o.Logger.Debugf("Received request to patch %s in namespace %s", section, namespace)
+
+	request := &api.UpdateRequest{}
+	if len(body) != 0 {
+		err := json.Unmarshal(body, request)
+		if err != nil {
+			return nil, 0, apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "failed to unmarshal request")
+		}
+	}
+
+	switch section {
+	case HSMCONFIG:
+		if request.HSMConfig == nil || len(request.HSMConfig.Raw) == 0 {
+			return nil, 0, apierror.InvalidField("hsmconfig", "hsmconfig is required")
+		}
+
+		hsmConfig, err := o.PatchHSMConfig(namespace, request.HSMConfig.Raw)
+		if err != nil {
+			o.Logger.Error(errors.Wrapf(err, "failed to patch hsm config in namespace %s", namespace))
+			return nil, 0, err
+		}
+
+		return &api.Response{
+			HSMConfig: hsmConfig,
+		}, http.StatusOK, nil
+	default:
+		return nil, 0, apierror.UnsupportedSection(section)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operator_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/operator"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/operator/mocks"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"go.uber.org/zap"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("Patch API", func() {
+
+	var (
+		err          error
+		testOperator *operator.Operator
+		mockKube     *mocks.Kube
+		logger       *zap.Logger
+	)
+
+	BeforeEach(func() {
+		logger, err = zap.NewProductionConfig().Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		mockKube = &mocks.Kube{}
+
+		testOperator = operator.New(logger, mockKube)
+
+		mockKube.GetConfigMapReturns(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      operator.HSMConfigMapName,
+				Namespace: "namespace",
+			},
+			Data: map[string]string{
+				operator.HSMConfigKey: `type: hsm
+version: v1
+library:
+  filepath: /usr/lib/libpkcs11.so
+  image: registry/hsm-client:1.0
+label: token
+envs:
+- name: HSM_DEBUG
+  value: "true"
+`,
+			},
+		}, nil)
+	})
+
+	Context("patch HSM config", func() {
+		It("returns error if patch is missing", func() {
+			_, _, err := testOperator.Patch(operator.HSMCONFIG, "namespace", []byte(`{}`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("hsmconfig is required"))
+		})
+
+		It("merges the patch into the existing config", func() {
+			resp, code, err := testOperator.Patch(operator.HSMCONFIG, "namespace", []byte(`{"hsmconfig": {"library": {"image": "registry/hsm-client:2.0"}, "label": null, "slot": 2}}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(code).To(Equal(200))
+			hsmConfig := resp.HSMConfig.(map[string]interface{})
+			Expect(hsmConfig["library"]).To(Equal(map[string]interface{}{
+				"filepath": "/usr/lib/libpkcs11.so",
+				"image":    "registry/hsm-client:2.0",
+			}))
+			Expect(hsmConfig).NotTo(HaveKey("label"))
+			Expect(hsmConfig["slot"]).To(BeEquivalentTo(2))
+			Expect(hsmConfig["envs"]).To(HaveLen(1))
+
+			Expect(mockKube.UpdateConfigMapCallCount()).To(Equal(1))
+			_, cm := mockKube.UpdateConfigMapArgsForCall(0)
+			Expect(cm.Data[operator.HSMConfigKey]).To(ContainSubstring("image: registry/hsm-client:2.0"))
+		})
+
+		It("returns error if patched config is invalid", func() {
+			_, _, err := testOperator.Patch(operator.HSMCONFIG, "namespace", []byte(`{"hsmconfig": {"library": {"filepath": "relative.so"}}}`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("library.filepath must be an absolute path"))
+			Expect(mockKube.UpdateConfigMapCallCount()).To(Equal(0))
+		})
+
+		It("returns error if patch contains unknown fields", func() {
+			_, _, err := testOperator.Patch(operator.HSMCONFIG, "namespace", []byte(`{"hsmconfig": {"libary": {}}}`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`unknown field "libary"`))
+			Expect(apierror.KindOf(err)).To(Equal(apierror.Validation))
+		})
+
+		It("keeps the fields of the stored config unknown to the hsm config", func() {
+			mockKube.GetConfigMapReturns(&corev1.ConfigMap{
+				Data: map[string]string{
+					operator.HSMConfigKey: "type: hsm\nlibrary:\n  filepath: /usr/lib/libpkcs11.so\n  certs: /etc/hsm/certs\n",
+				},
+			}, nil)
+
+			_, _, err := testOperator.Patch(operator.HSMCONFIG, "namespace", []byte(`{"hsmconfig": {"label": "token"}}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, cm := mockKube.UpdateConfigMapArgsForCall(0)
+			Expect(cm.Data[operator.HSMConfigKey]).To(ContainSubstring("certs: /etc/hsm/certs"))
+			Expect(cm.Data[operator.HSMConfigKey]).To(ContainSubstring("label: token"))
+		})
+
+		It("writes the config it returns", func() {
+			resp, _, err := testOperator.Patch(operator.HSMCONFIG, "namespace", []byte(`{"hsmconfig": {"slot": 2, "label": null}}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, cm := mockKube.UpdateConfigMapArgsForCall(0)
+			written := map[string]interface{}{}
+			Expect(yaml.Unmarshal([]byte(cm.Data[operator.HSMConfigKey]), &written)).To(Succeed())
+			Expect(written).To(Equal(resp.HSMConfig))
+		})
+	})
+})
@@ -0,0 +1,160 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operator_test
+
+import (
+	"github.com/pkg/errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/operator"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/operator/api"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/operator/mocks"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Update API", func() {
+
+	var (
+		err          error
+		testOperator *operator.Operator
+		mockKube     *mocks.Kube
+		logger       *zap.Logger
+		body         []byte
+	)
+
+	BeforeEach(func() {
+		logger, err = zap.NewProductionConfig().Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		mockKube = &mocks.Kube{}
+
+		testOperator = operator.New(logger, mockKube)
+
+		mockKube.GetConfigMapReturns(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      operator.HSMConfigMapName,
+				Namespace: "namespace",
+			},
+			Data: map[string]string{
+				operator.HSMConfigKey: "type: hsm\nlibrary:\n  filepath: /old/lib.so\n",
+				"other":               "untouched",
+			},
+		}, nil)
+
+		body = []byte(`{"hsmconfig": {"type": "hsm", "version": "v1", "library": {"filepath": "/usr/lib/libpkcs11.so"}, "slot": 1, "pin": {"name": "hsm-pin", "key": "pin"}}}`)
+	})
+
+	Context("update HSM config", func() {
+		It("returns error for unsupported section", func() {
+			_, _, err := testOperator.Update("bad", "namespace", body)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("section 'bad' not supported"))
+			Expect(apierror.KindOf(err)).To(Equal(apierror.Validation))
+		})
+
+		It("returns error if request is invalid", func() {
+			_, _, err := testOperator.Update(operator.HSMCONFIG, "namespace", []byte(`{"hsmconfig": {"library": {}}}`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("library.filepath is required"))
+			Expect(mockKube.UpdateConfigMapCallCount()).To(Equal(0))
+			Expect(mockKube.CreateConfigMapCallCount()).To(Equal(0))
+		})
+
+		It("returns error if the config has unknown fields", func() {
+			_, _, err := testOperator.Update(operator.HSMCONFIG, "namespace", []byte(`{"hsmconfig": {"library": {"filepath": "/usr/lib/libpkcs11.so", "certs": "/etc/hsm/certs"}}}`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`unknown field "certs"`))
+			Expect(apierror.KindOf(err)).To(Equal(apierror.Validation))
+			Expect(mockKube.UpdateConfigMapCallCount()).To(Equal(0))
+		})
+
+		It("accepts the fields of the operator hsm config", func() {
+			_, _, err := testOperator.Update(operator.HSMCONFIG, "namespace", []byte(`{"hsmconfig": {"library": {"filepath": "/usr/lib/libpkcs11.so"},
+				"mountpaths": [{"name": "hsmcrypto", "secret": "hsmcrypto", "mountpath": "/hsm", "usePathAsKey": true, "paths": [{"key": "cafile.pem", "path": "cafile.pem"}]}],
+				"daemon": {"image": "registry/hsm-daemon:1.0", "securityContext": {"privileged": true}, "resources": {"requests": {"cpu": "100m"}}}}}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, cm := mockKube.UpdateConfigMapArgsForCall(0)
+			Expect(cm.Data[operator.HSMConfigKey]).To(ContainSubstring("usePathAsKey: true"))
+			Expect(cm.Data[operator.HSMConfigKey]).To(ContainSubstring("privileged: true"))
+		})
+
+		It("creates the configmap if it does not exist", func() {
+			mockKube.GetConfigMapReturns(nil, k8serrors.NewNotFound(schema.GroupResource{}, "not found"))
+			resp, code, err := testOperator.Update(operator.HSMCONFIG, "namespace", body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(code).To(Equal(201))
+			Expect(resp.HSMConfig.(*api.HSMConfig).Library.FilePath).To(Equal("/usr/lib/libpkcs11.so"))
+
+			Expect(mockKube.CreateConfigMapCallCount()).To(Equal(1))
+			namespace, cm := mockKube.CreateConfigMapArgsForCall(0)
+			Expect(namespace).To(Equal("namespace"))
+			Expect(cm.Name).To(Equal(operator.HSMConfigMapName))
+			Expect(cm.Data[operator.HSMConfigKey]).To(ContainSubstring("filepath: /usr/lib/libpkcs11.so"))
+		})
+
+		It("replaces the config in an existing configmap", func() {
+			_, code, err := testOperator.Update(operator.HSMCONFIG, "namespace", body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(code).To(Equal(200))
+
+			Expect(mockKube.UpdateConfigMapCallCount()).To(Equal(1))
+			_, cm := mockKube.UpdateConfigMapArgsForCall(0)
+			Expect(cm.Data[operator.HSMConfigKey]).To(ContainSubstring("filepath: /usr/lib/libpkcs11.so"))
+			Expect(cm.Data[operator.HSMConfigKey]).To(ContainSubstring("slot: 1"))
+			Expect(cm.Data["other"]).To(Equal("untouched"))
+		})
+
+		It("returns error if fails to get configmap", func() {
+			mockKube.GetConfigMapReturns(nil, errors.New("get error"))
+			_, _, err := testOperator.Update(operator.HSMCONFIG, "namespace", body)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("get error"))
+		})
+	})
+
+	Context("delete HSM config", func() {
+		It("deletes the configmap", func() {
+			resp, _, err := testOperator.Delete(operator.HSMCONFIG, "namespace")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Message).To(Equal("ok"))
+
+			Expect(mockKube.DeleteConfigMapCallCount()).To(Equal(1))
+			namespace, name := mockKube.DeleteConfigMapArgsForCall(0)
+			Expect(namespace).To(Equal("namespace"))
+			Expect(name).To(Equal(operator.HSMConfigMapName))
+		})
+
+		It("returns error if fails to delete configmap", func() {
+			mockKube.DeleteConfigMapReturns(k8serrors.NewNotFound(schema.GroupResource{}, operator.HSMConfigMapName))
+			_, _, err := testOperator.Delete(operator.HSMCONFIG, "namespace")
+			Expect(err).To(HaveOccurred())
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
@@ -42,6 +42,8 @@ type CreateRequest struct {
 	Zone              []string                  `json:"zone,omitempty"`
 	Region            []string                  `json:"region,omitempty"`
 	ChannelLess       *bool                     `json:"channelless,omitempty"`
+	// Profile is the sizing profile of the defaults, e.g. production
+	Profile string `json:"profile,omitempty"`
 }
 
 type PrecreateRequest struct {
@@ -59,6 +61,8 @@ type PrecreateRequest struct {
 	Arch              []string                  `json:"arch,omitempty"`
 	Zone              string                    `json:"zone,omitempty"`
 	Region            string                    `json:"region,omitempty"`
+	// Profile is the sizing profile of the defaults, e.g. production
+	Profile string `json:"profile,omitempty"`
 }
 
 type DeleteRequest struct {
@@ -68,7 +72,9 @@ type DeleteRequest struct {
 }
 
 type UpdateRequest struct {
-	Version        string                    `json:"version,omitempty"`
+	Version string `json:"version,omitempty"`
+	// ForceVersion allows the version to be downgraded
+	ForceVersion   bool                      `json:"forceVersion,omitempty"`
 	Config         *current.SecretSpec       `json:"crypto,omitempty"`
 	AdminCerts     []string                  `json:"admincerts,omitempty"`
 	Resources      *current.OrdererResources `json:"resources,omitempty"`
@@ -82,6 +88,7 @@ type UpdateRequest struct {
 
 type Component struct {
 	Name                 string                    `json:"name,omitempty"`
+	Cluster              string                    `json:"cluster,omitempty"`
 	Endpoints            interface{}               `json:"endpoints,omitempty"`
 	MSP                  *common.MSP               `json:"msp,omitempty"`
 	Crypto               *current.SecretSpec       `json:"crypto,omitempty"`
@@ -108,6 +115,14 @@ type Component struct {
 
 type Response struct {
 	Component `json:",inline"`
+
+	// ResourceVersion of the CR, returned in the ETag header
+	ResourceVersion string `json:"-"`
+}
+
+// GetResourceVersion returns the resource version of the CR
+func (r *Response) GetResourceVersion() string {
+	return r.ResourceVersion
 }
 
 type Deleted struct {
@@ -0,0 +1,48 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package orderer
+
+import (
+	"fmt"
+
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/capacity"
+)
+
+// Requirements returns the pods of the nodes of the orderer cluster of the
+// create request, with the resources merged with the defaults
+func (o *Orderer) Requirements(compName string, body []byte) ([]capacity.Pod, error) {
+	if compName == "" {
+		return nil, apierror.InvalidField("componentName", "component name not valid, cannot be empty")
+	}
+
+	spec, _, err := o.renderCluster(body)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := roleResources(&current.IBPOrderer{Spec: *spec})
+	pods := []capacity.Pod{}
+	for i := 1; i <= spec.ClusterSize; i++ {
+		pods = append(pods, capacity.NewPod(fmt.Sprintf("%snode%d", compName, i), roles))
+	}
+	return pods, nil
+}
@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer/api"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
@@ -53,7 +54,12 @@ func (o *Orderer) GetAllCR(sID, namespace string) ([]api.Response, int, error) {
 		o.Logger.Error(errors.Wrapf(err, "Failed to get all orderer cr for in namespace '%s'", namespace))
 		return nil, 500, err
 	}
-	for _, originalCR := range ordererList.Items {
+	crs := byName(ordererList)
+	for i := range ordererList.Items {
+		originalCR := &ordererList.Items[i]
+		if !common.BelongsTo(serviceInstanceLabels(originalCR, crs), sID) {
+			continue
+		}
 		compName := originalCR.Name
 
 		response, _, err := o.GetCRResponse(ALL, compName, namespace, sID)
@@ -67,6 +73,57 @@ func (o *Orderer) GetAllCR(sID, namespace string) ([]api.Response, int, error) {
 	return allresponses, 200, nil
 }
 
+// ResourceUsage returns the resources of all the orderer nodes of the service
+// instance in the namespace, the parents of the clusters run no pods and are
+// left out
+func (o *Orderer) ResourceUsage(sID, namespace string) ([]util.ComponentResources, error) {
+	ordererList := &current.IBPOrdererList{}
+	err := o.IBPOperatorClient.GetAllCR(namespace, "IBPOrderers", ordererList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get all orderer cr in namespace '%s'", namespace)
+	}
+
+	parents := map[string]bool{}
+	for _, cr := range ordererList.Items {
+		if parent := cr.Labels["parent"]; parent != "" {
+			parents[parent] = true
+		}
+	}
+
+	crs := byName(ordererList)
+	usage := []util.ComponentResources{}
+	for i := range ordererList.Items {
+		cr := &ordererList.Items[i]
+		if parents[cr.Name] || !common.BelongsTo(serviceInstanceLabels(cr, crs), sID) {
+			continue
+		}
+		usage = append(usage, util.NewComponentResources("orderer", cr.Name, cr.Spec.Replicas, roleResources(cr)))
+	}
+	return usage, nil
+}
+
+// ServiceInstanceLabels returns the labels that assign the CR to a service
+// instance. The nodes of a cluster are created by the operator, without the
+// label, and belong to the service instance of their parent.
+func ServiceInstanceLabels(cr *current.IBPOrderer, parent *current.IBPOrderer) map[string]string {
+	if _, found := cr.Labels[common.ServiceInstanceLabel]; found || parent == nil {
+		return cr.Labels
+	}
+	return parent.Labels
+}
+
+func serviceInstanceLabels(cr *current.IBPOrderer, crs map[string]*current.IBPOrderer) map[string]string {
+	return ServiceInstanceLabels(cr, crs[cr.Labels["parent"]])
+}
+
+func byName(list *current.IBPOrdererList) map[string]*current.IBPOrderer {
+	crs := map[string]*current.IBPOrderer{}
+	for i := range list.Items {
+		crs[list.Items[i].Name] = &list.Items[i]
+	}
+	return crs
+}
+
 func (o *Orderer) GetCRResponse(section, compName, namespace, sID string) (*api.Response, int, error) {
 	o.Logger.Debugf("Received get request for '%s'", compName)
 
@@ -83,6 +140,8 @@ func (o *Orderer) GetCRResponse(section, compName, namespace, sID string) (*api.
 		return nil, common.StatusCode500, errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
 	}
 
+	response.ResourceVersion = originalCR.ResourceVersion
+
 	switch section {
 	case RESOURCES:
 		o.getResources(originalCR, response)
@@ -127,7 +186,7 @@ func (o *Orderer) GetCRResponse(section, compName, namespace, sID string) (*api.
 		o.getOther(originalCR, response)
 		o.getChannelLess(originalCR, response)
 	default:
-		return nil, http.StatusBadRequest, errors.Errorf("section %s not supported: %d", section, http.StatusBadRequest)
+		return nil, http.StatusBadRequest, apierror.UnsupportedSection(section)
 	}
 
 	return response, *statusCode, nil
@@ -135,24 +194,27 @@ func (o *Orderer) GetCRResponse(section, compName, namespace, sID string) (*api.
 }
 
 func (o *Orderer) getResources(originalCR *current.IBPOrderer, response *api.Response) {
-	var totalResources *util.ResourceReturn
-	var individualResources *current.OrdererResources
-	ordererResources := []*corev1.ResourceRequirements{}
-	if originalCR.Spec.Resources != nil {
-		if originalCR.Spec.Resources.Orderer != nil {
-			ordererResources = append(ordererResources, originalCR.Spec.Resources.Orderer)
-		}
-		if originalCR.Spec.Resources.GRPCProxy != nil {
-			ordererResources = append(ordererResources, originalCR.Spec.Resources.GRPCProxy)
-		}
-		if originalCR.Spec.Resources.Init != nil {
-			ordererResources = append(ordererResources, originalCR.Spec.Resources.Init)
-		}
+	response.Resources = util.GetResourcesByRole(roleResources(originalCR))
+	response.IndividualResources = o.GetIndividualResources(originalCR.Spec.Resources)
+}
+
+// roleResources returns the resources of the containers the orderer node
+// runs, by role
+func roleResources(cr *current.IBPOrderer) map[string]*corev1.ResourceRequirements {
+	roles := map[string]*corev1.ResourceRequirements{}
+	resources := cr.Spec.Resources
+	if resources == nil {
+		return roles
+	}
+
+	roles[util.RoleOrderer] = resources.Orderer
+	roles[util.RoleProxy] = resources.GRPCProxy
+	roles[util.RoleInit] = resources.Init
+	if cr.Spec.HSM != nil {
+		roles[util.RoleEnroller] = resources.Enroller
+		roles[util.RoleHSMDaemon] = resources.HSMDaemon
 	}
-	totalResources = util.GetTotalDeploymentResources(ordererResources)
-	individualResources = o.GetIndividualResources(originalCR.Spec.Resources)
-	response.Resources = totalResources
-	response.IndividualResources = individualResources
+	return roles
 }
 
 func (o *Orderer) getStorage(originalCR *current.IBPOrderer, response *api.Response) {
@@ -164,7 +226,7 @@ func (o *Orderer) getStatus(originalCR *current.IBPOrderer, response *api.Respon
 	parentCR := &current.IBPOrderer{}
 	parentName := originalCR.ObjectMeta.Labels["parent"]
 	if parentName != "" {
-		err := o.IBPOperatorClient.GetCR(o.Config.Namespace, "IBPOrderers", parentName, parentCR)
+		err := o.IBPOperatorClient.GetCR(originalCR.Namespace, "IBPOrderers", parentName, parentCR)
 		if err != nil {
 			return errors.Wrapf(err, "failed to get cr for '%s'", parentName)
 		}
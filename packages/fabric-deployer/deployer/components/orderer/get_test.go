@@ -32,6 +32,7 @@ import (
 
 	"github.com/IBM-Blockchain/fabric-deployer/config"
 	cfg "github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer/mocks"
 	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
@@ -192,5 +193,52 @@ var _ = Describe("GET API", func() {
 			Expect(code).To(Equal(200))
 			Expect(client.GetCRCallCount()).To(Equal(2))
 		})
+
+		It("returns only the orderers of the service instance", func() {
+			client.GetAllCRStub = func(namespace string, kind string, crList runtime.Object) error {
+				list := crList.(*current.IBPOrdererList)
+				list.Items = []current.IBPOrderer{
+					{ObjectMeta: metav1.ObjectMeta{Name: "os1", Labels: map[string]string{common.ServiceInstanceLabel: "testSID"}}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "os1node1", Labels: map[string]string{"parent": "os1"}}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "os2", Labels: map[string]string{common.ServiceInstanceLabel: "otherSID"}}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "os2node1", Labels: map[string]string{"parent": "os2"}}},
+				}
+				return nil
+			}
+
+			resp, _, err := ordererComp.GetAllCR("testSID", "namespace")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp).To(HaveLen(2))
+			Expect(resp[0].Name).To(Equal("os1"))
+			Expect(resp[1].Name).To(Equal("os1node1"))
+		})
+	})
+
+	Context("resource usage", func() {
+		It("returns the resources of the orderer nodes but not of their parents", func() {
+			client.GetAllCRStub = func(namespace string, kind string, crList runtime.Object) error {
+				list := crList.(*current.IBPOrdererList)
+				parent := current.IBPOrderer{ObjectMeta: metav1.ObjectMeta{Name: "orderer"}}
+				node := current.IBPOrderer{ObjectMeta: metav1.ObjectMeta{Name: "orderernode1", Labels: map[string]string{"parent": "orderer"}}}
+				node.Spec.Resources = &current.OrdererResources{
+					Orderer: &corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1.5"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+					},
+					GRPCProxy: &corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("200M")},
+					},
+				}
+				list.Items = append(list.Items, parent, node)
+				return nil
+			}
+
+			usage, err := ordererComp.ResourceUsage("testSID", "namespace")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(usage).To(HaveLen(1))
+			Expect(usage[0].Name).To(Equal("orderernode1"))
+			Expect(usage[0].Resources.Requests.CPU).To(Equal("1600m"))
+			Expect(usage[0].Resources.Requests.Memory).To(Equal("1274M"))
+			Expect(usage[0].Resources.Roles).To(HaveKey("proxy"))
+		})
 	})
 })
@@ -2,6 +2,7 @@
 package mocks
 
 import (
+	"context"
 	"sync"
 
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer"
@@ -170,6 +171,21 @@ type Kube struct {
 		result1 *v1.Secret
 		result2 error
 	}
+	WaitForConfigMapStub        func(context.Context, string, string) (*v1.ConfigMap, error)
+	waitForConfigMapMutex       sync.RWMutex
+	waitForConfigMapArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	waitForConfigMapReturns struct {
+		result1 *v1.ConfigMap
+		result2 error
+	}
+	waitForConfigMapReturnsOnCall map[int]struct {
+		result1 *v1.ConfigMap
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -942,6 +958,72 @@ func (fake *Kube) UpdateSecretReturnsOnCall(i int, result1 *v1.Secret, result2 e
 	}{result1, result2}
 }
 
+func (fake *Kube) WaitForConfigMap(arg1 context.Context, arg2 string, arg3 string) (*v1.ConfigMap, error) {
+	fake.waitForConfigMapMutex.Lock()
+	ret, specificReturn := fake.waitForConfigMapReturnsOnCall[len(fake.waitForConfigMapArgsForCall)]
+	fake.waitForConfigMapArgsForCall = append(fake.waitForConfigMapArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.WaitForConfigMapStub
+	fakeReturns := fake.waitForConfigMapReturns
+	fake.recordInvocation("WaitForConfigMap", []interface{}{arg1, arg2, arg3})
+	fake.waitForConfigMapMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Kube) WaitForConfigMapCallCount() int {
+	fake.waitForConfigMapMutex.RLock()
+	defer fake.waitForConfigMapMutex.RUnlock()
+	return len(fake.waitForConfigMapArgsForCall)
+}
+
+func (fake *Kube) WaitForConfigMapCalls(stub func(context.Context, string, string) (*v1.ConfigMap, error)) {
+	fake.waitForConfigMapMutex.Lock()
+	defer fake.waitForConfigMapMutex.Unlock()
+	fake.WaitForConfigMapStub = stub
+}
+
+func (fake *Kube) WaitForConfigMapArgsForCall(i int) (context.Context, string, string) {
+	fake.waitForConfigMapMutex.RLock()
+	defer fake.waitForConfigMapMutex.RUnlock()
+	argsForCall := fake.waitForConfigMapArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Kube) WaitForConfigMapReturns(result1 *v1.ConfigMap, result2 error) {
+	fake.waitForConfigMapMutex.Lock()
+	defer fake.waitForConfigMapMutex.Unlock()
+	fake.WaitForConfigMapStub = nil
+	fake.waitForConfigMapReturns = struct {
+		result1 *v1.ConfigMap
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) WaitForConfigMapReturnsOnCall(i int, result1 *v1.ConfigMap, result2 error) {
+	fake.waitForConfigMapMutex.Lock()
+	defer fake.waitForConfigMapMutex.Unlock()
+	fake.WaitForConfigMapStub = nil
+	if fake.waitForConfigMapReturnsOnCall == nil {
+		fake.waitForConfigMapReturnsOnCall = make(map[int]struct {
+			result1 *v1.ConfigMap
+			result2 error
+		})
+	}
+	fake.waitForConfigMapReturnsOnCall[i] = struct {
+		result1 *v1.ConfigMap
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Kube) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -969,6 +1051,8 @@ func (fake *Kube) Invocations() map[string][][]interface{} {
 	defer fake.getServiceMutex.RUnlock()
 	fake.updateSecretMutex.RLock()
 	defer fake.updateSecretMutex.RUnlock()
+	fake.waitForConfigMapMutex.RLock()
+	defer fake.waitForConfigMapMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -19,14 +19,17 @@
 package orderer
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
 
 	dconfig "github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer/api"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
@@ -37,8 +40,8 @@ import (
 	"go.uber.org/zap"
 
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 const (
@@ -82,6 +85,7 @@ type Kube interface {
 	DeleteDeployment(namespace string, depName string) error
 	GetPodsByLabel(namespace, name string) (*corev1.Pod, error)
 	ClusterType(namespace string) string
+	WaitForConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error)
 }
 
 //go:generate counterfeiter -o mocks/ibp_client.go -fake-name IBPOperatorClient . IBPOperatorClient
@@ -93,6 +97,7 @@ type IBPOperatorClient interface {
 	DeleteCR(namespace string, kind string, name string) error
 	UpdateCR(namespace string, kind string, name string, bytes []byte) error
 	PatchCR(namespace string, kind string, name string, bytes []byte) error
+	WaitForCRStatus(ctx context.Context, namespace string, kind string, name string, cond func(status *current.CRStatus) (bool, error)) (*current.CRStatus, error)
 }
 
 type Orderer struct {
@@ -140,16 +145,77 @@ func (o *Orderer) Images(version string) *current.OrdererImages {
 	return images
 }
 
-func (o *Orderer) CreateCluster(domain, sID, compName string, body []byte) ([]api.Response, int, error) {
+func (o *Orderer) CreateCluster(domain, sID, compName, namespace string, body []byte) ([]api.Response, int, error) {
+	return o.createCluster(domain, sID, compName, namespace, body, nil)
+}
+
+func (o *Orderer) createCluster(domain, sID, compName, namespace string, body []byte, progress common.NodeProgressFunc) ([]api.Response, int, error) {
 	o.Logger.Debugf("Received request to create cluster with domain '%s', id '%s'", domain, sID)
-	var err error
 	statusCode := 0
+	spec, version, err := o.renderCluster(body)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	resp, statusCodeLocal, err := o.Create(domain, compName, namespace, version, sID, spec, progress)
+	if err != nil {
+		o.Logger.Error(errors.Wrapf(err, "Failed to complete request to create in domain '%s'", domain))
+		return nil, statusCodeLocal, err
+	}
+
+	if statusCode == 0 && statusCodeLocal != 0 {
+		statusCode = statusCodeLocal
+	}
+
+	return resp, statusCode, nil
+}
+
+// DryRunCreateCR returns the orderer CR that would be created for the
+// request, nothing is created
+func (o *Orderer) DryRunCreateCR(compName, namespace string, body []byte) (*common.DryRunResponse, int, error) {
+	o.Logger.Debugf("Received dry run request to create orderer cr '%s' in namespace '%s'", compName, namespace)
+
+	if compName == "" {
+		o.Logger.Error("Component name not valid, cannot be empty")
+		return nil, 0, apierror.InvalidField("componentName", "component name not valid, cannot be empty")
+	}
+
+	spec, _, err := o.renderCluster(body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	existing := &current.IBPOrderer{}
+	err = o.IBPOperatorClient.GetCR(namespace, "ibporderers", compName, existing)
+	if err == nil {
+		return nil, http.StatusConflict, apierror.New(apierror.Conflict, apierror.CodeAlreadyExists, "cr '%s' already exists", compName)
+	}
+	if !k8serrors.IsNotFound(err) {
+		return nil, 0, errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+	}
+
+	cr := &current.IBPOrderer{
+		Spec: *spec,
+	}
+	cr.Name = compName
+	redactSecrets(cr)
+
+	response, err := common.NewDryRunResponse(nil, cr)
+	if err != nil {
+		return nil, 0, err
+	}
+	return response, http.StatusOK, nil
+}
+
+// renderCluster builds the orderer spec and fabric version for the request
+func (o *Orderer) renderCluster(body []byte) (*current.IBPOrdererSpec, string, error) {
+	var err error
 	request := &api.CreateRequest{}
 	if len(body) != 0 {
 		err = json.Unmarshal(body, request)
 		if err != nil {
 			o.Logger.Error(errors.Wrapf(err, "failed to unmarshal configuration, configuration is not a valid yaml file"))
-			return nil, statusCode, errors.Wrapf(err, "failed to unmarshal configuration, configuration is not a valid yaml file")
+			return nil, "", apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "failed to unmarshal configuration, configuration is not a valid yaml file")
 		}
 	}
 
@@ -165,28 +231,34 @@ func (o *Orderer) CreateCluster(domain, sID, compName string, body []byte) ([]ap
 	} else if !util.IsValidVersion("orderer", request.Version, o.Config.Versions) {
 		// version is not valid
 		o.Logger.Error(errors.Errorf("Version not valid"))
-		return nil, statusCode, errors.Errorf("version not valid")
+		return nil, "", apierror.InvalidField("version", "version not valid")
 	}
 
 	zones := request.Zone
 	regions := request.Region
 
 	if zones != nil && len(zones) != number {
-		o.Logger.Error(errors.Wrap(err, "zones length must be equal to cluster size"))
-		return nil, statusCode, errors.Wrap(err, "zones length must be equal to cluster size")
+		o.Logger.Error("zones length must be equal to cluster size")
+		return nil, "", apierror.InvalidField("zone", "zones length must be equal to cluster size")
 	}
 
 	if regions != nil && len(regions) != number {
-		o.Logger.Error(errors.Wrap(err, "regions length must be equal to cluster size"))
-		return nil, statusCode, errors.Wrap(err, "regions length must be equal to cluster size")
+		o.Logger.Error("regions length must be equal to cluster size")
+		return nil, "", apierror.InvalidField("region", "regions length must be equal to cluster size")
 	}
 
 	if regions == nil {
 		regions = make([]string, number)
 	}
 
-	storage := o.GetStorage(o.Config.Defaults, request.Storage)
-	resources := o.GetResources(o.Config.Defaults, request.Resources)
+	defaults, err := o.Config.Defaults.ForProfile(request.Profile)
+	if err != nil {
+		o.Logger.Error(err)
+		return nil, "", apierror.InvalidField("profile", "%s", err.Error())
+	}
+
+	storage := o.GetStorage(defaults, request.Storage)
+	resources := o.GetResources(defaults, request.Resources)
 	systemChannelName := request.SystemChannelName
 	if systemChannelName == "" {
 		systemChannelName = "testchainid"
@@ -236,32 +308,36 @@ func (o *Orderer) CreateCluster(domain, sID, compName string, body []byte) ([]ap
 		spec.GenesisBlock = request.Genesis.Block
 	}
 
-	// TODO: why do we pass namespace from config and not the namespace passed from API call?
-	resp, statusCodeLocal, err := o.Create(domain, compName, o.Config.Namespace, version, sID, spec)
-	if err != nil {
-		o.Logger.Error(errors.Wrapf(err, "Failed to complete request to create in domain '%s'", domain))
-		return nil, statusCodeLocal, err
-	}
+	return spec, version, nil
+}
 
-	if statusCode == 0 && statusCodeLocal != 0 {
-		statusCode = statusCodeLocal
+// redactSecrets removes the keys and enrollment secrets from the crypto of the
+// cr before it is returned
+func redactSecrets(cr *current.IBPOrderer) {
+	common.RemoveSensitiveDataFromCrypto(cr.Spec.Secret)
+	for _, secret := range cr.Spec.ClusterSecret {
+		common.RemoveSensitiveDataFromCrypto(secret)
 	}
-
-	return resp, statusCode, nil
 }
 
 func (o *Orderer) CreateCR(domain, sID, compName, namespace string, body []byte) ([]api.Response, int, error) {
+	return o.CreateCRWithProgress(domain, sID, compName, namespace, body, nil)
+}
+
+// CreateCRWithProgress creates the orderer cluster and reports the state of
+// each orderer node to progress as it is deployed
+func (o *Orderer) CreateCRWithProgress(domain, sID, compName, namespace string, body []byte, progress common.NodeProgressFunc) ([]api.Response, int, error) {
 	var err error
 	statusCode := 0
 
 	if compName == "" {
 		o.Logger.Error("Component name not valid, cannot be empty")
-		return nil, statusCode, errors.New("component name not valid, cannot be empty")
+		return nil, statusCode, apierror.InvalidField("componentName", "component name not valid, cannot be empty")
 	}
 
 	o.Logger.Debugf("Received request to create orderer cr for '%s' in namespace '%s', domain '%s', id '%s'", compName, namespace, domain, sID)
 
-	resp, statusCode, err := o.CreateCluster(domain, sID, compName, body) // TODO: should we pass namespace passed into this function
+	resp, statusCode, err := o.createCluster(domain, sID, compName, namespace, body, progress)
 	if err != nil {
 		o.Logger.Error(errors.Wrapf(err, "Failed to create orderer for '%s' in namespace '%s'", compName, namespace))
 		return nil, statusCode, errors.Wrapf(err, "failed to create orderer")
@@ -269,7 +345,7 @@ func (o *Orderer) CreateCR(domain, sID, compName, namespace string, body []byte)
 	return resp, statusCode, nil
 }
 
-func (o *Orderer) Create(domain, compName, namespace, version, sID string, spec *current.IBPOrdererSpec) ([]api.Response, int, error) {
+func (o *Orderer) Create(domain, compName, namespace, version, sID string, spec *current.IBPOrdererSpec, progress common.NodeProgressFunc) ([]api.Response, int, error) {
 	o.Logger.Debugf("Received request to create cr in domain '%s', name '%s', namespace '%s'", domain, compName, namespace)
 
 	statusCode := 0
@@ -277,6 +353,7 @@ func (o *Orderer) Create(domain, compName, namespace, version, sID string, spec
 		Spec: *spec,
 	}
 	cr.Name = compName
+	common.SetServiceInstance(cr, sID)
 
 	err := o.IBPOperatorClient.CreateCR(namespace, "ibporderers", cr)
 	if err != nil {
@@ -284,52 +361,29 @@ func (o *Orderer) Create(domain, compName, namespace, version, sID string, spec
 		return nil, statusCode, err
 	}
 
+	if progress == nil {
+		progress = func(string, int, string, error) {}
+	}
+
 	allresponses := []api.Response{}
 	for i := 1; i <= spec.ClusterSize; i++ {
 		nodeName := fmt.Sprintf("%s%s%d", compName, common.NODE, i)
+		progress(nodeName, spec.ClusterSize, common.NodeStateDeploying, nil)
 
-		originalCR := &current.IBPOrderer{}
-
-		o.Logger.Debugf("Cluster type is %s, polling for cr spec status '%s'", o.Config.ClusterType, nodeName)
-		failedCounter := 0
-		err = wait.Poll(500*time.Millisecond, time.Duration(o.Config.Timeouts.Deployment)*time.Millisecond, func() (bool, error) {
-			err = o.IBPOperatorClient.GetCR(namespace, "ibporderers", nodeName, originalCR)
-			if err == nil {
-				// check the status field
-				if originalCR.Status.Status == current.True {
-					// go out if error
-					if originalCR.Status.Type == current.Error {
-						// if cr status is error, no need to get configs
-						return true, errors.New("CR status is set to error")
-					}
-					cmName := nodeName + "-connection-profile"
-					cm, err := o.Kube.GetConfigMap(namespace, cmName)
-					if err == nil && cm != nil {
-						return true, nil
-					}
-				}
-				return false, nil
-			} else {
-				// for first node wait for sometime for genesis block logic to happen
-				failedCounter++
-				if i == 1 && failedCounter >= o.Config.Timeouts.OrdererFailureCount {
-					return true, err
-				} else if i != 1 {
-					return true, err
-				}
-				return false, nil
-			}
-		})
-
+		o.Logger.Debugf("Cluster type is %s, waiting for cr spec status '%s'", o.Config.ClusterType, nodeName)
+		crStatus, err := o.waitForNode(namespace, nodeName, i == 1)
 		if err != nil {
 			o.Logger.Warnf("CR Status not updated before timeout or got an error: %s", err)
+			progress(nodeName, spec.ClusterSize, common.NodeStateFailed, err)
 			// return error immediately, something went wrong
 			statusCode = 500
-			if originalCR.Status.Status == current.True && originalCR.Status.Type == current.Error {
+			if crStatus != nil && crStatus.Status == current.True && crStatus.Type == current.Error {
 				// dont error out
 			} else {
 				return nil, statusCode, err
 			}
+		} else {
+			progress(nodeName, spec.ClusterSize, common.NodeStateDeployed, nil)
 		}
 
 		// build the response
@@ -354,8 +408,8 @@ func (o *Orderer) Create(domain, compName, namespace, version, sID string, spec
 
 // precreate creates a CR spec with directly the orderer node spec
 // we need to give it name and number and leave the genesis block blank
-func (o *Orderer) PrecreateCR(domain, sID string, body []byte, compName string) (*api.Response, int, error) {
-	o.Logger.Debugf("Received request to precreate cr for '%s' in domain '%s', id '%s'", compName, domain, sID)
+func (o *Orderer) PrecreateCR(domain, sID string, body []byte, compName, namespace string) (*api.Response, int, error) {
+	o.Logger.Debugf("Received request to precreate cr for '%s' in domain '%s', id '%s', namespace '%s'", compName, domain, sID, namespace)
 
 	var err error
 	statusCode := 0
@@ -364,7 +418,7 @@ func (o *Orderer) PrecreateCR(domain, sID string, body []byte, compName string)
 		err = json.Unmarshal(body, request)
 		if err != nil {
 			o.Logger.Error(errors.Wrapf(err, "Failed to unmarshal configuration, configuration is not a valid yaml file"))
-			return nil, statusCode, errors.Wrapf(err, "failed to unmarshal configuration, configuration is not a valid yaml file")
+			return nil, statusCode, apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "failed to unmarshal configuration, configuration is not a valid yaml file")
 		}
 	}
 
@@ -374,8 +428,14 @@ func (o *Orderer) PrecreateCR(domain, sID string, body []byte, compName string)
 		version = util.GetDefaultVersion("orderer", o.Config.Versions)
 	}
 
-	storage := o.GetStorage(o.Config.Defaults, request.Storage)
-	resources := o.GetResources(o.Config.Defaults, request.Resources)
+	defaults, err := o.Config.Defaults.ForProfile(request.Profile)
+	if err != nil {
+		o.Logger.Error(err)
+		return nil, statusCode, apierror.InvalidField("profile", "%s", err.Error())
+	}
+
+	storage := o.GetStorage(defaults, request.Storage)
+	resources := o.GetResources(defaults, request.Resources)
 	systemChannelName := request.SystemChannelName
 	if systemChannelName == "" {
 		systemChannelName = "testchainid"
@@ -416,42 +476,20 @@ func (o *Orderer) PrecreateCR(domain, sID string, body []byte, compName string)
 		Spec: *spec,
 	}
 	cr.Name = compName
+	common.SetServiceInstance(cr, sID)
 
-	err = o.IBPOperatorClient.CreateCR(o.Config.Namespace, "ibporderers", cr)
+	err = o.IBPOperatorClient.CreateCR(namespace, "ibporderers", cr)
 	if err != nil {
-		o.Logger.Error(errors.Wrapf(err, "Failed to create cr for '%s' in namespace '%s'", cr.Name, o.Config.Namespace))
+		o.Logger.Error(errors.Wrapf(err, "Failed to create cr for '%s' in namespace '%s'", cr.Name, namespace))
 		return nil, statusCode, err
 	}
 
-	originalCR := &current.IBPOrderer{}
-	o.Logger.Debugf("Cluster type is %s, polling for cr spec status '%s'", o.Config.ClusterType, compName)
-	err = wait.Poll(500*time.Millisecond, time.Duration(o.Config.Timeouts.Deployment)*time.Millisecond, func() (bool, error) {
-		err = o.IBPOperatorClient.GetCR(o.Config.Namespace, "ibporderers", compName, originalCR)
-		if err == nil {
-			// check the status field
-			if originalCR.Status.Status == current.True {
-				// go out if error
-				if originalCR.Status.Type == current.Error {
-					// if cr status is error, no need to get configs
-					return true, errors.New("CR status is set to error")
-				}
-				cmName := compName + "-connection-profile"
-				cm, err := o.Kube.GetConfigMap(o.Config.Namespace, cmName)
-				if err == nil && cm != nil {
-					return true, nil
-				}
-			}
-			return false, nil
-		} else {
-			// immediately fail on error
-			return true, err
-		}
-	})
-
+	o.Logger.Debugf("Cluster type is %s, waiting for cr spec status '%s'", o.Config.ClusterType, compName)
+	crStatus, err := o.waitForNode(namespace, compName, false)
 	if err != nil {
 		o.Logger.Warnf("cr status not set after timeout or got an error: %s", err)
 		statusCode = 500
-		if originalCR.Status.Status == current.True && originalCR.Status.Type == current.Error {
+		if crStatus != nil && crStatus.Status == current.True && crStatus.Type == current.Error {
 			// dont error out
 		} else {
 			return nil, statusCode, err
@@ -459,7 +497,7 @@ func (o *Orderer) PrecreateCR(domain, sID string, body []byte, compName string)
 	}
 
 	// build the response
-	response, statusCodeNew, err := o.GetCRResponse(ALL, compName, o.Config.Namespace, sID)
+	response, statusCodeNew, err := o.GetCRResponse(ALL, compName, namespace, sID)
 	if err != nil {
 		o.Logger.Error(errors.Wrapf(err, "Failed to build response object '%s'", compName))
 		return nil, statusCode, err
@@ -475,6 +513,21 @@ func (o *Orderer) PrecreateCR(domain, sID string, body []byte, compName string)
 	return response, statusCode, nil
 }
 
+// waitForNode waits for the operator to deploy the orderer node and create
+// its connection profile, the CR status is returned if it has been set. The
+// CR of the first node may be missing a few times while the genesis block is
+// created.
+func (o *Orderer) waitForNode(namespace, nodeName string, first bool) (*current.CRStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Config.Timeouts.Deployment)*time.Millisecond)
+	defer cancel()
+
+	misses := 0
+	if first {
+		misses = o.Config.Timeouts.OrdererFailureCount
+	}
+	return common.WaitForDeployment(ctx, o.IBPOperatorClient, o.Kube, namespace, "ibporderers", nodeName, misses)
+}
+
 func (o *Orderer) DeleteCR(sID, compName, namespace string, body []byte) (*api.DeleteResponse, int, error) {
 	var err error
 	statusCode := 0
@@ -485,7 +538,7 @@ func (o *Orderer) DeleteCR(sID, compName, namespace string, body []byte) (*api.D
 		err = json.Unmarshal(body, request)
 		if err != nil {
 			o.Logger.Error(errors.Wrapf(err, "failed to unmarshal configuration, configuration is not a valid yaml file"))
-			return nil, statusCode, errors.Wrapf(err, "failed to unmarshal configuration, configuration is not a valid yaml file")
+			return nil, statusCode, apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "failed to unmarshal configuration, configuration is not a valid yaml file")
 		}
 	}
 
@@ -509,7 +562,7 @@ func (o *Orderer) GetConnectionProfile(compName, namespace string) (*common.Conn
 	}
 	binaryData := cm.BinaryData
 	if binaryData["profile.json"] == nil {
-		return nil, errors.New("profile.json not found in configmap")
+		return nil, apierror.New(apierror.NotFound, apierror.CodeNotFound, "profile.json not found in configmap")
 	}
 	data := binaryData["profile.json"]
 	connectionProfile := &common.ConnectionProfile{}
@@ -530,7 +583,7 @@ func (o *Orderer) GetConfig(compName, namespace string, fabricVersion string) (i
 	}
 	binaryData := cm.BinaryData
 	if binaryData["orderer.yaml"] == nil {
-		return nil, errors.New("orderer.yaml not found in configmap")
+		return nil, apierror.New(apierror.NotFound, apierror.CodeNotFound, "orderer.yaml not found in configmap")
 	}
 	data := binaryData["orderer.yaml"]
 
@@ -554,7 +607,9 @@ func (o *Orderer) GetConfig(compName, namespace string, fabricVersion string) (i
 func (o *Orderer) GetStorage(defaults *dconfig.DeployerDefaults, override *current.OrdererStorages) *current.OrdererStorages {
 	o.Logger.Debug("Received request to get storage")
 
-	storage := defaults.Storage.Orderer
+	// the defaults are shared by all the requests, they are copied so the
+	// overrides do not change them
+	storage := defaults.Storage.Orderer.DeepCopy()
 
 	if override != nil {
 		if override.Orderer != nil && override.Orderer.Size != "" {
@@ -570,7 +625,7 @@ func (o *Orderer) GetStorage(defaults *dconfig.DeployerDefaults, override *curre
 
 func (o *Orderer) GetResources(defaults *dconfig.DeployerDefaults, override *current.OrdererResources) *current.OrdererResources {
 	o.Logger.Debug("Received request to get resources")
-	resources := defaults.Resources.Orderer
+	resources := defaults.Resources.Orderer.DeepCopy()
 
 	if override != nil {
 		if override.Orderer != nil {
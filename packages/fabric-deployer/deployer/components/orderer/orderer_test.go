@@ -19,10 +19,12 @@
 package orderer_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 
 	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
 	orderer "github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer/api"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer/mocks"
@@ -33,9 +35,11 @@ import (
 	. "github.com/onsi/gomega"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 var _ = Describe("Orderer", func() {
@@ -142,6 +146,9 @@ var _ = Describe("Orderer", func() {
 			return nil
 		}
 
+		mockIBPClient.WaitForCRStatusReturns(&current.CRStatus{Status: current.True, Type: current.Deployed}, nil)
+		mockKube.WaitForConfigMapReturns(&corev1.ConfigMap{}, nil)
+
 		mockKube.GetConfigMapStub = func(namespace, name string) (*corev1.ConfigMap, error) {
 			type profile struct {
 				Endpoints interface{}
@@ -202,13 +209,13 @@ var _ = Describe("Orderer", func() {
 		It("returns an error if it fails to create orderer", func() {
 			mockIBPClient.CreateCRReturns(errors.New("failed to create orderer"))
 
-			_, _, err := testOrderer.CreateCluster("0.0.0.0", "sID1", "orderer", body)
+			_, _, err := testOrderer.CreateCluster("0.0.0.0", "sID1", "orderer", "namespace", body)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(Equal("failed to create orderer"))
 		})
 
 		It("creates Orderer cluster", func() {
-			_, _, err := testOrderer.CreateCluster("0.0.0.0", "sID1", "orderer", body)
+			_, _, err := testOrderer.CreateCluster("0.0.0.0", "sID1", "orderer", "namespace", body)
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
@@ -277,20 +284,40 @@ var _ = Describe("Orderer", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("creates the custom resource in the namespace of the service instance, labelled with its ID", func() {
+			_, _, err := testOrderer.CreateCR("0.0.0.0", "sID1", "orderer1", "sid1-ns", body)
+			Expect(err).NotTo(HaveOccurred())
+
+			namespace, _, cr := mockIBPClient.CreateCRArgsForCall(0)
+			Expect(namespace).To(Equal("sid1-ns"))
+			Expect(cr.(*current.IBPOrderer).Labels).To(HaveKeyWithValue(common.ServiceInstanceLabel, "sID1"))
+		})
+
+		It("waits for the CR status and the connection profile", func() {
+			_, _, err := testOrderer.CreateCR("0.0.0.0", "sID1", "orderer1", "default", body)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(mockIBPClient.WaitForCRStatusCallCount()).To(Equal(1))
+			_, namespace, kind, name, _ := mockIBPClient.WaitForCRStatusArgsForCall(0)
+			Expect(namespace).To(Equal("default"))
+			Expect(kind).To(Equal("ibporderers"))
+			Expect(name).To(Equal("orderer1node1"))
+
+			Expect(mockKube.WaitForConfigMapCallCount()).To(Equal(1))
+			_, namespace, name = mockKube.WaitForConfigMapArgsForCall(0)
+			Expect(namespace).To(Equal("default"))
+			Expect(name).To(Equal("orderer1node1-connection-profile"))
+		})
+
+		It("returns error if the connection profile is not created before timeout", func() {
+			mockKube.WaitForConfigMapReturns(nil, context.DeadlineExceeded)
+			_, statusCode, err := testOrderer.CreateCR("0.0.0.0", "sID1", "orderer1", "default", body)
+			Expect(err).To(HaveOccurred())
+			Expect(statusCode).Should(Equal(500))
+		})
+
 		It("returns error if get CR timesout", func() {
-			mockIBPClient.GetCRStub = func(namespace string, kind string, name string, ordererCR runtime.Object) error {
-				c := ordererCR.(*current.IBPOrderer)
-				c.Spec = current.IBPOrdererSpec{
-					ClusterSize: 1,
-					Resources:   resources,
-				}
-				c.Status = current.IBPOrdererStatus{
-					CRStatus: current.CRStatus{
-						Status: current.False,
-					},
-				}
-				return nil
-			}
+			mockIBPClient.WaitForCRStatusReturns(&current.CRStatus{Status: current.False}, context.DeadlineExceeded)
 			testOrderer.Config.Timeouts = &config.Timeouts{
 				Deployment: 1 * 100,
 			}
@@ -306,6 +333,11 @@ var _ = Describe("Orderer", func() {
 		})
 
 		It("returns 500 if CR status is error", func() {
+			mockIBPClient.WaitForCRStatusStub = func(_ context.Context, _, _, _ string, cond func(*current.CRStatus) (bool, error)) (*current.CRStatus, error) {
+				status := &current.CRStatus{Status: current.True, Type: current.Error}
+				_, err := cond(status)
+				return status, err
+			}
 			mockIBPClient.GetCRStub = func(namespace string, kind string, name string, ordererCR runtime.Object) error {
 				c := ordererCR.(*current.IBPOrderer)
 				c.Spec = current.IBPOrdererSpec{
@@ -363,6 +395,55 @@ var _ = Describe("Orderer", func() {
 		})
 	})
 
+	Context("Dry run create", func() {
+		var body []byte
+
+		BeforeEach(func() {
+			b := api.CreateRequest{
+				Resources: &current.OrdererResources{
+					Orderer:   &corev1.ResourceRequirements{},
+					GRPCProxy: &corev1.ResourceRequirements{},
+				},
+				Config: []*current.SecretSpec{
+					&current.SecretSpec{
+						Enrollment: &current.EnrollmentSpec{
+							Component: &current.Enrollment{
+								EnrollID:     "admin",
+								EnrollSecret: "adminpw",
+							},
+						},
+					},
+				},
+			}
+
+			body, err = json.Marshal(b)
+			Expect(err).NotTo(HaveOccurred())
+
+			mockIBPClient.GetCRReturns(k8serrors.NewNotFound(schema.GroupResource{}, "orderer1"))
+		})
+
+		It("returns a conflict if the cr already exists", func() {
+			mockIBPClient.GetCRReturns(nil)
+			_, statusCode, err := testOrderer.DryRunCreateCR("orderer1", "default", body)
+			Expect(err).To(HaveOccurred())
+			Expect(statusCode).To(Equal(409))
+		})
+
+		It("returns the rendered cr without creating it", func() {
+			resp, statusCode, err := testOrderer.DryRunCreateCR("orderer1", "default", body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(statusCode).To(Equal(200))
+			Expect(mockIBPClient.CreateCRCallCount()).To(Equal(0))
+
+			Expect(resp.DryRun).To(Equal(true))
+			cr := resp.CR.(*current.IBPOrderer)
+			Expect(cr.Name).To(Equal("orderer1"))
+			Expect(cr.Spec.ClusterSize).To(Equal(1))
+			Expect(cr.Spec.ClusterSecret[0].Enrollment.Component.EnrollSecret).To(Equal("redacted"))
+			Expect(resp.Diff).NotTo(BeEmpty())
+		})
+	})
+
 	Context("Delete Custom Resource", func() {
 		It("returns error if body is not json", func() {
 			_, _, err := testOrderer.DeleteCR("sID", "name", "namespace", []byte{1})
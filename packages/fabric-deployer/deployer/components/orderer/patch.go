@@ -20,9 +20,10 @@ package orderer
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer/api"
 	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
 	"github.com/pkg/errors"
@@ -30,7 +31,13 @@ import (
 )
 
 func (o *Orderer) PatchCR(section, compName, namespace, sID string, body []byte) (*api.Response, int, error) {
-	err := o.patchCR(section, compName, namespace, sID, body)
+	return o.PatchCRIfMatch(section, compName, namespace, sID, "", body)
+}
+
+// PatchCRIfMatch patches the CR if its ETag matches ifMatch, the value of the
+// If-Match header of the request
+func (o *Orderer) PatchCRIfMatch(section, compName, namespace, sID, ifMatch string, body []byte) (*api.Response, int, error) {
+	err := o.patchCR(section, compName, namespace, sID, ifMatch, body)
 	if err != nil {
 		o.Logger.Error(errors.Wrapf(err, "patch err for %s", compName))
 		return nil, 500, err
@@ -45,20 +52,64 @@ func (o *Orderer) PatchCR(section, compName, namespace, sID string, body []byte)
 	return response, 200, nil
 }
 
-func (o *Orderer) patchCR(section, compName, namespace, sID string, body []byte) error {
+// DryRunPatchCR returns the CR that would be patched for the request,
+// nothing is patched
+func (o *Orderer) DryRunPatchCR(section, compName, namespace, ifMatch string, body []byte) (*common.DryRunResponse, int, error) {
+	unchangedCR, updatedCR, err := o.renderPatch(section, compName, namespace, ifMatch, body)
+	if err != nil {
+		o.Logger.Error(errors.Wrapf(err, "dry run patch err for %s", compName))
+		return nil, 0, err
+	}
+	redactSecrets(unchangedCR)
+	redactSecrets(updatedCR)
+
+	response, err := common.NewDryRunResponse(unchangedCR, updatedCR)
+	if err != nil {
+		return nil, 0, err
+	}
+	return response, http.StatusOK, nil
+}
+
+func (o *Orderer) patchCR(section, compName, namespace, sID, ifMatch string, body []byte) error {
+	_, updatedCR, err := o.renderPatch(section, compName, namespace, ifMatch, body)
+	if err != nil {
+		return err
+	}
+
+	crBytes, err := json.Marshal(updatedCR)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal, invalid request")
+	}
+
+	err = o.IBPOperatorClient.PatchCR(namespace, "ibporderers", compName, crBytes)
+	if err != nil {
+		return errors.Wrapf(err, "failed patch cr '%s' in namespace '%s'", compName, namespace)
+	}
+
+	return nil
+}
+
+// renderPatch returns the current CR and the CR patched with the request
+func (o *Orderer) renderPatch(section, compName, namespace, ifMatch string, body []byte) (*current.IBPOrderer, *current.IBPOrderer, error) {
 	o.Logger.Debugf("Received patch request for '%s'", compName)
 
 	originalCR := &current.IBPOrderer{}
 	err := o.IBPOperatorClient.GetCR(namespace, "ibporderers", compName, originalCR)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+		return nil, nil, errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+	}
+	unchangedCR := originalCR.DeepCopy()
+
+	err = common.CheckIfMatch(ifMatch, originalCR.ResourceVersion)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	request := &api.UpdateRequest{}
 	if len(body) != 0 {
 		err = json.Unmarshal(body, request)
 		if err != nil {
-			return errors.Wrapf(err, "failed to unmarshal, invalid request")
+			return nil, nil, apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "failed to unmarshal, invalid request")
 		}
 	}
 
@@ -78,29 +129,23 @@ func (o *Orderer) patchCR(section, compName, namespace, sID string, body []byte)
 	case ACTIONS:
 		err = o.patchActions(originalCR, request.Actions)
 		if err != nil {
-			return errors.Wrap(err, "failed to patch actions")
+			return nil, nil, errors.Wrap(err, "failed to patch actions")
+		}
+	case VERSION:
+		err = o.updateVersion(originalCR, request.Version, request.ForceVersion)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to patch version")
 		}
 	case ALL:
 		err = o.patchAll(originalCR, request)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 	default:
-		return errors.Errorf("section %s not supported: %d", section, http.StatusBadRequest)
-	}
-
-	crBytes, err := json.Marshal(originalCR)
-	if err != nil {
-		return errors.Wrapf(err, "failed to marshal, invalid request")
-	}
-
-	err = o.IBPOperatorClient.PatchCR(namespace, "ibporderers", compName, crBytes)
-	if err != nil {
-		return errors.Wrapf(err, "failed patch cr '%s' in namespace '%s'", compName, namespace)
+		return nil, nil, apierror.UnsupportedSection(section)
 	}
 
-	return nil
-
+	return unchangedCR, originalCR, nil
 }
 
 func (o *Orderer) patchResources(originalCR *current.IBPOrderer, resources *current.OrdererResources) {
@@ -151,38 +196,38 @@ func (o *Orderer) patchActions(originalCR *current.IBPOrderer, actions *current.
 
 	// Check actions we want to patch
 	if actions.Enroll.Ecert && actions.Reenroll.Ecert {
-		return fmt.Errorf("cannot request to enroll and re-enroll the ecert at the same time")
+		return apierror.InvalidField("actions", "cannot request to enroll and re-enroll the ecert at the same time")
 	}
 	if actions.Enroll.TLSCert && actions.Reenroll.TLSCert {
-		return fmt.Errorf("cannot request to enroll and re-enroll the TLS cert at the same time")
+		return apierror.InvalidField("actions", "cannot request to enroll and re-enroll the TLS cert at the same time")
 	}
 	if actions.Reenroll.Ecert && actions.Reenroll.EcertNewKey {
-		return fmt.Errorf("cannot request to re-enroll the ecert and re-enroll the ecert with a new key at the same time")
+		return apierror.InvalidField("actions", "cannot request to re-enroll the ecert and re-enroll the ecert with a new key at the same time")
 	}
 	if actions.Reenroll.TLSCert && actions.Reenroll.TLSCertNewKey {
-		return fmt.Errorf("cannot request to re-enroll the TLS cert and re-enroll the TLS cert with a new key at the same time")
+		return apierror.InvalidField("actions", "cannot request to re-enroll the TLS cert and re-enroll the TLS cert with a new key at the same time")
 	}
 
 	// Check new actions against existing actions
 	originalActions := originalCR.Spec.Action
 	if actions.Enroll.Ecert {
 		if originalActions.Reenroll.Ecert || originalActions.Reenroll.EcertNewKey {
-			return fmt.Errorf("cannot request to enroll ecert when ecert re-enroll action is pending")
+			return apierror.InvalidField("actions", "cannot request to enroll ecert when ecert re-enroll action is pending")
 		}
 	}
 	if actions.Enroll.TLSCert {
 		if originalActions.Reenroll.TLSCert || originalActions.Reenroll.TLSCertNewKey {
-			return fmt.Errorf("cannot request to enroll TLS cert when TLS cert re-enroll action is pending")
+			return apierror.InvalidField("actions", "cannot request to enroll TLS cert when TLS cert re-enroll action is pending")
 		}
 	}
 	if actions.Reenroll.Ecert || actions.Reenroll.EcertNewKey {
 		if originalActions.Enroll.Ecert {
-			return fmt.Errorf("cannot request to re-enroll ecert when ecert enroll action is pending")
+			return apierror.InvalidField("actions", "cannot request to re-enroll ecert when ecert enroll action is pending")
 		}
 	}
 	if actions.Reenroll.TLSCert || actions.Reenroll.TLSCertNewKey {
 		if originalActions.Enroll.TLSCert {
-			return fmt.Errorf("cannot request to re-enroll TLS cert when TLS cert enroll action is pending")
+			return apierror.InvalidField("actions", "cannot request to re-enroll TLS cert when TLS cert enroll action is pending")
 		}
 	}
 
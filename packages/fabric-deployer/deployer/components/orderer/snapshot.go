@@ -0,0 +1,95 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package orderer
+
+import (
+	"encoding/json"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/snapshot"
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+	"github.com/pkg/errors"
+)
+
+// SnapshotVolumes returns the ledger volume of the orderer, the PVC is named
+// by the operator unless the CR sets a custom name
+func (o *Orderer) SnapshotVolumes(compName, namespace string) ([]snapshot.Volume, error) {
+	cr, err := o.getCR(compName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	ordererPVC := cr.Spec.CustomNames.PVC.Orderer
+	if ordererPVC == "" {
+		ordererPVC = compName + "-pvc"
+	}
+
+	return []snapshot.Volume{{Name: "orderer", PVC: ordererPVC}}, nil
+}
+
+// ScaleReplicas sets the replicas of the orderer and returns the previous ones
+func (o *Orderer) ScaleReplicas(compName, namespace string, replicas *int32) (*int32, error) {
+	cr, err := o.getCR(compName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := cr.Spec.Replicas
+	err = o.updateReplicas(cr, replicas)
+	if err != nil {
+		return nil, err
+	}
+
+	return previous, o.writeCR(compName, namespace, cr)
+}
+
+// RebindVolumes sets the PVC of the orderer volume
+func (o *Orderer) RebindVolumes(compName, namespace string, pvcs map[string]string) error {
+	cr, err := o.getCR(compName, namespace)
+	if err != nil {
+		return err
+	}
+
+	if pvc, found := pvcs["orderer"]; found {
+		cr.Spec.CustomNames.PVC.Orderer = pvc
+	}
+
+	return o.writeCR(compName, namespace, cr)
+}
+
+func (o *Orderer) getCR(compName, namespace string) (*current.IBPOrderer, error) {
+	cr := &current.IBPOrderer{}
+	err := o.IBPOperatorClient.GetCR(namespace, "ibporderers", compName, cr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+	}
+	return cr, nil
+}
+
+func (o *Orderer) writeCR(compName, namespace string, cr *current.IBPOrderer) error {
+	crBytes, err := json.Marshal(cr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal, invalid request")
+	}
+
+	err = o.IBPOperatorClient.UpdateCR(namespace, "ibporderers", compName, crBytes)
+	if err != nil {
+		return errors.Wrapf(err, "failed update cr '%s' in namespace '%s'", compName, namespace)
+	}
+	return nil
+}
@@ -23,6 +23,8 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer/api"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
 	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
@@ -31,7 +33,13 @@ import (
 )
 
 func (o *Orderer) UpdateCR(section, compName, namespace, sID string, body []byte) (*api.Response, int, error) {
-	err := o.updateCR(section, compName, namespace, sID, body)
+	return o.UpdateCRIfMatch(section, compName, namespace, sID, "", body)
+}
+
+// UpdateCRIfMatch updates the CR if its ETag matches ifMatch, the value of the
+// If-Match header of the request
+func (o *Orderer) UpdateCRIfMatch(section, compName, namespace, sID, ifMatch string, body []byte) (*api.Response, int, error) {
+	err := o.updateCR(section, compName, namespace, sID, ifMatch, body)
 	if err != nil {
 		o.Logger.Error(errors.Wrapf(err, "update err for %s", compName))
 		return nil, 500, err
@@ -46,20 +54,65 @@ func (o *Orderer) UpdateCR(section, compName, namespace, sID string, body []byte
 	return response, 200, nil
 }
 
-func (o *Orderer) updateCR(section, compName, namespace, sID string, body []byte) error {
+// DryRunUpdateCR returns the CR the update request would submit and its
+// changes from the current CR, nothing is updated
+func (o *Orderer) DryRunUpdateCR(section, compName, namespace, ifMatch string, body []byte) (*common.DryRunResponse, int, error) {
+	unchangedCR, updatedCR, err := o.renderUpdate(section, compName, namespace, ifMatch, body)
+	if err != nil {
+		o.Logger.Error(errors.Wrapf(err, "dry run update err for %s", compName))
+		return nil, 0, err
+	}
+	redactSecrets(unchangedCR)
+	redactSecrets(updatedCR)
+
+	response, err := common.NewDryRunResponse(unchangedCR, updatedCR)
+	if err != nil {
+		return nil, 0, err
+	}
+	return response, http.StatusOK, nil
+}
+
+func (o *Orderer) updateCR(section, compName, namespace, sID, ifMatch string, body []byte) error {
+	_, updatedCR, err := o.renderUpdate(section, compName, namespace, ifMatch, body)
+	if err != nil {
+		return err
+	}
+
+	crBytes, err := json.Marshal(updatedCR)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal, invalid request")
+	}
+
+	err = o.IBPOperatorClient.UpdateCR(namespace, "ibporderers", compName, crBytes)
+	if err != nil {
+		return errors.Wrapf(err, "failed update cr '%s' in namespace '%s'", compName, namespace)
+	}
+
+	return nil
+}
+
+// renderUpdate returns the current CR and the CR with the section of the
+// request updated
+func (o *Orderer) renderUpdate(section, compName, namespace, ifMatch string, body []byte) (*current.IBPOrderer, *current.IBPOrderer, error) {
 	o.Logger.Debugf("Received update request for '%s'", compName)
 
 	originalCR := &current.IBPOrderer{}
 	err := o.IBPOperatorClient.GetCR(namespace, "ibporderers", compName, originalCR)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+		return nil, nil, errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+	}
+	unchangedCR := originalCR.DeepCopy()
+
+	err = common.CheckIfMatch(ifMatch, originalCR.ResourceVersion)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	request := &api.UpdateRequest{}
 	if len(body) != 0 {
 		err = json.Unmarshal(body, request)
 		if err != nil {
-			return errors.Wrapf(err, "failed to unmarshal, invalid request")
+			return nil, nil, apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "failed to unmarshal, invalid request")
 		}
 	}
 
@@ -71,19 +124,19 @@ func (o *Orderer) updateCR(section, compName, namespace, sID string, body []byte
 	case ADMINCERTS:
 		err := o.updateAdminCerts(originalCR, request.AdminCerts)
 		if err != nil {
-			return errors.Wrap(err, "failed to update admin certs")
+			return nil, nil, errors.Wrap(err, "failed to update admin certs")
 		}
 	case NODEOU:
 		o.updateNodeOU(originalCR, request.NodeOU)
 	case VERSION:
-		err := o.updateVersion(originalCR, request.Version)
+		err := o.updateVersion(originalCR, request.Version, request.ForceVersion)
 		if err != nil {
-			return errors.Wrap(err, "failed to update version")
+			return nil, nil, errors.Wrap(err, "failed to update version")
 		}
 	case REPLICAS:
 		err := o.updateReplicas(originalCR, request.Replicas)
 		if err != nil {
-			return errors.Wrap(err, "failed to update replicas")
+			return nil, nil, errors.Wrap(err, "failed to update replicas")
 		}
 	case GENESIS:
 		o.updateGenesisBlock(originalCR, request.Genesis)
@@ -92,23 +145,13 @@ func (o *Orderer) updateCR(section, compName, namespace, sID string, body []byte
 	case ALL:
 		err := o.updateAll(originalCR, request)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 	default:
-		return errors.Errorf("section %s not supported: %d", section, http.StatusBadRequest)
-	}
-
-	crBytes, err := json.Marshal(originalCR)
-	if err != nil {
-		return errors.Wrapf(err, "failed to marshal, invalid request")
-	}
-
-	err = o.IBPOperatorClient.UpdateCR(namespace, "ibporderers", compName, crBytes)
-	if err != nil {
-		return errors.Wrapf(err, "failed update cr '%s' in namespace '%s'", compName, namespace)
+		return nil, nil, apierror.UnsupportedSection(section)
 	}
 
-	return nil
+	return unchangedCR, originalCR, nil
 }
 
 func (o *Orderer) updateConfig(originalCR *current.IBPOrderer, configOverride *runtime.RawExtension) {
@@ -150,14 +193,20 @@ func (o *Orderer) updateAdminCerts(originalCR *current.IBPOrderer, adminCerts []
 	return nil
 }
 
-func (o *Orderer) updateVersion(originalCR *current.IBPOrderer, version string) error {
+func (o *Orderer) updateVersion(originalCR *current.IBPOrderer, version string, force bool) error {
 	if version == "" {
 		return nil
 	}
 
 	if !util.IsValidVersion("orderer", version, o.Config.Versions) {
 		o.Logger.Error("Version not valid")
-		return errors.New("version not valid")
+		return apierror.InvalidField("version", "version not valid")
+	}
+
+	err := util.CheckVersionTransition("orderer", originalCR.Spec.FabricVersion, version, force, o.Config.Versions)
+	if err != nil {
+		o.Logger.Error(err)
+		return apierror.InvalidField("version", "%s", err.Error())
 	}
 
 	image := o.Config.Versions.Orderer[version].Image
@@ -176,7 +225,7 @@ func (o *Orderer) updateReplicas(originalCR *current.IBPOrderer, replicas *int32
 	}
 
 	if *replicas < 0 || *replicas > 1 {
-		return errors.New("replicas not valid, expecting 0 or 1")
+		return apierror.InvalidField("replicas", "replicas not valid, expecting 0 or 1")
 	}
 
 	originalCR.Spec.Replicas = replicas
@@ -228,7 +277,7 @@ func (o *Orderer) updateAll(originalCR *current.IBPOrderer, request *api.UpdateR
 		}
 	}
 
-	err := o.updateVersion(originalCR, request.Version)
+	err := o.updateVersion(originalCR, request.Version, request.ForceVersion)
 	if err != nil {
 		return errors.Wrap(err, "failed to update version")
 	}
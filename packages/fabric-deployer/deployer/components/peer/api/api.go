@@ -38,6 +38,8 @@ type CreateRequest struct {
 	HSM            *current.HSM           `json:"hsm,omitempty"` // DEPRECATED
 	Arch           []string               `json:"arch,omitempty"`
 	Region         string                 `json:"region,omitempty"`
+	// Profile is the sizing profile of the defaults, e.g. production
+	Profile string `json:"profile,omitempty"`
 }
 
 type DeleteRequest struct {
@@ -47,7 +49,9 @@ type DeleteRequest struct {
 }
 
 type UpdateRequest struct {
-	Version        string                 `json:"version,omitempty"`
+	Version string `json:"version,omitempty"`
+	// ForceVersion allows the version to be downgraded
+	ForceVersion   bool                   `json:"forceVersion,omitempty"`
 	Config         *current.SecretSpec    `json:"crypto,omitempty"`
 	AdminCerts     []string               `json:"admincerts,omitempty"`
 	Resources      *current.PeerResources `json:"resources,omitempty"`
@@ -60,6 +64,7 @@ type UpdateRequest struct {
 
 type Component struct {
 	Name                 string                 `json:"name,omitempty"`
+	Cluster              string                 `json:"cluster,omitempty"`
 	Endpoints            interface{}            `json:"endpoints,omitempty"`
 	Resources            *util.ResourceReturn   `json:"resources,omitempty"`
 	IndividualResources  *current.PeerResources `json:"individualResources,omitempty"`
@@ -84,6 +89,14 @@ type Component struct {
 
 type Response struct {
 	Component `json:",inline"`
+
+	// ResourceVersion of the CR, returned in the ETag header
+	ResourceVersion string `json:"-"`
+}
+
+// GetResourceVersion returns the resource version of the CR
+func (r *Response) GetResourceVersion() string {
+	return r.ResourceVersion
 }
 
 type Deleted struct {
@@ -0,0 +1,131 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"encoding/json"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// PlanApply returns the changes that applying the create request would make
+// to the CR of the component, found is false if the component does not exist
+func (peer *Peer) PlanApply(sID, compName, namespace string, body []byte) (bool, []common.Change, error) {
+	unchangedCR, updatedCR, err := peer.renderApply(sID, compName, namespace, body)
+	if err != nil || unchangedCR == nil {
+		return false, nil, err
+	}
+
+	diff, err := common.Diff(unchangedCR, updatedCR)
+	if err != nil {
+		return true, nil, err
+	}
+	return true, diff, nil
+}
+
+// ApplyCR updates the CR of an existing component with the fields of the
+// create request that can be changed after the component is created
+func (peer *Peer) ApplyCR(sID, compName, namespace string, body []byte) error {
+	unchangedCR, updatedCR, err := peer.renderApply(sID, compName, namespace, body)
+	if err != nil {
+		return err
+	}
+	if unchangedCR == nil {
+		return apierror.New(apierror.NotFound, apierror.CodeNotFound, "cr '%s' not found in namespace '%s'", compName, namespace)
+	}
+
+	crBytes, err := json.Marshal(updatedCR)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal, invalid request")
+	}
+
+	err = peer.IBPOperatorClient.UpdateCR(namespace, "ibppeers", compName, crBytes)
+	if err != nil {
+		return errors.Wrapf(err, "failed update cr '%s' in namespace '%s'", compName, namespace)
+	}
+
+	return nil
+}
+
+// renderApply returns the current CR and the CR updated with the create
+// request, both nil if the CR does not exist. Storage, crypto, state database,
+// zone and region are only set on create, the config override and HSM are
+// kept if the request does not set them. CRs of other service instances are
+// forbidden and the version must follow the transition policy.
+func (peer *Peer) renderApply(sID, compName, namespace string, body []byte) (*current.IBPPeer, *current.IBPPeer, error) {
+	desiredCR, _, err := peer.renderCR(compName, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	originalCR := &current.IBPPeer{}
+	err = peer.IBPOperatorClient.GetCR(namespace, "ibppeers", compName, originalCR)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+	}
+	if !common.BelongsTo(originalCR.Labels, sID) {
+		return nil, nil, apierror.New(apierror.Forbidden, apierror.CodeForbidden, "peer '%s' belongs to another service instance", compName)
+	}
+
+	err = util.CheckVersionTransition("peer", originalCR.Spec.FabricVersion, desiredCR.Spec.FabricVersion, false, peer.Config.Versions)
+	if err != nil {
+		return nil, nil, apierror.InvalidField("version", "%s", err.Error())
+	}
+
+	unchangedCR := originalCR.DeepCopy()
+
+	originalCR.Spec.FabricVersion = desiredCR.Spec.FabricVersion
+	originalCR.Spec.Images = desiredCR.Spec.Images
+	originalCR.Spec.Resources = desiredCR.Spec.Resources
+	if desiredCR.Spec.ConfigOverride != nil {
+		originalCR.Spec.ConfigOverride = desiredCR.Spec.ConfigOverride
+	}
+	if desiredCR.Spec.HSM != nil {
+		originalCR.Spec.HSM = desiredCR.Spec.HSM
+	}
+
+	return unchangedCR, originalCR, nil
+}
+
+// ListLabelled returns the names of the peers labelled with the service
+// instance, the peers that apply can prune. Peers without the label, created
+// before the CRs were labelled, are left out.
+func (peer *Peer) ListLabelled(sID, namespace string) ([]string, error) {
+	list := &current.IBPPeerList{}
+	err := peer.IBPOperatorClient.GetAllCR(namespace, "ibppeers", list)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get all peer cr in namespace '%s'", namespace)
+	}
+
+	names := []string{}
+	for _, cr := range list.Items {
+		if common.LabelledWith(cr.Labels, sID) {
+			names = append(names, cr.Name)
+		}
+	}
+	return names, nil
+}
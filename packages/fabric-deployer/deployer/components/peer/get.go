@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/peer/api"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
@@ -54,6 +55,9 @@ func (peer *Peer) GetAllCR(sID, namespace string) ([]api.Response, int, error) {
 		return nil, 500, err
 	}
 	for _, originalCR := range peerList.Items {
+		if !common.BelongsTo(originalCR.Labels, sID) {
+			continue
+		}
 		compName := originalCR.Name
 
 		response, _, err := peer.GetCRResponse(ALL, compName, namespace, sID)
@@ -67,6 +71,26 @@ func (peer *Peer) GetAllCR(sID, namespace string) ([]api.Response, int, error) {
 	return allresponses, 200, nil
 }
 
+// ResourceUsage returns the resources of all the peers of the service
+// instance in the namespace
+func (peer *Peer) ResourceUsage(sID, namespace string) ([]util.ComponentResources, error) {
+	peerList := &current.IBPPeerList{}
+	err := peer.IBPOperatorClient.GetAllCR(namespace, "ibppeers", peerList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get all peer cr in namespace '%s'", namespace)
+	}
+
+	usage := []util.ComponentResources{}
+	for i := range peerList.Items {
+		cr := &peerList.Items[i]
+		if !common.BelongsTo(cr.Labels, sID) {
+			continue
+		}
+		usage = append(usage, util.NewComponentResources("peer", cr.Name, cr.Spec.Replicas, roleResources(cr)))
+	}
+	return usage, nil
+}
+
 func (peer *Peer) GetCRResponse(section, compName, namespace, sID string) (*api.Response, int, error) {
 	peer.Logger.Debugf("Received get request for '%s'", compName)
 
@@ -83,6 +107,8 @@ func (peer *Peer) GetCRResponse(section, compName, namespace, sID string) (*api.
 		return nil, *statusCode, errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
 	}
 
+	response.ResourceVersion = originalCR.ResourceVersion
+
 	switch section {
 	case RESOURCES:
 		peer.getResources(originalCR, response)
@@ -120,7 +146,7 @@ func (peer *Peer) GetCRResponse(section, compName, namespace, sID string) (*api.
 		peer.getReplicas(originalCR, response)
 		peer.getOther(originalCR, response)
 	default:
-		return nil, http.StatusBadRequest, errors.Errorf("section %s not supported: %d", section, http.StatusBadRequest)
+		return nil, http.StatusBadRequest, apierror.UnsupportedSection(section)
 	}
 
 	return response, *statusCode, nil
@@ -128,34 +154,33 @@ func (peer *Peer) GetCRResponse(section, compName, namespace, sID string) (*api.
 }
 
 func (peer *Peer) getResources(originalCR *current.IBPPeer, response *api.Response) {
-	var totalResources *util.ResourceReturn
-	var individualResources *current.PeerResources
-	peerResources := []*corev1.ResourceRequirements{}
-	if originalCR.Spec.Resources != nil {
-		if originalCR.Spec.Resources.Peer != nil {
-			peerResources = append(peerResources, originalCR.Spec.Resources.Peer)
-		}
-		if strings.ToLower(originalCR.Spec.StateDb) == "couchdb" {
-			if originalCR.Spec.Resources.CouchDB != nil {
-				peerResources = append(peerResources, originalCR.Spec.Resources.CouchDB)
-			}
-		}
-		if originalCR.Spec.Resources.GRPCProxy != nil {
-			peerResources = append(peerResources, originalCR.Spec.Resources.GRPCProxy)
-		}
+	response.Resources = util.GetResourcesByRole(roleResources(originalCR))
+	response.IndividualResources = peer.GetIndividualResources("peer", originalCR.Spec.Resources, originalCR.Spec.FabricVersion, originalCR.Spec.StateDb)
+}
+
+// roleResources returns the resources of the containers the peer runs, by
+// role
+func roleResources(cr *current.IBPPeer) map[string]*corev1.ResourceRequirements {
+	roles := map[string]*corev1.ResourceRequirements{}
+	resources := cr.Spec.Resources
+	if resources == nil {
+		return roles
 	}
 
-	if util.GetMajorRelease(originalCR.Spec.FabricVersion) == 2 {
-		if originalCR.Spec.Resources != nil {
-			if originalCR.Spec.Resources.CCLauncher != nil {
-				peerResources = append(peerResources, originalCR.Spec.Resources.CCLauncher)
-			}
-		}
+	roles[util.RolePeer] = resources.Peer
+	roles[util.RoleProxy] = resources.GRPCProxy
+	roles[util.RoleInit] = resources.Init
+	if strings.ToLower(cr.Spec.StateDb) == "couchdb" {
+		roles[util.RoleCouchDB] = resources.CouchDB
+	}
+	if util.GetMajorRelease(cr.Spec.FabricVersion) == 2 {
+		roles[util.RoleChaincodeLauncher] = resources.CCLauncher
+	}
+	if cr.Spec.HSM != nil {
+		roles[util.RoleEnroller] = resources.Enroller
+		roles[util.RoleHSMDaemon] = resources.HSMDaemon
 	}
-	totalResources = util.GetTotalDeploymentResources(peerResources)
-	individualResources = peer.GetIndividualResources("peer", originalCR.Spec.Resources, originalCR.Spec.FabricVersion, originalCR.Spec.StateDb)
-	response.Resources = totalResources
-	response.IndividualResources = individualResources
+	return roles
 }
 
 func (peer *Peer) getStorage(originalCR *current.IBPPeer, response *api.Response) {
@@ -19,7 +19,9 @@
 package peer
 
 import (
+	"context"
 	"encoding/json"
+	"net/http"
 	"strings"
 	"time"
 
@@ -28,6 +30,7 @@ import (
 	"github.com/pkg/errors"
 
 	dconfig "github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/peer/api"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
@@ -37,8 +40,8 @@ import (
 	"go.uber.org/zap"
 
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 const (
@@ -77,6 +80,7 @@ type Kube interface {
 	GetPorts(namespace, name string) ([]corev1.ServicePort, error)
 	CreateSecret(namespace string, secret *corev1.Secret) (*corev1.Secret, error)
 	ClusterType(namespace string) string
+	WaitForConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error)
 }
 
 //go:generate counterfeiter -o mocks/ibp_client.go -fake-name IBPOperatorClient . IBPOperatorClient
@@ -88,6 +92,7 @@ type IBPOperatorClient interface {
 	DeleteCR(namespace string, kind string, name string) error
 	UpdateCR(namespace string, kind string, name string, bytes []byte) error
 	PatchCR(namespace string, kind string, name string, bytes []byte) error
+	WaitForCRStatus(ctx context.Context, namespace string, kind string, name string, cond func(status *current.CRStatus) (bool, error)) (*current.CRStatus, error)
 }
 
 type Peer struct {
@@ -171,23 +176,101 @@ func (peer *Peer) Images(version string) *current.PeerImages {
 }
 
 func (peer *Peer) CreateCR(domain, sID, compName, namespace string, body []byte) (*api.Response, int, error) {
-	var err error
 	statusCode := 0
 
+	peer.Logger.Debugf("Received request to create peer cr '%s' in namespace '%s'", compName, namespace)
+
+	cr, version, err := peer.renderCR(compName, body)
+	if err != nil {
+		return nil, statusCode, err
+	}
+	common.SetServiceInstance(cr, sID)
+
+	err = peer.IBPOperatorClient.CreateCR(namespace, "ibppeers", cr)
+	if err != nil {
+		peer.Logger.Error(errors.Wrapf(err, "Error in creating cr: %s, namespace: %s", cr.Name, namespace))
+		return nil, statusCode, err
+	}
+
+	peer.Logger.Debugf("Created cr '%s'", cr.Name)
+
+	// get cr status
+	// if status is deployed -> get connection profile
+	// if status is error -> we are done
+	peer.Logger.Debugf("Waiting for cr spec status '%s'", compName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(peer.Config.Timeouts.Deployment)*time.Millisecond)
+	defer cancel()
+	crStatus, err := common.WaitForDeployment(ctx, peer.IBPOperatorClient, peer.Kube, namespace, "ibppeers", compName, 0)
+
+	// cr status did not change to deployer/error before timeout
+	if err != nil {
+		peer.Logger.Warnf("Status not set after timeout or got an error: %s", err)
+		// return error immediately, something went wrong
+		statusCode = 500
+		if crStatus != nil && crStatus.Status == current.True && crStatus.Type == current.Error {
+			// dont error out
+		} else {
+			return nil, statusCode, err
+		}
+	}
+
+	// build the response
+	response, statusCodeNew, err := peer.GetCRResponse(ALL, compName, namespace, sID)
+	if err != nil {
+		peer.Logger.Error(errors.Wrapf(err, "Failed to build response object '%s'", compName))
+		return nil, statusCode, err
+	}
+	if statusCode == 0 && statusCodeNew != 0 {
+		statusCode = statusCodeNew
+	}
+	response.Version = version
+	timestamp := time.Now().Unix()
+	response.CreationTimestamp = timestamp
+	response.LastUpdatedTimestamp = timestamp
+
+	return response, statusCode, nil
+}
+
+// DryRunCreateCR returns the peer CR that would be created for the request,
+// nothing is created
+func (peer *Peer) DryRunCreateCR(compName, namespace string, body []byte) (*common.DryRunResponse, int, error) {
+	peer.Logger.Debugf("Received dry run request to create peer cr '%s' in namespace '%s'", compName, namespace)
+
+	cr, _, err := peer.renderCR(compName, body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = peer.IBPOperatorClient.GetCR(namespace, "ibppeers", compName, &current.IBPPeer{})
+	if err == nil {
+		return nil, http.StatusConflict, apierror.New(apierror.Conflict, apierror.CodeAlreadyExists, "cr '%s' already exists", compName)
+	}
+	if !k8serrors.IsNotFound(err) {
+		return nil, 0, errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+	}
+	common.RemoveSensitiveDataFromCrypto(cr.Spec.Secret)
+
+	response, err := common.NewDryRunResponse(nil, cr)
+	if err != nil {
+		return nil, 0, err
+	}
+	return response, http.StatusOK, nil
+}
+
+// renderCR builds the peer CR and fabric version for the create request
+func (peer *Peer) renderCR(compName string, body []byte) (*current.IBPPeer, string, error) {
 	// if comp name is not passed assign random name
 	if compName == "" {
 		peer.Logger.Error("Component name not valid, cannot be empty")
-		return nil, statusCode, errors.New("component name not valid, cannot be empty")
+		return nil, "", apierror.InvalidField("componentName", "component name not valid, cannot be empty")
 	}
 
-	peer.Logger.Debugf("Received request to create peer cr '%s' in namespace '%s'", compName, namespace)
-
 	request := &api.CreateRequest{}
 	if len(body) != 0 {
-		err = json.Unmarshal(body, request)
+		err := json.Unmarshal(body, request)
 		if err != nil {
 			peer.Logger.Error(errors.Wrapf(err, "failed to unmarshal body, Request body is Invalid !!"))
-			return nil, statusCode, errors.Wrapf(err, "failed to unmarshal body, Request body is Invalid !!")
+			return nil, "", apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "failed to unmarshal body, Request body is Invalid !!")
 		}
 	}
 
@@ -201,12 +284,18 @@ func (peer *Peer) CreateCR(domain, sID, compName, namespace string, body []byte)
 		version = util.GetDefaultVersion("peer", peer.Config.Versions)
 	} else if !util.IsValidVersion("peer", request.Version, peer.Config.Versions) {
 		peer.Logger.Error("Version not valid")
-		return nil, statusCode, errors.Errorf("version not valid")
+		return nil, "", apierror.InvalidField("version", "version not valid")
+	}
+
+	defaults, err := peer.Config.Defaults.ForProfile(request.Profile)
+	if err != nil {
+		peer.Logger.Error(err)
+		return nil, "", apierror.InvalidField("profile", "%s", err.Error())
 	}
 
 	// merge storage and resources
-	storage := peer.GetStorage(peer.Config.Defaults, request.Storage)
-	resources := peer.GetResources(*peer.Config.Defaults.Resources.Peer, request.Resources, request.StateDB, version)
+	storage := peer.GetStorage(defaults, request.Storage)
+	resources := peer.GetResources(*defaults.Resources.Peer, request.Resources, request.StateDB, version)
 	zone, region := util.GetZoneAndRegion(request.Zone, request.Region)
 
 	// pass the msp info to cr spec
@@ -246,69 +335,7 @@ func (peer *Peer) CreateCR(domain, sID, compName, namespace string, body []byte)
 	}
 	cr.Name = compName
 
-	err = peer.IBPOperatorClient.CreateCR(namespace, "ibppeers", cr)
-	if err != nil {
-		peer.Logger.Error(errors.Wrapf(err, "Error in creating cr: %s, namespace: %s", cr.Name, namespace))
-		return nil, statusCode, err
-	}
-
-	peer.Logger.Debugf("Created cr '%s'", cr.Name)
-
-	// get cr status
-	// if status is deployed -> get connection profile
-	// if status is error -> we are done
-	originalCR := &current.IBPPeer{}
-	peer.Logger.Debugf("Polling for cr spec status '%s'", compName)
-	err = wait.Poll(500*time.Millisecond, time.Duration(peer.Config.Timeouts.Deployment)*time.Millisecond, func() (bool, error) {
-		err = peer.IBPOperatorClient.GetCR(namespace, "ibppeers", compName, originalCR)
-		if err == nil {
-			// check the status field
-			if originalCR.Status.Status == current.True {
-				// go out if error
-				if originalCR.Status.Type == current.Error {
-					// if cr status is error, no need to get configs
-					return true, errors.New("CR status is set to error")
-				}
-				cmName := compName + "-connection-profile"
-				cm, err := peer.Kube.GetConfigMap(namespace, cmName)
-				if err == nil && cm != nil {
-					return true, nil
-				}
-			}
-			return false, nil
-		} else {
-			// immediately fail on error
-			return true, err
-		}
-	})
-
-	// cr status did not change to deployer/error before timeout
-	if err != nil {
-		peer.Logger.Warnf("Status not set after timeout or got an error: %s", err)
-		// return error immediately, something went wrong
-		statusCode = 500
-		if originalCR.Status.Status == current.True && originalCR.Status.Type == current.Error {
-			// dont error out
-		} else {
-			return nil, statusCode, err
-		}
-	}
-
-	// build the response
-	response, statusCodeNew, err := peer.GetCRResponse(ALL, compName, namespace, sID)
-	if err != nil {
-		peer.Logger.Error(errors.Wrapf(err, "Failed to build response object '%s'", compName))
-		return nil, statusCode, err
-	}
-	if statusCode == 0 && statusCodeNew != 0 {
-		statusCode = statusCodeNew
-	}
-	response.Version = version
-	timestamp := time.Now().Unix()
-	response.CreationTimestamp = timestamp
-	response.LastUpdatedTimestamp = timestamp
-
-	return response, statusCode, nil
+	return cr, version, nil
 }
 
 func (peer *Peer) DeleteCR(sID, compName, namespace string, body []byte) (*api.DeleteResponse, int, error) {
@@ -316,7 +343,7 @@ func (peer *Peer) DeleteCR(sID, compName, namespace string, body []byte) (*api.D
 	statusCode := 0
 	if compName == "" {
 		peer.Logger.Error("Name of the compenent to be deleted is required")
-		return nil, statusCode, errors.New("Name of the component to be delete is required")
+		return nil, statusCode, apierror.InvalidField("componentName", "Name of the component to be delete is required")
 	}
 
 	peer.Logger.Debugf("Received request delete peer cr '%s' in namespace '%s'", compName, namespace)
@@ -326,7 +353,7 @@ func (peer *Peer) DeleteCR(sID, compName, namespace string, body []byte) (*api.D
 		err = json.Unmarshal(body, request)
 		if err != nil {
 			peer.Logger.Error(errors.Wrap(err, "failed to unmarshal configuration, configuration is not a valid yaml file"))
-			return nil, statusCode, errors.Wrapf(err, "failed to unmarshal configuration, configuration is not a valid yaml file")
+			return nil, statusCode, apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "failed to unmarshal configuration, configuration is not a valid yaml file")
 		}
 	}
 
@@ -351,7 +378,7 @@ func (peer *Peer) GetConnectionProfile(compName, namespace string) (*common.Conn
 	}
 	binaryData := cm.BinaryData
 	if binaryData["profile.json"] == nil {
-		return nil, errors.New("profile.json not found in configmap")
+		return nil, apierror.New(apierror.NotFound, apierror.CodeNotFound, "profile.json not found in configmap")
 	}
 	data := binaryData["profile.json"]
 	connectionProfile := &common.ConnectionProfile{}
@@ -372,7 +399,7 @@ func (peer *Peer) GetConfig(compName, namespace string, fabricVersion string) (i
 	}
 	binaryData := cm.BinaryData
 	if binaryData["core.yaml"] == nil {
-		return nil, errors.New("core.yaml not found in configmap")
+		return nil, apierror.New(apierror.NotFound, apierror.CodeNotFound, "core.yaml not found in configmap")
 	}
 	data := binaryData["core.yaml"]
 
@@ -398,7 +425,9 @@ func (peer *Peer) GetConfig(compName, namespace string, fabricVersion string) (i
 func (peer *Peer) GetStorage(defaults *dconfig.DeployerDefaults, override *current.PeerStorages) *current.PeerStorages {
 	peer.Logger.Debug("Received request to get storage")
 
-	storage := defaults.Storage.Peer
+	// the defaults are shared by all the requests, they are copied so the
+	// overrides do not change them
+	storage := defaults.Storage.Peer.DeepCopy()
 
 	if override != nil {
 		if override.StateDB != nil && override.StateDB.Size != "" {
@@ -422,7 +451,7 @@ func (peer *Peer) GetStorage(defaults *dconfig.DeployerDefaults, override *curre
 func (peer *Peer) GetResources(defaults current.PeerResources, override *current.PeerResources, statedb, fabricVersion string) *current.PeerResources {
 	peer.Logger.Debug("Received request to get resources")
 
-	resources := defaults
+	resources := defaults.DeepCopy()
 
 	if override != nil {
 		if override.Peer != nil {
@@ -463,7 +492,7 @@ func (peer *Peer) GetResources(defaults current.PeerResources, override *current
 		}
 	}
 
-	return &resources
+	return resources
 }
 
 func (peer *Peer) GetUpdateResources(current, override *current.PeerResources) (*current.PeerResources, error) {
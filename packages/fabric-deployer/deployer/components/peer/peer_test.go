@@ -19,6 +19,7 @@
 package peer_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -133,6 +134,9 @@ var _ = Describe("Peer", func() {
 			return nil
 		}
 
+		mockIBPClient.WaitForCRStatusReturns(&current.CRStatus{Status: current.True, Type: current.Deployed}, nil)
+		mockKube.WaitForConfigMapReturns(&corev1.ConfigMap{}, nil)
+
 		mockKube.GetConfigMapStub = func(namespace, name string) (*corev1.ConfigMap, error) {
 			type tls struct {
 				Cert string
@@ -227,17 +231,76 @@ var _ = Describe("Peer", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
-		It("returns error if get CR timesout", func() {
-			mockIBPClient.GetCRStub = func(namespace string, kind string, name string, peerCR runtime.Object) error {
-				c := peerCR.(*current.IBPPeer)
-				c.Spec = current.IBPPeerSpec{}
-				c.Status = current.IBPPeerStatus{
-					CRStatus: current.CRStatus{
-						Status: current.False,
+		It("creates the peer with the sizing profile", func() {
+			res := map[corev1.ResourceName]resource.Quantity{}
+			res[corev1.ResourceCPU] = resource.MustParse("4")
+			testPeer.Config.Defaults.Profiles = map[string]*config.Profile{
+				"production": {
+					Storage: &config.Storage{
+						Peer: &current.PeerStorages{Peer: &current.StorageSpec{Size: "100Gi"}},
 					},
-				}
-				return nil
+					Resources: &config.Resources{
+						Peer: &current.PeerResources{Peer: &corev1.ResourceRequirements{Requests: res, Limits: res}},
+					},
+				},
 			}
+			body, err = json.Marshal(api.CreateRequest{Profile: "production"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, err := testPeer.CreateCR("0.0.0.0", "sID1", "peer1", "default", body)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, cr := mockIBPClient.CreateCRArgsForCall(0)
+			spec := cr.(*current.IBPPeer).Spec
+			Expect(spec.Storage.Peer.Size).To(Equal("100Gi"))
+			Expect(spec.Storage.StateDB.Size).To(Equal("1Gi"))
+			Expect(spec.Resources.Peer.Requests.Cpu().String()).To(Equal("4"))
+			Expect(spec.Resources.GRPCProxy.Requests.Cpu().String()).To(Equal("1"))
+			Expect(resources.Peer.Requests.Cpu().String()).To(Equal("1"))
+		})
+
+		It("returns an error if the profile does not exist", func() {
+			body, err = json.Marshal(api.CreateRequest{Profile: "production"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, err := testPeer.CreateCR("0.0.0.0", "sID1", "peer1", "default", body)
+			Expect(err).To(MatchError("profile 'production' not found"))
+		})
+
+		It("does not change the defaults with the overrides of the request", func() {
+			body, err = json.Marshal(api.CreateRequest{Storage: &current.PeerStorages{Peer: &current.StorageSpec{Size: "50Gi"}}})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, err := testPeer.CreateCR("0.0.0.0", "sID1", "peer1", "default", body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testPeer.Config.Defaults.Storage.Peer.Peer.Size).To(Equal("1Gi"))
+		})
+
+		It("waits for the CR status and the connection profile", func() {
+			_, _, err := testPeer.CreateCR("0.0.0.0", "sID1", "peer1", "default", body)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(mockIBPClient.WaitForCRStatusCallCount()).To(Equal(1))
+			_, namespace, kind, name, _ := mockIBPClient.WaitForCRStatusArgsForCall(0)
+			Expect(namespace).To(Equal("default"))
+			Expect(kind).To(Equal("ibppeers"))
+			Expect(name).To(Equal("peer1"))
+
+			Expect(mockKube.WaitForConfigMapCallCount()).To(Equal(1))
+			_, namespace, name = mockKube.WaitForConfigMapArgsForCall(0)
+			Expect(namespace).To(Equal("default"))
+			Expect(name).To(Equal("peer1-connection-profile"))
+		})
+
+		It("returns error if the connection profile is not created before timeout", func() {
+			mockKube.WaitForConfigMapReturns(nil, context.DeadlineExceeded)
+			_, statusCode, err := testPeer.CreateCR("0.0.0.0", "sID1", "peer1", "default", body)
+			Expect(err).To(HaveOccurred())
+			Expect(statusCode).Should(Equal(500))
+		})
+
+		It("returns error if get CR timesout", func() {
+			mockIBPClient.WaitForCRStatusReturns(&current.CRStatus{Status: current.False}, context.DeadlineExceeded)
 			testPeer.Config.Timeouts = &config.Timeouts{
 				Deployment: 1 * 100,
 			}
@@ -247,6 +310,11 @@ var _ = Describe("Peer", func() {
 		})
 
 		It("returns 500 if CR status is error", func() {
+			mockIBPClient.WaitForCRStatusStub = func(_ context.Context, _, _, _ string, cond func(*current.CRStatus) (bool, error)) (*current.CRStatus, error) {
+				status := &current.CRStatus{Status: current.True, Type: current.Error}
+				_, err := cond(status)
+				return status, err
+			}
 			mockIBPClient.GetCRStub = func(namespace string, kind string, name string, peerCR runtime.Object) error {
 				c := peerCR.(*current.IBPPeer)
 				c.Spec = current.IBPPeerSpec{
@@ -525,6 +593,21 @@ var _ = Describe("Peer", func() {
 		})
 	})
 
+	Context("Requirements", func() {
+		It("leaves out the containers the peer does not run", func() {
+			body, err := json.Marshal(api.CreateRequest{StateDB: "leveldb"})
+			Expect(err).NotTo(HaveOccurred())
+
+			pods, err := testPeer.Requirements("peer1", body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pods).To(HaveLen(1))
+			Expect(pods[0].Containers).To(HaveLen(3))
+			Expect(pods[0].Containers).NotTo(HaveKey("couchdb"))
+			cpu := pods[0].Requests[corev1.ResourceCPU]
+			Expect(cpu.String()).To(Equal("2"))
+		})
+	})
+
 	Describe("GetUpdateResources", func() {
 		var currentResources *corev1.ResourceRequirements
 		var overrideResources *corev1.ResourceRequirements
@@ -929,5 +1012,11 @@ var _ = Describe("Peer", func() {
 				})
 			})
 		})
+
+		It("does not change the defaults", func() {
+			finalRes := testPeer.GetResources(*deployerDefualt.Resources.Peer, overridePeerres, "couchdb", "2.0.0")
+			Expect(finalRes.Peer.Requests).To(Equal(overrideResources.Requests))
+			Expect(deployerDefualt.Resources.Peer.Peer.Requests).To(Equal(currentResources.Requests))
+		})
 	})
 })
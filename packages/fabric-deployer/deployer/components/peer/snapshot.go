@@ -0,0 +1,105 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"encoding/json"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/snapshot"
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+	"github.com/pkg/errors"
+)
+
+// SnapshotVolumes returns the ledger and state database volumes of the peer,
+// the PVCs are named by the operator unless the CR sets custom names
+func (p *Peer) SnapshotVolumes(compName, namespace string) ([]snapshot.Volume, error) {
+	cr, err := p.getCR(compName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	peerPVC := cr.Spec.CustomNames.PVC.Peer
+	if peerPVC == "" {
+		peerPVC = compName + "-pvc"
+	}
+	stateDBPVC := cr.Spec.CustomNames.PVC.StateDB
+	if stateDBPVC == "" {
+		stateDBPVC = compName + "-statedb-pvc"
+	}
+
+	return []snapshot.Volume{
+		{Name: "peer", PVC: peerPVC},
+		{Name: "statedb", PVC: stateDBPVC},
+	}, nil
+}
+
+// ScaleReplicas sets the replicas of the peer and returns the previous ones
+func (p *Peer) ScaleReplicas(compName, namespace string, replicas *int32) (*int32, error) {
+	cr, err := p.getCR(compName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := cr.Spec.Replicas
+	err = p.updateReplicas(cr, replicas)
+	if err != nil {
+		return nil, err
+	}
+
+	return previous, p.writeCR(compName, namespace, cr)
+}
+
+// RebindVolumes sets the PVCs of the peer volumes by volume name
+func (p *Peer) RebindVolumes(compName, namespace string, pvcs map[string]string) error {
+	cr, err := p.getCR(compName, namespace)
+	if err != nil {
+		return err
+	}
+
+	if pvc, found := pvcs["peer"]; found {
+		cr.Spec.CustomNames.PVC.Peer = pvc
+	}
+	if pvc, found := pvcs["statedb"]; found {
+		cr.Spec.CustomNames.PVC.StateDB = pvc
+	}
+
+	return p.writeCR(compName, namespace, cr)
+}
+
+func (p *Peer) getCR(compName, namespace string) (*current.IBPPeer, error) {
+	cr := &current.IBPPeer{}
+	err := p.IBPOperatorClient.GetCR(namespace, "ibppeers", compName, cr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+	}
+	return cr, nil
+}
+
+func (p *Peer) writeCR(compName, namespace string, cr *current.IBPPeer) error {
+	crBytes, err := json.Marshal(cr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal, invalid request")
+	}
+
+	err = p.IBPOperatorClient.UpdateCR(namespace, "ibppeers", compName, crBytes)
+	if err != nil {
+		return errors.Wrapf(err, "failed update cr '%s' in namespace '%s'", compName, namespace)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/peer"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/peer/mocks"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/snapshot"
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+)
+
+var _ = Describe("Snapshot volumes", func() {
+	var (
+		peerComp *peer.Peer
+		client   *mocks.IBPOperatorClient
+		spec     current.IBPPeerSpec
+	)
+
+	BeforeEach(func() {
+		client = &mocks.IBPOperatorClient{}
+		one := int32(1)
+		spec = current.IBPPeerSpec{Replicas: &one}
+		client.GetCRStub = func(namespace string, kind string, name string, peerCR runtime.Object) error {
+			peerCR.(*current.IBPPeer).Spec = spec
+			return nil
+		}
+
+		peerComp = &peer.Peer{
+			Logger:            zap.NewNop().Sugar(),
+			IBPOperatorClient: client,
+		}
+	})
+
+	updatedCR := func() *current.IBPPeer {
+		Expect(client.UpdateCRCallCount()).To(Equal(1))
+		cr := &current.IBPPeer{}
+		_, kind, name, crBytes := client.UpdateCRArgsForCall(0)
+		Expect(kind).To(Equal("ibppeers"))
+		Expect(name).To(Equal("peer1"))
+		Expect(json.Unmarshal(crBytes, cr)).To(Succeed())
+		return cr
+	}
+
+	It("returns the pvcs named by the operator", func() {
+		volumes, err := peerComp.SnapshotVolumes("peer1", "namespace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(volumes).To(Equal([]snapshot.Volume{
+			{Name: "peer", PVC: "peer1-pvc"},
+			{Name: "statedb", PVC: "peer1-statedb-pvc"},
+		}))
+	})
+
+	It("returns the custom pvcs", func() {
+		spec.CustomNames.PVC.Peer = "restored-pvc"
+		volumes, err := peerComp.SnapshotVolumes("peer1", "namespace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(volumes[0].PVC).To(Equal("restored-pvc"))
+	})
+
+	It("scales the replicas and returns the previous ones", func() {
+		zero := int32(0)
+		previous, err := peerComp.ScaleReplicas("peer1", "namespace", &zero)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*previous).To(Equal(int32(1)))
+		Expect(*updatedCR().Spec.Replicas).To(Equal(int32(0)))
+	})
+
+	It("rebinds the volumes to the custom pvcs", func() {
+		err := peerComp.RebindVolumes("peer1", "namespace", map[string]string{"statedb": "restored-pvc"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updatedCR().Spec.CustomNames.PVC).To(Equal(current.PeerPVCNames{StateDB: "restored-pvc"}))
+	})
+})
@@ -23,6 +23,8 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/peer/api"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
 	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
@@ -31,7 +33,13 @@ import (
 )
 
 func (p *Peer) UpdateCR(section, compName, namespace, sID string, body []byte) (*api.Response, int, error) {
-	err := p.updateCR(section, compName, namespace, sID, body)
+	return p.UpdateCRIfMatch(section, compName, namespace, sID, "", body)
+}
+
+// UpdateCRIfMatch updates the CR if its ETag matches ifMatch, the value of the
+// If-Match header of the request
+func (p *Peer) UpdateCRIfMatch(section, compName, namespace, sID, ifMatch string, body []byte) (*api.Response, int, error) {
+	err := p.updateCR(section, compName, namespace, sID, ifMatch, body)
 	if err != nil {
 		p.Logger.Error(errors.Wrapf(err, "update err for %s", compName))
 		return nil, 500, err
@@ -46,20 +54,65 @@ func (p *Peer) UpdateCR(section, compName, namespace, sID string, body []byte) (
 	return response, 200, nil
 }
 
-func (p *Peer) updateCR(section, compName, namespace, sID string, body []byte) error {
+// DryRunUpdateCR returns the CR the update request would submit and its
+// changes from the current CR, nothing is updated
+func (p *Peer) DryRunUpdateCR(section, compName, namespace, ifMatch string, body []byte) (*common.DryRunResponse, int, error) {
+	unchangedCR, updatedCR, err := p.renderUpdate(section, compName, namespace, ifMatch, body)
+	if err != nil {
+		p.Logger.Error(errors.Wrapf(err, "dry run update err for %s", compName))
+		return nil, 0, err
+	}
+	common.RemoveSensitiveDataFromCrypto(unchangedCR.Spec.Secret)
+	common.RemoveSensitiveDataFromCrypto(updatedCR.Spec.Secret)
+
+	response, err := common.NewDryRunResponse(unchangedCR, updatedCR)
+	if err != nil {
+		return nil, 0, err
+	}
+	return response, http.StatusOK, nil
+}
+
+func (p *Peer) updateCR(section, compName, namespace, sID, ifMatch string, body []byte) error {
+	_, updatedCR, err := p.renderUpdate(section, compName, namespace, ifMatch, body)
+	if err != nil {
+		return err
+	}
+
+	crBytes, err := json.Marshal(updatedCR)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal, invalid request")
+	}
+
+	err = p.IBPOperatorClient.UpdateCR(namespace, "ibppeers", compName, crBytes)
+	if err != nil {
+		return errors.Wrapf(err, "failed update cr '%s' in namespace '%s'", compName, namespace)
+	}
+
+	return nil
+}
+
+// renderUpdate returns the current CR and the CR with the section of the
+// request updated
+func (p *Peer) renderUpdate(section, compName, namespace, ifMatch string, body []byte) (*current.IBPPeer, *current.IBPPeer, error) {
 	p.Logger.Debugf("Received update request for '%s'", compName)
 
 	originalCR := &current.IBPPeer{}
 	err := p.IBPOperatorClient.GetCR(namespace, "ibppeers", compName, originalCR)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+		return nil, nil, errors.Wrapf(err, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+	}
+	unchangedCR := originalCR.DeepCopy()
+
+	err = common.CheckIfMatch(ifMatch, originalCR.ResourceVersion)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	request := &api.UpdateRequest{}
 	if len(body) != 0 {
 		err = json.Unmarshal(body, request)
 		if err != nil {
-			return errors.Wrapf(err, "failed to unmarshal, invalid request")
+			return nil, nil, apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "failed to unmarshal, invalid request")
 		}
 	}
 
@@ -73,40 +126,30 @@ func (p *Peer) updateCR(section, compName, namespace, sID string, body []byte) e
 	case ADMINCERTS:
 		err := p.updateAdminCerts(originalCR, request.AdminCerts)
 		if err != nil {
-			return errors.Wrap(err, "failed to update admin certs")
+			return nil, nil, errors.Wrap(err, "failed to update admin certs")
 		}
 	case VERSION:
-		err := p.updateVersion(originalCR, request.Version)
+		err := p.updateVersion(originalCR, request.Version, request.ForceVersion)
 		if err != nil {
-			return errors.Wrap(err, "failed to update version")
+			return nil, nil, errors.Wrap(err, "failed to update version")
 		}
 	case REPLICAS:
 		err := p.updateReplicas(originalCR, request.Replicas)
 		if err != nil {
-			return errors.Wrap(err, "failed to update replicas")
+			return nil, nil, errors.Wrap(err, "failed to update replicas")
 		}
 	case HSM:
 		p.updateHSM(originalCR, request.HSM)
 	case ALL:
 		err := p.updateAll(originalCR, request)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 	default:
-		return errors.Errorf("section %s not supported: %d", section, http.StatusBadRequest)
-	}
-
-	crBytes, err := json.Marshal(originalCR)
-	if err != nil {
-		return errors.Wrapf(err, "failed to marshal, invalid request")
-	}
-
-	err = p.IBPOperatorClient.UpdateCR(namespace, "ibppeers", compName, crBytes)
-	if err != nil {
-		return errors.Wrapf(err, "failed update cr '%s' in namespace '%s'", compName, namespace)
+		return nil, nil, apierror.UnsupportedSection(section)
 	}
 
-	return nil
+	return unchangedCR, originalCR, nil
 }
 
 func (p *Peer) updateConfig(originalCR *current.IBPPeer, configOverride *runtime.RawExtension) {
@@ -148,14 +191,20 @@ func (p *Peer) updateAdminCerts(originalCR *current.IBPPeer, adminCerts []string
 	return nil
 }
 
-func (p *Peer) updateVersion(originalCR *current.IBPPeer, version string) error {
+func (p *Peer) updateVersion(originalCR *current.IBPPeer, version string, force bool) error {
 	if version == "" {
 		return nil
 	}
 
 	if !util.IsValidVersion("peer", version, p.Config.Versions) {
 		p.Logger.Error("Version not valid")
-		return errors.New("version not valid")
+		return apierror.InvalidField("version", "version not valid")
+	}
+
+	err := util.CheckVersionTransition("peer", originalCR.Spec.FabricVersion, version, force, p.Config.Versions)
+	if err != nil {
+		p.Logger.Error(err)
+		return apierror.InvalidField("version", "%s", err.Error())
 	}
 
 	image := p.Config.Versions.Peer[version].Image
@@ -174,7 +223,7 @@ func (p *Peer) updateReplicas(originalCR *current.IBPPeer, replicas *int32) erro
 	}
 
 	if *replicas < 0 || *replicas > 1 {
-		return errors.New("replicas not valid, expecting 0 or 1")
+		return apierror.InvalidField("replicas", "replicas not valid, expecting 0 or 1")
 	}
 
 	originalCR.Spec.Replicas = replicas
@@ -217,7 +266,7 @@ func (p *Peer) updateAll(originalCR *current.IBPPeer, request *api.UpdateRequest
 		}
 	}
 
-	err := p.updateVersion(originalCR, request.Version)
+	err := p.updateVersion(originalCR, request.Version, request.ForceVersion)
 	if err != nil {
 		return errors.Wrap(err, "failed to update version")
 	}
@@ -20,6 +20,7 @@ package peer_test
 
 import (
 	"encoding/json"
+	"net/http"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -31,6 +32,7 @@ import (
 
 	"github.com/IBM-Blockchain/fabric-deployer/config"
 	cfg "github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/peer"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/peer/api"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/peer/mocks"
@@ -244,6 +246,47 @@ var _ = Describe("Update API", func() {
 			})
 		})
 
+		Context("if match", func() {
+			BeforeEach(func() {
+				getCR := client.GetCRStub
+				client.GetCRStub = func(namespace string, kind string, name string, peerCR runtime.Object) error {
+					err := getCR(namespace, kind, name, peerCR)
+					peerCR.(*current.IBPPeer).ResourceVersion = "123"
+					return err
+				}
+			})
+
+			It("returns a precondition failed error if the ETag does not match", func() {
+				_, _, err := peerComp.UpdateCRIfMatch(peer.CRYPTO, "peer1", "namespace", "testSID", `"122"`, body)
+				Expect(err).To(MatchError(ContainSubstring("precondition failed")))
+				Expect(client.UpdateCRCallCount()).To(Equal(0))
+			})
+
+			It("performs update if the ETag matches", func() {
+				resp, _, err := peerComp.UpdateCRIfMatch(peer.CRYPTO, "peer1", "namespace", "testSID", `"123"`, body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.UpdateCRCallCount()).To(Equal(1))
+				Expect(resp.GetResourceVersion()).To(Equal("123"))
+			})
+		})
+
+		Context("dry run", func() {
+			It("returns the updated cr with the crypto redacted without updating it", func() {
+				resp, code, err := peerComp.DryRunUpdateCR(peer.CRYPTO, "peer1", "namespace", "", body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(code).To(Equal(200))
+				Expect(client.UpdateCRCallCount()).To(Equal(0))
+
+				cr := resp.CR.(*current.IBPPeer)
+				Expect(cr.Spec.Secret.MSP.Component.SignCerts).To(Equal("newcert"))
+				Expect(cr.Spec.Secret.MSP.Component.KeyStore).To(Equal("redacted"))
+				Expect(resp.Diff).NotTo(BeEmpty())
+				for _, change := range resp.Diff {
+					Expect(change.Path).To(HavePrefix("/spec/secret"))
+				}
+			})
+		})
+
 		Context("admin certs", func() {
 			It("returns error if secret spec doesn't exist in cr", func() {
 				client.GetCRStub = func(namespace string, kind string, name string, peerCR runtime.Object) error {
@@ -317,6 +360,38 @@ var _ = Describe("Update API", func() {
 					Expect(cr.Spec.Images).To(Equal(&current.PeerImages{}))
 				})
 			})
+
+			Context("transition policy", func() {
+				BeforeEach(func() {
+					getCR := client.GetCRStub
+					client.GetCRStub = func(namespace string, kind string, name string, peerCR runtime.Object) error {
+						err := getCR(namespace, kind, name, peerCR)
+						peerCR.(*current.IBPPeer).Spec.FabricVersion = "2.2.10"
+						return err
+					}
+				})
+
+				It("returns a 400 on downgrades", func() {
+					_, _, err := peerComp.UpdateCR(peer.VERSION, "peer1", "namespace", "testSID", body)
+					Expect(err).To(MatchError(ContainSubstring("cannot downgrade peer from '2.2.10' to '1.4.1-1', downgrades must be forced")))
+					Expect(apierror.StatusCode(err)).To(Equal(http.StatusBadRequest))
+					Expect(client.UpdateCRCallCount()).To(Equal(0))
+				})
+
+				It("downgrades when forced", func() {
+					body, err = json.Marshal(&api.UpdateRequest{Version: "1.4.1-1", ForceVersion: true})
+					Expect(err).NotTo(HaveOccurred())
+
+					_, _, err := peerComp.UpdateCR(peer.VERSION, "peer1", "namespace", "testSID", body)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(client.UpdateCRCallCount()).To(Equal(1))
+				})
+
+				It("enforces the policy on patch", func() {
+					_, _, err := peerComp.PatchCR(peer.VERSION, "peer1", "namespace", "testSID", body)
+					Expect(err).To(MatchError(ContainSubstring("downgrades must be forced")))
+				})
+			})
 		})
 
 		Context("replicas", func() {
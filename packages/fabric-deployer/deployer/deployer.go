@@ -24,9 +24,13 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
 
@@ -35,14 +39,24 @@ import (
 	"k8s.io/client-go/rest"
 
 	"github.com/IBM-Blockchain/fabric-deployer/config"
-	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/ca"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/audit"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/auth"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/bundle"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/capacity"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
-	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/mustgather"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/operator"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer"
-	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/peer"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/events"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/ibpoperator"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/instance"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/kube"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/metrics"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/openapi"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/operations"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/snapshot"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/tracing"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/upgrade"
 	"go.uber.org/zap"
 )
 
@@ -63,14 +77,46 @@ type Deployer struct {
 	Listener          net.Listener
 	IBPOperatorClient *ibpoperator.Client
 	K8SClient         *kube.Kube
+	Authenticator     auth.Authenticator
+	Authorizer        *Authorizer
+	Audit             audit.Store
+	OpenAPI           *openapi.Document
 
-	CA         *ca.CA
-	Peer       *peer.Peer
-	Orderer    *orderer.Orderer
 	Operator   *operator.Operator
-	Mustgather *mustgather.Mustgather
+	Operations *operations.Store
+	Events     *events.Broker
+	Bundle     *bundle.Bundler
+	Snapshots  *snapshot.Snapshotter
+	Upgrader   *upgrade.Upgrader
+	// CapacityChecker checks the resources of the components before they
+	// are created
+	CapacityChecker *capacity.Checker
+	// Instances resolves the namespaces of the service instances
+	Instances *instance.Resolver
+	// Targets are the clusters the deployer deploys to besides its own, by
+	// name
+	Targets map[string]*Target
+	// Metrics are the Prometheus metrics, nil if they are disabled
+	Metrics *metrics.Metrics
+	// Tracing exports the OpenTelemetry spans, nil if tracing is disabled
+	Tracing *tracing.Tracing
 
 	httpServer *http.Server
+	// eventWatches are the namespaces whose CRs are watched for events
+	eventWatches      map[string]bool
+	eventWatchesMutex sync.Mutex
+	// metricsWatches are the namespaces whose CRs are watched for the
+	// metrics, by cluster
+	metricsWatches      map[string]bool
+	metricsWatchesMutex sync.Mutex
+
+	// components are the components built from the configuration in use,
+	// they are replaced when the configuration file is reloaded
+	components atomic.Pointer[Components]
+
+	// upgrades are the upgrades that are running or paused, by operation
+	upgrades      map[string]*upgradeRun
+	upgradesMutex sync.Mutex
 }
 
 // New is a hook that is called with the Options the program is run
@@ -112,6 +158,11 @@ func (d *Deployer) Init() error {
 		if err != nil {
 			return errors.Wrap(err, "error loading TLS Certificates")
 		}
+
+		err = d.configureClientAuth(tlsConfig)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = d.CreateListener(address, tlsConfig)
@@ -119,33 +170,38 @@ func (d *Deployer) Init() error {
 		return err
 	}
 
-	var ibpOperatorClient *ibpoperator.Client
-	var k8sClient *kube.Kube
-	if d.LocalConfig.KubeConfig != nil {
-		ibpOperatorClient, err = ibpoperator.New(d.LocalConfig.KubeConfig)
-		if err != nil {
-			return err
-		}
-		k8sClient, err = kube.NewForConfig(d.LocalConfig.KubeConfig)
-		if err != nil {
-			return err
-		}
-	} else {
-		cfg, err := rest.InClusterConfig()
-		if err != nil {
-			return err
-		}
-		ibpOperatorClient, err = ibpoperator.New(cfg)
-		if err != nil {
-			return err
-		}
-		k8sClient, err = kube.NewForConfig(cfg)
+	if config.Metrics != nil && config.Metrics.Enabled {
+		d.Metrics = metrics.New(d.LocalConfig.Logger)
+	}
+	d.Tracing, err = tracing.New(d.LocalConfig.Logger, config.Tracing)
+	if err != nil {
+		return errors.Wrap(err, "error configuring tracing")
+	}
+
+	restConfig := d.LocalConfig.KubeConfig
+	if restConfig == nil {
+		restConfig, err = rest.InClusterConfig()
 		if err != nil {
 			return err
 		}
 	}
-	d.IBPOperatorClient = ibpOperatorClient
-	d.K8SClient = k8sClient
+	d.IBPOperatorClient, d.K8SClient, err = d.newClients(restConfig, "")
+	if err != nil {
+		return err
+	}
+
+	d.Authenticator, err = auth.New(d.LocalConfig.Logger, config, d.K8SClient)
+	if err != nil {
+		return errors.Wrap(err, "error configuring authentication")
+	}
+	d.Authorizer, err = NewAuthorizer(config.Authorization)
+	if err != nil {
+		return errors.Wrap(err, "error configuring authorization")
+	}
+	d.Audit, err = audit.New(d.LocalConfig.Logger, config, &http.Client{})
+	if err != nil {
+		return errors.Wrap(err, "error configuring audit log")
+	}
 
 	d.httpServer = &http.Server{
 		Addr:    address,
@@ -158,16 +214,51 @@ func (d *Deployer) Init() error {
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
-	d.CA = ca.New(d.LocalConfig.Logger, d.K8SClient, d.IBPOperatorClient, d.Config)
-	d.Peer = peer.New(d.LocalConfig.Logger, d.K8SClient, d.IBPOperatorClient, d.Config)
-	d.Orderer = orderer.New(d.LocalConfig.Logger, d.K8SClient, d.IBPOperatorClient, d.Config)
 	d.Operator = operator.New(d.LocalConfig.Logger, d.K8SClient)
-	d.Mustgather = mustgather.New(d.LocalConfig.Logger, d.K8SClient, d.Config, &http.Client{})
+	d.Operations = operations.NewStore(operations.DefaultRetention)
+	d.Events = events.New(d.LocalConfig.Logger, events.DefaultHistorySize)
+	d.Bundle = bundle.New(d.LocalConfig.Logger, d.K8SClient, d.IBPOperatorClient)
+	d.Snapshots = snapshot.New(d.LocalConfig.Logger, d.K8SClient, time.Duration(config.Timeouts.Deployment)*time.Millisecond)
+	d.Upgrader = upgrade.New(d.LocalConfig.Logger, d.K8SClient, d.upgradeTargets(""), time.Duration(config.Timeouts.Deployment)*time.Millisecond)
+	d.CapacityChecker = capacity.New(d.LocalConfig.Logger, d.K8SClient)
+	d.Instances = instance.New(d.LocalConfig.Logger, d.K8SClient, d.IBPOperatorClient, config.Namespace)
+	d.OpenAPI = NewOpenAPIDocument()
+
+	d.Targets = map[string]*Target{}
+	for name, cluster := range config.Clusters {
+		d.Targets[name], err = d.newTarget(name, cluster)
+		if err != nil {
+			return err
+		}
+	}
+	d.components.Store(d.newComponents(d.Config, d.LocalConfig.Revision))
+	d.watchComponents()
 
 	d.registerEndpoints()
 	return nil
 }
 
+// configureClientAuth requests client certificates when the mtls authentication
+// method is enabled. Client certificates are optional, requests without one
+// are authenticated by the other authentication methods.
+func (d *Deployer) configureClientAuth(tlsConfig *tls.Config) error {
+	if !d.Config.Authentication.Uses(config.AuthMethodMTLS) || d.Config.TLS.ClientCACertPath == "" {
+		return nil
+	}
+
+	caCerts, err := ioutil.ReadFile(d.Config.TLS.ClientCACertPath)
+	if err != nil {
+		return errors.Wrap(err, "error loading client CA certificates")
+	}
+	tlsConfig.ClientCAs = x509.NewCertPool()
+	if !tlsConfig.ClientCAs.AppendCertsFromPEM(caCerts) {
+		return errors.New("no client CA certificates found")
+	}
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+
+	return nil
+}
+
 func (d *Deployer) CreateListener(address string, tlsConfig *tls.Config) error {
 	var err error
 	if tlsConfig != nil {
@@ -203,52 +294,116 @@ func (d *Deployer) Serve() {
 func (d *Deployer) Stop() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	return d.httpServer.Shutdown(ctx)
+	err := d.httpServer.Shutdown(ctx)
+
+	if d.IBPOperatorClient != nil {
+		d.IBPOperatorClient.StopWatchers()
+	}
+	if d.K8SClient != nil {
+		d.K8SClient.StopWatchers()
+	}
+	for _, target := range d.Targets {
+		target.IBPOperatorClient.StopWatchers()
+		target.K8SClient.StopWatchers()
+	}
+	if d.Tracing != nil {
+		d.Tracing.Shutdown(ctx)
+	}
+
+	return err
 }
 
 func (d *Deployer) registerEndpoints() {
 	r := d.Router
+	r.Use(d.RequestIDMiddleware)
+	r.Use(d.TracingMiddleware)
+	r.Use(d.AccessLogMiddleware)
+	r.Use(d.MetricsMiddleware)
 	r.Use(d.AddHSTSHeaderMiddleware)
-	r.Use(d.BasicAuthMiddleware)
+	r.Use(d.AuthMiddleware)
+	r.Use(d.ClusterMiddleware)
 	r.Handle("/", d)
 	r.Get("/healthcheck", d.healthCheck)
+	r.Get("/api/v3/openapi.json", d.OpenAPIEndpoint())
+	if d.Metrics != nil {
+		r.With(d.AuthorizationMiddleware).Get("/metrics", d.Metrics.Handler().ServeHTTP)
+	}
 
 	// v3 apis
-	// get versions
-	r.Get("/api/v3/instance/{serviceInstanceID}/type/{type}/versions", d.VersionEndpoint())
-	// get all components
-	r.Get("/api/v3/instance/{serviceInstanceID}/type/all", d.GetAllEndpoint())
-	// create components
-	r.Post("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}", d.CreateEndpoint())
-	r.Post("/api/v3/instance/{serviceInstanceID}/precreate/type/orderer/component/{componentName}", d.PrecreatedOrdererEndpoint())
-	// delete individual component
-	r.Delete("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}", d.DeleteEndpoint())
-	// get individual component
-	r.Get("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}", d.GetEndpointSection())
-	r.Get("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}/{section}", d.GetEndpointSection())
-	// update
-	r.Put("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}", d.UpdateEndpointSection())
-	r.Put("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}/{section}", d.UpdateEndpointSection())
-	// patch
-	r.Patch("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}", d.PatchEndpointSection())
-	r.Patch("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}/{section}", d.PatchEndpointSection())
-
-	// hsm config
-	r.Get("/api/v3/instance/{serviceInstanceID}/hsmconfig", d.HSMEndpoint(GET))
-	r.Post("/api/v3/instance/{serviceInstanceID}/hsmconfig", d.HSMEndpoint(POST))
-	r.Patch("/api/v3/instance/{serviceInstanceID}/hsmconfig", d.HSMEndpoint(PATCH))
-	r.Delete("/api/v3/instance/{serviceInstanceID}/hsmconfig", d.HSMEndpoint(DELETE))
-
-	// k8s
-	r.Get("/api/v3/instance/{serviceInstanceID}/k8s/cluster/version", d.K8sVersionEndpoint())
-	r.Get("/api/v3/instance/{serviceInstanceID}/k8s/cluster/type", d.ClusterTypeEndpoint())
-
-	// mustgather
-	r.Get("/api/v3/instance/{serviceInstanceID}/mustgather", d.GetMustgatherEndpoint())
-	r.Post("/api/v3/instance/{serviceInstanceID}/mustgather", d.StartMustgatherEndpoint())
-	r.Delete("/api/v3/instance/{serviceInstanceID}/mustgather", d.StopMustgatherEndpoint())
-	r.Get("/api/v3/instance/{serviceInstanceID}/mustgather/download", d.DownloadMustgatherHandler())
-
+	r.Group(func(r chi.Router) {
+		r.Use(d.AuditMiddleware)
+		r.Use(d.AuthorizationMiddleware)
+		r.Use(d.InstanceMiddleware)
+		r.Use(d.ValidationMiddleware)
+
+		// get versions
+		r.Get("/api/v3/instance/{serviceInstanceID}/type/{type}/versions", d.VersionEndpoint())
+		// check the capacity for a create request
+		r.Get("/api/v3/instance/{serviceInstanceID}/type/{type}/capacity", d.CapacityEndpoint())
+		// get the resources of all the components
+		r.Get("/api/v3/instance/{serviceInstanceID}/resources", d.ResourcesEndpoint())
+		// get the revision of the configuration in use
+		r.Get("/api/v3/instance/{serviceInstanceID}/config/revision", d.ConfigRevisionEndpoint())
+		// get all components
+		r.Get("/api/v3/instance/{serviceInstanceID}/type/all", d.GetAllEndpoint())
+		// create components
+		r.Post("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}", d.CreateEndpoint())
+		r.Post("/api/v3/instance/{serviceInstanceID}/precreate/type/orderer/component/{componentName}", d.PrecreatedOrdererEndpoint())
+
+		// Apply a manifest of components
+		r.Post("/api/v3/instance/{serviceInstanceID}/apply", d.ApplyEndpoint())
+
+		// Upgrade the versions of all the components
+		r.Post("/api/v3/instance/{serviceInstanceID}/upgrade", d.UpgradeEndpoint())
+		r.Post("/api/v3/instance/{serviceInstanceID}/upgrade/{operationID}/resume", d.ResumeUpgradeEndpoint())
+		r.Post("/api/v3/instance/{serviceInstanceID}/upgrade/{operationID}/abort", d.AbortUpgradeEndpoint())
+
+		// Export and import the component definitions
+		r.Get("/api/v3/instance/{serviceInstanceID}/export", d.ExportHandler())
+		r.Post("/api/v3/instance/{serviceInstanceID}/import", d.ImportEndpoint())
+		// delete individual component
+		r.Delete("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}", d.DeleteEndpoint())
+		// Snapshots of the volumes of peers and orderers
+		r.Get("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}/snapshot", d.ListSnapshotsEndpoint())
+		r.Put("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}/snapshot", d.CreateSnapshotEndpoint())
+		r.Put("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}/restore", d.RestoreSnapshotEndpoint())
+
+		// get individual component
+		r.Get("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}", d.GetEndpointSection())
+		r.Get("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}/{section}", d.GetEndpointSection())
+		// update
+		r.Put("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}", d.UpdateEndpointSection())
+		r.Put("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}/{section}", d.UpdateEndpointSection())
+		// patch
+		r.Patch("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}", d.PatchEndpointSection())
+		r.Patch("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}/{section}", d.PatchEndpointSection())
+
+		// operations
+		r.Get("/api/v3/instance/{serviceInstanceID}/operations", d.ListOperationsEndpoint())
+		r.Get("/api/v3/instance/{serviceInstanceID}/operations/{operationID}", d.GetOperationEndpoint())
+
+		// events
+		r.Get("/api/v3/instance/{serviceInstanceID}/events", d.EventsHandler())
+
+		// audit
+		r.Get("/api/v3/instance/{serviceInstanceID}/audit", d.AuditEndpoint())
+
+		// hsm config
+		r.Get("/api/v3/instance/{serviceInstanceID}/hsmconfig", d.HSMEndpoint(GET))
+		r.Post("/api/v3/instance/{serviceInstanceID}/hsmconfig", d.HSMEndpoint(POST))
+		r.Patch("/api/v3/instance/{serviceInstanceID}/hsmconfig", d.HSMEndpoint(PATCH))
+		r.Delete("/api/v3/instance/{serviceInstanceID}/hsmconfig", d.HSMEndpoint(DELETE))
+
+		// k8s
+		r.Get("/api/v3/instance/{serviceInstanceID}/k8s/cluster/version", d.K8sVersionEndpoint())
+		r.Get("/api/v3/instance/{serviceInstanceID}/k8s/cluster/type", d.ClusterTypeEndpoint())
+
+		// mustgather
+		r.Get("/api/v3/instance/{serviceInstanceID}/mustgather", d.GetMustgatherEndpoint())
+		r.Post("/api/v3/instance/{serviceInstanceID}/mustgather", d.StartMustgatherEndpoint())
+		r.Delete("/api/v3/instance/{serviceInstanceID}/mustgather", d.StopMustgatherEndpoint())
+		r.Get("/api/v3/instance/{serviceInstanceID}/mustgather/download", d.DownloadMustgatherHandler())
+	})
 }
 
 func (d *Deployer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -265,36 +420,28 @@ func (d *Deployer) AddHSTSHeaderMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func (d *Deployer) BasicAuthMiddleware(next http.Handler) http.Handler {
+// AuthMiddleware authenticates the requests and stores the principal in the
+// request context
+func (d *Deployer) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		user, err := d.BasicAuth(r)
+		principal, err := d.Authenticator.Authenticate(r)
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			_, err := w.Write([]byte("Unauthorized"))
-			if err != nil {
-				d.Logger.Errorw("Error writing to HTTP response", err)
-			}
+			d.Logger.Debugf("Request to '%s' not authenticated: %s", r.URL.Path, err)
+			w.Header().Add("WWW-Authenticate", `Basic realm="deployer"`)
+			w.Header().Add("WWW-Authenticate", `Bearer realm="deployer"`)
+			NewEndpoint(func(http.ResponseWriter, *http.Request) (interface{}, int, error) {
+				return nil, 0, apierror.New(apierror.Unauthorized, apierror.CodeUnauthenticated, "unauthorized")
+			}, d.LocalConfig.Logger).ServeHTTP(w, r)
 			return
 		}
-		ctx := context.WithValue(r.Context(), "user", user)
+		if entry := accessEntryOf(r.Context()); entry != nil {
+			entry.principal = principal.Name
+		}
+		ctx := auth.WithPrincipal(r.Context(), principal)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-func (d *Deployer) BasicAuth(r *http.Request) (string, error) {
-	user, pass, ok := r.BasicAuth()
-
-	if !ok {
-		return "", errors.New("failed to authorize")
-	}
-
-	if user != d.Config.Auth.Username || pass != d.Config.Auth.Password {
-		return "", errors.New("unauthorized")
-	}
-
-	return user, nil
-}
-
 func (d *Deployer) healthCheck(w http.ResponseWriter, r *http.Request) {
 	d.Logger.Infof("incoming request to get deployer healthcheck")
 	_, err := w.Write([]byte("Deployer reporting all ok"))
@@ -324,6 +471,14 @@ func (d *Deployer) CreateEndpoint() func(http.ResponseWriter, *http.Request) {
 	return NewEndpoint(d.Create, d.LocalConfig.Logger).ServeHTTP
 }
 
+func (d *Deployer) ListOperationsEndpoint() func(http.ResponseWriter, *http.Request) {
+	return NewEndpoint(d.ListOperations, d.LocalConfig.Logger).ServeHTTP
+}
+
+func (d *Deployer) GetOperationEndpoint() func(http.ResponseWriter, *http.Request) {
+	return NewEndpoint(d.GetOperation, d.LocalConfig.Logger).ServeHTTP
+}
+
 func (d *Deployer) PrecreatedOrdererEndpoint() func(http.ResponseWriter, *http.Request) {
 	return NewEndpoint(d.PrecreateOrderer, d.LocalConfig.Logger).ServeHTTP
 }
@@ -363,31 +518,65 @@ func (d *Deployer) PatchEndpointSection() func(http.ResponseWriter, *http.Reques
 	return NewEndpoint(d.PatchSection, d.LocalConfig.Logger).ServeHTTP
 }
 
+// GetAll lists the components of the service instance in the cluster of the
+// request, or in all the clusters with the allClusters query parameter
 func (d *Deployer) GetAll(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
 	sID := chi.URLParam(r, "serviceInstanceID")
 
+	if !strings.EqualFold(r.URL.Query().Get("allClusters"), "true") {
+		return getAll(d.clusterComponents(r), sID, d.namespace(r), "")
+	}
+
+	components := d.Components()
+	response := []interface{}{}
+	for _, target := range d.allTargets() {
+		namespace, err := target.Instances.NamespaceOf(components.For(target.Name).Config.Instances, sID)
+		if apierror.KindOf(err) == apierror.NotFound {
+			// the service instance has no namespace in the cluster
+			continue
+		}
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "failed to get the components of cluster '%s'", target.Name)
+		}
+
+		list, statusCode, err := getAll(components.For(target.Name), sID, namespace, target.Name)
+		if err != nil {
+			return nil, statusCode, errors.Wrapf(err, "failed to get the components of cluster '%s'", target.Name)
+		}
+		response = append(response, list...)
+	}
+
+	return response, 0, nil
+}
+
+// getAll lists the components of the service instance in the namespace, the
+// cluster is set on the components if it is not empty
+func getAll(components *Components, sID, namespace, cluster string) ([]interface{}, int, error) {
 	var response []interface{}
-	cas, _, err := d.CA.GetAllCR(sID, d.Config.Namespace)
+	cas, _, err := components.CA.GetAllCR(sID, namespace)
 	if err != nil {
 		return nil, 0, err
 	}
 	for _, ca := range cas {
+		ca.Cluster = cluster
 		response = append(response, ca)
 	}
 
-	orderers, statusCode, err := d.Orderer.GetAllCR(sID, d.Config.Namespace)
+	orderers, statusCode, err := components.Orderer.GetAllCR(sID, namespace)
 	if err != nil {
 		return nil, statusCode, err
 	}
 	for _, orderer := range orderers {
+		orderer.Cluster = cluster
 		response = append(response, orderer)
 	}
 
-	peers, statusCode, err := d.Peer.GetAllCR(sID, d.Config.Namespace)
+	peers, statusCode, err := components.Peer.GetAllCR(sID, namespace)
 	if err != nil {
 		return nil, statusCode, err
 	}
 	for _, peer := range peers {
+		peer.Cluster = cluster
 		response = append(response, peer)
 	}
 
@@ -398,31 +587,145 @@ func (d *Deployer) Create(w http.ResponseWriter, r *http.Request) (interface{},
 	typeOfComponent := chi.URLParam(r, "type")
 	sID := chi.URLParam(r, "serviceInstanceID")
 	compName := chi.URLParam(r, "componentName")
+	namespace := d.namespace(r)
+	components := d.clusterComponents(r)
 
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		return nil, 0, errors.New("failed to ready request body")
 	}
 
+	switch typeOfComponent {
+	case "ca", "peer", "orderer":
+	default:
+		return nil, 0, apierror.UnsupportedComponentType(typeOfComponent)
+	}
+
+	if isDryRun(r) {
+		return dryRunCreate(components, typeOfComponent, compName, namespace, body)
+	}
+
+	err = d.checkCapacity(r, typeOfComponent, compName, namespace, body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if !isAsyncRequest(r) {
+		return create(components, typeOfComponent, sID, compName, namespace, body, nil)
+	}
+
+	op := d.Operations.Start(sID, operations.CREATE, typeOfComponent, compName)
+	go func() {
+		resp, statusCode, err := create(components, typeOfComponent, sID, compName, namespace, body, op.UpdateNode)
+		if err != nil {
+			d.Logger.Errorf("Operation '%s' to create %s '%s' failed: %s", op.ID, typeOfComponent, compName, err)
+		}
+		op.Finish(resp, statusCode, err)
+	}()
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v3/instance/%s/operations/%s", sID, op.ID))
+	w.Header().Set("Preference-Applied", "respond-async")
+	return op.Snapshot(), http.StatusAccepted, nil
+}
+
+// create deploys the component, progress is called with the state of each
+// node as it is deployed
+func create(components *Components, typeOfComponent, sID, compName, namespace string, body []byte, progress common.NodeProgressFunc) (interface{}, int, error) {
+	if progress == nil {
+		progress = func(string, int, string, error) {}
+	}
+
 	switch typeOfComponent {
 	case "ca":
-		return d.CA.CreateCR(d.Config.Domain, sID, compName, d.Config.Namespace, body)
+		progress(compName, 1, common.NodeStateDeploying, nil)
+		resp, statusCode, err := components.CA.CreateCR(components.Config.Domain, sID, compName, namespace, body)
+		reportNode(progress, compName, err)
+		return resp, statusCode, err
 	case "peer":
-		return d.Peer.CreateCR(d.Config.Domain, sID, compName, d.Config.Namespace, body)
+		progress(compName, 1, common.NodeStateDeploying, nil)
+		resp, statusCode, err := components.Peer.CreateCR(components.Config.Domain, sID, compName, namespace, body)
+		reportNode(progress, compName, err)
+		return resp, statusCode, err
 	case "orderer":
-		return d.Orderer.CreateCR(d.Config.Domain, sID, compName, d.Config.Namespace, body)
+		return components.Orderer.CreateCRWithProgress(components.Config.Domain, sID, compName, namespace, body, progress)
 	}
 
-	return nil, 0, errors.Errorf("Component type not supported: %d", http.StatusBadRequest)
+	return nil, 0, apierror.UnsupportedComponentType(typeOfComponent)
+}
+
+// dryRunCreate renders the CR that would be created for the component
+func dryRunCreate(components *Components, typeOfComponent, compName, namespace string, body []byte) (interface{}, int, error) {
+	switch typeOfComponent {
+	case "ca":
+		return components.CA.DryRunCreateCR(compName, namespace, body)
+	case "peer":
+		return components.Peer.DryRunCreateCR(compName, namespace, body)
+	case "orderer":
+		return components.Orderer.DryRunCreateCR(compName, namespace, body)
+	}
+
+	return nil, 0, apierror.UnsupportedComponentType(typeOfComponent)
+}
+
+func reportNode(progress common.NodeProgressFunc, nodeName string, err error) {
+	if err != nil {
+		progress(nodeName, 1, common.NodeStateFailed, err)
+		return
+	}
+	progress(nodeName, 1, common.NodeStateDeployed, nil)
+}
+
+// isAsyncRequest returns true if the client asked for the request to be
+// processed in the background, either with the async query parameter or
+// with the respond-async preference (RFC 7240)
+func isAsyncRequest(r *http.Request) bool {
+	if async := r.URL.Query().Get("async"); async != "" {
+		return strings.EqualFold(async, "true")
+	}
+
+	for _, prefer := range r.Header.Values("Prefer") {
+		for _, pref := range strings.Split(prefer, ",") {
+			if strings.EqualFold(strings.TrimSpace(pref), "respond-async") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isDryRun returns true if the client asked for the CR to be rendered and
+// returned without being written
+func isDryRun(r *http.Request) bool {
+	return strings.EqualFold(r.URL.Query().Get("dryRun"), "true")
+}
+
+func (d *Deployer) ListOperations(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	sID := chi.URLParam(r, "serviceInstanceID")
+
+	return d.Operations.List(sID), http.StatusOK, nil
+}
+
+func (d *Deployer) GetOperation(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	sID := chi.URLParam(r, "serviceInstanceID")
+	opID := chi.URLParam(r, "operationID")
+
+	op, found := d.Operations.Get(sID, opID)
+	if !found {
+		return nil, http.StatusNotFound, apierror.New(apierror.NotFound, apierror.CodeNotFound, "operation '%s' not found", opID)
+	}
+
+	return op, http.StatusOK, nil
 }
 
 func (d *Deployer) Delete(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
 	typeOfComponent := chi.URLParam(r, "type")
 	sID := chi.URLParam(r, "serviceInstanceID")
+	namespace := d.namespace(r)
 	compName := chi.URLParam(r, "componentName")
 
 	if compName == "" {
-		return nil, 0, errors.New("Name of the component to be deleted is required")
+		return nil, 0, apierror.InvalidField("componentName", "Name of the component to be deleted is required")
 	}
 
 	body, err := ioutil.ReadAll(r.Body)
@@ -432,14 +735,14 @@ func (d *Deployer) Delete(w http.ResponseWriter, r *http.Request) (interface{},
 
 	switch typeOfComponent {
 	case "ca":
-		return d.CA.DeleteCR(sID, compName, d.Config.Namespace, body)
+		return d.clusterComponents(r).CA.DeleteCR(sID, compName, namespace, body)
 	case "peer":
-		return d.Peer.DeleteCR(sID, compName, d.Config.Namespace, body)
+		return d.clusterComponents(r).Peer.DeleteCR(sID, compName, namespace, body)
 	case "orderer":
-		return d.Orderer.DeleteCR(sID, compName, d.Config.Namespace, body)
+		return d.clusterComponents(r).Orderer.DeleteCR(sID, compName, namespace, body)
 	}
 
-	return nil, 0, errors.Errorf("Component type not supported: %d", http.StatusBadRequest)
+	return nil, 0, apierror.UnsupportedComponentType(typeOfComponent)
 }
 
 func (d *Deployer) PrecreateOrderer(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
@@ -450,17 +753,19 @@ func (d *Deployer) PrecreateOrderer(w http.ResponseWriter, r *http.Request) (int
 		return nil, 0, errors.New("failed to ready request body")
 	}
 
-	return d.Orderer.PrecreateCR(d.Config.Domain, sID, body, compName)
+	components := d.clusterComponents(r)
+	return components.Orderer.PrecreateCR(components.Config.Domain, sID, body, compName, d.namespace(r))
 }
 
 func (d *Deployer) GetSection(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
 	typeOfComponent := chi.URLParam(r, "type")
 	sID := chi.URLParam(r, "serviceInstanceID")
+	namespace := d.namespace(r)
 	compName := chi.URLParam(r, "componentName")
 	section := chi.URLParam(r, "section")
 
 	if compName == "" {
-		return nil, 0, errors.New("Name of the component to get is required")
+		return nil, 0, apierror.InvalidField("componentName", "Name of the component to get is required")
 	}
 
 	// If no section is provided, treat it like request to patch entire component
@@ -468,24 +773,46 @@ func (d *Deployer) GetSection(w http.ResponseWriter, r *http.Request) (interface
 		section = orderer.ALL
 	}
 
+	var resp interface{}
+	var statusCode int
+	var err error
 	switch typeOfComponent {
 	case "ca":
-		return d.CA.GetCR(section, compName, d.Config.Namespace, sID)
+		resp, statusCode, err = d.clusterComponents(r).CA.GetCR(section, compName, namespace, sID)
 	case "peer":
-		return d.Peer.GetCR(section, compName, d.Config.Namespace, sID)
+		resp, statusCode, err = d.clusterComponents(r).Peer.GetCR(section, compName, namespace, sID)
 	case "orderer":
-		return d.Orderer.GetCR(section, compName, d.Config.Namespace, sID)
+		resp, statusCode, err = d.clusterComponents(r).Orderer.GetCR(section, compName, namespace, sID)
+	default:
+		return nil, 0, apierror.UnsupportedComponentType(typeOfComponent)
 	}
 
-	return nil, 0, errors.Errorf("Component type not supported: %d", http.StatusBadRequest)
+	return withETag(w, resp, statusCode, err)
+}
+
+// withETag sets the ETag header to the resource version of the CR of the
+// component in the response, for clients to send back in If-Match
+func withETag(w http.ResponseWriter, resp interface{}, statusCode int, err error) (interface{}, int, error) {
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	if versioned, ok := resp.(interface{ GetResourceVersion() string }); ok {
+		if etag := common.ETag(versioned.GetResourceVersion()); etag != "" {
+			w.Header().Set("ETag", etag)
+		}
+	}
+
+	return resp, statusCode, nil
 }
 
 func (d *Deployer) UpdateSection(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
 	sID := chi.URLParam(r, "serviceInstanceID")
+	namespace := d.namespace(r)
 	compName := chi.URLParam(r, "componentName")
 	section := chi.URLParam(r, "section")
 	if compName == "" {
-		return nil, 0, errors.New("Name of the component to get is required")
+		return nil, 0, apierror.InvalidField("componentName", "Name of the component to get is required")
 	}
 
 	// If no section is provided, treat it like request to patch entire component
@@ -499,27 +826,45 @@ func (d *Deployer) UpdateSection(w http.ResponseWriter, r *http.Request) (interf
 	}
 
 	typeOfComponent := chi.URLParam(r, "type")
+	if isDryRun(r) {
+		switch typeOfComponent {
+		case "ca":
+			return d.clusterComponents(r).CA.DryRunUpdateCR(section, compName, namespace, r.Header.Get("If-Match"), body)
+		case "peer":
+			return d.clusterComponents(r).Peer.DryRunUpdateCR(section, compName, namespace, r.Header.Get("If-Match"), body)
+		case "orderer":
+			return d.clusterComponents(r).Orderer.DryRunUpdateCR(section, compName, namespace, r.Header.Get("If-Match"), body)
+		}
+		return nil, 0, apierror.UnsupportedComponentType(typeOfComponent)
+	}
+
+	ifMatch := r.Header.Get("If-Match")
+	var resp interface{}
+	var statusCode int
 	switch typeOfComponent {
 	case "ca":
-		return d.CA.UpdateCR(section, compName, d.Config.Namespace, sID, body)
+		resp, statusCode, err = d.clusterComponents(r).CA.UpdateCRIfMatch(section, compName, namespace, sID, ifMatch, body)
 	case "peer":
-		return d.Peer.UpdateCR(section, compName, d.Config.Namespace, sID, body)
+		resp, statusCode, err = d.clusterComponents(r).Peer.UpdateCRIfMatch(section, compName, namespace, sID, ifMatch, body)
 	case "orderer":
-		return d.Orderer.UpdateCR(section, compName, d.Config.Namespace, sID, body)
+		resp, statusCode, err = d.clusterComponents(r).Orderer.UpdateCRIfMatch(section, compName, namespace, sID, ifMatch, body)
+	default:
+		return nil, 0, apierror.UnsupportedComponentType(typeOfComponent)
 	}
 
-	return nil, 0, errors.Errorf("Component type not supported: %d", http.StatusBadRequest)
+	return withETag(w, resp, statusCode, err)
 }
 
 func (d *Deployer) PatchSection(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
 	typeOfComponent := chi.URLParam(r, "type")
 
 	sID := chi.URLParam(r, "serviceInstanceID")
+	namespace := d.namespace(r)
 	section := chi.URLParam(r, "section")
 	compName := chi.URLParam(r, "componentName")
 
 	if compName == "" {
-		return nil, 400, errors.New("Name of the component to patch is required")
+		return nil, 400, apierror.InvalidField("componentName", "Name of the component to patch is required")
 	}
 
 	// If no section is provided, treat it like request to patch entire component
@@ -532,22 +877,39 @@ func (d *Deployer) PatchSection(w http.ResponseWriter, r *http.Request) (interfa
 		return nil, 500, errors.New("failed to ready request body")
 	}
 
+	if isDryRun(r) {
+		switch typeOfComponent {
+		case "ca":
+			return d.clusterComponents(r).CA.DryRunPatchCR(section, compName, namespace, r.Header.Get("If-Match"), body)
+		case "peer":
+			return d.clusterComponents(r).Peer.DryRunPatchCR(section, compName, namespace, r.Header.Get("If-Match"), body)
+		case "orderer":
+			return d.clusterComponents(r).Orderer.DryRunPatchCR(section, compName, namespace, r.Header.Get("If-Match"), body)
+		}
+		return nil, 0, apierror.UnsupportedComponentType(typeOfComponent)
+	}
+
+	ifMatch := r.Header.Get("If-Match")
+	var resp interface{}
+	var statusCode int
 	switch typeOfComponent {
 	case "ca":
-		return d.CA.PatchCR(section, compName, d.Config.Namespace, sID, body)
+		resp, statusCode, err = d.clusterComponents(r).CA.PatchCRIfMatch(section, compName, namespace, sID, ifMatch, body)
 	case "peer":
-		return d.Peer.PatchCR(section, compName, d.Config.Namespace, sID, body)
+		resp, statusCode, err = d.clusterComponents(r).Peer.PatchCRIfMatch(section, compName, namespace, sID, ifMatch, body)
 	case "orderer":
-		return d.Orderer.PatchCR(section, compName, d.Config.Namespace, sID, body)
+		resp, statusCode, err = d.clusterComponents(r).Orderer.PatchCRIfMatch(section, compName, namespace, sID, ifMatch, body)
+	default:
+		return nil, 0, apierror.UnsupportedComponentType(typeOfComponent)
 	}
 
-	return nil, 0, errors.Errorf("Component type not supported: %d", http.StatusBadRequest)
+	return withETag(w, resp, statusCode, err)
 }
 
 func (d *Deployer) Version(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
 	typeOfComponent := chi.URLParam(r, "type")
 
-	versions := d.Config.Versions
+	versions := d.clusterComponents(r).Config.Versions
 	if typeOfComponent == "ca" {
 		return common.VersionResponseCA{
 			Versions: versions.CA,
@@ -576,27 +938,34 @@ func (d *Deployer) Version(w http.ResponseWriter, r *http.Request) (interface{},
 }
 
 func (d *Deployer) GetHSMConfigEndpoint(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
-	return d.Operator.Get(operator.HSMCONFIG, d.Config.Namespace)
+	return d.target(r).Operator.Get(operator.HSMCONFIG, d.namespace(r))
 }
 
 func (d *Deployer) UpdateHSMConfigEndpoint(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
-	// PLACEHOLDER
-	return nil, 0, errors.Errorf("Request type not yet supported: %d", http.StatusBadRequest)
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, 500, errors.New("failed to ready request body")
+	}
+
+	return d.target(r).Operator.Update(operator.HSMCONFIG, d.namespace(r), body)
 }
 
 func (d *Deployer) PatchHSMConfigEndpoint(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
-	// PLACEHOLDER
-	return nil, 0, errors.Errorf("Request type not yet supported: %d", http.StatusBadRequest)
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, 500, errors.New("failed to ready request body")
+	}
+
+	return d.target(r).Operator.Patch(operator.HSMCONFIG, d.namespace(r), body)
 }
 
 func (d *Deployer) DeleteHSMConfigEndpoint(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
-	// PLACEHOLDER
-	return nil, 0, errors.Errorf("Request type not yet supported: %d", http.StatusBadRequest)
+	return d.target(r).Operator.Delete(operator.HSMCONFIG, d.namespace(r))
 }
 
 // ClusterVersionHandler will handle getting kubernetes cluster version
 func (d *Deployer) ClusterVersionHandler(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
-	version, err := d.K8SClient.GetVersion()
+	version, err := d.target(r).K8SClient.GetVersion()
 	if err != nil {
 		return nil, 500, errors.Wrap(err, "failed to get kubernetes server version")
 	}
@@ -620,7 +989,14 @@ func (d *Deployer) DownloadMustgatherHandler() func(http.ResponseWriter, *http.R
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Essentially proxy the request through to download
 		d.Logger.Infof("incoming request to download mustgater content")
-		resp, respErr := d.Mustgather.Download()
+		mustgather, respErr := d.mustgather(r)
+		if respErr != nil {
+			NewEndpoint(func(http.ResponseWriter, *http.Request) (interface{}, int, error) {
+				return nil, 0, respErr
+			}, d.LocalConfig.Logger).ServeHTTP(w, r)
+			return
+		}
+		resp, respErr := mustgather.Download()
 		if respErr != nil {
 			http.Error(w, respErr.Error(), http.StatusInternalServerError)
 			d.Logger.Errorf("error occured while trying to get response for download file", respErr.Error())
@@ -649,26 +1025,58 @@ func (d *Deployer) DownloadMustgatherHandler() func(http.ResponseWriter, *http.R
 
 }
 
+// EventsHandler streams the status changes of the components of the service
+// instance as server-sent events. The CR watches of a namespace are started
+// with its first stream.
+func (d *Deployer) EventsHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		namespace := d.namespace(r)
+		d.watchEvents(r, namespace)
+
+		d.Logger.Infof("incoming request to stream events")
+		filter := instanceEvents(chi.URLParam(r, "serviceInstanceID"), clusterOf(r), namespace)
+		err := d.Events.Stream(w, r, events.DefaultHeartbeat, filter)
+		if err != nil {
+			d.Logger.Errorf("error occured while streaming events: %s", err)
+			return
+		}
+		d.Logger.Infof("request to stream events completed")
+	}
+}
+
 func (d *Deployer) GetMustgatherStatus(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
-	status, err := d.Mustgather.Status()
+	mustgather, err := d.mustgather(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	status, err := mustgather.Status()
 	return status, 200, err
 }
 
 func (d *Deployer) StartMustgather(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
 	d.Logger.Infof("incoming request to start mustgather")
-	err := d.Mustgather.Create()
+	mustgather, err := d.mustgather(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	err = mustgather.Create()
 	d.Logger.Infof("request to start mustgather completed")
 	return nil, 201, err
 }
 
 func (d *Deployer) StopMustgather(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
 	d.Logger.Infof("incoming request to stop mustgather")
-	err := d.Mustgather.Delete()
+	mustgather, err := d.mustgather(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	err = mustgather.Delete()
 	d.Logger.Infof("request to stop mustgather completed")
 	return nil, 200, err
 }
 
 // ClusterTypeHandler will handle returning the clustertype kubernetes cluster version
 func (d *Deployer) ClusterTypeHandler(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
-	return d.K8SClient.ClusterType(d.Config.Namespace), 0, nil
+	target := d.target(r)
+	return target.K8SClient.ClusterType(target.Config.Namespace), 0, nil
 }
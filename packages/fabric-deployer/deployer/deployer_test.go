@@ -18,19 +18,45 @@
 package deployer_test
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"crypto/tls"
-	"io/ioutil"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/IBM-Blockchain/fabric-deployer/config"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/auth"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/bundle"
+	bundlemocks "github.com/IBM-Blockchain/fabric-deployer/deployer/bundle/mocks"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/capacity"
+	capacitymocks "github.com/IBM-Blockchain/fabric-deployer/deployer/capacity/mocks"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/instance"
+	instancemocks "github.com/IBM-Blockchain/fabric-deployer/deployer/instance/mocks"
 	"github.com/IBM-Blockchain/fabric-deployer/deployer/kube"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/operations"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/upgrade"
+	upgrademocks "github.com/IBM-Blockchain/fabric-deployer/deployer/upgrade/mocks"
 	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
 )
 
@@ -138,21 +164,31 @@ var _ = Describe("Deployer", func() {
 		)
 
 		BeforeEach(func() {
+			err = d.Init()
+			Expect(err).NotTo(HaveOccurred())
 			testReq, err = http.NewRequest("GET", "0.0.0.0", nil)
 			Expect(err).To(BeNil())
 		})
 
 		It("fails with incorrect credentials", func() {
 			testReq.SetBasicAuth("admin", "badpass")
-			_, err := d.BasicAuth(testReq)
+			_, err := d.Authenticator.Authenticate(testReq)
 			Expect(err).NotTo(BeNil())
 		})
 
 		It("passes with correct credentials", func() {
 			testReq.SetBasicAuth("admin", "adminpw")
-			_, err := d.BasicAuth(testReq)
+			_, err := d.Authenticator.Authenticate(testReq)
 			Expect(err).To(BeNil())
 		})
+
+		It("returns an error if an authentication method is not supported", func() {
+			d.Config.Authentication = &config.Authentication{
+				Methods: []string{"unknown"},
+			}
+			err := d.Init()
+			Expect(err).To(MatchError(ContainSubstring("authentication method 'unknown' not supported")))
+		})
 	})
 
 	Context("Create Listener", func() {
@@ -189,62 +225,614 @@ var _ = Describe("Deployer", func() {
 		})
 	})
 
-	Context("Basic Auth Middleware", func() {
+	Context("Auth Middleware", func() {
 		var (
 			req *http.Request
 			w   *httptest.ResponseRecorder
 		)
 
 		BeforeEach(func() {
+			err := d.Init()
+			Expect(err).NotTo(HaveOccurred())
 			req = httptest.NewRequest(http.MethodHead, "http://localhost:8080", nil)
 			w = httptest.NewRecorder()
 		})
 
 		It("returns an error if missing authorization header", func() {
-			h := d.BasicAuthMiddleware(&missingAuthHandler{})
+			h := d.AuthMiddleware(&missingAuthHandler{})
 			h.ServeHTTP(w, req)
 
 			result := w.Result()
 			Expect(result.StatusCode).To(Equal(http.StatusUnauthorized))
-			body, err := ioutil.ReadAll(result.Body)
+			Expect(result.Header.Values("WWW-Authenticate")).To(ContainElement(`Basic realm="deployer"`))
+			errs := &deployer.Errors{}
+			Expect(json.NewDecoder(result.Body).Decode(errs)).To(Succeed())
+			Expect(errs.Status).To(Equal(http.StatusUnauthorized))
+			Expect(errs.Kind).To(Equal(apierror.Unauthorized))
+			Expect(errs.Code).To(Equal(apierror.CodeUnauthenticated))
+		})
+
+		It("returns an error if incorrect username and password used", func() {
+			req.SetBasicAuth("a", "b")
+			h := d.AuthMiddleware(&missingAuthHandler{})
+			h.ServeHTTP(w, req)
+
+			Expect(w.Result().StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("sets the request context with the principal", func() {
+			req.SetBasicAuth("admin", "adminpw")
+			h := d.AuthMiddleware(&properAuthHandler{})
+			h.ServeHTTP(w, req)
+
+			Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("operations", func() {
+		var (
+			req *http.Request
+			w   *httptest.ResponseRecorder
+		)
+
+		BeforeEach(func() {
+			err := d.Init()
 			Expect(err).NotTo(HaveOccurred())
-			Expect(string(body)).To(Equal("Unauthorized"))
+			w = httptest.NewRecorder()
 		})
 
-		It("sets the request context with user name", func() {
-			d.Config.Auth = config.BasicAuth{
-				Username: "admin",
-				Password: "adminpw",
+		It("returns a 404 if the operation does not exist", func() {
+			req = httptest.NewRequest(http.MethodGet, "/api/v3/instance/sid/operations/missing", nil)
+			req.SetBasicAuth("admin", "adminpw")
+			d.Router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("returns a 404 if the operation belongs to another instance", func() {
+			op := d.Operations.Start("othersid", operations.CREATE, "ca", "ca1")
+			req = httptest.NewRequest(http.MethodGet, "/api/v3/instance/sid/operations/"+op.ID, nil)
+			req.SetBasicAuth("admin", "adminpw")
+			d.Router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("returns the operation", func() {
+			op := d.Operations.Start("sid", operations.CREATE, "ca", "ca1")
+			req = httptest.NewRequest(http.MethodGet, "/api/v3/instance/sid/operations/"+op.ID, nil)
+			req.SetBasicAuth("admin", "adminpw")
+			d.Router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring(`"id":"` + op.ID + `"`))
+			Expect(w.Body.String()).To(ContainSubstring(`"status":"running"`))
+		})
+
+		It("lists the operations of the instance", func() {
+			d.Operations.Start("sid", operations.CREATE, "ca", "ca1")
+			d.Operations.Start("othersid", operations.CREATE, "ca", "ca2")
+			req = httptest.NewRequest(http.MethodGet, "/api/v3/instance/sid/operations", nil)
+			req.SetBasicAuth("admin", "adminpw")
+			d.Router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring(`"componentName":"ca1"`))
+			Expect(w.Body.String()).NotTo(ContainSubstring(`"componentName":"ca2"`))
+		})
+	})
+
+	Context("import", func() {
+		var (
+			w             *httptest.ResponseRecorder
+			mockIBPClient *bundlemocks.IBPOperatorClient
+			mockKube      *bundlemocks.Kube
+			archive       *bytes.Buffer
+		)
+
+		BeforeEach(func() {
+			err := d.Init()
+			Expect(err).NotTo(HaveOccurred())
+			w = httptest.NewRecorder()
+
+			mockIBPClient = &bundlemocks.IBPOperatorClient{}
+			mockKube = &bundlemocks.Kube{}
+			d.Bundle = bundle.New(zap.NewNop(), mockKube, mockIBPClient)
+			mockIBPClient.GetCRReturns(k8serrors.NewNotFound(schema.GroupResource{}, ""))
+
+			archive = &bytes.Buffer{}
+			err = bundle.Write(archive, &bundle.Bundle{
+				Peers: []current.IBPPeer{{ObjectMeta: metav1.ObjectMeta{Name: "peer1"}}},
+			}, "passphrase")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		importArchive := func(path string) {
+			req := httptest.NewRequest(http.MethodPost, path, archive)
+			req.SetBasicAuth("admin", "adminpw")
+			req.Header.Set(deployer.PassphraseHeader, "passphrase")
+			d.Router.ServeHTTP(w, req)
+		}
+
+		It("creates the components with the mapping of the query", func() {
+			importArchive("/api/v3/instance/sid/import?domain=new.example.com")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring(`"status":"created"`))
+
+			_, kind, cr := mockIBPClient.CreateCRArgsForCall(0)
+			Expect(kind).To(Equal("ibppeers"))
+			Expect(cr.(*current.IBPPeer).Spec.Domain).To(Equal("new.example.com"))
+		})
+
+		It("returns a 409 with the conflicts", func() {
+			mockIBPClient.GetCRReturns(nil)
+			importArchive("/api/v3/instance/sid/import")
+			Expect(w.Code).To(Equal(http.StatusConflict))
+			Expect(w.Body.String()).To(ContainSubstring(`"conflicts":1`))
+			Expect(mockIBPClient.CreateCRCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("upgrade", func() {
+		var (
+			w        *httptest.ResponseRecorder
+			mockKube *upgrademocks.Kube
+			peers    *upgrademocks.Target
+		)
+
+		BeforeEach(func() {
+			err := d.Init()
+			Expect(err).NotTo(HaveOccurred())
+			w = httptest.NewRecorder()
+
+			mockKube = &upgrademocks.Kube{}
+			mockKube.GetPodsByLabelStub = func(namespace, name string) (*corev1.Pod, error) {
+				return &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(time.Now().Add(time.Second))},
+					Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+				}, nil
 			}
+			peers = &upgrademocks.Target{}
+			peers.NodesReturns([]upgrade.Node{{Name: "peer1", FromVersion: "2.2.10"}}, nil)
+			peers.WaitForStatusStub = func(ctx context.Context, namespace, name string, cond func(*current.CRStatus) (bool, error)) (*current.CRStatus, error) {
+				status := &current.CRStatus{Type: current.Deployed}
+				_, err := cond(status)
+				return status, err
+			}
+			d.Upgrader = upgrade.New(zap.NewNop(), mockKube, map[string]upgrade.Target{upgrade.TypePeer: peers}, time.Second)
+		})
 
+		post := func(path, body string) *httptest.ResponseRecorder {
+			w = httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
 			req.SetBasicAuth("admin", "adminpw")
-			h := d.BasicAuthMiddleware(&properAuthHandler{})
-			h.ServeHTTP(w, req)
+			d.Router.ServeHTTP(w, req)
+			return w
+		}
+
+		operationStatus := func(id string) func() string {
+			return func() string {
+				op, _ := d.Operations.Get("sid", id)
+				return op.Status
+			}
+		}
+
+		It("returns the plan on dry runs", func() {
+			post("/api/v3/instance/sid/upgrade?dryRun=true", `{"versions":{"peer":"2.5.4"}}`)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring(`"name":"peer1"`))
+			Expect(peers.UpgradeCallCount()).To(Equal(0))
+		})
+
+		It("pauses on failure and resumes", func() {
+			peers.UpgradeReturnsOnCall(0, errors.New("update failed"))
+			post("/api/v3/instance/sid/upgrade", `{"versions":{"peer":"2.5.4"},"onFailure":"pause"}`)
+			Expect(w.Code).To(Equal(http.StatusAccepted))
+			op := &operations.Operation{}
+			Expect(json.Unmarshal(w.Body.Bytes(), op)).To(Succeed())
+			Eventually(operationStatus(op.ID)).Should(Equal(operations.StatusPaused))
+
+			post("/api/v3/instance/sid/upgrade", `{"versions":{"peer":"2.5.4"}}`)
+			Expect(w.Code).To(Equal(http.StatusConflict))
+
+			post("/api/v3/instance/sid/upgrade/"+op.ID+"/resume", "")
+			Expect(w.Code).To(Equal(http.StatusAccepted))
+			Eventually(operationStatus(op.ID)).Should(Equal(operations.StatusSucceeded))
+			Expect(peers.UpgradeCallCount()).To(Equal(2))
+		})
+
+		It("aborts paused upgrades", func() {
+			peers.UpgradeReturns(errors.New("update failed"))
+			post("/api/v3/instance/sid/upgrade", `{"versions":{"peer":"2.5.4"},"onFailure":"pause"}`)
+			op := &operations.Operation{}
+			Expect(json.Unmarshal(w.Body.Bytes(), op)).To(Succeed())
+			Eventually(operationStatus(op.ID)).Should(Equal(operations.StatusPaused))
+
+			post("/api/v3/instance/sid/upgrade/"+op.ID+"/abort", "")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(operationStatus(op.ID)()).To(Equal(operations.StatusFailed))
+
+			post("/api/v3/instance/sid/upgrade/"+op.ID+"/resume", "")
+			Expect(w.Code).To(Equal(http.StatusConflict))
 		})
 	})
 
-	Context("Basic Auth", func() {
-		It("returns an error if missing authorization header", func() {
-			req, err := http.NewRequest(http.MethodHead, "http://localhost:8080", nil)
+	Context("capacity", func() {
+		var (
+			w        *httptest.ResponseRecorder
+			mockKube *capacitymocks.Kube
+		)
+
+		BeforeEach(func() {
+			cfg.Versions = &config.Versions{
+				Peer: map[string]config.VersionPeer{"2.5.4": {Default: true}},
+			}
+			err := d.Init()
 			Expect(err).NotTo(HaveOccurred())
 
-			_, err = d.BasicAuth(req)
-			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(Equal("failed to authorize"))
+			mockKube = &capacitymocks.Kube{}
+			mockKube.ListNodesReturns([]corev1.Node{{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+				Status: corev1.NodeStatus{
+					Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+					Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+				},
+			}}, nil)
+			d.CapacityChecker = capacity.New(zap.NewNop(), mockKube)
 		})
 
-		It("returns an error if incorrect username and password used", func() {
-			d.Config.Auth = config.BasicAuth{
-				Username: "admin",
-				Password: "adminpw",
+		send := func(method, path, body string) *httptest.ResponseRecorder {
+			w = httptest.NewRecorder()
+			req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+			req.SetBasicAuth("admin", "adminpw")
+			d.Router.ServeHTTP(w, req)
+			return w
+		}
+
+		It("returns the capacity report of a create request", func() {
+			send(http.MethodGet, "/api/v3/instance/sid/type/peer/capacity", `{"resources":{"peer":{"requests":{"cpu":"1"}}}}`)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring(`"fits":true`))
+			Expect(w.Body.String()).To(ContainSubstring(`"name":"peer"`))
+		})
+
+		It("rejects creating components that fit no node", func() {
+			send(http.MethodPost, "/api/v3/instance/sid/type/peer/component/peer1", `{"resources":{"peer":{"requests":{"cpu":"4"}}}}`)
+			Expect(w.Code).To(Equal(http.StatusUnprocessableEntity))
+			Expect(w.Body.String()).To(ContainSubstring(apierror.CodeUnschedulable))
+			Expect(w.Body.String()).To(ContainSubstring("pods.peer1"))
+		})
+	})
+
+	Context("config reload", func() {
+		BeforeEach(func() {
+			cfg.Namespace = "ns"
+			cfg.Versions = &config.Versions{
+				Peer: map[string]config.VersionPeer{"2.5.4": {Default: true}},
 			}
-			req, err := http.NewRequest(http.MethodHead, "http://localhost:8080", nil)
-			req.SetBasicAuth("a", "b")
+			err := d.Init()
 			Expect(err).NotTo(HaveOccurred())
+		})
 
-			_, err = d.BasicAuth(req)
-			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(Equal("unauthorized"))
+		It("replaces the components with components using the reloaded configuration", func() {
+			initial := d.Components()
+			Expect(initial.Peer.Config).To(Equal(cfg))
+
+			reloaded := &config.DeployerSettingsConfig{
+				Versions: &config.Versions{
+					Peer: map[string]config.VersionPeer{"2.5.4": {Default: true}, "2.5.5": {}},
+				},
+			}
+			d.ApplyConfig(reloaded, config.Revision{Number: 2, Hash: "abc"})
+
+			components := d.Components()
+			Expect(components).NotTo(BeIdenticalTo(initial))
+			Expect(components.Peer.Config.Versions.Peer).To(HaveKey("2.5.5"))
+			Expect(components.Peer.Config.Namespace).To(Equal("ns"))
+			Expect(initial.Peer.Config.Versions.Peer).NotTo(HaveKey("2.5.5"))
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v3/instance/sid/config/revision", nil)
+			req.SetBasicAuth("admin", "adminpw")
+			d.Router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring(`"number":2`))
+			Expect(w.Body.String()).To(ContainSubstring(`"hash":"abc"`))
+		})
+	})
+
+	Context("service instances", func() {
+		var (
+			w       *httptest.ResponseRecorder
+			mockIBP *instancemocks.IBPOperatorClient
+			peers   *upgrademocks.Target
+		)
+
+		BeforeEach(func() {
+			cfg.Namespace = "ns"
+			cfg.Instances = &config.Instances{
+				Namespaces: map[string]string{"sid1": "ns1"},
+				Strict:     true,
+			}
+			err := d.Init()
+			Expect(err).NotTo(HaveOccurred())
+
+			mockIBP = &instancemocks.IBPOperatorClient{}
+			d.Instances = instance.New(zap.NewNop(), &instancemocks.Kube{}, mockIBP, "ns")
+			peers = &upgrademocks.Target{}
+			peers.NodesReturns([]upgrade.Node{{Name: "peer1", FromVersion: "2.2.10"}}, nil)
+			d.Upgrader = upgrade.New(zap.NewNop(), &upgrademocks.Kube{}, map[string]upgrade.Target{upgrade.TypePeer: peers}, time.Second)
+		})
+
+		send := func(method, path, body string) *httptest.ResponseRecorder {
+			w = httptest.NewRecorder()
+			req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+			req.SetBasicAuth("admin", "adminpw")
+			d.Router.ServeHTTP(w, req)
+			return w
+		}
+
+		It("uses the namespace of the service instance", func() {
+			send(http.MethodPost, "/api/v3/instance/sid1/upgrade?dryRun=true", `{"versions":{"peer":"2.5.4"}}`)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			sID, namespace := peers.NodesArgsForCall(0)
+			Expect(sID).To(Equal("sid1"))
+			Expect(namespace).To(Equal("ns1"))
+		})
+
+		It("rejects service instances that are not mapped", func() {
+			send(http.MethodGet, "/api/v3/instance/sid2/resources", "")
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+			Expect(w.Body.String()).To(ContainSubstring("service instance 'sid2' not found"))
+		})
+
+		It("rejects requests to the components of another service instance", func() {
+			mockIBP.GetCRStub = func(namespace, kind, name string, cr runtime.Object) error {
+				cr.(*current.IBPPeer).Labels = map[string]string{common.ServiceInstanceLabel: "sid3"}
+				return nil
+			}
+
+			send(http.MethodDelete, "/api/v3/instance/sid1/type/peer/component/peer1", "")
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			Expect(w.Body.String()).To(ContainSubstring("peer 'peer1' belongs to another service instance"))
+			namespace, _, _, _ := mockIBP.GetCRArgsForCall(0)
+			Expect(namespace).To(Equal("ns1"))
+		})
+	})
+
+	Context("clusters", func() {
+		var (
+			w     *httptest.ResponseRecorder
+			peers *upgrademocks.Target
+		)
+
+		BeforeEach(func() {
+			err := d.Init()
+			Expect(err).NotTo(HaveOccurred())
+
+			peers = &upgrademocks.Target{}
+			peers.NodesReturns([]upgrade.Node{{Name: "peer1", FromVersion: "2.2.10"}}, nil)
+			d.Targets = map[string]*deployer.Target{
+				"east": {
+					Name:      "east",
+					Instances: instance.New(zap.NewNop(), &instancemocks.Kube{}, &instancemocks.IBPOperatorClient{}, "east-ns"),
+					Upgrader:  upgrade.New(zap.NewNop(), &upgrademocks.Kube{}, map[string]upgrade.Target{upgrade.TypePeer: peers}, time.Second),
+				},
+			}
+		})
+
+		send := func(method, path, cluster string) *httptest.ResponseRecorder {
+			w = httptest.NewRecorder()
+			req := httptest.NewRequest(method, path, bytes.NewBufferString(`{"versions":{"peer":"2.5.4"}}`))
+			req.SetBasicAuth("admin", "adminpw")
+			if cluster != "" {
+				req.Header.Set(deployer.ClusterHeader, cluster)
+			}
+			d.Router.ServeHTTP(w, req)
+			return w
+		}
+
+		It("routes the requests with the path prefix to the cluster", func() {
+			send(http.MethodPost, "/api/v3/cluster/east/instance/sid1/upgrade?dryRun=true", "")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			_, namespace := peers.NodesArgsForCall(0)
+			Expect(namespace).To(Equal("east-ns"))
+		})
+
+		It("routes the requests with the header to the cluster", func() {
+			send(http.MethodPost, "/api/v3/instance/sid1/upgrade?dryRun=true", "east")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(peers.NodesCallCount()).To(Equal(1))
+		})
+
+		It("rejects unknown clusters", func() {
+			send(http.MethodGet, "/api/v3/cluster/west/instance/sid1/resources", "")
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+			Expect(w.Body.String()).To(ContainSubstring("cluster 'west' not found"))
+
+			send(http.MethodGet, "/api/v3/instance/sid1/resources", "west")
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("rejects mustgather on other clusters", func() {
+			send(http.MethodGet, "/api/v3/cluster/east/instance/sid1/mustgather", "")
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring("mustgather is not supported on cluster 'east'"))
+		})
+	})
+
+	Context("metrics", func() {
+		send := func(path string, authenticated bool) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			if authenticated {
+				req.SetBasicAuth("admin", "adminpw")
+			}
+			d.Router.ServeHTTP(w, req)
+			return w
+		}
+
+		It("does not serve the metrics if they are disabled", func() {
+			Expect(d.Init()).To(Succeed())
+			Expect(d.Metrics).To(BeNil())
+			Expect(send("/metrics", true).Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("serves the metrics of the requests to authenticated principals", func() {
+			cfg.Metrics = &config.Metrics{Enabled: true}
+			Expect(d.Init()).To(Succeed())
+			defer d.Stop()
+
+			Expect(send("/healthcheck", true).Code).To(Equal(http.StatusOK))
+			Expect(send("/metrics", false).Code).To(Equal(http.StatusUnauthorized))
+
+			w := send("/metrics", true)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring(`deployer_http_requests_total{code="200",component_type="",method="GET",route="/healthcheck"} 1`))
+			Expect(w.Body.String()).To(ContainSubstring(`deployer_http_requests_total{code="401",component_type="",method="GET",route="unmatched"} 1`))
+		})
+	})
+
+	Context("request IDs", func() {
+		var logs *observer.ObservedLogs
+
+		BeforeEach(func() {
+			var core zapcore.Core
+			core, logs = observer.New(zapcore.InfoLevel)
+			d.LocalConfig.Logger = zap.New(core)
+			Expect(d.Init()).To(Succeed())
+		})
+
+		send := func(path, requestID string, header http.Header) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.SetBasicAuth("admin", "adminpw")
+			for key, values := range header {
+				req.Header[key] = values
+			}
+			if requestID != "" {
+				req.Header.Set(deployer.RequestIDHeader, requestID)
+			}
+			d.Router.ServeHTTP(w, req)
+			return w
+		}
+
+		accessLog := func() map[string]interface{} {
+			entries := []observer.LoggedEntry{}
+			for _, entry := range logs.All() {
+				if entry.LoggerName == "Access" {
+					entries = append(entries, entry)
+				}
+			}
+			Expect(entries).To(HaveLen(1))
+			return entries[0].ContextMap()
+		}
+
+		It("generates the ID of requests without one and echoes it", func() {
+			w := send("/healthcheck", "", nil)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get(deployer.RequestIDHeader)).To(MatchRegexp("^[0-9a-f]{32}$"))
+			Expect(accessLog()).To(HaveKeyWithValue("requestID", w.Header().Get(deployer.RequestIDHeader)))
+		})
+
+		It("replaces invalid IDs", func() {
+			w := send("/healthcheck", "not valid", nil)
+			Expect(w.Header().Get(deployer.RequestIDHeader)).To(MatchRegexp("^[0-9a-f]{32}$"))
+		})
+
+		It("echoes the ID in the error body and logs the request once", func() {
+			w := send("/api/v3/instance/sid1/type/all", "req-1", http.Header{deployer.ClusterHeader: []string{"unknown"}})
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+			Expect(w.Header().Get(deployer.RequestIDHeader)).To(Equal("req-1"))
+
+			errs := &deployer.Errors{}
+			Expect(json.Unmarshal(w.Body.Bytes(), errs)).To(Succeed())
+			Expect(errs.RequestID).To(Equal("req-1"))
+
+			entry := accessLog()
+			Expect(entry).To(HaveKeyWithValue("requestID", "req-1"))
+			Expect(entry).To(HaveKeyWithValue("method", http.MethodGet))
+			Expect(entry).To(HaveKeyWithValue("status", int64(http.StatusNotFound)))
+			Expect(entry).To(HaveKeyWithValue("principal", "admin"))
+			Expect(entry).To(HaveKeyWithValue("errorKind", "not_found"))
+			Expect(entry).To(HaveKeyWithValue("bytes", int64(w.Body.Len())))
+			Expect(entry).To(HaveKey("latency"))
+		})
+
+		It("logs the requests that are not authenticated", func() {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/healthcheck", nil)
+			req.Header.Set(deployer.RequestIDHeader, "req-1")
+			d.Router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusUnauthorized))
+
+			errs := &deployer.Errors{}
+			Expect(json.Unmarshal(w.Body.Bytes(), errs)).To(Succeed())
+			Expect(errs.RequestID).To(Equal("req-1"))
+
+			entry := accessLog()
+			Expect(entry).To(HaveKeyWithValue("route", "unmatched"))
+			Expect(entry).To(HaveKeyWithValue("principal", ""))
+		})
+	})
+
+	Context("events", func() {
+		stream := func() {
+			Expect(d.Init()).To(Succeed())
+			defer d.Stop()
+
+			server := httptest.NewServer(d.Router)
+			defer server.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v3/instance/sid1/events?lastEventId=unknown", nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.SetBasicAuth("admin", "adminpw")
+			resp, err := server.Client().Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+			line, err := bufio.NewReader(resp.Body).ReadString('\n')
+			Expect(err).NotTo(HaveOccurred())
+			Expect(line).To(Equal("event: reset\n"))
+		}
+
+		It("streams the events through the middlewares", func() {
+			stream()
+		})
+
+		It("streams the events with the metrics enabled", func() {
+			cfg.Metrics = &config.Metrics{Enabled: true}
+			stream()
+		})
+
+		It("streams the events with the tracing enabled", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "traces.json")
+			cfg.Tracing = &config.Tracing{Exporter: config.TracingExporterFile, Path: path}
+			stream()
+		})
+	})
+
+	Context("tracing", func() {
+		It("continues the trace of the request through the endpoint into the kubernetes calls", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "traces.json")
+			cfg.Tracing = &config.Tracing{Exporter: config.TracingExporterFile, Path: path}
+			Expect(d.Init()).To(Succeed())
+			Expect(d.Tracing).NotTo(BeNil())
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v3/instance/sID/k8s/cluster/version", nil)
+			req.SetBasicAuth("admin", "adminpw")
+			req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			d.Router.ServeHTTP(w, req)
+			Expect(d.Stop()).To(Succeed())
+
+			data, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			traces := string(data)
+			Expect(traces).To(ContainSubstring(`"Name":"GET /api/v3/instance/{serviceInstanceID}/k8s/cluster/version"`))
+			Expect(traces).To(ContainSubstring(`"Name":"Endpoint.ServeHTTP"`))
+			Expect(traces).To(ContainSubstring(`"Name":"GET /version"`))
+			Expect(traces).To(ContainSubstring(`"TraceID":"4bf92f3577b34da6a3ce929d0e0e4736"`))
 		})
 	})
 
@@ -268,7 +856,8 @@ func (m *missingAuthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 type properAuthHandler struct{}
 
 func (m *properAuthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	user := ctx.Value("user")
-	Expect(user.(string)).To(Equal("admin"))
+	principal, found := auth.PrincipalFrom(r.Context())
+	Expect(found).To(BeTrue())
+	Expect(principal.Name).To(Equal("admin"))
+	Expect(principal.Method).To(Equal(config.AuthMethodBasic))
 }
@@ -22,7 +22,9 @@ import (
 	"encoding/json"
 	"net/http"
 
-	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
 )
 
@@ -43,27 +45,51 @@ func NewEndpoint(handler func(http.ResponseWriter, *http.Request) (interface{},
 
 // Errors represent an errors response
 type Errors struct {
-	Status  int    `json:"status"`
-	Message string `json:"message"`
+	Status    int                   `json:"status"`
+	Message   string                `json:"message"`
+	Kind      apierror.Kind         `json:"kind,omitempty"`
+	Code      string                `json:"code,omitempty"`
+	Details   []apierror.FieldError `json:"details,omitempty"`
+	RequestID string                `json:"requestId,omitempty"`
 }
 
 // ServeHTTP encapsulates the call to underlying Handlers to handle the request
 // and return the response with a proper HTTP status code
 func (se *Endpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer().Start(r.Context(), "Endpoint.ServeHTTP")
+	defer span.End()
+	r = r.WithContext(ctx)
+
 	var resp interface{}
 	w = NewHTTPResponseWriter(r, w, se)
 	resp, statusCode, err := se.Handler(w, r)
 	if err != nil {
 		// An error occurred
-		status := util.GetErrorStatusCode(err)
+		apiErr := apierror.From(err)
+		status := apiErr.StatusCode()
+		span.RecordError(err)
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, err.Error())
+		}
 		w.WriteHeader(status)
 		httpErr := &Errors{
-			Status:  status,
-			Message: err.Error(),
+			Status:    status,
+			Message:   err.Error(),
+			Kind:      apiErr.Kind,
+			Code:      apiErr.Code,
+			Details:   apiErr.Details,
+			RequestID: RequestIDFrom(r.Context()),
 		}
 
 		se.writeJSON(httpErr, w)
-		se.Logger.Errorf(`failed to complete request to: %s %s %s, error code: %d, message: %s"`, r.RemoteAddr, r.Method, r.URL, httpErr.Status, httpErr.Message)
+		// the error is logged with the access log entry of the request
+		if entry := accessEntryOf(r.Context()); entry != nil {
+			entry.err = err
+			entry.errorKind = apiErr.Kind
+			return
+		}
+		se.Logger.Errorw("Failed to complete request", "method", r.Method, "url", r.URL.String(), "status", httpErr.Status, "error", httpErr.Message)
 		return
 	}
 
@@ -71,8 +97,8 @@ func (se *Endpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if statusCode != 0 {
 		httpStatus = statusCode
 	}
+	span.SetAttributes(attribute.Int("http.response.status_code", httpStatus))
 	w.WriteHeader(httpStatus)
-	se.Logger.Infof(`%s %s %s %d 0 "OK"`, r.RemoteAddr, r.Method, r.URL, httpStatus)
 
 	// If a response was returned by the handler, write it now.
 	se.writeJSON(resp, w)
@@ -25,6 +25,7 @@ import (
 	"net/http/httptest"
 
 	"github.com/IBM-Blockchain/fabric-deployer/deployer"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.uber.org/zap"
@@ -58,7 +59,30 @@ var _ = Describe("Endpoint", func() {
 			Expect(result.StatusCode).To(Equal(http.StatusInternalServerError))
 			body, err := ioutil.ReadAll(result.Body)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(string(body)).To(Equal("{\"status\":500,\"message\":\"handler error\"}\n"))
+			Expect(string(body)).To(Equal("{\"status\":500,\"message\":\"handler error\",\"kind\":\"internal\",\"code\":\"internal_error\"}\n"))
+		})
+	})
+
+	Context("handler returns a typed error", func() {
+		BeforeEach(func() {
+			endpoint = &deployer.Endpoint{
+				Handler: func(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+					return nil, 0, apierror.InvalidField("version", "version '%s' is not valid", "x")
+				},
+				Logger: logger,
+			}
+		})
+
+		It("writes the status, code and field details", func() {
+			req := httptest.NewRequest(http.MethodHead, "http://localhost:8080", nil)
+			w := httptest.NewRecorder()
+			endpoint.ServeHTTP(w, req)
+
+			result := w.Result()
+			Expect(result.StatusCode).To(Equal(http.StatusBadRequest))
+			body, err := ioutil.ReadAll(result.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal("{\"status\":400,\"message\":\"version 'x' is not valid\",\"kind\":\"validation\",\"code\":\"invalid_field\",\"details\":[{\"field\":\"version\",\"message\":\"version 'x' is not valid\"}]}\n"))
 		})
 	})
 
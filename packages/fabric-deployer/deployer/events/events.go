@@ -0,0 +1,295 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package events
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/ibpoperator"
+)
+
+// Event types
+const (
+	STATUS   = "status"
+	REPLICAS = "replicas"
+	DELETED  = "deleted"
+
+	// RESET is sent to a client resuming a stream when the events after
+	// its last event id are no longer available, the client should get the
+	// components again
+	RESET = "reset"
+)
+
+const (
+	// DefaultHistorySize is the number of events kept to resume streams
+	DefaultHistorySize = 1000
+
+	// subscriberBuffer is the number of events buffered for a subscriber,
+	// a subscriber that falls further behind is disconnected and has to
+	// resume the stream with its last event id
+	subscriberBuffer = 100
+)
+
+// Event is a change of the status, replicas or existence of a component
+type Event struct {
+	ID               string    `json:"id"`
+	Type             string    `json:"type"`
+	ComponentType    string    `json:"componentType"`
+	ComponentName    string    `json:"componentName"`
+	Cluster          string    `json:"cluster,omitempty"`
+	Status           *Status   `json:"status,omitempty"`
+	PreviousStatus   *Status   `json:"previousStatus,omitempty"`
+	Replicas         *int32    `json:"replicas,omitempty"`
+	PreviousReplicas *int32    `json:"previousReplicas,omitempty"`
+	Timestamp        time.Time `json:"timestamp"`
+
+	// Namespace and ServiceInstanceID identify the service instance of the
+	// component, streams only send the events of their service instance
+	Namespace         string `json:"-"`
+	ServiceInstanceID string `json:"-"`
+}
+
+type Status struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Broker keeps a history of the events and fans them out to the subscribers
+type Broker struct {
+	Logger *zap.SugaredLogger
+
+	// epoch identifies this broker in the event ids, ids handed out by
+	// a previous run of the deployer can not be resumed
+	epoch       string
+	seq         uint64
+	history     []Event
+	historySize int
+	subscribers map[int]chan Event
+	nextID      int
+	mutex       sync.Mutex
+}
+
+func New(logger *zap.Logger, historySize int) *Broker {
+	if historySize <= 0 {
+		historySize = DefaultHistorySize
+	}
+
+	return &Broker{
+		Logger:      logger.Sugar().Named("Events"),
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		historySize: historySize,
+		subscribers: map[int]chan Event{},
+	}
+}
+
+// HandleCRChange converts the change of a CR into events, it matches
+// ibpoperator.CRHandler. CRs that are added do not produce events, their
+// status changes once deployed do.
+func (b *Broker) HandleCRChange(kind string, old, cr *ibpoperator.CRState) {
+	b.handleCRChange("", kind, old, cr)
+}
+
+// ClusterHandler returns the handler of the CR changes of a cluster other
+// than the cluster of the deployer, its events are set to the cluster
+func (b *Broker) ClusterHandler(cluster string) ibpoperator.CRHandler {
+	return func(kind string, old, cr *ibpoperator.CRState) {
+		b.handleCRChange(cluster, kind, old, cr)
+	}
+}
+
+func (b *Broker) handleCRChange(cluster, kind string, old, cr *ibpoperator.CRState) {
+	componentType := componentType(kind)
+	if componentType == "" || old == nil {
+		return
+	}
+
+	if cr == nil {
+		b.Publish(Event{
+			Type:              DELETED,
+			ComponentType:     componentType,
+			Cluster:           cluster,
+			ComponentName:     old.Name,
+			PreviousStatus:    status(old),
+			PreviousReplicas:  old.Replicas,
+			Namespace:         old.Namespace,
+			ServiceInstanceID: old.Labels[common.ServiceInstanceLabel],
+		})
+		return
+	}
+
+	if old.Status.Type != cr.Status.Type || old.Status.Reason != cr.Status.Reason {
+		b.Publish(Event{
+			Type:              STATUS,
+			ComponentType:     componentType,
+			Cluster:           cluster,
+			ComponentName:     cr.Name,
+			Status:            status(cr),
+			PreviousStatus:    status(old),
+			Namespace:         cr.Namespace,
+			ServiceInstanceID: cr.Labels[common.ServiceInstanceLabel],
+		})
+	}
+
+	if !equalReplicas(old.Replicas, cr.Replicas) {
+		b.Publish(Event{
+			Type:              REPLICAS,
+			ComponentType:     componentType,
+			Cluster:           cluster,
+			ComponentName:     cr.Name,
+			Replicas:          cr.Replicas,
+			PreviousReplicas:  old.Replicas,
+			Namespace:         cr.Namespace,
+			ServiceInstanceID: cr.Labels[common.ServiceInstanceLabel],
+		})
+	}
+}
+
+// Publish assigns the next id to the event, records it in the history and
+// sends it to the subscribers
+func (b *Broker) Publish(event Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.seq++
+	event.ID = fmt.Sprintf("%s-%d", b.epoch, b.seq)
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.history = append(b.history, event)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for id, events := range b.subscribers {
+		select {
+		case events <- event:
+		default:
+			b.Logger.Warnf("Subscriber %d is too slow, disconnecting it", id)
+			close(events)
+			delete(b.subscribers, id)
+		}
+	}
+}
+
+// Subscribe returns the events published after lastEventID and a channel
+// receiving the events published from now on. complete is false if some of
+// the events after lastEventID are no longer in the history. The channel is
+// closed if the subscriber falls behind, cancel must be called once the
+// subscriber is done.
+func (b *Broker) Subscribe(lastEventID string) (replay []Event, complete bool, events <-chan Event, cancel func()) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	replay, complete = b.since(lastEventID)
+
+	ch := make(chan Event, subscriberBuffer)
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = ch
+
+	return replay, complete, ch, func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		if _, found := b.subscribers[id]; found {
+			close(ch)
+			delete(b.subscribers, id)
+		}
+	}
+}
+
+// since must be called with the broker mutex held
+func (b *Broker) since(lastEventID string) ([]Event, bool) {
+	if lastEventID == "" {
+		return nil, true
+	}
+
+	epoch, seq, err := parseID(lastEventID)
+	if err != nil || epoch != b.epoch || seq > b.seq {
+		return b.copyHistory(0), false
+	}
+
+	if seq == b.seq {
+		return nil, true
+	}
+
+	if len(b.history) == 0 {
+		return nil, false
+	}
+
+	// ids in the history are consecutive
+	first := b.seq - uint64(len(b.history)) + 1
+	if seq+1 < first {
+		return b.copyHistory(0), false
+	}
+
+	return b.copyHistory(int(seq + 1 - first)), true
+}
+
+func (b *Broker) copyHistory(from int) []Event {
+	return append([]Event(nil), b.history[from:]...)
+}
+
+func parseID(id string) (string, uint64, error) {
+	i := strings.LastIndex(id, "-")
+	if i < 0 {
+		return "", 0, fmt.Errorf("invalid event id '%s'", id)
+	}
+
+	seq, err := strconv.ParseUint(id[i+1:], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid event id '%s'", id)
+	}
+
+	return id[:i], seq, nil
+}
+
+func componentType(kind string) string {
+	switch kind {
+	case "ibpcas":
+		return "ca"
+	case "ibppeers":
+		return "peer"
+	case "ibporderers":
+		return "orderer"
+	}
+	return ""
+}
+
+func status(cr *ibpoperator.CRState) *Status {
+	return &Status{
+		Type:   string(cr.Status.Type),
+		Reason: cr.Status.Reason,
+	}
+}
+
+func equalReplicas(a, b *int32) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
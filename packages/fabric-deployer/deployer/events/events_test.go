@@ -0,0 +1,210 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package events_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/events"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/ibpoperator"
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+)
+
+var _ = Describe("Events", func() {
+	var (
+		broker *events.Broker
+	)
+
+	replicas := func(r int32) *int32 {
+		return &r
+	}
+
+	BeforeEach(func() {
+		logger, err := zap.NewProductionConfig().Build()
+		Expect(err).NotTo(HaveOccurred())
+		broker = events.New(logger, 3)
+	})
+
+	Context("CR changes", func() {
+		var (
+			old *ibpoperator.CRState
+			cr  *ibpoperator.CRState
+		)
+
+		BeforeEach(func() {
+			old = &ibpoperator.CRState{
+				Name: "peer1",
+				Status: current.CRStatus{
+					Type:   current.Deploying,
+					Status: current.True,
+				},
+				Replicas: replicas(1),
+			}
+			cr = &ibpoperator.CRState{
+				Name:      "peer1",
+				Namespace: "ns1",
+				Labels:    map[string]string{common.ServiceInstanceLabel: "sid1"},
+				Status: current.CRStatus{
+					Type:   current.Deployed,
+					Status: current.True,
+				},
+				Replicas: replicas(1),
+			}
+		})
+
+		It("publishes status changes", func() {
+			broker.HandleCRChange("ibppeers", old, cr)
+
+			replay, complete, _, cancel := broker.Subscribe("0")
+			defer cancel()
+			Expect(complete).To(BeFalse())
+			Expect(len(replay)).To(Equal(1))
+			Expect(replay[0].Type).To(Equal(events.STATUS))
+			Expect(replay[0].ComponentType).To(Equal("peer"))
+			Expect(replay[0].ComponentName).To(Equal("peer1"))
+			Expect(replay[0].Status).To(Equal(&events.Status{Type: "Deployed"}))
+			Expect(replay[0].PreviousStatus).To(Equal(&events.Status{Type: "Deploying"}))
+			Expect(replay[0].Namespace).To(Equal("ns1"))
+			Expect(replay[0].ServiceInstanceID).To(Equal("sid1"))
+		})
+
+		It("publishes reason changes", func() {
+			old.Status = cr.Status
+			cr.Status.Reason = "pod crash looping"
+			broker.HandleCRChange("ibppeers", old, cr)
+
+			replay, _, _, cancel := broker.Subscribe("0")
+			defer cancel()
+			Expect(len(replay)).To(Equal(1))
+			Expect(replay[0].Status.Reason).To(Equal("pod crash looping"))
+		})
+
+		It("publishes replica changes", func() {
+			old.Status = cr.Status
+			cr.Replicas = replicas(0)
+			broker.HandleCRChange("ibporderers", old, cr)
+
+			replay, _, _, cancel := broker.Subscribe("0")
+			defer cancel()
+			Expect(len(replay)).To(Equal(1))
+			Expect(replay[0].Type).To(Equal(events.REPLICAS))
+			Expect(replay[0].ComponentType).To(Equal("orderer"))
+			Expect(*replay[0].Replicas).To(Equal(int32(0)))
+			Expect(*replay[0].PreviousReplicas).To(Equal(int32(1)))
+		})
+
+		It("publishes deletions", func() {
+			broker.HandleCRChange("ibpcas", old, nil)
+
+			replay, _, _, cancel := broker.Subscribe("0")
+			defer cancel()
+			Expect(len(replay)).To(Equal(1))
+			Expect(replay[0].Type).To(Equal(events.DELETED))
+			Expect(replay[0].ComponentType).To(Equal("ca"))
+		})
+
+		It("does not publish added CRs or unchanged CRs", func() {
+			broker.HandleCRChange("ibppeers", nil, cr)
+			broker.HandleCRChange("ibppeers", cr, cr)
+			broker.HandleCRChange("ibpconsoles", old, cr)
+
+			replay, _, _, cancel := broker.Subscribe("0")
+			defer cancel()
+			Expect(replay).To(BeEmpty())
+		})
+	})
+
+	Context("subscribe", func() {
+		publish := func(n int) []events.Event {
+			_, _, ch, cancel := broker.Subscribe("")
+			defer cancel()
+
+			published := []events.Event{}
+			for i := 0; i < n; i++ {
+				broker.Publish(events.Event{Type: events.STATUS, ComponentName: "peer1"})
+				published = append(published, <-ch)
+			}
+			return published
+		}
+
+		It("receives the events published after subscribing", func() {
+			replay, complete, ch, cancel := broker.Subscribe("")
+			defer cancel()
+			Expect(replay).To(BeEmpty())
+			Expect(complete).To(BeTrue())
+
+			broker.Publish(events.Event{Type: events.STATUS})
+			Eventually(ch).Should(Receive())
+		})
+
+		It("replays the events after the last event id", func() {
+			published := publish(3)
+
+			replay, complete, _, cancel := broker.Subscribe(published[0].ID)
+			defer cancel()
+			Expect(complete).To(BeTrue())
+			Expect(replay).To(Equal(published[1:]))
+		})
+
+		It("replays nothing if the client is up to date", func() {
+			published := publish(2)
+
+			replay, complete, _, cancel := broker.Subscribe(published[1].ID)
+			defer cancel()
+			Expect(complete).To(BeTrue())
+			Expect(replay).To(BeEmpty())
+		})
+
+		It("replays the history if events after the last event id were dropped", func() {
+			published := publish(5)
+
+			replay, complete, _, cancel := broker.Subscribe(published[0].ID)
+			defer cancel()
+			Expect(complete).To(BeFalse())
+			Expect(replay).To(Equal(published[2:]))
+		})
+
+		It("replays the history for ids of another run of the deployer", func() {
+			published := publish(2)
+
+			replay, complete, _, cancel := broker.Subscribe("otherepoch-1")
+			defer cancel()
+			Expect(complete).To(BeFalse())
+			Expect(replay).To(Equal(published))
+		})
+
+		It("disconnects subscribers that fall behind", func() {
+			_, _, ch, cancel := broker.Subscribe("")
+			defer cancel()
+
+			for i := 0; i <= 100; i++ {
+				broker.Publish(events.Event{Type: events.STATUS})
+			}
+
+			received := 0
+			for range ch {
+				received++
+			}
+			Expect(received).To(Equal(100))
+		})
+	})
+})
@@ -0,0 +1,126 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultHeartbeat is the interval of the comments sent to keep idle streams
+// from being closed by proxies
+const DefaultHeartbeat = 15 * time.Second
+
+// Stream writes the events to w as server-sent events until the client goes
+// away or falls behind, errors before the stream is started are written to
+// w. The stream is resumed from the Last-Event-ID header, or the lastEventId
+// query parameter for clients that can not set headers. Only the events
+// matching filter are sent, all of them if filter is nil.
+func (b *Broker) Stream(w http.ResponseWriter, r *http.Request, heartbeat time.Duration, filter func(Event) bool) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return errors.New("streaming not supported")
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+
+	replay, complete, events, cancel := b.Subscribe(lastEventID)
+	defer cancel()
+
+	// the stream outlives the write timeout of the server
+	err := http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if !complete {
+		_, err = fmt.Fprintf(w, "event: %s\ndata: {}\n\n", RESET)
+		if err != nil {
+			return err
+		}
+	}
+	if filter == nil {
+		filter = func(Event) bool { return true }
+	}
+	for _, event := range replay {
+		if !filter(event) {
+			continue
+		}
+		err = WriteEvent(w, event)
+		if err != nil {
+			return err
+		}
+	}
+	flusher.Flush()
+
+	if heartbeat <= 0 {
+		heartbeat = DefaultHeartbeat
+	}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				// fell behind, the client reconnects and resumes
+				return nil
+			}
+			if !filter(event) {
+				continue
+			}
+			err = WriteEvent(w, event)
+		case <-ticker.C:
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		case <-r.Context().Done():
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		flusher.Flush()
+	}
+}
+
+// WriteEvent writes the event in the server-sent events format
+func WriteEvent(w io.Writer, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal event")
+	}
+
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package events_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/events"
+)
+
+var _ = Describe("Stream", func() {
+	var (
+		broker *events.Broker
+		server *httptest.Server
+		filter func(events.Event) bool
+	)
+
+	BeforeEach(func() {
+		logger, err := zap.NewProductionConfig().Build()
+		Expect(err).NotTo(HaveOccurred())
+		broker = events.New(logger, events.DefaultHistorySize)
+		filter = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = broker.Stream(w, r, 50*time.Millisecond, filter)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	// connect returns a channel receiving the non empty lines of the stream,
+	// heartbeats are only received if requested
+	connect := func(ctx context.Context, lastEventID string, heartbeats bool) (*http.Response, <-chan string) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		Expect(err).NotTo(HaveOccurred())
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		lines := make(chan string, 100)
+		go func() {
+			defer close(lines)
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				line := scanner.Text()
+				if line == "" || (line == ": heartbeat" && !heartbeats) {
+					continue
+				}
+				lines <- line
+			}
+		}()
+		return resp, lines
+	}
+
+	It("streams events", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		resp, lines := connect(ctx, "", false)
+		defer resp.Body.Close()
+		Expect(resp.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+
+		broker.Publish(events.Event{Type: events.DELETED, ComponentType: "peer", ComponentName: "peer1"})
+
+		Eventually(lines).Should(Receive(MatchRegexp(`^id: .+-1$`)))
+		Eventually(lines).Should(Receive(Equal("event: deleted")))
+		Eventually(lines).Should(Receive(And(
+			HavePrefix("data: "),
+			ContainSubstring(`"componentName":"peer1"`),
+		)))
+	})
+
+	It("only streams the events matching the filter", func() {
+		filter = func(event events.Event) bool { return event.ServiceInstanceID == "sid1" }
+		broker.Publish(events.Event{Type: events.STATUS, ComponentName: "peer1", ServiceInstanceID: "sid2"})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		resp, lines := connect(ctx, "", false)
+		defer resp.Body.Close()
+
+		broker.Publish(events.Event{Type: events.STATUS, ComponentName: "peer2", ServiceInstanceID: "sid2"})
+		broker.Publish(events.Event{Type: events.STATUS, ComponentName: "peer3", ServiceInstanceID: "sid1"})
+
+		Eventually(lines).Should(Receive(HavePrefix("id: ")))
+		Eventually(lines).Should(Receive(Equal("event: status")))
+		Eventually(lines).Should(Receive(ContainSubstring(`"componentName":"peer3"`)))
+	})
+
+	It("sends heartbeats", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		resp, lines := connect(ctx, "", true)
+		defer resp.Body.Close()
+
+		Eventually(lines).Should(Receive(Equal(": heartbeat")))
+	})
+
+	It("resumes from the last event id", func() {
+		broker.Publish(events.Event{Type: events.STATUS, ComponentName: "peer1"})
+		broker.Publish(events.Event{Type: events.STATUS, ComponentName: "peer2"})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		firstID := ""
+		resp, lines := connect(ctx, "", false)
+		broker.Publish(events.Event{Type: events.STATUS, ComponentName: "peer3"})
+		Eventually(lines).Should(Receive(&firstID))
+		resp.Body.Close()
+		cancel()
+
+		ctx, cancel = context.WithCancel(context.Background())
+		defer cancel()
+		lastEventID := strings.TrimPrefix(firstID, "id: ")
+		broker.Publish(events.Event{Type: events.STATUS, ComponentName: "peer4"})
+		resp, lines = connect(ctx, lastEventID, false)
+		defer resp.Body.Close()
+
+		Eventually(lines).Should(Receive(HavePrefix("id: ")))
+		Eventually(lines).Should(Receive(Equal("event: status")))
+		Eventually(lines).Should(Receive(ContainSubstring(`"componentName":"peer4"`)))
+	})
+
+	It("asks the client to reset if the last event id can not be resumed", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		resp, lines := connect(ctx, "unknown-1", false)
+		defer resp.Body.Close()
+
+		Eventually(lines).Should(Receive(Equal("event: reset")))
+	})
+})
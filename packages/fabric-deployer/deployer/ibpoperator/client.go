@@ -20,14 +20,45 @@ package ibpoperator
 
 import (
 	"context"
+	"sync"
 
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/kube"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 )
 
 type Client struct {
-	client *IBPClient
+	client  *IBPClient
+	dynamic dynamic.Interface
+
+	// watches are shared by the copies of the client bound to a context
+	watches *watches
+
+	observer Observer
+
+	// ctx is the context of the calls to the API server, it carries the span
+	// of the request the client is bound to
+	ctx context.Context
+}
+
+// watches holds the CR watchers, they are started on first use, one per
+// namespace and kind
+type watches struct {
+	watchers map[string]*kube.Watcher
+	mutex    sync.Mutex
+	stopCh   chan struct{}
+}
+
+// Observer is notified of the CRs the client creates and updates and of the
+// waits of the client that time out, e.g. to record them in metrics
+type Observer interface {
+	kube.Observer
+	// CRChanged is called with the create or update operation once the CR
+	// is created or updated
+	CRChanged(operation, namespace, kind, name string)
 }
 
 func New(config *rest.Config) (*Client, error) {
@@ -36,13 +67,52 @@ func New(config *rest.Config) (*Client, error) {
 		return nil, err
 	}
 
+	d, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewForClients(c, d), nil
+}
+
+// NewForClients returns a client using the passed rest and dynamic clients,
+// the dynamic client is only used to watch CRs
+func NewForClients(client *IBPClient, dynamicClient dynamic.Interface) *Client {
 	return &Client{
-		client: c,
-	}, nil
+		client:  client,
+		dynamic: dynamicClient,
+		watches: &watches{
+			watchers: map[string]*kube.Watcher{},
+			stopCh:   make(chan struct{}),
+		},
+		ctx: context.Background(),
+	}
+}
+
+// WithContext returns a copy of the client whose calls to the API server use
+// ctx, e.g. so that they are traced as part of a request. The copy shares the
+// watches of the client.
+func (i *Client) WithContext(ctx context.Context) *Client {
+	c := *i
+	c.ctx = ctx
+	return &c
+}
+
+func (i *Client) callContext() context.Context {
+	if i.ctx == nil {
+		return context.Background()
+	}
+	return i.ctx
+}
+
+// SetObserver sets the observer of the client, it must be set before the
+// client is used
+func (i *Client) SetObserver(observer Observer) {
+	i.observer = observer
 }
 
 func (i *Client) GetCR(namespace string, kind string, name string, cr runtime.Object) error {
-	result := i.client.Get().Namespace(namespace).Resource(kind).Name(name).Do(context.TODO())
+	result := i.client.Get().Namespace(namespace).Resource(kind).Name(name).Do(i.callContext())
 	err := result.Error()
 	if err != nil {
 		return err
@@ -55,7 +125,7 @@ func (i *Client) GetCR(namespace string, kind string, name string, cr runtime.Ob
 }
 
 func (i *Client) GetAllCR(namespace string, kind string, crList runtime.Object) error {
-	result := i.client.Get().Namespace(namespace).Resource(kind).Do(context.TODO())
+	result := i.client.Get().Namespace(namespace).Resource(kind).Do(i.callContext())
 	err := result.Error()
 	if err != nil {
 		return err
@@ -68,16 +138,19 @@ func (i *Client) GetAllCR(namespace string, kind string, crList runtime.Object)
 }
 
 func (i *Client) CreateCR(namespace string, kind string, cr interface{}) error {
-	result := i.client.Post().Namespace(namespace).Resource(kind).Body(cr).Do(context.TODO())
+	result := i.client.Post().Namespace(namespace).Resource(kind).Body(cr).Do(i.callContext())
 	err := result.Error()
 	if err != nil {
 		return err
 	}
+	if object, err := meta.Accessor(cr); err == nil {
+		i.changed("create", namespace, kind, object.GetName())
+	}
 	return nil
 }
 
 func (i *Client) DeleteCR(namespace string, kind string, name string) error {
-	result := i.client.Delete().Namespace(namespace).Resource(kind).Name(name).Do(context.TODO())
+	result := i.client.Delete().Namespace(namespace).Resource(kind).Name(name).Do(i.callContext())
 	err := result.Error()
 	if err != nil {
 		return err
@@ -86,19 +159,27 @@ func (i *Client) DeleteCR(namespace string, kind string, name string) error {
 }
 
 func (i *Client) UpdateCR(namespace string, kind string, name string, bytes []byte) error {
-	result := i.client.Put().Namespace(namespace).Resource(kind).Name(name).Body(bytes).Do(context.TODO())
+	result := i.client.Put().Namespace(namespace).Resource(kind).Name(name).Body(bytes).Do(i.callContext())
 	err := result.Error()
 	if err != nil {
 		return err
 	}
+	i.changed("update", namespace, kind, name)
 	return nil
 }
 
 func (i *Client) PatchCR(namespace string, kind string, name string, bytes []byte) error {
-	result := i.client.Patch(types.MergePatchType).Namespace(namespace).Resource(kind).Name(name).Body(bytes).Do(context.TODO())
+	result := i.client.Patch(types.MergePatchType).Namespace(namespace).Resource(kind).Name(name).Body(bytes).Do(i.callContext())
 	err := result.Error()
 	if err != nil {
 		return err
 	}
+	i.changed("update", namespace, kind, name)
 	return nil
 }
+
+func (i *Client) changed(operation, namespace, kind, name string) {
+	if i.observer != nil {
+		i.observer.CRChanged(operation, namespace, kind, name)
+	}
+}
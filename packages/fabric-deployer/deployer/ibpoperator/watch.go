@@ -0,0 +1,182 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ibpoperator
+
+import (
+	"context"
+
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+	"github.com/pkg/errors"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/kube"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// CRState is the state of a CR that is reported to CR handlers
+type CRState struct {
+	Name      string
+	Namespace string
+	Labels    map[string]string
+	Status    current.CRStatus
+	Replicas  *int32
+}
+
+// CRHandler is called with the previous and the current state of a CR every
+// time it changes. old is nil when the CR is added and cr is nil when the CR
+// is deleted.
+type CRHandler func(kind string, old, cr *CRState)
+
+// WatchCR calls handler for every change of the CRs of the kind in the
+// namespace, existing CRs are reported as added when the watch starts
+func (i *Client) WatchCR(namespace string, kind string, handler CRHandler) {
+	i.watcher(namespace, kind).AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			cr := crState(obj)
+			if cr != nil {
+				handler(kind, nil, cr)
+			}
+		},
+		UpdateFunc: func(oldObj, obj interface{}) {
+			old, cr := crState(oldObj), crState(obj)
+			if old != nil && cr != nil {
+				handler(kind, old, cr)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			cr := crState(obj)
+			if cr != nil {
+				handler(kind, cr, nil)
+			}
+		},
+	})
+}
+
+// WaitForCRStatus blocks until cond returns true or an error for the status
+// of the CR, or until ctx is done. cond is evaluated every time the CR changes
+// and is passed a nil status while the CR does not exist. The last status of
+// the CR is returned, nil if it has not been seen. CRs are served from a
+// shared watch of the namespace and kind.
+func (i *Client) WaitForCRStatus(ctx context.Context, namespace string, kind string, name string, cond func(status *current.CRStatus) (bool, error)) (*current.CRStatus, error) {
+	var status *current.CRStatus
+	_, err := i.watcher(namespace, kind).Wait(kube.WithSpanOf(ctx, i.callContext()), namespace, name, func(obj interface{}) (bool, error) {
+		if obj == nil {
+			return cond(nil)
+		}
+		cr, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return false, errors.Errorf("unexpected object type %T in '%s' watch", obj, kind)
+		}
+
+		s, err := crStatus(cr)
+		if err != nil {
+			return false, err
+		}
+		status = s
+
+		return cond(status)
+	})
+	if err != nil {
+		if i.observer != nil && errors.Is(err, context.DeadlineExceeded) {
+			i.observer.WaitTimedOut(kind)
+		}
+		return status, errors.Wrapf(err, "failed waiting for status of '%s'", name)
+	}
+
+	return status, nil
+}
+
+func (i *Client) watcher(namespace, kind string) *kube.Watcher {
+	i.watches.mutex.Lock()
+	defer i.watches.mutex.Unlock()
+
+	key := namespace + "/" + kind
+	if w, found := i.watches.watchers[key]; found {
+		return w
+	}
+
+	informer := dynamicinformer.NewFilteredDynamicSharedInformerFactory(i.dynamic, 0, namespace, nil).
+		ForResource(SchemeGroupVersion.WithResource(kind)).Informer()
+	w := kube.NewWatcher(informer)
+	go informer.Run(i.watches.stopCh)
+	i.watches.watchers[key] = w
+
+	return w
+}
+
+// StopWatchers stops all the watches started by the client
+func (i *Client) StopWatchers() {
+	i.watches.mutex.Lock()
+	defer i.watches.mutex.Unlock()
+
+	select {
+	case <-i.watches.stopCh:
+	default:
+		close(i.watches.stopCh)
+	}
+}
+
+func crState(obj interface{}) *CRState {
+	cr, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+
+	state := &CRState{
+		Name:      cr.GetName(),
+		Namespace: cr.GetNamespace(),
+		Labels:    cr.GetLabels(),
+	}
+	status, err := crStatus(cr)
+	if err == nil {
+		state.Status = *status
+	}
+	replicas, found, err := unstructured.NestedInt64(cr.Object, "spec", "replicas")
+	if err == nil && found {
+		r := int32(replicas)
+		state.Replicas = &r
+	}
+
+	return state
+}
+
+// crStatus returns the status common to all the IBP CRs
+func crStatus(cr *unstructured.Unstructured) (*current.CRStatus, error) {
+	status := &current.CRStatus{}
+
+	s, found, err := unstructured.NestedMap(cr.Object, "status")
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid status in cr '%s'", cr.GetName())
+	}
+	if !found {
+		return status, nil
+	}
+
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(s, status)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid status in cr '%s'", cr.GetName())
+	}
+
+	return status, nil
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ibpoperator_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/ibpoperator"
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("Watch", func() {
+	var (
+		client  *ibpoperator.Client
+		dynamic *fake.FakeDynamicClient
+		gvr     schema.GroupVersionResource
+	)
+
+	newCA := func(name string, status current.CRStatus) *unstructured.Unstructured {
+		ca := &current.IBPCA{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: ibpoperator.SchemeGroupVersion.String(),
+				Kind:       "IBPCA",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Status: current.IBPCAStatus{
+				CRStatus: status,
+			},
+		}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ca)
+		Expect(err).NotTo(HaveOccurred())
+		return &unstructured.Unstructured{Object: obj}
+	}
+
+	statusSet := func(status *current.CRStatus) (bool, error) {
+		return status != nil && status.Status == current.True, nil
+	}
+
+	BeforeEach(func() {
+		gvr = ibpoperator.SchemeGroupVersion.WithResource("ibpcas")
+		dynamic = fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			gvr: "IBPCAList",
+		})
+		client = ibpoperator.NewForClients(nil, dynamic)
+	})
+
+	AfterEach(func() {
+		client.StopWatchers()
+	})
+
+	Context("wait for CR status", func() {
+		It("returns the status of a deployed CR", func() {
+			_, err := dynamic.Resource(gvr).Namespace("default").Create(context.TODO(), newCA("ca1", current.CRStatus{
+				Type:   current.Deployed,
+				Status: current.True,
+			}), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			status, err := client.WaitForCRStatus(ctx, "default", "ibpcas", "ca1", statusSet)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Type).To(Equal(current.Deployed))
+		})
+
+		It("returns the status once it is set by the operator", func() {
+			cr, err := dynamic.Resource(gvr).Namespace("default").Create(context.TODO(), newCA("ca1", current.CRStatus{}), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			go func() {
+				defer GinkgoRecover()
+				time.Sleep(100 * time.Millisecond)
+				err := unstructured.SetNestedMap(cr.Object, map[string]interface{}{
+					"type":   string(current.Error),
+					"status": string(current.True),
+				}, "status")
+				Expect(err).NotTo(HaveOccurred())
+				_, err = dynamic.Resource(gvr).Namespace("default").Update(context.TODO(), cr, metav1.UpdateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			status, err := client.WaitForCRStatus(ctx, "default", "ibpcas", "ca1", statusSet)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Type).To(Equal(current.Error))
+		})
+
+		It("returns an error if the status is not set before timeout", func() {
+			_, err := dynamic.Resource(gvr).Namespace("default").Create(context.TODO(), newCA("ca1", current.CRStatus{
+				Type:   current.Deploying,
+				Status: current.False,
+			}), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			observer := &fakeObserver{}
+			client.SetObserver(observer)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			status, err := client.WaitForCRStatus(ctx, "default", "ibpcas", "ca1", statusSet)
+			Expect(err).To(MatchError(ContainSubstring("context deadline exceeded")))
+			Expect(status.Type).To(Equal(current.Deploying))
+			Expect(observer.timedOut).To(Equal([]string{"ibpcas"}))
+		})
+
+		It("evaluates the condition with no status while the CR does not exist", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			status, err := client.WaitForCRStatus(ctx, "default", "ibpcas", "ca1", func(status *current.CRStatus) (bool, error) {
+				if status == nil {
+					return false, errors.New("cr not found")
+				}
+				return true, nil
+			})
+			Expect(err).To(MatchError(ContainSubstring("cr not found")))
+			Expect(status).To(BeNil())
+		})
+	})
+
+	Context("watch CR", func() {
+		type change struct {
+			kind string
+			old  *ibpoperator.CRState
+			cr   *ibpoperator.CRState
+		}
+
+		It("reports CRs being added, updated and deleted", func() {
+			changes := make(chan change, 10)
+			client.WatchCR("default", "ibpcas", func(kind string, old, cr *ibpoperator.CRState) {
+				changes <- change{kind, old, cr}
+			})
+
+			cr, err := dynamic.Resource(gvr).Namespace("default").Create(context.TODO(), newCA("ca1", current.CRStatus{}), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			var c change
+			Eventually(changes, 5*time.Second).Should(Receive(&c))
+			Expect(c.kind).To(Equal("ibpcas"))
+			Expect(c.old).To(BeNil())
+			Expect(c.cr.Name).To(Equal("ca1"))
+
+			Expect(unstructured.SetNestedField(cr.Object, int64(2), "spec", "replicas")).To(Succeed())
+			Expect(unstructured.SetNestedField(cr.Object, string(current.Deployed), "status", "type")).To(Succeed())
+			_, err = dynamic.Resource(gvr).Namespace("default").Update(context.TODO(), cr, metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(changes, 5*time.Second).Should(Receive(&c))
+			Expect(c.old.Status.Type).To(BeEmpty())
+			Expect(c.cr.Status.Type).To(Equal(current.Deployed))
+			Expect(*c.cr.Replicas).To(Equal(int32(2)))
+
+			err = dynamic.Resource(gvr).Namespace("default").Delete(context.TODO(), "ca1", metav1.DeleteOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(changes, 5*time.Second).Should(Receive(&c))
+			Expect(c.old.Name).To(Equal("ca1"))
+			Expect(c.cr).To(BeNil())
+		})
+	})
+})
+
+type fakeObserver struct {
+	timedOut []string
+}
+
+func (o *fakeObserver) WaitTimedOut(resource string) {
+	o.timedOut = append(o.timedOut, resource)
+}
+
+func (o *fakeObserver) CRChanged(operation, namespace, kind, name string) {}
@@ -0,0 +1,110 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/events"
+)
+
+type namespaceKey struct{}
+
+// InstanceMiddleware resolves the namespace of the service instance of the
+// request and rejects the requests to components of another service
+// instance. It must be used on routed requests.
+func (d *Deployer) InstanceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sID := chi.URLParam(r, "serviceInstanceID")
+		instances := d.target(r).Instances
+		namespace, err := instances.NamespaceOf(d.clusterComponents(r).Config.Instances, sID)
+
+		// creates are checked by the components, the CR does not exist yet
+		compName := chi.URLParam(r, "componentName")
+		if err == nil && compName != "" && r.Method != http.MethodPost {
+			err = instances.CheckComponent(sID, namespace, chi.URLParam(r, "type"), compName)
+		}
+		if err != nil {
+			NewEndpoint(func(http.ResponseWriter, *http.Request) (interface{}, int, error) {
+				return nil, 0, err
+			}, d.LocalConfig.Logger).ServeHTTP(w, r)
+			return
+		}
+
+		if d.Metrics != nil {
+			d.watchMetrics(d.target(r), namespace)
+		}
+
+		ctx := context.WithValue(r.Context(), namespaceKey{}, namespace)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// namespace returns the namespace of the service instance of the request,
+// the namespace of the cluster of the request for requests not routed
+// through the InstanceMiddleware
+func (d *Deployer) namespace(r *http.Request) string {
+	if r != nil {
+		if namespace, ok := r.Context().Value(namespaceKey{}).(string); ok {
+			return namespace
+		}
+	}
+	return d.clusterComponents(r).Config.Namespace
+}
+
+// watchEvents starts the CR watches of the namespace of the cluster of the
+// request once, they are kept running so that clients can resume their
+// streams
+func (d *Deployer) watchEvents(r *http.Request, namespace string) {
+	d.eventWatchesMutex.Lock()
+	defer d.eventWatchesMutex.Unlock()
+
+	cluster := clusterOf(r)
+	key := cluster + "/" + namespace
+	if d.eventWatches[key] {
+		return
+	}
+	if d.eventWatches == nil {
+		d.eventWatches = map[string]bool{}
+	}
+	handler := d.Events.HandleCRChange
+	if cluster != "" {
+		handler = d.Events.ClusterHandler(cluster)
+	}
+	for _, kind := range []string{"ibpcas", "ibppeers", "ibporderers"} {
+		d.target(r).IBPOperatorClient.WatchCR(namespace, kind, handler)
+	}
+	d.eventWatches[key] = true
+}
+
+// instanceEvents returns the filter of the events of the components of the
+// service instance in the cluster
+func instanceEvents(sID, cluster, namespace string) func(events.Event) bool {
+	return func(event events.Event) bool {
+		labels := map[string]string{}
+		if event.ServiceInstanceID != "" {
+			labels[common.ServiceInstanceLabel] = event.ServiceInstanceID
+		}
+		return event.Cluster == cluster && event.Namespace == namespace && common.BelongsTo(labels, sID)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package instance maps the service instances to the namespaces of their
+// components and checks that the components of a request belong to the
+// service instance of the request.
+package instance
+
+import (
+	"fmt"
+	"strings"
+
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer"
+)
+
+//go:generate counterfeiter -o mocks/kube.go -fake-name Kube . Kube
+
+type Kube interface {
+	ListNamespaces(labelSelector string) ([]corev1.Namespace, error)
+}
+
+//go:generate counterfeiter -o mocks/ibp_client.go -fake-name IBPOperatorClient . IBPOperatorClient
+
+type IBPOperatorClient interface {
+	GetCR(namespace string, kind string, name string, cr runtime.Object) error
+}
+
+type Resolver struct {
+	Kube              Kube
+	IBPOperatorClient IBPOperatorClient
+	// Namespace is the namespace of the deployer, the components of the
+	// service instances that are not mapped are in this namespace
+	Namespace string
+	Logger    *zap.SugaredLogger
+}
+
+func New(logger *zap.Logger, kube Kube, ibpClient IBPOperatorClient, namespace string) *Resolver {
+	return &Resolver{
+		Kube:              kube,
+		IBPOperatorClient: ibpClient,
+		Namespace:         namespace,
+		Logger:            logger.Sugar().Named("Instance"),
+	}
+}
+
+// NamespaceOf returns the namespace of the components of the service
+// instance, from the mapping of the instances or else the namespace labelled
+// with the service instance ID
+func (r *Resolver) NamespaceOf(instances *config.Instances, sID string) (string, error) {
+	if errs := validation.IsValidLabelValue(sID); len(errs) > 0 {
+		return "", apierror.InvalidField("serviceInstanceID", "service instance ID '%s' not valid: %s", sID, strings.Join(errs, ", "))
+	}
+	if instances == nil {
+		return r.Namespace, nil
+	}
+
+	if namespace, found := instances.Namespaces[sID]; found {
+		return namespace, nil
+	}
+
+	if instances.NamespaceLabel != "" {
+		namespaces, err := r.Kube.ListNamespaces(fmt.Sprintf("%s=%s", instances.NamespaceLabel, sID))
+		if err != nil {
+			return "", apierror.Wrap(err, apierror.Upstream, apierror.CodeUpstream, "failed to list the namespaces of service instance '%s'", sID)
+		}
+		switch {
+		case len(namespaces) == 1:
+			return namespaces[0].Name, nil
+		case len(namespaces) > 1:
+			return "", apierror.New(apierror.Conflict, apierror.CodeConflict, "service instance '%s' is labelled on %d namespaces", sID, len(namespaces))
+		}
+	}
+
+	if instances.Strict {
+		return "", apierror.New(apierror.NotFound, apierror.CodeNotFound, "service instance '%s' not found", sID)
+	}
+	return r.Namespace, nil
+}
+
+// CheckComponent returns a forbidden error if the component belongs to
+// another service instance. Components that do not exist are not checked.
+func (r *Resolver) CheckComponent(sID, namespace, componentType, compName string) error {
+	if compName == "" {
+		return nil
+	}
+
+	var kind string
+	var cr interface {
+		runtime.Object
+		metav1.Object
+	}
+	switch componentType {
+	case "ca":
+		kind, cr = "ibpcas", &current.IBPCA{}
+	case "peer":
+		kind, cr = "ibppeers", &current.IBPPeer{}
+	case "orderer":
+		kind, cr = "ibporderers", &current.IBPOrderer{}
+	default:
+		return nil
+	}
+
+	err := r.IBPOperatorClient.GetCR(namespace, kind, compName, cr)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return apierror.Wrap(err, apierror.Upstream, apierror.CodeUpstream, "failed to get cr for '%s' in namespace '%s'", compName, namespace)
+	}
+
+	labels := cr.GetLabels()
+	if node, ok := cr.(*current.IBPOrderer); ok && labels["parent"] != "" {
+		parent := &current.IBPOrderer{}
+		err = r.IBPOperatorClient.GetCR(namespace, kind, labels["parent"], parent)
+		if err == nil {
+			labels = orderer.ServiceInstanceLabels(node, parent)
+		}
+	}
+
+	if !common.BelongsTo(labels, sID) {
+		r.Logger.Infof("Request of service instance '%s' for %s '%s' of another service instance denied", sID, componentType, compName)
+		return apierror.New(apierror.Forbidden, apierror.CodeForbidden, "%s '%s' belongs to another service instance", componentType, compName)
+	}
+	return nil
+}
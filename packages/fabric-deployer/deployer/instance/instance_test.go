@@ -0,0 +1,172 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance_test
+
+import (
+	"errors"
+	"net/http"
+
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/instance"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/instance/mocks"
+)
+
+var _ = Describe("Instance", func() {
+	var (
+		resolver  *instance.Resolver
+		mockKube  *mocks.Kube
+		mockIBP   *mocks.IBPOperatorClient
+		instances *config.Instances
+	)
+
+	BeforeEach(func() {
+		mockKube = &mocks.Kube{}
+		mockIBP = &mocks.IBPOperatorClient{}
+		resolver = instance.New(zap.NewNop(), mockKube, mockIBP, "deployer")
+		instances = &config.Instances{
+			Namespaces:     map[string]string{"sid1": "fabric-sid1"},
+			NamespaceLabel: "fabric/instance",
+		}
+	})
+
+	Context("namespace", func() {
+		It("uses the namespace of the deployer without instances", func() {
+			namespace, err := resolver.NamespaceOf(nil, "sid1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespace).To(Equal("deployer"))
+		})
+
+		It("uses the namespace the service instance is mapped to", func() {
+			namespace, err := resolver.NamespaceOf(instances, "sid1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespace).To(Equal("fabric-sid1"))
+			Expect(mockKube.ListNamespacesCallCount()).To(Equal(0))
+		})
+
+		It("uses the namespace labelled with the service instance ID", func() {
+			mockKube.ListNamespacesReturns([]corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "fabric-sid2"}}}, nil)
+
+			namespace, err := resolver.NamespaceOf(instances, "sid2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespace).To(Equal("fabric-sid2"))
+			Expect(mockKube.ListNamespacesArgsForCall(0)).To(Equal("fabric/instance=sid2"))
+		})
+
+		It("returns an error if the service instance is labelled on several namespaces", func() {
+			mockKube.ListNamespacesReturns([]corev1.Namespace{{}, {}}, nil)
+
+			_, err := resolver.NamespaceOf(instances, "sid2")
+			Expect(err).To(MatchError("service instance 'sid2' is labelled on 2 namespaces"))
+			Expect(apierror.StatusCode(err)).To(Equal(http.StatusConflict))
+		})
+
+		It("returns an upstream error if the namespaces cannot be listed", func() {
+			mockKube.ListNamespacesReturns(nil, errors.New("forbidden"))
+
+			_, err := resolver.NamespaceOf(instances, "sid2")
+			Expect(apierror.StatusCode(err)).To(Equal(http.StatusBadGateway))
+		})
+
+		It("uses the namespace of the deployer for service instances that are not mapped", func() {
+			namespace, err := resolver.NamespaceOf(instances, "sid2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespace).To(Equal("deployer"))
+		})
+
+		It("rejects service instances that are not mapped if strict", func() {
+			instances.Strict = true
+
+			_, err := resolver.NamespaceOf(instances, "sid2")
+			Expect(err).To(MatchError("service instance 'sid2' not found"))
+			Expect(apierror.StatusCode(err)).To(Equal(http.StatusNotFound))
+		})
+
+		It("rejects service instance IDs that are not valid label values", func() {
+			_, err := resolver.NamespaceOf(nil, "sid/1")
+			Expect(err).To(HaveOccurred())
+			Expect(apierror.StatusCode(err)).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("component", func() {
+		labelled := func(sID string) map[string]string {
+			return map[string]string{common.ServiceInstanceLabel: sID}
+		}
+
+		It("allows the components of the service instance", func() {
+			mockIBP.GetCRStub = func(namespace, kind, name string, cr runtime.Object) error {
+				cr.(*current.IBPPeer).Labels = labelled("sid1")
+				return nil
+			}
+
+			Expect(resolver.CheckComponent("sid1", "fabric-sid1", "peer", "peer1")).To(Succeed())
+			namespace, kind, name, _ := mockIBP.GetCRArgsForCall(0)
+			Expect(namespace).To(Equal("fabric-sid1"))
+			Expect(kind).To(Equal("ibppeers"))
+			Expect(name).To(Equal("peer1"))
+		})
+
+		It("allows the components without label", func() {
+			Expect(resolver.CheckComponent("sid1", "fabric-sid1", "ca", "ca1")).To(Succeed())
+		})
+
+		It("allows the components that do not exist", func() {
+			mockIBP.GetCRReturns(k8serrors.NewNotFound(schema.GroupResource{Resource: "ibpcas"}, "ca1"))
+			Expect(resolver.CheckComponent("sid1", "fabric-sid1", "ca", "ca1")).To(Succeed())
+		})
+
+		It("rejects the components of another service instance", func() {
+			mockIBP.GetCRStub = func(namespace, kind, name string, cr runtime.Object) error {
+				cr.(*current.IBPPeer).Labels = labelled("sid2")
+				return nil
+			}
+
+			err := resolver.CheckComponent("sid1", "fabric-sid1", "peer", "peer1")
+			Expect(err).To(MatchError("peer 'peer1' belongs to another service instance"))
+			Expect(apierror.StatusCode(err)).To(Equal(http.StatusForbidden))
+		})
+
+		It("rejects the orderer nodes of a cluster of another service instance", func() {
+			mockIBP.GetCRStub = func(namespace, kind, name string, cr runtime.Object) error {
+				if name == "os1node1" {
+					cr.(*current.IBPOrderer).Labels = map[string]string{"parent": "os1"}
+				} else {
+					cr.(*current.IBPOrderer).Labels = labelled("sid2")
+				}
+				return nil
+			}
+
+			err := resolver.CheckComponent("sid1", "fabric-sid1", "orderer", "os1node1")
+			Expect(apierror.StatusCode(err)).To(Equal(http.StatusForbidden))
+			Expect(mockIBP.GetCRCallCount()).To(Equal(2))
+		})
+	})
+})
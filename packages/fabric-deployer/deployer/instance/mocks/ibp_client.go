@@ -0,0 +1,118 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"sync"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/instance"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type IBPOperatorClient struct {
+	GetCRStub        func(string, string, string, runtime.Object) error
+	getCRMutex       sync.RWMutex
+	getCRArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 runtime.Object
+	}
+	getCRReturns struct {
+		result1 error
+	}
+	getCRReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *IBPOperatorClient) GetCR(arg1 string, arg2 string, arg3 string, arg4 runtime.Object) error {
+	fake.getCRMutex.Lock()
+	ret, specificReturn := fake.getCRReturnsOnCall[len(fake.getCRArgsForCall)]
+	fake.getCRArgsForCall = append(fake.getCRArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 runtime.Object
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.GetCRStub
+	fakeReturns := fake.getCRReturns
+	fake.recordInvocation("GetCR", []interface{}{arg1, arg2, arg3, arg4})
+	fake.getCRMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *IBPOperatorClient) GetCRCallCount() int {
+	fake.getCRMutex.RLock()
+	defer fake.getCRMutex.RUnlock()
+	return len(fake.getCRArgsForCall)
+}
+
+func (fake *IBPOperatorClient) GetCRCalls(stub func(string, string, string, runtime.Object) error) {
+	fake.getCRMutex.Lock()
+	defer fake.getCRMutex.Unlock()
+	fake.GetCRStub = stub
+}
+
+func (fake *IBPOperatorClient) GetCRArgsForCall(i int) (string, string, string, runtime.Object) {
+	fake.getCRMutex.RLock()
+	defer fake.getCRMutex.RUnlock()
+	argsForCall := fake.getCRArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *IBPOperatorClient) GetCRReturns(result1 error) {
+	fake.getCRMutex.Lock()
+	defer fake.getCRMutex.Unlock()
+	fake.GetCRStub = nil
+	fake.getCRReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *IBPOperatorClient) GetCRReturnsOnCall(i int, result1 error) {
+	fake.getCRMutex.Lock()
+	defer fake.getCRMutex.Unlock()
+	fake.GetCRStub = nil
+	if fake.getCRReturnsOnCall == nil {
+		fake.getCRReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.getCRReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *IBPOperatorClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getCRMutex.RLock()
+	defer fake.getCRMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *IBPOperatorClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ instance.IBPOperatorClient = new(IBPOperatorClient)
@@ -0,0 +1,117 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"sync"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/instance"
+	v1 "k8s.io/api/core/v1"
+)
+
+type Kube struct {
+	ListNamespacesStub        func(string) ([]v1.Namespace, error)
+	listNamespacesMutex       sync.RWMutex
+	listNamespacesArgsForCall []struct {
+		arg1 string
+	}
+	listNamespacesReturns struct {
+		result1 []v1.Namespace
+		result2 error
+	}
+	listNamespacesReturnsOnCall map[int]struct {
+		result1 []v1.Namespace
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *Kube) ListNamespaces(arg1 string) ([]v1.Namespace, error) {
+	fake.listNamespacesMutex.Lock()
+	ret, specificReturn := fake.listNamespacesReturnsOnCall[len(fake.listNamespacesArgsForCall)]
+	fake.listNamespacesArgsForCall = append(fake.listNamespacesArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ListNamespacesStub
+	fakeReturns := fake.listNamespacesReturns
+	fake.recordInvocation("ListNamespaces", []interface{}{arg1})
+	fake.listNamespacesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Kube) ListNamespacesCallCount() int {
+	fake.listNamespacesMutex.RLock()
+	defer fake.listNamespacesMutex.RUnlock()
+	return len(fake.listNamespacesArgsForCall)
+}
+
+func (fake *Kube) ListNamespacesCalls(stub func(string) ([]v1.Namespace, error)) {
+	fake.listNamespacesMutex.Lock()
+	defer fake.listNamespacesMutex.Unlock()
+	fake.ListNamespacesStub = stub
+}
+
+func (fake *Kube) ListNamespacesArgsForCall(i int) string {
+	fake.listNamespacesMutex.RLock()
+	defer fake.listNamespacesMutex.RUnlock()
+	argsForCall := fake.listNamespacesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Kube) ListNamespacesReturns(result1 []v1.Namespace, result2 error) {
+	fake.listNamespacesMutex.Lock()
+	defer fake.listNamespacesMutex.Unlock()
+	fake.ListNamespacesStub = nil
+	fake.listNamespacesReturns = struct {
+		result1 []v1.Namespace
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) ListNamespacesReturnsOnCall(i int, result1 []v1.Namespace, result2 error) {
+	fake.listNamespacesMutex.Lock()
+	defer fake.listNamespacesMutex.Unlock()
+	fake.ListNamespacesStub = nil
+	if fake.listNamespacesReturnsOnCall == nil {
+		fake.listNamespacesReturnsOnCall = make(map[int]struct {
+			result1 []v1.Namespace
+			result2 error
+		})
+	}
+	fake.listNamespacesReturnsOnCall[i] = struct {
+		result1 []v1.Namespace
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.listNamespacesMutex.RLock()
+	defer fake.listNamespacesMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *Kube) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ instance.Kube = new(Kube)
@@ -22,20 +22,51 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
 	"github.com/IBM-Blockchain/fabric-deployer/offering"
 	"github.com/pkg/errors"
 
+	authenticationv1 "k8s.io/api/authentication/v1"
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
 type Kube struct {
-	clientset *kubernetes.Clientset
+	clientset kubernetes.Interface
+	// dynamic is used for the resources without a typed client, e.g. volume
+	// snapshots
+	dynamic dynamic.Interface
+
+	// watches are shared by the copies of the client bound to a context
+	watches *watches
+
+	observer Observer
+
+	// ctx is the context of the calls to the API server, it carries the span
+	// of the request the client is bound to
+	ctx context.Context
+}
+
+// watches holds the configmap watchers, they are started on first use, one
+// per namespace
+type watches struct {
+	configMapWatchers map[string]*Watcher
+	mutex             sync.Mutex
+	stopCh            chan struct{}
+}
+
+// Observer is notified of the waits of the client that time out, e.g. to
+// count them in metrics
+type Observer interface {
+	WaitTimedOut(resource string)
 }
 
 func InClusterConfig() (*rest.Config, error) {
@@ -47,30 +78,88 @@ func NewForConfig(config *rest.Config) (*Kube, error) {
 	if err != nil {
 		return nil, err
 	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewForClients(clientSet, dynamicClient), nil
+}
+
+// NewForClients returns a client using the passed typed and dynamic clients
+func NewForClients(clientset kubernetes.Interface, dynamicClient dynamic.Interface) *Kube {
+	k := NewForClientset(clientset)
+	k.dynamic = dynamicClient
+	return k
+}
+
+func NewForClientset(clientset kubernetes.Interface) *Kube {
 	return &Kube{
-		clientset: clientSet,
-	}, nil
+		clientset: clientset,
+		watches: &watches{
+			configMapWatchers: map[string]*Watcher{},
+			stopCh:            make(chan struct{}),
+		},
+		ctx: context.Background(),
+	}
+}
+
+// WithContext returns a copy of the client whose calls to the API server use
+// ctx, e.g. so that they are traced as part of a request. The copy shares the
+// watches of the client.
+func (k *Kube) WithContext(ctx context.Context) *Kube {
+	c := *k
+	c.ctx = ctx
+	return &c
+}
+
+// Context returns the context the client is bound to, the background context
+// if it is not bound
+func (k *Kube) Context() context.Context {
+	if k.ctx == nil {
+		return context.Background()
+	}
+	return k.ctx
+}
+
+// SetObserver sets the observer of the client, it must be set before the
+// client is used
+func (k *Kube) SetObserver(observer Observer) {
+	k.observer = observer
 }
 
 func (k *Kube) GetNamespaces() (*apiv1.NamespaceList, error) {
-	return k.clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	return k.clientset.CoreV1().Namespaces().List(k.Context(), metav1.ListOptions{})
 }
 
 // GetVersion returns back kubernetes server version
 func (k *Kube) GetVersion() (*version.Info, error) {
-	v, err := k.clientset.DiscoveryClient.ServerVersion()
+	v, err := k.clientset.Discovery().ServerVersion()
 	if err != nil {
 		return nil, errors.Wrap(err, "call to kubernetes API server failed")
 	}
 	return v, nil
 }
 
+// ReviewToken validates the bearer token with the TokenReview API
+func (k *Kube) ReviewToken(ctx context.Context, token string, audiences []string) (*authenticationv1.TokenReviewStatus, error) {
+	review, err := k.clientset.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token:     token,
+			Audiences: audiences,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to review token")
+	}
+	return &review.Status, nil
+}
+
 func (k *Kube) GetService(namespace, name string) (*apiv1.Service, error) {
-	return k.clientset.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	return k.clientset.CoreV1().Services(namespace).Get(k.Context(), name, metav1.GetOptions{})
 }
 
 func (k *Kube) CreateService(namespace string, service *apiv1.Service) (*apiv1.Service, error) {
-	service, err := k.clientset.CoreV1().Services(namespace).Create(context.TODO(), service, metav1.CreateOptions{})
+	service, err := k.clientset.CoreV1().Services(namespace).Create(k.Context(), service, metav1.CreateOptions{})
 	if err != nil {
 		if !strings.Contains(err.Error(), "already exists") {
 			return nil, errors.Wrap(err, "failed to create service")
@@ -81,11 +170,11 @@ func (k *Kube) CreateService(namespace string, service *apiv1.Service) (*apiv1.S
 }
 
 func (k *Kube) DeleteService(namespace, name string) error {
-	return k.clientset.CoreV1().Services(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	return k.clientset.CoreV1().Services(namespace).Delete(k.Context(), name, metav1.DeleteOptions{})
 }
 
 func (k *Kube) CreateConfigMap(namespace string, cm *apiv1.ConfigMap) (*apiv1.ConfigMap, error) {
-	configMap, err := k.clientset.CoreV1().ConfigMaps(namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+	configMap, err := k.clientset.CoreV1().ConfigMaps(namespace).Create(k.Context(), cm, metav1.CreateOptions{})
 	if err != nil {
 		if !strings.Contains(err.Error(), "already exists") {
 			return nil, errors.Wrap(err, "failed to create config map")
@@ -95,11 +184,64 @@ func (k *Kube) CreateConfigMap(namespace string, cm *apiv1.ConfigMap) (*apiv1.Co
 }
 
 func (k *Kube) GetConfigMap(namespace, name string) (*apiv1.ConfigMap, error) {
-	return k.clientset.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	return k.clientset.CoreV1().ConfigMaps(namespace).Get(k.Context(), name, metav1.GetOptions{})
+}
+
+// WaitForConfigMap blocks until the config map exists or ctx is done. Config
+// maps are served from a shared watch of the namespace.
+func (k *Kube) WaitForConfigMap(ctx context.Context, namespace, name string) (*apiv1.ConfigMap, error) {
+	obj, err := k.configMapWatcher(namespace).Wait(WithSpanOf(ctx, k.Context()), namespace, name, func(obj interface{}) (bool, error) {
+		return obj != nil, nil
+	})
+	if err != nil {
+		if k.observer != nil && errors.Is(err, context.DeadlineExceeded) {
+			k.observer.WaitTimedOut("configmaps")
+		}
+		return nil, errors.Wrapf(err, "failed waiting for config map '%s'", name)
+	}
+
+	return obj.(*apiv1.ConfigMap), nil
+}
+
+func (k *Kube) configMapWatcher(namespace string) *Watcher {
+	k.watches.mutex.Lock()
+	defer k.watches.mutex.Unlock()
+
+	if w, found := k.watches.configMapWatchers[namespace]; found {
+		return w
+	}
+
+	informer := informers.NewSharedInformerFactoryWithOptions(k.clientset, 0, informers.WithNamespace(namespace)).
+		Core().V1().ConfigMaps().Informer()
+	w := NewWatcher(informer)
+	go informer.Run(k.watches.stopCh)
+	k.watches.configMapWatchers[namespace] = w
+
+	return w
+}
+
+// StopWatchers stops all the watches started by the client
+func (k *Kube) StopWatchers() {
+	k.watches.mutex.Lock()
+	defer k.watches.mutex.Unlock()
+
+	select {
+	case <-k.watches.stopCh:
+	default:
+		close(k.watches.stopCh)
+	}
+}
+
+func (k *Kube) UpdateConfigMap(namespace string, cm *apiv1.ConfigMap) (*apiv1.ConfigMap, error) {
+	return k.clientset.CoreV1().ConfigMaps(namespace).Update(k.Context(), cm, metav1.UpdateOptions{})
+}
+
+func (k *Kube) DeleteConfigMap(namespace, name string) error {
+	return k.clientset.CoreV1().ConfigMaps(namespace).Delete(k.Context(), name, metav1.DeleteOptions{})
 }
 
 func (k *Kube) CreateSecret(namespace string, secret *apiv1.Secret) (*apiv1.Secret, error) {
-	secret, err := k.clientset.CoreV1().Secrets(namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+	secret, err := k.clientset.CoreV1().Secrets(namespace).Create(k.Context(), secret, metav1.CreateOptions{})
 	if err != nil {
 		if !strings.Contains(err.Error(), "already exists") {
 			return nil, errors.Wrap(err, "failed to create secret")
@@ -109,11 +251,11 @@ func (k *Kube) CreateSecret(namespace string, secret *apiv1.Secret) (*apiv1.Secr
 }
 
 func (k *Kube) GetSecret(namespace string, name string) (*apiv1.Secret, error) {
-	return k.clientset.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	return k.clientset.CoreV1().Secrets(namespace).Get(k.Context(), name, metav1.GetOptions{})
 }
 
 func (k *Kube) UpdateSecret(namespace, name, path string, data []byte) (*apiv1.Secret, error) {
-	return k.clientset.CoreV1().Secrets(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, data, metav1.PatchOptions{}, path)
+	return k.clientset.CoreV1().Secrets(namespace).Patch(k.Context(), name, types.StrategicMergePatchType, data, metav1.PatchOptions{}, path)
 }
 
 func (k *Kube) DeleteAndCreateSecret(namespace string, secret *apiv1.Secret) (*apiv1.Secret, error) {
@@ -126,11 +268,11 @@ func (k *Kube) DeleteAndCreateSecret(namespace string, secret *apiv1.Secret) (*a
 }
 
 func (k *Kube) DeleteSecret(namespace string, secretName string) error {
-	return k.clientset.CoreV1().Secrets(namespace).Delete(context.TODO(), secretName, metav1.DeleteOptions{})
+	return k.clientset.CoreV1().Secrets(namespace).Delete(k.Context(), secretName, metav1.DeleteOptions{})
 }
 
 func (k *Kube) GetPort(namespace, name string) (int32, error) {
-	service, err := k.clientset.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	service, err := k.clientset.CoreV1().Services(namespace).Get(k.Context(), name, metav1.GetOptions{})
 	if err != nil {
 		return 0, err
 	}
@@ -138,7 +280,7 @@ func (k *Kube) GetPort(namespace, name string) (int32, error) {
 }
 
 func (k *Kube) GetPorts(namespace, name string) ([]apiv1.ServicePort, error) {
-	service, err := k.clientset.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	service, err := k.clientset.CoreV1().Services(namespace).Get(k.Context(), name, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -146,33 +288,33 @@ func (k *Kube) GetPorts(namespace, name string) ([]apiv1.ServicePort, error) {
 }
 
 func (k *Kube) DeleteDeployment(namespace string, depName string) error {
-	return k.clientset.AppsV1().Deployments(namespace).Delete(context.TODO(), depName, metav1.DeleteOptions{})
+	return k.clientset.AppsV1().Deployments(namespace).Delete(k.Context(), depName, metav1.DeleteOptions{})
 }
 
 func (k *Kube) GetPodsByLabel(namespace, name string) (*apiv1.Pod, error) {
-	podsList, err := k.clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+	podsList, err := k.clientset.CoreV1().Pods(namespace).List(k.Context(), metav1.ListOptions{
 		LabelSelector: fmt.Sprintf("app=%s", name),
 	})
 	if err != nil {
 		return nil, err
 	}
 	if podsList == nil || len(podsList.Items) == 0 {
-		return nil, errors.New("pod not found")
+		return nil, apierror.New(apierror.NotFound, apierror.CodeNotFound, "pod not found")
 	}
 	pod := podsList.Items[0]
 	return &pod, nil
 }
 
 func (k *Kube) CreatePod(namespace string, pod *apiv1.Pod) (*apiv1.Pod, error) {
-	return k.clientset.CoreV1().Pods(namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+	return k.clientset.CoreV1().Pods(namespace).Create(k.Context(), pod, metav1.CreateOptions{})
 }
 
 func (k *Kube) DeletePod(namespace string, name string) error {
-	return k.clientset.CoreV1().Pods(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	return k.clientset.CoreV1().Pods(namespace).Delete(k.Context(), name, metav1.DeleteOptions{})
 }
 
 func (k *Kube) DeleteAllPodsMatchingLabel(namespace string, label string) error {
-	podsList, getPodsByLabelErr := k.clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+	podsList, getPodsByLabelErr := k.clientset.CoreV1().Pods(namespace).List(k.Context(), metav1.ListOptions{
 		LabelSelector: label,
 	})
 	if getPodsByLabelErr != nil {
@@ -219,3 +361,43 @@ func (k *Kube) ClusterType(namespace string) string {
 	}
 	return clusterType
 }
+
+// ListResourceQuotas returns the resource quotas of the namespace
+func (k *Kube) ListResourceQuotas(namespace string) ([]apiv1.ResourceQuota, error) {
+	list, err := k.clientset.CoreV1().ResourceQuotas(namespace).List(k.Context(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListNodes returns the nodes of the cluster
+func (k *Kube) ListNodes() ([]apiv1.Node, error) {
+	list, err := k.clientset.CoreV1().Nodes().List(k.Context(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListNamespaces returns the namespaces that match the label selector
+func (k *Kube) ListNamespaces(labelSelector string) ([]apiv1.Namespace, error) {
+	list, err := k.clientset.CoreV1().Namespaces().List(k.Context(), metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListPods returns the pods of all the namespaces that are not terminated
+func (k *Kube) ListPods() ([]apiv1.Pod, error) {
+	list, err := k.clientset.CoreV1().Pods("").List(k.Context(), metav1.ListOptions{
+		FieldSelector: "status.phase!=Succeeded,status.phase!=Failed",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
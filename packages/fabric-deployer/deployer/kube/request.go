@@ -0,0 +1,53 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kube
+
+import (
+	"net/http"
+	"strings"
+)
+
+// VerbOf returns the verb of a Kubernetes API call, that is the HTTP method
+// or WATCH for the watches
+func VerbOf(req *http.Request) string {
+	if req.URL.Query().Get("watch") == "true" {
+		return "WATCH"
+	}
+	return req.Method
+}
+
+// ResourceOf returns the resource of the path of a Kubernetes API call, e.g.
+// configmaps for /api/v1/namespaces/ns/configmaps/name, or the path for the
+// calls that are not to a resource, e.g. /version
+func ResourceOf(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(segments) >= 3 && segments[0] == "api":
+		segments = segments[2:]
+	case len(segments) >= 4 && segments[0] == "apis":
+		segments = segments[3:]
+	default:
+		return path
+	}
+
+	if len(segments) >= 3 && segments[0] == "namespaces" {
+		return segments[2]
+	}
+	return segments[0]
+}
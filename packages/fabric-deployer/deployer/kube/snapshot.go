@@ -0,0 +1,139 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kube
+
+import (
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// VolumeSnapshotGroup is the API group of the volume snapshots of the CSI
+// external snapshotter
+const VolumeSnapshotGroup = "snapshot.storage.k8s.io"
+
+var volumeSnapshotResource = schema.GroupVersionResource{Group: VolumeSnapshotGroup, Version: "v1", Resource: "volumesnapshots"}
+
+// VolumeSnapshot is the subset of the snapshot.storage.k8s.io/v1
+// VolumeSnapshot used by the deployer
+type VolumeSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VolumeSnapshotSpec    `json:"spec"`
+	Status *VolumeSnapshotStatus `json:"status,omitempty"`
+}
+
+type VolumeSnapshotSpec struct {
+	Source                  VolumeSnapshotSource `json:"source"`
+	VolumeSnapshotClassName *string              `json:"volumeSnapshotClassName,omitempty"`
+}
+
+type VolumeSnapshotSource struct {
+	PersistentVolumeClaimName *string `json:"persistentVolumeClaimName,omitempty"`
+}
+
+type VolumeSnapshotStatus struct {
+	CreationTime *metav1.Time         `json:"creationTime,omitempty"`
+	ReadyToUse   *bool                `json:"readyToUse,omitempty"`
+	RestoreSize  *resource.Quantity   `json:"restoreSize,omitempty"`
+	Error        *VolumeSnapshotError `json:"error,omitempty"`
+}
+
+type VolumeSnapshotError struct {
+	Time    *metav1.Time `json:"time,omitempty"`
+	Message *string      `json:"message,omitempty"`
+}
+
+func (k *Kube) GetPVC(namespace, name string) (*apiv1.PersistentVolumeClaim, error) {
+	return k.clientset.CoreV1().PersistentVolumeClaims(namespace).Get(k.Context(), name, metav1.GetOptions{})
+}
+
+func (k *Kube) CreatePVC(namespace string, pvc *apiv1.PersistentVolumeClaim) (*apiv1.PersistentVolumeClaim, error) {
+	return k.clientset.CoreV1().PersistentVolumeClaims(namespace).Create(k.Context(), pvc, metav1.CreateOptions{})
+}
+
+func (k *Kube) CreateVolumeSnapshot(namespace string, snapshot *VolumeSnapshot) (*VolumeSnapshot, error) {
+	if k.dynamic == nil {
+		return nil, errors.New("dynamic client is not configured")
+	}
+
+	snapshot.APIVersion = volumeSnapshotResource.GroupVersion().String()
+	snapshot.Kind = "VolumeSnapshot"
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(snapshot)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert volume snapshot")
+	}
+
+	created, err := k.dynamic.Resource(volumeSnapshotResource).Namespace(namespace).Create(k.Context(), &unstructured.Unstructured{Object: object}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return toVolumeSnapshot(created)
+}
+
+func (k *Kube) GetVolumeSnapshot(namespace, name string) (*VolumeSnapshot, error) {
+	if k.dynamic == nil {
+		return nil, errors.New("dynamic client is not configured")
+	}
+
+	object, err := k.dynamic.Resource(volumeSnapshotResource).Namespace(namespace).Get(k.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return toVolumeSnapshot(object)
+}
+
+// ListVolumeSnapshots returns the volume snapshots of the namespace matching
+// the label selector
+func (k *Kube) ListVolumeSnapshots(namespace, labelSelector string) ([]VolumeSnapshot, error) {
+	if k.dynamic == nil {
+		return nil, errors.New("dynamic client is not configured")
+	}
+
+	list, err := k.dynamic.Resource(volumeSnapshotResource).Namespace(namespace).List(k.Context(), metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []VolumeSnapshot{}
+	for i := range list.Items {
+		snapshot, err := toVolumeSnapshot(&list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, *snapshot)
+	}
+	return snapshots, nil
+}
+
+func toVolumeSnapshot(object *unstructured.Unstructured) (*VolumeSnapshot, error) {
+	snapshot := &VolumeSnapshot{}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, snapshot)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to convert volume snapshot '%s'", object.GetName())
+	}
+	return snapshot, nil
+}
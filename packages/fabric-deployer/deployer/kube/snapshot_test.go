@@ -0,0 +1,70 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kube_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/kube"
+)
+
+var _ = Describe("Volume snapshots", func() {
+	var k *kube.Kube
+
+	BeforeEach(func() {
+		resource := schema.GroupVersionResource{Group: kube.VolumeSnapshotGroup, Version: "v1", Resource: "volumesnapshots"}
+		dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			resource: "VolumeSnapshotList",
+		})
+		k = kube.NewForClients(fake.NewSimpleClientset(), dynamicClient)
+	})
+
+	It("creates, gets and lists volume snapshots", func() {
+		pvc := "peer1-pvc"
+		_, err := k.CreateVolumeSnapshot("ns1", &kube.VolumeSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: "snap1-peer", Labels: map[string]string{"app": "peer1"}},
+			Spec:       kube.VolumeSnapshotSpec{Source: kube.VolumeSnapshotSource{PersistentVolumeClaimName: &pvc}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		snapshot, err := k.GetVolumeSnapshot("ns1", "snap1-peer")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*snapshot.Spec.Source.PersistentVolumeClaimName).To(Equal(pvc))
+
+		snapshots, err := k.ListVolumeSnapshots("ns1", "app=peer1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshots).To(HaveLen(1))
+
+		snapshots, err = k.ListVolumeSnapshots("ns1", "app=peer2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshots).To(BeEmpty())
+	})
+
+	It("returns an error without a dynamic client", func() {
+		k = kube.NewForClients(fake.NewSimpleClientset(), nil)
+		_, err := k.GetVolumeSnapshot("ns1", "snap1-peer")
+		Expect(err).To(MatchError("dynamic client is not configured"))
+	})
+})
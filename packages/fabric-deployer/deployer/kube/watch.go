@@ -0,0 +1,188 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kube
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+func tracer() trace.Tracer {
+	return otel.Tracer("github.com/IBM-Blockchain/fabric-deployer/deployer/kube")
+}
+
+// Condition is evaluated against the cached object every time the object
+// changes. obj is nil while the object does not exist.
+type Condition func(obj interface{}) (done bool, err error)
+
+// Watcher lets callers wait on individual objects of a shared informer, so
+// that many concurrent waits are served from a single watch on the API server
+// instead of each of them polling it
+type Watcher struct {
+	informer cache.SharedIndexInformer
+
+	mutex       sync.Mutex
+	subscribers map[string]map[int]chan struct{}
+	nextID      int
+}
+
+// NewWatcher registers the watcher on the informer, the informer is expected
+// to be started by the caller
+func NewWatcher(informer cache.SharedIndexInformer) *Watcher {
+	w := &Watcher{
+		informer:    informer,
+		subscribers: map[string]map[int]chan struct{}{},
+	}
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: w.notify,
+		UpdateFunc: func(_, obj interface{}) {
+			w.notify(obj)
+		},
+		DeleteFunc: w.notify,
+	})
+
+	return w
+}
+
+// AddEventHandler registers handler on the informer of the watcher, it is
+// called for every change of the watched objects
+func (w *Watcher) AddEventHandler(handler cache.ResourceEventHandler) {
+	w.informer.AddEventHandler(handler)
+}
+
+// Wait blocks until cond returns true or an error for the object, or until
+// ctx is done. The last cached state of the object is returned.
+func (w *Watcher) Wait(ctx context.Context, namespace, name string, cond Condition) (interface{}, error) {
+	ctx, span := tracer().Start(ctx, "kube.Watcher.Wait", trace.WithAttributes(
+		attribute.String("k8s.namespace.name", namespace),
+		attribute.String("k8s.object.name", name),
+	))
+	defer span.End()
+
+	obj, err := w.wait(ctx, namespace, name, cond)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return obj, err
+}
+
+func (w *Watcher) wait(ctx context.Context, namespace, name string, cond Condition) (interface{}, error) {
+	if !cache.WaitForCacheSync(ctx.Done(), w.informer.HasSynced) {
+		return nil, errors.Wrap(ctx.Err(), "timed out waiting for watch cache to sync")
+	}
+
+	key := name
+	if namespace != "" {
+		key = namespace + "/" + name
+	}
+
+	// subscribe before reading the cache so no change is missed in between
+	changed, unsubscribe := w.subscribe(key)
+	defer unsubscribe()
+
+	for {
+		obj, exists, err := w.informer.GetStore().GetByKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			obj = nil
+		}
+
+		done, err := cond(obj)
+		if err != nil {
+			return obj, err
+		}
+		if done {
+			return obj, nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return obj, errors.Wrapf(ctx.Err(), "timed out waiting for '%s'", key)
+		}
+	}
+}
+
+// WithSpanOf returns ctx carrying the span of from when ctx has none, so that
+// the waits on a context without a span, e.g. a timeout, are traced as part of
+// the request the client is bound to
+func WithSpanOf(ctx, from context.Context) context.Context {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	span := trace.SpanFromContext(from)
+	if !span.SpanContext().IsValid() {
+		return ctx
+	}
+	return trace.ContextWithSpan(ctx, span)
+}
+
+func (w *Watcher) subscribe(key string) (<-chan struct{}, func()) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	// buffered so notifications are coalesced while the condition is
+	// evaluated, the store is read again after every notification
+	changed := make(chan struct{}, 1)
+	id := w.nextID
+	w.nextID++
+	if w.subscribers[key] == nil {
+		w.subscribers[key] = map[int]chan struct{}{}
+	}
+	w.subscribers[key][id] = changed
+
+	return changed, func() {
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+
+		delete(w.subscribers[key], id)
+		if len(w.subscribers[key]) == 0 {
+			delete(w.subscribers, key)
+		}
+	}
+}
+
+func (w *Watcher) notify(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for _, changed := range w.subscribers[key] {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+}
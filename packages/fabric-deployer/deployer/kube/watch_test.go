@@ -0,0 +1,122 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kube_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/kube"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Watch", func() {
+	var (
+		k         *kube.Kube
+		clientset *fake.Clientset
+	)
+
+	BeforeEach(func() {
+		clientset = fake.NewSimpleClientset()
+		k = kube.NewForClientset(clientset)
+	})
+
+	AfterEach(func() {
+		k.StopWatchers()
+	})
+
+	Context("wait for config map", func() {
+		It("returns a config map that already exists", func() {
+			_, err := clientset.CoreV1().ConfigMaps("default").Create(context.TODO(), &apiv1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "ca1-connection-profile", Namespace: "default"},
+			}, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			cm, err := k.WaitForConfigMap(ctx, "default", "ca1-connection-profile")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cm.Name).To(Equal("ca1-connection-profile"))
+		})
+
+		It("returns the config map once it is created", func() {
+			go func() {
+				defer GinkgoRecover()
+				time.Sleep(100 * time.Millisecond)
+				_, err := clientset.CoreV1().ConfigMaps("default").Create(context.TODO(), &apiv1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "ca1-connection-profile", Namespace: "default"},
+				}, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			cm, err := k.WaitForConfigMap(ctx, "default", "ca1-connection-profile")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cm.Name).To(Equal("ca1-connection-profile"))
+		})
+
+		It("does not return config maps of another namespace", func() {
+			_, err := clientset.CoreV1().ConfigMaps("other").Create(context.TODO(), &apiv1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "ca1-connection-profile", Namespace: "other"},
+			}, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			_, err = k.WaitForConfigMap(ctx, "default", "ca1-connection-profile")
+			Expect(err).To(MatchError(ContainSubstring("context deadline exceeded")))
+		})
+
+		It("serves concurrent waits from the same watch", func() {
+			done := make(chan error, 2)
+			for _, name := range []string{"peer1-connection-profile", "peer2-connection-profile"} {
+				go func(name string) {
+					ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+					defer cancel()
+					_, err := k.WaitForConfigMap(ctx, "default", name)
+					done <- err
+				}(name)
+			}
+
+			for _, name := range []string{"peer1-connection-profile", "peer2-connection-profile"} {
+				_, err := clientset.CoreV1().ConfigMaps("default").Create(context.TODO(), &apiv1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				}, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Eventually(done, 5*time.Second).Should(Receive(BeNil()))
+			Eventually(done, 5*time.Second).Should(Receive(BeNil()))
+
+			listWatches := 0
+			for _, action := range clientset.Actions() {
+				if action.GetResource().Resource == "configmaps" && (action.GetVerb() == "list" || action.GetVerb() == "watch") {
+					listWatches++
+				}
+			}
+			Expect(listWatches).To(Equal(2))
+		})
+	})
+})
@@ -0,0 +1,84 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+// MetricsMiddleware records the requests in the metrics, including the ones
+// that are not authenticated or not routed
+func (d *Deployer) MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if d.Metrics == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+		if recorder.statusCode == 0 {
+			recorder.statusCode = http.StatusOK
+		}
+
+		d.Metrics.ObserveRequest(routeOf(r), r.Method, chi.URLParam(r, "type"), recorder.statusCode, time.Since(start))
+	})
+}
+
+// watchComponents starts the CR watches the components are counted by, for
+// the namespaces of the clusters and of the service instances of the
+// configuration. The namespaces found by label are watched once requested.
+func (d *Deployer) watchComponents() {
+	if d.Metrics == nil {
+		return
+	}
+
+	instances := d.Components().Config.Instances
+	for _, target := range d.allTargets() {
+		d.watchMetrics(target, target.Config.Namespace)
+		if instances != nil {
+			for _, namespace := range instances.Namespaces {
+				d.watchMetrics(target, namespace)
+			}
+		}
+	}
+}
+
+// watchMetrics starts the CR watches of the namespace of the cluster once
+func (d *Deployer) watchMetrics(target *Target, namespace string) {
+	d.metricsWatchesMutex.Lock()
+	defer d.metricsWatchesMutex.Unlock()
+
+	key := target.Name + "/" + namespace
+	if d.metricsWatches[key] {
+		return
+	}
+	if d.metricsWatches == nil {
+		d.metricsWatches = map[string]bool{}
+	}
+	handler := d.Metrics.Cluster(target.Name).HandleCRChange
+	for _, kind := range []string{"ibpcas", "ibppeers", "ibporderers"} {
+		target.IBPOperatorClient.WatchCR(namespace, kind, handler)
+	}
+	d.metricsWatches[key] = true
+}
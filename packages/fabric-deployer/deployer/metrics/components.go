@@ -0,0 +1,122 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"strings"
+	"time"
+
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/ibpoperator"
+)
+
+// unknownStatus is the status of the components whose CR has no status yet
+const unknownStatus = "Unknown"
+
+// Cluster observes the clients and the CRs of a cluster
+type Cluster struct {
+	metrics *Metrics
+	name    string
+}
+
+// Cluster returns the observer of the clients and the CRs of the cluster
+func (m *Metrics) Cluster(name string) *Cluster {
+	return &Cluster{metrics: m, name: name}
+}
+
+// CRChanged starts timing the create or update of a CR, until the CR is
+// deployed or set to error. It matches the ibpoperator.Observer.
+func (c *Cluster) CRChanged(operation, namespace, kind, name string) {
+	m := c.metrics
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.pending[c.key(namespace, kind, name)] = &change{operation: operation, start: time.Now()}
+}
+
+// WaitTimedOut counts a wait on a resource that timed out, it matches the
+// kube.Observer
+func (c *Cluster) WaitTimedOut(resource string) {
+	c.metrics.waitTimeouts.WithLabelValues(c.name, resource).Inc()
+}
+
+// HandleCRChange counts the components by CR status and completes the
+// timing of the creates and updates, it matches ibpoperator.CRHandler
+func (c *Cluster) HandleCRChange(kind string, old, cr *ibpoperator.CRState) {
+	componentType := componentType(kind)
+	if componentType == "" {
+		return
+	}
+
+	m := c.metrics
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	state := cr
+	if state == nil {
+		state = old
+	}
+	key := c.key(state.Namespace, kind, state.Name)
+
+	// the last counted status is used instead of the status of old, the
+	// watch of a namespace may be started again
+	if status, found := m.statuses[key]; found {
+		m.components.WithLabelValues(c.name, state.Namespace, componentType, status).Dec()
+		delete(m.statuses, key)
+	}
+	if cr == nil {
+		delete(m.pending, key)
+		return
+	}
+
+	status := string(cr.Status.Type)
+	if status == "" {
+		status = unknownStatus
+	}
+	m.components.WithLabelValues(c.name, cr.Namespace, componentType, status).Inc()
+	m.statuses[key] = status
+
+	pending, found := m.pending[key]
+	if !found || old == nil || old.Status.Type == cr.Status.Type {
+		return
+	}
+	switch cr.Status.Type {
+	case current.Deployed, current.Error:
+		m.deployDuration.WithLabelValues(c.name, componentType, pending.operation, strings.ToLower(string(cr.Status.Type))).
+			Observe(time.Since(pending.start).Seconds())
+		delete(m.pending, key)
+	}
+}
+
+func (c *Cluster) key(namespace, kind, name string) string {
+	return c.name + "/" + namespace + "/" + kind + "/" + name
+}
+
+func componentType(kind string) string {
+	switch kind {
+	case "ibpcas":
+		return "ca"
+	case "ibppeers":
+		return "peer"
+	case "ibporderers":
+		return "orderer"
+	}
+	return ""
+}
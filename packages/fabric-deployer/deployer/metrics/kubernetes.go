@@ -0,0 +1,60 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/kube"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+)
+
+// InstrumentConfig returns a copy of the config whose clients record the
+// latency of their calls to the Kubernetes API of the cluster, client names
+// the client using the config, e.g. kube or ibpoperator
+func (m *Metrics) InstrumentConfig(config *rest.Config, cluster, client string) *rest.Config {
+	config = rest.CopyConfig(config)
+	config.WrapTransport = transport.Wrappers(config.WrapTransport, func(rt http.RoundTripper) http.RoundTripper {
+		return &instrumentedTransport{next: rt, metrics: m, cluster: cluster, client: client}
+	})
+	return config
+}
+
+type instrumentedTransport struct {
+	next    http.RoundTripper
+	metrics *Metrics
+	cluster string
+	client  string
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	t.metrics.kubernetesLatency.WithLabelValues(t.cluster, t.client, kube.VerbOf(req), kube.ResourceOf(req.URL.Path), code).Observe(time.Since(start).Seconds())
+
+	return resp, err
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+const namespace = "deployer"
+
+// Metrics are the Prometheus metrics of the deployer, they are registered
+// on their own registry
+type Metrics struct {
+	Logger   *zap.SugaredLogger
+	Registry *prometheus.Registry
+
+	requests          *prometheus.CounterVec
+	requestDuration   *prometheus.HistogramVec
+	deployDuration    *prometheus.HistogramVec
+	waitTimeouts      *prometheus.CounterVec
+	kubernetesLatency *prometheus.HistogramVec
+	components        *prometheus.GaugeVec
+
+	// pending are the CRs created or updated by the deployer that are not
+	// deployed yet, by cluster, namespace, kind and name
+	pending map[string]*change
+	// statuses are the statuses the components are counted by
+	statuses map[string]string
+	mutex    sync.Mutex
+}
+
+// change is a create or update of a CR
+type change struct {
+	operation string
+	start     time.Time
+}
+
+func New(logger *zap.Logger) *Metrics {
+	m := &Metrics{
+		Logger:   logger.Sugar().Named("Metrics"),
+		Registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of API requests by route, method, component type and status code.",
+		}, []string{"route", "method", "component_type", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of the API requests by route, method, component type and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "component_type", "code"}),
+		deployDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "component_deploy_duration_seconds",
+			Help:      "Time from the create or update of a CR until it is deployed, or set to error.",
+			Buckets:   []float64{5, 10, 30, 60, 120, 300, 600, 1200},
+		}, []string{"cluster", "component_type", "operation", "result"}),
+		waitTimeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "wait_timeouts_total",
+			Help:      "Number of waits on CRs and config maps that timed out.",
+		}, []string{"cluster", "resource"}),
+		kubernetesLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "kubernetes_request_duration_seconds",
+			Help:      "Latency of the Kubernetes API calls of the kube and ibpoperator clients.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"cluster", "client", "verb", "resource", "code"}),
+		components: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "components",
+			Help:      "Number of components by component type and CR status.",
+		}, []string{"cluster", "namespace", "component_type", "status"}),
+		pending:  map[string]*change{},
+		statuses: map[string]string{},
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.deployDuration,
+		m.waitTimeouts,
+		m.kubernetesLatency,
+		m.components,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records an API request, route is the route pattern of the
+// request
+func (m *Metrics) ObserveRequest(route, method, componentType string, statusCode int, duration time.Duration) {
+	componentType = requestType(componentType)
+	code := strconv.Itoa(statusCode)
+	m.requests.WithLabelValues(route, method, componentType, code).Inc()
+	m.requestDuration.WithLabelValues(route, method, componentType, code).Observe(duration.Seconds())
+}
+
+// requestType returns the component type label of the type of a request, the
+// types are taken from the URL so unknown types are counted as "other" to
+// bound the label values
+func requestType(componentType string) string {
+	switch componentType {
+	case "", "ca", "peer", "orderer", "all":
+		return componentType
+	}
+	return "other"
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"k8s.io/client-go/rest"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/ibpoperator"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/kube"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/metrics"
+)
+
+var _ = Describe("Metrics", func() {
+	var m *metrics.Metrics
+
+	BeforeEach(func() {
+		m = metrics.New(zap.NewNop())
+	})
+
+	scrape := func() string {
+		w := httptest.NewRecorder()
+		m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		Expect(w.Code).To(Equal(http.StatusOK))
+		return w.Body.String()
+	}
+
+	It("records the requests by route, method, component type and status", func() {
+		m.ObserveRequest("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}", http.MethodPost, "peer", http.StatusCreated, time.Second)
+
+		body := scrape()
+		Expect(body).To(ContainSubstring(`deployer_http_requests_total{code="201",component_type="peer",method="POST",route="/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}"} 1`))
+		Expect(body).To(ContainSubstring(`deployer_http_request_duration_seconds_count{code="201",component_type="peer",method="POST",route="/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}"} 1`))
+	})
+
+	It("records the requests of unknown component types as other", func() {
+		m.ObserveRequest("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}", http.MethodGet, "peer1", http.StatusBadRequest, time.Second)
+
+		Expect(scrape()).To(ContainSubstring(`deployer_http_requests_total{code="400",component_type="other",method="GET",route="/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}"} 1`))
+		Expect(scrape()).NotTo(ContainSubstring(`component_type="peer1"`))
+	})
+
+	It("records the latency of the kubernetes api calls by resource", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		client, err := kube.NewForConfig(m.InstrumentConfig(&rest.Config{Host: server.URL}, "local", "kube"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.GetConfigMap("ns1", "cm1")
+		Expect(err).To(HaveOccurred())
+
+		Expect(scrape()).To(ContainSubstring(`deployer_kubernetes_request_duration_seconds_count{client="kube",cluster="local",code="404",resource="configmaps",verb="GET"} 1`))
+	})
+
+	Context("components", func() {
+		var cluster *metrics.Cluster
+
+		state := func(name string, status current.IBPCRStatusType) *ibpoperator.CRState {
+			return &ibpoperator.CRState{Name: name, Namespace: "ns1", Status: current.CRStatus{Type: status}}
+		}
+
+		BeforeEach(func() {
+			cluster = m.Cluster("east")
+		})
+
+		It("counts the components by type and status", func() {
+			cluster.HandleCRChange("ibppeers", nil, state("peer1", current.Deploying))
+			cluster.HandleCRChange("ibppeers", nil, state("peer2", current.Deployed))
+			cluster.HandleCRChange("ibppeers", state("peer1", current.Deploying), state("peer1", current.Deployed))
+			cluster.HandleCRChange("ibpcas", nil, state("ca1", ""))
+
+			body := scrape()
+			Expect(body).To(ContainSubstring(`deployer_components{cluster="east",component_type="peer",namespace="ns1",status="Deployed"} 2`))
+			Expect(body).To(ContainSubstring(`deployer_components{cluster="east",component_type="peer",namespace="ns1",status="Deploying"} 0`))
+			Expect(body).To(ContainSubstring(`deployer_components{cluster="east",component_type="ca",namespace="ns1",status="Unknown"} 1`))
+
+			cluster.HandleCRChange("ibppeers", state("peer2", current.Deployed), nil)
+			Expect(scrape()).To(ContainSubstring(`deployer_components{cluster="east",component_type="peer",namespace="ns1",status="Deployed"} 1`))
+		})
+
+		It("does not count a component twice when its namespace is watched again", func() {
+			cluster.HandleCRChange("ibppeers", nil, state("peer1", current.Deployed))
+			cluster.HandleCRChange("ibppeers", nil, state("peer1", current.Deployed))
+			Expect(scrape()).To(ContainSubstring(`deployer_components{cluster="east",component_type="peer",namespace="ns1",status="Deployed"} 1`))
+		})
+
+		It("times the creates and updates until the CR is deployed", func() {
+			cluster.CRChanged("create", "ns1", "ibporderers", "os1node1")
+			cluster.HandleCRChange("ibporderers", nil, state("os1node1", ""))
+			cluster.HandleCRChange("ibporderers", state("os1node1", ""), state("os1node1", current.Deploying))
+			Expect(scrape()).NotTo(ContainSubstring(`deployer_component_deploy_duration_seconds_count`))
+
+			cluster.HandleCRChange("ibporderers", state("os1node1", current.Deploying), state("os1node1", current.Deployed))
+			Expect(scrape()).To(ContainSubstring(`deployer_component_deploy_duration_seconds_count{cluster="east",component_type="orderer",operation="create",result="deployed"} 1`))
+
+			By("timing only the changes made by the deployer", func() {
+				cluster.HandleCRChange("ibporderers", state("os1node1", current.Deployed), state("os1node1", current.Error))
+				Expect(scrape()).NotTo(ContainSubstring(`result="error"`))
+			})
+
+			cluster.CRChanged("update", "ns1", "ibporderers", "os1node1")
+			cluster.HandleCRChange("ibporderers", state("os1node1", current.Deployed), state("os1node1", current.Error))
+			Expect(scrape()).To(ContainSubstring(`deployer_component_deploy_duration_seconds_count{cluster="east",component_type="orderer",operation="update",result="error"} 1`))
+		})
+
+		It("counts the waits that time out", func() {
+			cluster.WaitTimedOut("ibpcas")
+			Expect(scrape()).To(ContainSubstring(`deployer_wait_timeouts_total{cluster="east",resource="ibpcas"} 1`))
+		})
+	})
+})
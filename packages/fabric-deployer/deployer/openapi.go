@@ -0,0 +1,282 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"sigs.k8s.io/yaml"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apply"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/audit"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/bundle"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/capacity"
+	caapi "github.com/IBM-Blockchain/fabric-deployer/deployer/components/ca/api"
+	operatorapi "github.com/IBM-Blockchain/fabric-deployer/deployer/components/operator/api"
+	ordererapi "github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer/api"
+	peerapi "github.com/IBM-Blockchain/fabric-deployer/deployer/components/peer/api"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/openapi"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/operations"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/snapshot"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/upgrade"
+)
+
+const (
+	instancePath  = "/api/v3/instance/{serviceInstanceID}"
+	componentPath = instancePath + "/type/%s/component/{componentName}"
+)
+
+// componentAPIs are the bodies of the component APIs by component type
+var componentAPIs = []struct {
+	Type     string
+	Create   interface{}
+	Update   interface{}
+	Delete   interface{}
+	Response interface{}
+}{
+	{"ca", &caapi.CreateRequest{}, &caapi.UpdateRequest{}, &caapi.DeleteRequest{}, &caapi.Response{}},
+	{"peer", &peerapi.CreateRequest{}, &peerapi.UpdateRequest{}, &peerapi.DeleteRequest{}, &peerapi.Response{}},
+	{"orderer", &ordererapi.CreateRequest{}, &ordererapi.UpdateRequest{}, &ordererapi.DeleteRequest{}, &ordererapi.Response{}},
+}
+
+// consoleFields are sent by the console in the create requests of all the
+// component types, they are not used by the deployer
+var consoleFields = map[string]*openapi.Schema{
+	"type":             {Type: openapi.TypeString},
+	"parameters":       {},
+	"dep_component_id": {Type: openapi.TypeString},
+}
+
+// raftConsoleFields are sent by the console in the create requests of raft
+// orderer nodes on top of the consoleFields
+var raftConsoleFields = map[string]*openapi.Schema{
+	"cluster_name":      {Type: openapi.TypeString},
+	"append":            {Type: openapi.TypeBoolean},
+	"system_channel_id": {Type: openapi.TypeString},
+}
+
+// NewOpenAPIDocument returns the OpenAPI document of the v3 APIs. The
+// component APIs are documented for each component type, their request
+// bodies differ.
+func NewOpenAPIDocument() *openapi.Document {
+	doc := openapi.New("Deployer API", "v3", &Errors{})
+
+	for _, component := range componentAPIs {
+		path := fmt.Sprintf(componentPath, component.Type)
+		tag := component.Type
+
+		doc.Add(openapi.Route{Method: http.MethodPost, Path: path, ID: "create" + title(tag), Tag: tag,
+			Summary: "Create a " + tag, Parameters: []*openapi.Parameter{query("dryRun", openapi.TypeBoolean), query("async", openapi.TypeBoolean)},
+			Request: component.Create})
+		doc.Add(openapi.Route{Method: http.MethodDelete, Path: path, ID: "delete" + title(tag), Tag: tag,
+			Summary: "Delete a " + tag, Request: component.Delete})
+		doc.Add(openapi.Route{Method: http.MethodGet, Path: fmt.Sprintf(instancePath+"/type/%s/capacity", tag), ID: "check" + title(tag) + "Capacity", Tag: tag,
+			Summary: "Check the capacity for the create request of a " + tag, Parameters: []*openapi.Parameter{query("componentName", openapi.TypeString)},
+			Request: component.Create, Response: &capacity.Report{}})
+		doc.AddProperties(component.Create, consoleFields)
+		if component.Type == "orderer" {
+			doc.AddProperties(component.Create, raftConsoleFields)
+		}
+
+		for _, sectionPath := range []string{path, path + "/{section}"} {
+			suffix := ""
+			if sectionPath != path {
+				suffix = "Section"
+			}
+			updateParameters := []*openapi.Parameter{query("dryRun", openapi.TypeBoolean), header("If-Match")}
+
+			doc.Add(openapi.Route{Method: http.MethodGet, Path: sectionPath, ID: "get" + title(tag) + suffix, Tag: tag,
+				Summary: "Get a " + tag, Response: component.Response})
+			doc.Add(openapi.Route{Method: http.MethodPut, Path: sectionPath, ID: "update" + title(tag) + suffix, Tag: tag,
+				Summary: "Update a " + tag, Parameters: updateParameters, Request: component.Update, Response: component.Response})
+			doc.Add(openapi.Route{Method: http.MethodPatch, Path: sectionPath, ID: "patch" + title(tag) + suffix, Tag: tag,
+				Summary: "Patch a " + tag, Parameters: updateParameters, Request: component.Update, Response: component.Response})
+		}
+
+		if component.Type == "peer" || component.Type == "orderer" {
+			asyncParameters := []*openapi.Parameter{query("async", openapi.TypeBoolean)}
+			doc.Add(openapi.Route{Method: http.MethodGet, Path: path + "/snapshot", ID: "list" + title(tag) + "Snapshots", Tag: tag,
+				Summary: "List the volume snapshots of a " + tag, Response: []*snapshot.Snapshot{}})
+			doc.Add(openapi.Route{Method: http.MethodPut, Path: path + "/snapshot", ID: "snapshot" + title(tag), Tag: tag,
+				Summary: "Take a snapshot of the volumes of a " + tag, Parameters: asyncParameters,
+				Request: &snapshot.CreateRequest{}, Response: &snapshot.Snapshot{}})
+			doc.Add(openapi.Route{Method: http.MethodPut, Path: path + "/restore", ID: "restore" + title(tag), Tag: tag,
+				Summary: "Restore the volumes of a " + tag + " from a snapshot", Parameters: asyncParameters,
+				Request: &snapshot.RestoreRequest{}, Response: &snapshot.RestoreResponse{}})
+		}
+	}
+
+	for _, route := range []openapi.Route{
+		{Method: http.MethodGet, Path: instancePath + "/type/{type}/versions", ID: "listVersions", Tag: "versions",
+			Summary: "List the available versions of a component type"},
+		{Method: http.MethodGet, Path: instancePath + "/type/all", ID: "listComponents", Tag: "components",
+			Summary: "List all the components, of all the clusters with allClusters", Parameters: []*openapi.Parameter{query("allClusters", openapi.TypeBoolean)}},
+		{Method: http.MethodGet, Path: instancePath + "/resources", ID: "getResources", Tag: "components",
+			Summary: "Get the resources of all the components, by component type and by container role", Response: &InstanceResources{}},
+		{Method: http.MethodGet, Path: instancePath + "/config/revision", ID: "getConfigRevision", Tag: "config",
+			Summary: "Get the revision of the configuration file in use", Response: &config.Revision{}},
+		{Method: http.MethodPost, Path: instancePath + "/precreate/type/orderer/component/{componentName}", ID: "precreateOrderer", Tag: "orderer",
+			Summary: "Precreate a raft node", Request: &ordererapi.PrecreateRequest{}},
+		{Method: http.MethodPost, Path: instancePath + "/apply", ID: "applyManifest", Tag: "apply",
+			Summary: "Create, update and delete the components to match a manifest", Request: &apply.Manifest{}, Response: &apply.Response{},
+			Parameters: []*openapi.Parameter{query("dryRun", openapi.TypeBoolean), query("async", openapi.TypeBoolean)}},
+		{Method: http.MethodPost, Path: instancePath + "/upgrade", ID: "upgradeComponents", Tag: "upgrade",
+			Summary: "Upgrade the versions of the components, CAs first, then orderer nodes and peers", Request: &upgrade.Request{}, Response: &operations.Operation{},
+			Parameters: []*openapi.Parameter{query("dryRun", openapi.TypeBoolean)}},
+		{Method: http.MethodPost, Path: instancePath + "/upgrade/{operationID}/resume", ID: "resumeUpgrade", Tag: "upgrade",
+			Summary: "Resume a paused upgrade", Response: &operations.Operation{}},
+		{Method: http.MethodPost, Path: instancePath + "/upgrade/{operationID}/abort", ID: "abortUpgrade", Tag: "upgrade",
+			Summary: "Abort a running or paused upgrade", Response: &operations.Operation{}},
+		{Method: http.MethodGet, Path: instancePath + "/export", ID: "exportComponents", Tag: "bundle",
+			Summary: "Export the component definitions as a gzipped tar archive", Parameters: []*openapi.Parameter{header(PassphraseHeader)}},
+		{Method: http.MethodPost, Path: instancePath + "/import", ID: "importComponents", Tag: "bundle",
+			Summary: "Import an archive of component definitions", Response: &bundle.ImportResponse{},
+			Parameters: []*openapi.Parameter{header(PassphraseHeader), query("domain", openapi.TypeString), query("registry", openapi.TypeString),
+				query("storageClass", openapi.TypeString), query("dryRun", openapi.TypeBoolean)}},
+
+		{Method: http.MethodGet, Path: instancePath + "/operations", ID: "listOperations", Tag: "operations",
+			Summary: "List the operations", Response: []*operations.Operation{}},
+		{Method: http.MethodGet, Path: instancePath + "/operations/{operationID}", ID: "getOperation", Tag: "operations",
+			Summary: "Get an operation", Response: &operations.Operation{}},
+
+		{Method: http.MethodGet, Path: instancePath + "/events", ID: "streamEvents", Tag: "events",
+			Summary: "Stream the status changes of the components as server-sent events"},
+
+		{Method: http.MethodGet, Path: instancePath + "/audit", ID: "listAuditEntries", Tag: "audit",
+			Summary: "Query the audit log", Response: []*audit.Entry{},
+			Parameters: []*openapi.Parameter{query("principal", openapi.TypeString), query("verb", openapi.TypeString),
+				query("resource", openapi.TypeString), query("type", openapi.TypeString), query("component", openapi.TypeString),
+				query("since", openapi.TypeString), query("until", openapi.TypeString), query("limit", openapi.TypeInteger)}},
+
+		{Method: http.MethodGet, Path: instancePath + "/hsmconfig", ID: "getHSMConfig", Tag: "hsmconfig",
+			Summary: "Get the HSM config", Response: &operatorapi.Response{}},
+		{Method: http.MethodPost, Path: instancePath + "/hsmconfig", ID: "createHSMConfig", Tag: "hsmconfig",
+			Summary: "Create or replace the HSM config", Request: &operatorapi.CreateRequest{}, Response: &operatorapi.Response{}},
+		{Method: http.MethodPatch, Path: instancePath + "/hsmconfig", ID: "patchHSMConfig", Tag: "hsmconfig",
+			Summary: "Patch the HSM config", Request: &operatorapi.UpdateRequest{}, Response: &operatorapi.Response{}},
+		{Method: http.MethodDelete, Path: instancePath + "/hsmconfig", ID: "deleteHSMConfig", Tag: "hsmconfig",
+			Summary: "Delete the HSM config", Response: &operatorapi.DeleteResponse{}},
+
+		{Method: http.MethodGet, Path: instancePath + "/k8s/cluster/version", ID: "getClusterVersion", Tag: "k8s",
+			Summary: "Get the kubernetes version of the cluster"},
+		{Method: http.MethodGet, Path: instancePath + "/k8s/cluster/type", ID: "getClusterType", Tag: "k8s",
+			Summary: "Get the type of the cluster, kubernetes or openshift"},
+
+		{Method: http.MethodGet, Path: instancePath + "/mustgather", ID: "getMustgather", Tag: "mustgather",
+			Summary: "Get the status of the mustgather job"},
+		{Method: http.MethodPost, Path: instancePath + "/mustgather", ID: "startMustgather", Tag: "mustgather",
+			Summary: "Start the mustgather job"},
+		{Method: http.MethodDelete, Path: instancePath + "/mustgather", ID: "stopMustgather", Tag: "mustgather",
+			Summary: "Stop the mustgather job"},
+		{Method: http.MethodGet, Path: instancePath + "/mustgather/download", ID: "downloadMustgather", Tag: "mustgather",
+			Summary: "Download the mustgather archive"},
+
+		{Method: http.MethodGet, Path: "/api/v3/openapi.json", ID: "getOpenAPIDocument", Tag: "openapi",
+			Summary: "Get the OpenAPI document of the API"},
+	} {
+		doc.Add(route)
+	}
+	doc.AddProperties(&ordererapi.PrecreateRequest{}, consoleFields)
+	doc.AddProperties(&ordererapi.PrecreateRequest{}, raftConsoleFields)
+
+	return doc
+}
+
+func title(s string) string {
+	if s == "ca" {
+		return "CA"
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func query(name, schemaType string) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: "query", Schema: &openapi.Schema{Type: schemaType}}
+}
+
+func header(name string) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: "header", Schema: &openapi.Schema{Type: openapi.TypeString}}
+}
+
+// OpenAPIEndpoint returns an endpoint type that is responsible for handling
+// getting the OpenAPI document
+func (d *Deployer) OpenAPIEndpoint() func(http.ResponseWriter, *http.Request) {
+	return NewEndpoint(d.GetOpenAPIDocument, d.LocalConfig.Logger).ServeHTTP
+}
+
+func (d *Deployer) GetOpenAPIDocument(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	return d.OpenAPI, http.StatusOK, nil
+}
+
+// ValidationMiddleware rejects the request bodies that do not match the schema
+// of the operation in the OpenAPI document. It must be used on routed
+// requests.
+func (d *Deployer) ValidationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if d.OpenAPI == nil || r.Body == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// the component APIs are documented by component type
+		path := chi.RouteContext(r.Context()).RoutePattern()
+		if componentType := chi.URLParam(r, "type"); componentType != "" && strings.Contains(path, "/component/") {
+			path = strings.Replace(path, "{type}", componentType, 1)
+		}
+		schema := d.OpenAPI.RequestSchema(r.Method, path)
+		if schema == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err == nil && isYAML(r) {
+			// the body is validated, and passed on, as JSON
+			body, err = yaml.YAMLToJSON(body)
+			if err != nil {
+				err = apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "invalid request body")
+			}
+			r.Header.Set("Content-Type", "application/json")
+		}
+		if err == nil {
+			err = d.OpenAPI.Validate(schema, body)
+		}
+		if err != nil {
+			NewEndpoint(func(http.ResponseWriter, *http.Request) (interface{}, int, error) {
+				return nil, http.StatusBadRequest, err
+			}, d.LocalConfig.Logger).ServeHTTP(w, r)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isYAML returns true if the request body is YAML
+func isYAML(r *http.Request) bool {
+	return strings.Contains(strings.ToLower(r.Header.Get("Content-Type")), "yaml")
+}
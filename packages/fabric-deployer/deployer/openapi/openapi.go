@@ -0,0 +1,238 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package openapi generates an OpenAPI 3 document of the API from the Go
+// types of the requests and responses, and validates request bodies against
+// the schemas of the document.
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	// Version of the OpenAPI specification of the documents
+	Version = "3.0.3"
+
+	jsonContentType = "application/json"
+)
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+
+	generator *Generator
+	errorType interface{}
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty"`
+	Put    *Operation `json:"put,omitempty"`
+	Post   *Operation `json:"post,omitempty"`
+	Patch  *Operation `json:"patch,omitempty"`
+	Delete *Operation `json:"delete,omitempty"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Route describes an operation of the API. Request and Response are values
+// of the Go types of the bodies, nil if the operation has none.
+type Route struct {
+	Method  string
+	Path    string
+	ID      string
+	Summary string
+	Tag     string
+	// Parameters are the query and header parameters of the operation
+	Parameters []*Parameter
+	Request    interface{}
+	Response   interface{}
+}
+
+var pathParam = regexp.MustCompile(`{([^}]+)}`)
+
+// New returns an empty document, errorType is a value of the Go type of the
+// body of the error responses of all the operations
+func New(title, version string, errorType interface{}) *Document {
+	d := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:   title,
+			Version: version,
+		},
+		Paths:     map[string]*PathItem{},
+		generator: NewGenerator(),
+	}
+	d.Components.Schemas = d.generator.Schemas
+	d.errorType = errorType
+
+	return d
+}
+
+// Add adds the operation of the route to the document, the path parameters
+// are taken from the path
+func (d *Document) Add(route Route) {
+	op := &Operation{
+		OperationID: route.ID,
+		Summary:     route.Summary,
+		Responses:   map[string]*Response{},
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+
+	for _, match := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+		op.Parameters = append(op.Parameters, &Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: TypeString},
+		})
+	}
+	op.Parameters = append(op.Parameters, route.Parameters...)
+
+	if route.Request != nil {
+		op.RequestBody = &RequestBody{
+			Content: map[string]*MediaType{
+				jsonContentType: {Schema: d.generator.Schema(route.Request)},
+			},
+		}
+	}
+
+	ok := &Response{Description: http.StatusText(http.StatusOK)}
+	if route.Response != nil {
+		ok.Content = map[string]*MediaType{
+			jsonContentType: {Schema: d.generator.Schema(route.Response)},
+		}
+	}
+	op.Responses["200"] = ok
+	if d.errorType != nil {
+		op.Responses["default"] = &Response{
+			Description: "Error",
+			Content: map[string]*MediaType{
+				jsonContentType: {Schema: d.generator.Schema(d.errorType)},
+			},
+		}
+	}
+
+	item, found := d.Paths[route.Path]
+	if !found {
+		item = &PathItem{}
+		d.Paths[route.Path] = item
+	}
+	switch strings.ToUpper(route.Method) {
+	case http.MethodGet:
+		item.Get = op
+	case http.MethodPut:
+		item.Put = op
+	case http.MethodPost:
+		item.Post = op
+	case http.MethodPatch:
+		item.Patch = op
+	case http.MethodDelete:
+		item.Delete = op
+	}
+}
+
+// Operation returns the operation of the method on the path of the document,
+// nil if the path does not have one
+func (d *Document) Operation(method, path string) *Operation {
+	item, found := d.Paths[path]
+	if !found {
+		return nil
+	}
+
+	switch strings.ToUpper(method) {
+	case http.MethodGet:
+		return item.Get
+	case http.MethodPut:
+		return item.Put
+	case http.MethodPost:
+		return item.Post
+	case http.MethodPatch:
+		return item.Patch
+	case http.MethodDelete:
+		return item.Delete
+	}
+	return nil
+}
+
+// AddProperties adds the properties to the schema of the named struct type of
+// v, e.g. to accept fields that are passed through without being decoded
+func (d *Document) AddProperties(v interface{}, properties map[string]*Schema) {
+	ref := d.generator.Schema(v)
+	schema, found := d.Components.Schemas[strings.TrimPrefix(ref.Ref, refPrefix)]
+	if !found {
+		return
+	}
+	for name, property := range properties {
+		schema.Properties[name] = property
+	}
+}
+
+// RequestSchema returns the schema of the request body of the method on the
+// path, nil if the operation does not take a body
+func (d *Document) RequestSchema(method, path string) *Schema {
+	op := d.Operation(method, path)
+	if op == nil || op.RequestBody == nil {
+		return nil
+	}
+	return op.RequestBody.Content[jsonContentType].Schema
+}
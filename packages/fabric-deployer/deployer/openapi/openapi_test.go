@@ -0,0 +1,115 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/openapi"
+)
+
+var _ = Describe("Document", func() {
+	var (
+		doc    *openapi.Document
+		schema *openapi.Schema
+	)
+
+	BeforeEach(func() {
+		doc = openapi.New("Test API", "v1", &apierror.FieldError{})
+		doc.Add(openapi.Route{
+			Method:  http.MethodPost,
+			Path:    "/api/instance/{id}/request",
+			ID:      "createRequest",
+			Request: &Request{},
+		})
+		schema = doc.RequestSchema(http.MethodPost, "/api/instance/{id}/request")
+	})
+
+	It("adds the operation with its path parameters", func() {
+		op := doc.Operation(http.MethodPost, "/api/instance/{id}/request")
+		Expect(op).NotTo(BeNil())
+		Expect(op.OperationID).To(Equal("createRequest"))
+		Expect(op.Parameters).To(HaveLen(1))
+		Expect(op.Parameters[0].Name).To(Equal("id"))
+		Expect(op.Parameters[0].In).To(Equal("path"))
+		Expect(op.Responses).To(HaveKey("200"))
+		Expect(op.Responses).To(HaveKey("default"))
+
+		Expect(doc.Operation(http.MethodGet, "/api/instance/{id}/request")).To(BeNil())
+		Expect(doc.RequestSchema(http.MethodGet, "/api/instance/{id}/request")).To(BeNil())
+	})
+
+	Context("validate", func() {
+		It("accepts valid bodies", func() {
+			Expect(doc.Validate(schema, []byte(`{"name": "peer1", "replicas": 1, "limits": {"peer": {"cpu": "100m", "memory": 1024}}, "override": {"any": ["thing"]}}`))).To(Succeed())
+		})
+
+		It("accepts empty bodies and null values", func() {
+			Expect(doc.Validate(schema, nil)).To(Succeed())
+			Expect(doc.Validate(schema, []byte(`{"replicas": null, "parent": null}`))).To(Succeed())
+		})
+
+		It("matches the field names case insensitively", func() {
+			Expect(doc.Validate(schema, []byte(`{"Name": "peer1"}`))).To(Succeed())
+		})
+
+		It("rejects unknown fields with their path", func() {
+			err := doc.Validate(schema, []byte(`{"name": "peer1", "limts": {}, "limits": {"peer": {"cpus": "1"}}, "parent": {"nme": "x"}}`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("invalid request body: limits.peer.cpus unknown field, limts unknown field, parent.nme unknown field"))
+
+			apiErr := apierror.From(err)
+			Expect(apiErr.Kind).To(Equal(apierror.Validation))
+			Expect(apiErr.Code).To(Equal(apierror.CodeInvalidRequest))
+			Expect(apiErr.Details).To(ConsistOf(
+				apierror.FieldError{Field: "limits.peer.cpus", Message: "limits.peer.cpus unknown field"},
+				apierror.FieldError{Field: "limts", Message: "limts unknown field"},
+				apierror.FieldError{Field: "parent.nme", Message: "parent.nme unknown field"},
+			))
+		})
+
+		It("rejects values of the wrong type", func() {
+			err := doc.Validate(schema, []byte(`{"name": 1, "replicas": 1.5, "zones": ["a", 2], "limits": {"peer": {"cpu": true}}}`))
+			Expect(err).To(HaveOccurred())
+
+			apiErr := apierror.From(err)
+			Expect(apiErr.Details).To(ConsistOf(
+				apierror.FieldError{Field: "limits.peer.cpu", Message: "limits.peer.cpu must be a string or number"},
+				apierror.FieldError{Field: "name", Message: "name must be a string"},
+				apierror.FieldError{Field: "replicas", Message: "replicas must be an integer"},
+				apierror.FieldError{Field: "zones[1]", Message: "zones[1] must be a string"},
+			))
+		})
+
+		It("rejects invalid JSON", func() {
+			err := doc.Validate(schema, []byte(`{"name": `))
+			Expect(err).To(HaveOccurred())
+			Expect(apierror.KindOf(err)).To(Equal(apierror.Validation))
+		})
+
+		It("rejects bodies that are not objects", func() {
+			err := doc.Validate(schema, []byte(`["name"]`))
+			Expect(err).To(MatchError("invalid request body: must be an object"))
+		})
+	})
+})
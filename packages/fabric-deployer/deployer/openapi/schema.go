@@ -0,0 +1,212 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeObject  = "object"
+	TypeArray   = "array"
+
+	refPrefix = "#/components/schemas/"
+)
+
+// Schema is the subset of the OpenAPI schema object used to describe the Go
+// types. AdditionalProperties is false for structs, which do not allow
+// unknown fields, or the schema of the values of maps.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+var (
+	jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+	// knownTypes have custom JSON encodings
+	knownTypes = map[reflect.Type]*Schema{
+		reflect.TypeOf(resource.Quantity{}):    {OneOf: []*Schema{{Type: TypeString}, {Type: TypeNumber}}},
+		reflect.TypeOf(intstr.IntOrString{}):   {OneOf: []*Schema{{Type: TypeString}, {Type: TypeInteger}}},
+		reflect.TypeOf(time.Time{}):            {Type: TypeString, Format: "date-time"},
+		reflect.TypeOf(metav1.Time{}):          {Type: TypeString, Format: "date-time"},
+		reflect.TypeOf(metav1.MicroTime{}):     {Type: TypeString, Format: "date-time"},
+		reflect.TypeOf(metav1.Duration{}):      {Type: TypeString},
+		reflect.TypeOf(runtime.RawExtension{}): {},
+		reflect.TypeOf(json.RawMessage{}):      {},
+	}
+)
+
+// Generator generates the schemas of Go types following the encoding/json
+// rules. Named structs are added to Schemas and referenced.
+type Generator struct {
+	Schemas map[string]*Schema
+
+	names map[reflect.Type]string
+}
+
+func NewGenerator() *Generator {
+	return &Generator{
+		Schemas: map[string]*Schema{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+// Schema returns the schema of the type of v
+func (g *Generator) Schema(v interface{}) *Schema {
+	return g.schema(reflect.TypeOf(v))
+}
+
+func (g *Generator) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if known, found := knownTypes[t]; found {
+		return known
+	}
+	if reflect.PtrTo(t).Implements(jsonUnmarshaler) {
+		return &Schema{}
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshaler) {
+		return &Schema{Type: TypeString}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: TypeBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: TypeInteger}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: TypeNumber}
+	case reflect.String:
+		return &Schema{Type: TypeString}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: TypeString, Format: "byte"}
+		}
+		return &Schema{Type: TypeArray, Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: TypeObject, AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.ref(t)
+	}
+
+	// interfaces and other types can hold any value
+	return &Schema{}
+}
+
+// ref adds the schema of the named struct to the schemas on first use
+func (g *Generator) ref(t reflect.Type) *Schema {
+	name, found := g.names[t]
+	if !found {
+		name = g.name(t)
+		g.names[t] = name
+		// the name is reserved before the fields for recursive types
+		g.Schemas[name] = &Schema{}
+		*g.Schemas[name] = *g.structSchema(t)
+	}
+
+	return &Schema{Ref: refPrefix + name}
+}
+
+// name returns the name of the schema of the type, qualified by the last two
+// elements of the package path, or the whole path if the name is taken
+func (g *Generator) name(t reflect.Type) string {
+	elements := strings.Split(t.PkgPath(), "/")
+	if len(elements) > 2 {
+		elements = elements[len(elements)-2:]
+	}
+	name := strings.Join(append(elements, t.Name()), ".")
+
+	if _, taken := g.Schemas[name]; taken {
+		name = strings.ReplaceAll(t.PkgPath(), "/", ".") + "." + t.Name()
+	}
+	return name
+}
+
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:                 TypeObject,
+		Properties:           map[string]*Schema{},
+		AdditionalProperties: false,
+	}
+
+	embedded := map[string]*Schema{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			// the fields of embedded structs are promoted unless a shallower
+			// field has the same name
+			for n, p := range g.structSchema(fieldType).Properties {
+				embedded[n] = p
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = g.schema(field.Type)
+	}
+
+	for name, p := range embedded {
+		if _, found := s.Properties[name]; !found {
+			s.Properties[name] = p
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/openapi"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type Limits struct {
+	CPU    *resource.Quantity `json:"cpu,omitempty"`
+	Memory *resource.Quantity `json:"memory,omitempty"`
+}
+
+type Base struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type Request struct {
+	Base `json:",inline"`
+
+	Version  int                   `json:"version,omitempty"`
+	Replicas *int32                `json:"replicas,omitempty"`
+	Limits   map[string]*Limits    `json:"limits,omitempty"`
+	Zones    []string              `json:"zones,omitempty"`
+	Override *runtime.RawExtension `json:"override,omitempty"`
+	Parent   *Request              `json:"parent,omitempty"`
+	Ignored  string                `json:"-"`
+	internal string
+}
+
+var _ = Describe("Schema", func() {
+	var generator *openapi.Generator
+
+	BeforeEach(func() {
+		generator = openapi.NewGenerator()
+	})
+
+	It("references the schemas of named structs", func() {
+		schema := generator.Schema(&Request{})
+		Expect(schema.Ref).To(Equal("#/components/schemas/deployer.openapi_test.Request"))
+		Expect(generator.Schemas).To(HaveKey("deployer.openapi_test.Request"))
+		Expect(generator.Schemas).To(HaveKey("deployer.openapi_test.Limits"))
+	})
+
+	It("describes the fields with their JSON names", func() {
+		generator.Schema(&Request{})
+		request := generator.Schemas["deployer.openapi_test.Request"]
+
+		Expect(request.Type).To(Equal(openapi.TypeObject))
+		Expect(request.AdditionalProperties).To(Equal(false))
+		Expect(request.Properties).To(HaveLen(7))
+		Expect(request.Properties).NotTo(HaveKey("Ignored"))
+		Expect(request.Properties).NotTo(HaveKey("internal"))
+		Expect(request.Properties["replicas"].Type).To(Equal(openapi.TypeInteger))
+		Expect(request.Properties["zones"].Items.Type).To(Equal(openapi.TypeString))
+		Expect(request.Properties["limits"].AdditionalProperties).To(Equal(&openapi.Schema{Ref: "#/components/schemas/deployer.openapi_test.Limits"}))
+		Expect(request.Properties["override"]).To(Equal(&openapi.Schema{}))
+		Expect(request.Properties["parent"].Ref).To(Equal("#/components/schemas/deployer.openapi_test.Request"))
+	})
+
+	It("promotes the fields of embedded structs unless shadowed", func() {
+		generator.Schema(&Request{})
+		request := generator.Schemas["deployer.openapi_test.Request"]
+
+		Expect(request.Properties["name"].Type).To(Equal(openapi.TypeString))
+		Expect(request.Properties["version"].Type).To(Equal(openapi.TypeInteger))
+	})
+
+	It("describes quantities as strings or numbers", func() {
+		generator.Schema(&Limits{})
+		cpu := generator.Schemas["deployer.openapi_test.Limits"].Properties["cpu"]
+		Expect(cpu.OneOf).To(ConsistOf(&openapi.Schema{Type: openapi.TypeString}, &openapi.Schema{Type: openapi.TypeNumber}))
+	})
+
+	It("marshals closed objects with additionalProperties false", func() {
+		generator.Schema(&Limits{})
+		data, err := json.Marshal(generator.Schemas["deployer.openapi_test.Limits"])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"additionalProperties":false`))
+	})
+})
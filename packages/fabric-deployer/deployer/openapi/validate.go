@@ -0,0 +1,171 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+)
+
+// Validate validates the JSON body against the schema. The errors list the
+// path of each invalid field. Null values are valid for any schema and
+// property names are matched case insensitively, like encoding/json does.
+func (d *Document) Validate(schema *Schema, body []byte) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "invalid request body")
+	}
+
+	problems := d.validate(schema, value, "")
+	if len(problems) == 0 {
+		return nil
+	}
+
+	messages := []string{}
+	for _, problem := range problems {
+		messages = append(messages, problem.Message)
+	}
+	return apierror.New(apierror.Validation, apierror.CodeInvalidRequest, "invalid request body: %s", strings.Join(messages, ", ")).
+		WithDetails(problems...)
+}
+
+func (d *Document) validate(schema *Schema, value interface{}, path string) []apierror.FieldError {
+	if schema == nil || value == nil {
+		return nil
+	}
+	if schema.Ref != "" {
+		return d.validate(d.Components.Schemas[strings.TrimPrefix(schema.Ref, refPrefix)], value, path)
+	}
+
+	if len(schema.OneOf) > 0 {
+		types := []string{}
+		for _, alternative := range schema.OneOf {
+			if len(d.validate(alternative, value, path)) == 0 {
+				return nil
+			}
+			types = append(types, alternative.Type)
+		}
+		return []apierror.FieldError{invalid(path, "must be a %s", strings.Join(types, " or "))}
+	}
+
+	switch schema.Type {
+	case TypeString:
+		if _, ok := value.(string); !ok {
+			return []apierror.FieldError{invalid(path, "must be a string")}
+		}
+	case TypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return []apierror.FieldError{invalid(path, "must be a boolean")}
+		}
+	case TypeNumber:
+		if _, ok := value.(json.Number); !ok {
+			return []apierror.FieldError{invalid(path, "must be a number")}
+		}
+	case TypeInteger:
+		number, ok := value.(json.Number)
+		if !ok {
+			return []apierror.FieldError{invalid(path, "must be an integer")}
+		}
+		if _, err := number.Int64(); err != nil {
+			return []apierror.FieldError{invalid(path, "must be an integer")}
+		}
+	case TypeArray:
+		items, ok := value.([]interface{})
+		if !ok {
+			return []apierror.FieldError{invalid(path, "must be an array")}
+		}
+		problems := []apierror.FieldError{}
+		for i, item := range items {
+			problems = append(problems, d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return problems
+	case TypeObject:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []apierror.FieldError{invalid(path, "must be an object")}
+		}
+		return d.validateObject(schema, object, path)
+	}
+
+	return nil
+}
+
+func (d *Document) validateObject(schema *Schema, object map[string]interface{}, path string) []apierror.FieldError {
+	keys := []string{}
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	problems := []apierror.FieldError{}
+	for _, key := range keys {
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+
+		property := lookupProperty(schema.Properties, key)
+		if property == nil {
+			switch additional := schema.AdditionalProperties.(type) {
+			case bool:
+				if !additional {
+					problems = append(problems, invalid(fieldPath, "unknown field"))
+					continue
+				}
+			case *Schema:
+				property = additional
+			}
+		}
+		problems = append(problems, d.validate(property, object[key], fieldPath)...)
+	}
+
+	return problems
+}
+
+func lookupProperty(properties map[string]*Schema, key string) *Schema {
+	if property, found := properties[key]; found {
+		return property
+	}
+	for name, property := range properties {
+		if strings.EqualFold(name, key) {
+			return property
+		}
+	}
+	return nil
+}
+
+func invalid(path, format string, args ...interface{}) apierror.FieldError {
+	message := fmt.Sprintf(format, args...)
+	if path != "" {
+		message = fmt.Sprintf("%s %s", path, message)
+	}
+	return apierror.FieldError{Field: path, Message: message}
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-chi/chi"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+)
+
+var _ = Describe("OpenAPI", func() {
+	var (
+		d      *deployer.Deployer
+		router *chi.Mux
+		body   string
+	)
+
+	BeforeEach(func() {
+		d = deployer.New(&config.DeployerSettingsConfig{}, &config.LocalConfig{Logger: zap.NewNop()}, false)
+		d.OpenAPI = deployer.NewOpenAPIDocument()
+
+		body = ""
+		router = chi.NewRouter()
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			data, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			body = string(data)
+			w.WriteHeader(http.StatusAccepted)
+		}
+		router.With(d.ValidationMiddleware).Post("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}", handler)
+		router.With(d.ValidationMiddleware).Put("/api/v3/instance/{serviceInstanceID}/type/{type}/component/{componentName}/{section}", handler)
+		router.With(d.ValidationMiddleware).Post("/api/v3/instance/{serviceInstanceID}/mustgather", handler)
+		router.With(d.ValidationMiddleware).Post("/api/v3/instance/{serviceInstanceID}/apply", handler)
+		router.Get("/api/v3/openapi.json", d.OpenAPIEndpoint())
+	})
+
+	serve := func(method, path, reqBody string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(reqBody))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	It("serves the OpenAPI document", func() {
+		w := serve(http.MethodGet, "/api/v3/openapi.json", "")
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		doc := map[string]interface{}{}
+		Expect(json.Unmarshal(w.Body.Bytes(), &doc)).To(Succeed())
+		Expect(doc["openapi"]).To(Equal("3.0.3"))
+		Expect(doc["paths"]).To(HaveKey("/api/v3/instance/{serviceInstanceID}/type/peer/component/{componentName}/{section}"))
+		Expect(doc["components"]).To(HaveKeyWithValue("schemas", HaveKey("peer.api.CreateRequest")))
+	})
+
+	It("passes valid bodies to the handler", func() {
+		reqBody := `{"version": "2.5.4", "resources": {"peer": {"requests": {"cpu": "100m"}}}}`
+		w := serve(http.MethodPost, "/api/v3/instance/sid/type/peer/component/peer1", reqBody)
+		Expect(w.Code).To(Equal(http.StatusAccepted))
+		Expect(body).To(Equal(reqBody))
+	})
+
+	It("accepts the create bodies of the console", func() {
+		reqBody := `{
+			"type": "fabric-peer",
+			"dep_component_id": "org1peer1",
+			"parameters": {"display_name": "Org1 Peer", "msp_id": "org1msp"},
+			"version": "2.5.4",
+			"crypto": {"enrollment": {"component": {"cahost": "ca1.example.com"}}},
+			"resources": {"peer": {"requests": {"cpu": "100m"}}}
+		}`
+		w := serve(http.MethodPost, "/api/v3/instance/sid/type/peer/component/org1peer1", reqBody)
+		Expect(w.Code).To(Equal(http.StatusAccepted))
+		Expect(body).To(Equal(reqBody))
+
+		reqBody = `{
+			"type": "fabric-orderer",
+			"dep_component_id": "os1",
+			"cluster_name": "Ordering Service",
+			"append": false,
+			"system_channel_id": "testchainid",
+			"parameters": {"display_name": "Ordering Service_1"},
+			"orgname": "osmsp",
+			"number": 1
+		}`
+		w = serve(http.MethodPost, "/api/v3/instance/sid/type/orderer/component/os1", reqBody)
+		Expect(w.Code).To(Equal(http.StatusAccepted))
+
+		// the raft fields are only passed through for orderers
+		w = serve(http.MethodPost, "/api/v3/instance/sid/type/peer/component/org1peer1", `{"system_channel_id": "testchainid"}`)
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("rejects unknown fields with their path", func() {
+		w := serve(http.MethodPost, "/api/v3/instance/sid/type/peer/component/peer1", `{"version": "2.5.4", "resorces": {}}`)
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+		resp := &deployer.Errors{}
+		Expect(json.Unmarshal(w.Body.Bytes(), resp)).To(Succeed())
+		Expect(resp.Kind).To(Equal(apierror.Validation))
+		Expect(resp.Code).To(Equal(apierror.CodeInvalidRequest))
+		Expect(resp.Details).To(ConsistOf(apierror.FieldError{Field: "resorces", Message: "resorces unknown field"}))
+	})
+
+	It("validates against the request of the component type", func() {
+		w := serve(http.MethodPut, "/api/v3/instance/sid/type/ca/component/ca1/replicas", `{"replicas": 2}`)
+		Expect(w.Code).To(Equal(http.StatusAccepted))
+
+		w = serve(http.MethodPut, "/api/v3/instance/sid/type/ca/component/ca1/resources", `{"resources": {"ca": {"limits": {"cpu": "1"}}, "peer": {}}}`)
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Body.String()).To(ContainSubstring(`"field":"resources.peer"`))
+	})
+
+	It("validates YAML bodies and passes them on as JSON", func() {
+		req := httptest.NewRequest(http.MethodPost, "/api/v3/instance/sid/apply", strings.NewReader("peers:\n- name: peer1\n  spec:\n    version: 2.5.4\n"))
+		req.Header.Set("Content-Type", "application/yaml")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusAccepted))
+		Expect(body).To(MatchJSON(`{"peers": [{"name": "peer1", "spec": {"version": "2.5.4"}}]}`))
+
+		req = httptest.NewRequest(http.MethodPost, "/api/v3/instance/sid/apply", strings.NewReader("peers:\n- name: peer1\n  spec:\n    versoin: 2.5.4\n"))
+		req.Header.Set("Content-Type", "application/yaml")
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Body.String()).To(ContainSubstring(`"field":"peers[0].spec.versoin"`))
+	})
+
+	It("does not validate operations without a request body", func() {
+		w := serve(http.MethodPost, "/api/v3/instance/sid/mustgather", `{"any": "thing"}`)
+		Expect(w.Code).To(Equal(http.StatusAccepted))
+	})
+
+	It("leaves unsupported component types to the handler", func() {
+		w := serve(http.MethodPost, "/api/v3/instance/sid/type/console/component/console1", `{"any": "thing"}`)
+		Expect(w.Code).To(Equal(http.StatusAccepted))
+	})
+})
@@ -0,0 +1,287 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operations
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
+)
+
+// Operation types
+const (
+	CREATE   = "create"
+	APPLY    = "apply"
+	SNAPSHOT = "snapshot"
+	RESTORE  = "restore"
+	UPGRADE  = "upgrade"
+)
+
+// Operation states
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	// StatusPaused operations stopped on a failure and can be resumed
+	StatusPaused = "paused"
+)
+
+// DefaultRetention is how long finished operations are kept before they
+// are removed from the store
+const DefaultRetention = time.Hour
+
+// Operation tracks a long running request that is processed in the background
+type Operation struct {
+	ID                string       `json:"id"`
+	ServiceInstanceID string       `json:"serviceInstanceID"`
+	Type              string       `json:"type"`
+	ComponentType     string       `json:"componentType"`
+	ComponentName     string       `json:"componentName"`
+	Status            string       `json:"status"`
+	Progress          Progress     `json:"progress"`
+	Nodes             []NodeStatus `json:"nodes,omitempty"`
+	StatusCode        int          `json:"statusCode,omitempty"`
+	Response          interface{}  `json:"response,omitempty"`
+	Error             string       `json:"error,omitempty"`
+	CreatedAt         time.Time    `json:"createdAt"`
+	UpdatedAt         time.Time    `json:"updatedAt"`
+	FinishedAt        *time.Time   `json:"finishedAt,omitempty"`
+
+	mutex *sync.RWMutex
+}
+
+// Progress is the number of nodes that finished deploying out of the total
+type Progress struct {
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+}
+
+// NodeStatus is the deployment state of a single node of the component
+type NodeStatus struct {
+	Name    string `json:"name"`
+	State   string `json:"state"`
+	Message string `json:"message,omitempty"`
+}
+
+// UpdateNode records the state of a node, it matches common.NodeProgressFunc
+// so it can be passed directly to the components
+func (op *Operation) UpdateNode(nodeName string, total int, state string, err error) {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+
+	node := NodeStatus{
+		Name:  nodeName,
+		State: state,
+	}
+	if err != nil {
+		node.Message = err.Error()
+	}
+
+	found := false
+	for i := range op.Nodes {
+		if op.Nodes[i].Name == nodeName {
+			op.Nodes[i] = node
+			found = true
+		}
+	}
+	if !found {
+		op.Nodes = append(op.Nodes, node)
+	}
+
+	completed := 0
+	for _, n := range op.Nodes {
+		if n.State == common.NodeStateDeployed || n.State == common.NodeStateFailed {
+			completed++
+		}
+	}
+	op.Progress = Progress{
+		Completed: completed,
+		Total:     total,
+	}
+	op.UpdatedAt = time.Now()
+}
+
+// Finish records the result of the operation the same way the endpoint would
+// have written it if the request was processed synchronously
+func (op *Operation) Finish(response interface{}, statusCode int, err error) {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+
+	now := time.Now()
+	op.UpdatedAt = now
+	op.FinishedAt = &now
+
+	if err != nil {
+		op.Status = StatusFailed
+		op.StatusCode = apierror.StatusCode(err)
+		op.Error = err.Error()
+		return
+	}
+
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	op.StatusCode = statusCode
+	op.Response = response
+	op.Status = StatusSucceeded
+	if statusCode >= http.StatusBadRequest {
+		op.Status = StatusFailed
+	}
+}
+
+// Pause records the failure of an operation that can be resumed, paused
+// operations are not removed from the store
+func (op *Operation) Pause(response interface{}, err error) {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+
+	op.UpdatedAt = time.Now()
+	op.Status = StatusPaused
+	op.StatusCode = apierror.StatusCode(err)
+	op.Response = response
+	op.Error = err.Error()
+}
+
+// Resume sets a paused operation running again, it returns false if the
+// operation is not paused
+func (op *Operation) Resume() bool {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+
+	if op.Status != StatusPaused {
+		return false
+	}
+	op.UpdatedAt = time.Now()
+	op.Status = StatusRunning
+	op.StatusCode = 0
+	op.Error = ""
+	return true
+}
+
+// Snapshot returns a copy of the operation that is safe to serialize while
+// the operation is still running
+func (op *Operation) Snapshot() *Operation {
+	op.mutex.RLock()
+	defer op.mutex.RUnlock()
+
+	snapshot := *op
+	snapshot.Nodes = append([]NodeStatus(nil), op.Nodes...)
+	snapshot.mutex = nil
+	return &snapshot
+}
+
+func (op *Operation) finishedBefore(t time.Time) bool {
+	op.mutex.RLock()
+	defer op.mutex.RUnlock()
+
+	return op.FinishedAt != nil && op.FinishedAt.Before(t)
+}
+
+// Store holds the operations in memory, operations do not survive a restart
+// of the deployer
+type Store struct {
+	Retention time.Duration
+
+	operations map[string]*Operation
+	mutex      sync.Mutex
+}
+
+func NewStore(retention time.Duration) *Store {
+	if retention == 0 {
+		retention = DefaultRetention
+	}
+
+	return &Store{
+		Retention:  retention,
+		operations: map[string]*Operation{},
+	}
+}
+
+// Start registers a new running operation
+func (s *Store) Start(sID, opType, componentType, componentName string) *Operation {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.prune()
+
+	now := time.Now()
+	op := &Operation{
+		ID:                util.NewID(),
+		ServiceInstanceID: sID,
+		Type:              opType,
+		ComponentType:     componentType,
+		ComponentName:     componentName,
+		Status:            StatusRunning,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		mutex:             &sync.RWMutex{},
+	}
+	s.operations[op.ID] = op
+
+	return op
+}
+
+// Get returns the operation with the id if it belongs to the service instance
+func (s *Store) Get(sID, id string) (*Operation, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	op, found := s.operations[id]
+	if !found || op.ServiceInstanceID != sID {
+		return nil, false
+	}
+
+	return op.Snapshot(), true
+}
+
+// List returns the operations of the service instance, oldest first
+func (s *Store) List(sID string) []*Operation {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.prune()
+
+	ops := []*Operation{}
+	for _, op := range s.operations {
+		if op.ServiceInstanceID == sID {
+			ops = append(ops, op.Snapshot())
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].CreatedAt.Before(ops[j].CreatedAt)
+	})
+
+	return ops
+}
+
+// prune removes operations that finished longer than the retention period ago,
+// must be called with the store mutex held
+func (s *Store) prune() {
+	cutoff := time.Now().Add(-s.Retention)
+	for id, op := range s.operations {
+		if op.finishedBefore(cutoff) {
+			delete(s.operations, id)
+		}
+	}
+}
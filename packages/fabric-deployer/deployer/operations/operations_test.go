@@ -0,0 +1,175 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operations_test
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/operations"
+)
+
+var _ = Describe("Operations", func() {
+	var (
+		store *operations.Store
+		op    *operations.Operation
+	)
+
+	BeforeEach(func() {
+		store = operations.NewStore(operations.DefaultRetention)
+		op = store.Start("sid", operations.CREATE, "orderer", "os")
+	})
+
+	Context("start", func() {
+		It("registers a running operation", func() {
+			Expect(op.ID).NotTo(BeEmpty())
+			Expect(op.Status).To(Equal(operations.StatusRunning))
+
+			got, found := store.Get("sid", op.ID)
+			Expect(found).To(BeTrue())
+			Expect(got.ComponentType).To(Equal("orderer"))
+			Expect(got.ComponentName).To(Equal("os"))
+		})
+
+		It("generates unique ids", func() {
+			other := store.Start("sid", operations.CREATE, "orderer", "os")
+			Expect(other.ID).NotTo(Equal(op.ID))
+		})
+	})
+
+	Context("get", func() {
+		It("does not return operations of another service instance", func() {
+			_, found := store.Get("othersid", op.ID)
+			Expect(found).To(BeFalse())
+		})
+
+		It("does not return unknown operations", func() {
+			_, found := store.Get("sid", "unknown")
+			Expect(found).To(BeFalse())
+		})
+	})
+
+	Context("list", func() {
+		It("returns the operations of the service instance", func() {
+			store.Start("othersid", operations.CREATE, "ca", "ca")
+			second := store.Start("sid", operations.CREATE, "peer", "peer")
+
+			ops := store.List("sid")
+			Expect(len(ops)).To(Equal(2))
+			Expect(ops[0].ID).To(Equal(op.ID))
+			Expect(ops[1].ID).To(Equal(second.ID))
+		})
+
+		It("prunes operations finished before the retention period", func() {
+			store.Retention = time.Millisecond
+			op.Finish(nil, http.StatusOK, nil)
+			time.Sleep(5 * time.Millisecond)
+
+			Expect(store.List("sid")).To(BeEmpty())
+		})
+
+		It("keeps running operations", func() {
+			store.Retention = time.Millisecond
+			time.Sleep(5 * time.Millisecond)
+
+			Expect(len(store.List("sid"))).To(Equal(1))
+		})
+	})
+
+	Context("update node", func() {
+		It("tracks the progress of each node", func() {
+			op.UpdateNode("os1", 2, common.NodeStateDeploying, nil)
+			op.UpdateNode("os1", 2, common.NodeStateDeployed, nil)
+			op.UpdateNode("os2", 2, common.NodeStateDeploying, nil)
+
+			got := op.Snapshot()
+			Expect(got.Progress).To(Equal(operations.Progress{Completed: 1, Total: 2}))
+			Expect(got.Nodes).To(Equal([]operations.NodeStatus{
+				{Name: "os1", State: common.NodeStateDeployed},
+				{Name: "os2", State: common.NodeStateDeploying},
+			}))
+		})
+
+		It("records the failure message of a node", func() {
+			op.UpdateNode("os1", 1, common.NodeStateFailed, errors.New("timed out"))
+
+			got := op.Snapshot()
+			Expect(got.Progress).To(Equal(operations.Progress{Completed: 1, Total: 1}))
+			Expect(got.Nodes[0].Message).To(Equal("timed out"))
+		})
+	})
+
+	Context("finish", func() {
+		It("succeeds with the response", func() {
+			op.Finish("response", 0, nil)
+
+			got := op.Snapshot()
+			Expect(got.Status).To(Equal(operations.StatusSucceeded))
+			Expect(got.StatusCode).To(Equal(http.StatusOK))
+			Expect(got.Response).To(Equal("response"))
+			Expect(got.FinishedAt).NotTo(BeNil())
+		})
+
+		It("fails with the error", func() {
+			op.Finish(nil, 0, apierror.New(apierror.Conflict, apierror.CodeAlreadyExists, "component already exists"))
+
+			got := op.Snapshot()
+			Expect(got.Status).To(Equal(operations.StatusFailed))
+			Expect(got.StatusCode).To(Equal(http.StatusConflict))
+			Expect(got.Error).To(Equal("component already exists"))
+		})
+
+		It("fails if the status code is an error", func() {
+			op.Finish(nil, http.StatusBadRequest, nil)
+
+			got := op.Snapshot()
+			Expect(got.Status).To(Equal(operations.StatusFailed))
+		})
+	})
+
+	Context("pause", func() {
+		It("pauses with the error and the response", func() {
+			op.Pause("plan", errors.New("node failed"))
+
+			got := op.Snapshot()
+			Expect(got.Status).To(Equal(operations.StatusPaused))
+			Expect(got.StatusCode).To(Equal(http.StatusInternalServerError))
+			Expect(got.Error).To(Equal("node failed"))
+			Expect(got.Response).To(Equal("plan"))
+			Expect(got.FinishedAt).To(BeNil())
+		})
+
+		It("resumes paused operations only", func() {
+			Expect(op.Resume()).To(BeFalse())
+
+			op.Pause("plan", errors.New("node failed"))
+			Expect(op.Resume()).To(BeTrue())
+
+			got := op.Snapshot()
+			Expect(got.Status).To(Equal(operations.StatusRunning))
+			Expect(got.Error).To(BeEmpty())
+		})
+	})
+})
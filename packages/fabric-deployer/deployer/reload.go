@@ -0,0 +1,159 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/ca"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/mustgather"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/peer"
+)
+
+// Components are the components built from one revision of the configuration.
+// A reload replaces them as a whole, requests that already started keep using
+// the components they started with.
+type Components struct {
+	Config     *config.DeployerSettingsConfig
+	Revision   config.Revision
+	CA         *ca.CA
+	Peer       *peer.Peer
+	Orderer    *orderer.Orderer
+	Mustgather *mustgather.Mustgather
+
+	// Targets are the components of the other clusters, by cluster, they
+	// have no mustgather
+	Targets map[string]*Components
+}
+
+// For returns the components of the cluster
+func (c *Components) For(cluster string) *Components {
+	if components, found := c.Targets[cluster]; found {
+		return components
+	}
+	return c
+}
+
+// WithContext returns a copy of the components whose clients make their calls
+// to the API server with ctx, the mustgather and the other clusters are kept
+func (c *Components) WithContext(ctx context.Context) *Components {
+	bound := *c
+	if c.CA != nil {
+		component := *c.CA
+		component.Kube = withContext(component.Kube, ctx)
+		component.IBPOperatorClient = withContext(component.IBPOperatorClient, ctx)
+		bound.CA = &component
+	}
+	if c.Peer != nil {
+		component := *c.Peer
+		component.Kube = withContext(component.Kube, ctx)
+		component.IBPOperatorClient = withContext(component.IBPOperatorClient, ctx)
+		bound.Peer = &component
+	}
+	if c.Orderer != nil {
+		component := *c.Orderer
+		component.Kube = withContext(component.Kube, ctx)
+		component.IBPOperatorClient = withContext(component.IBPOperatorClient, ctx)
+		bound.Orderer = &component
+	}
+	return &bound
+}
+
+// WithRequestID returns a copy of the components whose loggers, including the
+// logger of the mustgather, log the ID of the request with every entry
+func (c *Components) WithRequestID(id string) *Components {
+	bound := *c
+	if c.CA != nil {
+		component := *c.CA
+		component.Logger = component.Logger.With("requestID", id)
+		bound.CA = &component
+	}
+	if c.Peer != nil {
+		component := *c.Peer
+		component.Logger = component.Logger.With("requestID", id)
+		bound.Peer = &component
+	}
+	if c.Orderer != nil {
+		component := *c.Orderer
+		component.Logger = component.Logger.With("requestID", id)
+		bound.Orderer = &component
+	}
+	if c.Mustgather != nil {
+		component := *c.Mustgather
+		component.Logger = component.Logger.With("requestID", id)
+		bound.Mustgather = &component
+	}
+	return &bound
+}
+
+// Components returns the components built from the configuration in use
+func (d *Deployer) Components() *Components {
+	return d.components.Load()
+}
+
+func (d *Deployer) newComponents(cfg *config.DeployerSettingsConfig, revision config.Revision) *Components {
+	components := &Components{
+		Config:     cfg,
+		Revision:   revision,
+		CA:         ca.New(d.LocalConfig.Logger, d.K8SClient, d.IBPOperatorClient, cfg),
+		Peer:       peer.New(d.LocalConfig.Logger, d.K8SClient, d.IBPOperatorClient, cfg),
+		Orderer:    orderer.New(d.LocalConfig.Logger, d.K8SClient, d.IBPOperatorClient, cfg),
+		Mustgather: mustgather.New(d.LocalConfig.Logger, d.K8SClient, cfg, &http.Client{}),
+		Targets:    map[string]*Components{},
+	}
+
+	for name, target := range d.Targets {
+		targetCfg := cfg.ForCluster(target.Cluster)
+		logger := d.LocalConfig.Logger.Named(name)
+		components.Targets[name] = &Components{
+			Config:   targetCfg,
+			Revision: revision,
+			CA:       ca.New(logger, target.K8SClient, target.IBPOperatorClient, targetCfg),
+			Peer:     peer.New(logger, target.K8SClient, target.IBPOperatorClient, targetCfg),
+			Orderer:  orderer.New(logger, target.K8SClient, target.IBPOperatorClient, targetCfg),
+		}
+	}
+
+	return components
+}
+
+// ApplyConfig replaces the components with components built from a reloaded
+// configuration. The listener, TLS, authentication, authorization, namespace
+// and cluster settings are only read at startup and keep their values.
+func (d *Deployer) ApplyConfig(cfg *config.DeployerSettingsConfig, revision config.Revision) {
+	cfg.Namespace = d.Config.Namespace
+	cfg.Domain = d.Config.Domain
+	cfg.Clusters = d.Config.Clusters
+
+	d.components.Store(d.newComponents(cfg, revision))
+}
+
+// ConfigRevisionEndpoint returns an endpoint type that is responsible for
+// handling the revision of the configuration
+func (d *Deployer) ConfigRevisionEndpoint() func(http.ResponseWriter, *http.Request) {
+	return NewEndpoint(d.ConfigRevision, d.LocalConfig.Logger).ServeHTTP
+}
+
+// ConfigRevision returns the revision of the configuration in use
+func (d *Deployer) ConfigRevision(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	return d.Components().Revision, http.StatusOK, nil
+}
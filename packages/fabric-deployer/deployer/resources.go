@@ -0,0 +1,88 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
+	"github.com/go-chi/chi"
+)
+
+// InstanceResources are the resources of all the components of the service
+// instance, in total, by component type and by component
+type InstanceResources struct {
+	Total      *util.ResourceReturn            `json:"total"`
+	Types      map[string]*util.ResourceReturn `json:"types"`
+	Components []util.ComponentResources       `json:"components"`
+}
+
+// ResourcesEndpoint returns an endpoint type that is responsible for handling
+// the resources of the service instance
+func (d *Deployer) ResourcesEndpoint() func(http.ResponseWriter, *http.Request) {
+	return NewEndpoint(d.Resources, d.LocalConfig.Logger).ServeHTTP
+}
+
+// Resources returns the requests and limits of all the replicas of the
+// components, e.g. for chargeback
+func (d *Deployer) Resources(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	sID := chi.URLParam(r, "serviceInstanceID")
+	namespace := d.namespace(r)
+
+	current := d.clusterComponents(r)
+	components := []util.ComponentResources{}
+	for _, usage := range []func(string, string) ([]util.ComponentResources, error){
+		current.CA.ResourceUsage,
+		current.Orderer.ResourceUsage,
+		current.Peer.ResourceUsage,
+	} {
+		resources, err := usage(sID, namespace)
+		if err != nil {
+			return nil, 0, err
+		}
+		components = append(components, resources...)
+	}
+	sort.SliceStable(components, func(i, j int) bool {
+		if components[i].Type != components[j].Type {
+			return components[i].Type < components[j].Type
+		}
+		return components[i].Name < components[j].Name
+	})
+
+	total := util.NewTotals()
+	byType := map[string]*util.Totals{}
+	for _, component := range components {
+		if byType[component.Type] == nil {
+			byType[component.Type] = util.NewTotals()
+		}
+		byType[component.Type].Add(component.Roles, component.Replicas)
+		total.Add(component.Roles, component.Replicas)
+	}
+
+	response := &InstanceResources{
+		Total:      total.ResourceReturn(),
+		Types:      map[string]*util.ResourceReturn{},
+		Components: components,
+	}
+	for typeOfComponent, totals := range byType {
+		response.Types[typeOfComponent] = totals.ResourceReturn()
+	}
+	return response, http.StatusOK, nil
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/operations"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/snapshot"
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+)
+
+// ListSnapshotsEndpoint returns an endpoint type that is responsible for
+// handling listing the snapshots of a component
+func (d *Deployer) ListSnapshotsEndpoint() func(http.ResponseWriter, *http.Request) {
+	return NewEndpoint(d.ListSnapshots, d.LocalConfig.Logger).ServeHTTP
+}
+
+// CreateSnapshotEndpoint returns an endpoint type that is responsible for
+// handling taking a snapshot of a component
+func (d *Deployer) CreateSnapshotEndpoint() func(http.ResponseWriter, *http.Request) {
+	return NewEndpoint(d.CreateSnapshot, d.LocalConfig.Logger).ServeHTTP
+}
+
+// RestoreSnapshotEndpoint returns an endpoint type that is responsible for
+// handling restoring a snapshot of a component
+func (d *Deployer) RestoreSnapshotEndpoint() func(http.ResponseWriter, *http.Request) {
+	return NewEndpoint(d.RestoreSnapshot, d.LocalConfig.Logger).ServeHTTP
+}
+
+func (d *Deployer) ListSnapshots(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	typeOfComponent := chi.URLParam(r, "type")
+	compName := chi.URLParam(r, "componentName")
+
+	if _, err := d.snapshotComponent(r, typeOfComponent); err != nil {
+		return nil, 0, err
+	}
+
+	snapshots, err := d.target(r).Snapshots.List(typeOfComponent, compName, d.namespace(r))
+	if err != nil {
+		return nil, 0, err
+	}
+	return snapshots, http.StatusOK, nil
+}
+
+// CreateSnapshot takes a snapshot of the volumes of a peer or orderer, the
+// component is unavailable until the snapshots are cut
+func (d *Deployer) CreateSnapshot(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	typeOfComponent := chi.URLParam(r, "type")
+	compName := chi.URLParam(r, "componentName")
+	namespace := d.namespace(r)
+
+	component, err := d.snapshotComponent(r, typeOfComponent)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	request := &snapshot.CreateRequest{}
+	err = readSnapshotRequest(r, request)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return d.runSnapshotOperation(w, r, operations.SNAPSHOT, http.StatusCreated, func() (interface{}, error) {
+		return d.target(r).Snapshots.Create(component, typeOfComponent, compName, namespace, request)
+	})
+}
+
+// RestoreSnapshot replaces the volumes of a peer or orderer with new volumes
+// restored from a snapshot
+func (d *Deployer) RestoreSnapshot(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	typeOfComponent := chi.URLParam(r, "type")
+	compName := chi.URLParam(r, "componentName")
+	namespace := d.namespace(r)
+
+	component, err := d.snapshotComponent(r, typeOfComponent)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	request := &snapshot.RestoreRequest{}
+	err = readSnapshotRequest(r, request)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return d.runSnapshotOperation(w, r, operations.RESTORE, http.StatusOK, func() (interface{}, error) {
+		return d.target(r).Snapshots.Restore(component, typeOfComponent, compName, namespace, request)
+	})
+}
+
+// runSnapshotOperation runs the snapshot operation, in the background if the
+// client asked for it. Snapshot operations scale the component down and
+// cannot be dry run.
+func (d *Deployer) runSnapshotOperation(w http.ResponseWriter, r *http.Request, opType string, statusCode int, run func() (interface{}, error)) (interface{}, int, error) {
+	if isDryRun(r) {
+		return nil, 0, apierror.New(apierror.Validation, apierror.CodeInvalidRequest, "dry run is not supported for %s", opType)
+	}
+
+	sID := chi.URLParam(r, "serviceInstanceID")
+	typeOfComponent := chi.URLParam(r, "type")
+	compName := chi.URLParam(r, "componentName")
+
+	if !isAsyncRequest(r) {
+		resp, err := run()
+		if err != nil {
+			return nil, 0, err
+		}
+		return resp, statusCode, nil
+	}
+
+	op := d.Operations.Start(sID, opType, typeOfComponent, compName)
+	go func() {
+		resp, err := run()
+		if err != nil {
+			d.Logger.Errorf("Operation '%s' to %s '%s' failed: %s", op.ID, opType, compName, err)
+		}
+		op.Finish(resp, statusCode, err)
+	}()
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v3/instance/%s/operations/%s", sID, op.ID))
+	w.Header().Set("Preference-Applied", "respond-async")
+	return op.Snapshot(), http.StatusAccepted, nil
+}
+
+func (d *Deployer) snapshotComponent(r *http.Request, typeOfComponent string) (snapshot.Component, error) {
+	switch typeOfComponent {
+	case "peer":
+		return d.clusterComponents(r).Peer, nil
+	case "orderer":
+		return d.clusterComponents(r).Orderer, nil
+	}
+	return nil, apierror.UnsupportedComponentType(typeOfComponent)
+}
+
+func readSnapshotRequest(r *http.Request, request interface{}) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.New("failed to ready request body")
+	}
+	if len(body) == 0 {
+		return nil
+	}
+
+	err = json.Unmarshal(body, request)
+	if err != nil {
+		return apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "failed to unmarshal request")
+	}
+	return nil
+}
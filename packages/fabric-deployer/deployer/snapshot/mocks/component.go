@@ -0,0 +1,279 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"sync"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/snapshot"
+)
+
+type Component struct {
+	RebindVolumesStub        func(string, string, map[string]string) error
+	rebindVolumesMutex       sync.RWMutex
+	rebindVolumesArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 map[string]string
+	}
+	rebindVolumesReturns struct {
+		result1 error
+	}
+	rebindVolumesReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ScaleReplicasStub        func(string, string, *int32) (*int32, error)
+	scaleReplicasMutex       sync.RWMutex
+	scaleReplicasArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 *int32
+	}
+	scaleReplicasReturns struct {
+		result1 *int32
+		result2 error
+	}
+	scaleReplicasReturnsOnCall map[int]struct {
+		result1 *int32
+		result2 error
+	}
+	SnapshotVolumesStub        func(string, string) ([]snapshot.Volume, error)
+	snapshotVolumesMutex       sync.RWMutex
+	snapshotVolumesArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	snapshotVolumesReturns struct {
+		result1 []snapshot.Volume
+		result2 error
+	}
+	snapshotVolumesReturnsOnCall map[int]struct {
+		result1 []snapshot.Volume
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *Component) RebindVolumes(arg1 string, arg2 string, arg3 map[string]string) error {
+	fake.rebindVolumesMutex.Lock()
+	ret, specificReturn := fake.rebindVolumesReturnsOnCall[len(fake.rebindVolumesArgsForCall)]
+	fake.rebindVolumesArgsForCall = append(fake.rebindVolumesArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 map[string]string
+	}{arg1, arg2, arg3})
+	stub := fake.RebindVolumesStub
+	fakeReturns := fake.rebindVolumesReturns
+	fake.recordInvocation("RebindVolumes", []interface{}{arg1, arg2, arg3})
+	fake.rebindVolumesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Component) RebindVolumesCallCount() int {
+	fake.rebindVolumesMutex.RLock()
+	defer fake.rebindVolumesMutex.RUnlock()
+	return len(fake.rebindVolumesArgsForCall)
+}
+
+func (fake *Component) RebindVolumesCalls(stub func(string, string, map[string]string) error) {
+	fake.rebindVolumesMutex.Lock()
+	defer fake.rebindVolumesMutex.Unlock()
+	fake.RebindVolumesStub = stub
+}
+
+func (fake *Component) RebindVolumesArgsForCall(i int) (string, string, map[string]string) {
+	fake.rebindVolumesMutex.RLock()
+	defer fake.rebindVolumesMutex.RUnlock()
+	argsForCall := fake.rebindVolumesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Component) RebindVolumesReturns(result1 error) {
+	fake.rebindVolumesMutex.Lock()
+	defer fake.rebindVolumesMutex.Unlock()
+	fake.RebindVolumesStub = nil
+	fake.rebindVolumesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Component) RebindVolumesReturnsOnCall(i int, result1 error) {
+	fake.rebindVolumesMutex.Lock()
+	defer fake.rebindVolumesMutex.Unlock()
+	fake.RebindVolumesStub = nil
+	if fake.rebindVolumesReturnsOnCall == nil {
+		fake.rebindVolumesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.rebindVolumesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Component) ScaleReplicas(arg1 string, arg2 string, arg3 *int32) (*int32, error) {
+	fake.scaleReplicasMutex.Lock()
+	ret, specificReturn := fake.scaleReplicasReturnsOnCall[len(fake.scaleReplicasArgsForCall)]
+	fake.scaleReplicasArgsForCall = append(fake.scaleReplicasArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 *int32
+	}{arg1, arg2, arg3})
+	stub := fake.ScaleReplicasStub
+	fakeReturns := fake.scaleReplicasReturns
+	fake.recordInvocation("ScaleReplicas", []interface{}{arg1, arg2, arg3})
+	fake.scaleReplicasMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Component) ScaleReplicasCallCount() int {
+	fake.scaleReplicasMutex.RLock()
+	defer fake.scaleReplicasMutex.RUnlock()
+	return len(fake.scaleReplicasArgsForCall)
+}
+
+func (fake *Component) ScaleReplicasCalls(stub func(string, string, *int32) (*int32, error)) {
+	fake.scaleReplicasMutex.Lock()
+	defer fake.scaleReplicasMutex.Unlock()
+	fake.ScaleReplicasStub = stub
+}
+
+func (fake *Component) ScaleReplicasArgsForCall(i int) (string, string, *int32) {
+	fake.scaleReplicasMutex.RLock()
+	defer fake.scaleReplicasMutex.RUnlock()
+	argsForCall := fake.scaleReplicasArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Component) ScaleReplicasReturns(result1 *int32, result2 error) {
+	fake.scaleReplicasMutex.Lock()
+	defer fake.scaleReplicasMutex.Unlock()
+	fake.ScaleReplicasStub = nil
+	fake.scaleReplicasReturns = struct {
+		result1 *int32
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Component) ScaleReplicasReturnsOnCall(i int, result1 *int32, result2 error) {
+	fake.scaleReplicasMutex.Lock()
+	defer fake.scaleReplicasMutex.Unlock()
+	fake.ScaleReplicasStub = nil
+	if fake.scaleReplicasReturnsOnCall == nil {
+		fake.scaleReplicasReturnsOnCall = make(map[int]struct {
+			result1 *int32
+			result2 error
+		})
+	}
+	fake.scaleReplicasReturnsOnCall[i] = struct {
+		result1 *int32
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Component) SnapshotVolumes(arg1 string, arg2 string) ([]snapshot.Volume, error) {
+	fake.snapshotVolumesMutex.Lock()
+	ret, specificReturn := fake.snapshotVolumesReturnsOnCall[len(fake.snapshotVolumesArgsForCall)]
+	fake.snapshotVolumesArgsForCall = append(fake.snapshotVolumesArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.SnapshotVolumesStub
+	fakeReturns := fake.snapshotVolumesReturns
+	fake.recordInvocation("SnapshotVolumes", []interface{}{arg1, arg2})
+	fake.snapshotVolumesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Component) SnapshotVolumesCallCount() int {
+	fake.snapshotVolumesMutex.RLock()
+	defer fake.snapshotVolumesMutex.RUnlock()
+	return len(fake.snapshotVolumesArgsForCall)
+}
+
+func (fake *Component) SnapshotVolumesCalls(stub func(string, string) ([]snapshot.Volume, error)) {
+	fake.snapshotVolumesMutex.Lock()
+	defer fake.snapshotVolumesMutex.Unlock()
+	fake.SnapshotVolumesStub = stub
+}
+
+func (fake *Component) SnapshotVolumesArgsForCall(i int) (string, string) {
+	fake.snapshotVolumesMutex.RLock()
+	defer fake.snapshotVolumesMutex.RUnlock()
+	argsForCall := fake.snapshotVolumesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Component) SnapshotVolumesReturns(result1 []snapshot.Volume, result2 error) {
+	fake.snapshotVolumesMutex.Lock()
+	defer fake.snapshotVolumesMutex.Unlock()
+	fake.SnapshotVolumesStub = nil
+	fake.snapshotVolumesReturns = struct {
+		result1 []snapshot.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Component) SnapshotVolumesReturnsOnCall(i int, result1 []snapshot.Volume, result2 error) {
+	fake.snapshotVolumesMutex.Lock()
+	defer fake.snapshotVolumesMutex.Unlock()
+	fake.SnapshotVolumesStub = nil
+	if fake.snapshotVolumesReturnsOnCall == nil {
+		fake.snapshotVolumesReturnsOnCall = make(map[int]struct {
+			result1 []snapshot.Volume
+			result2 error
+		})
+	}
+	fake.snapshotVolumesReturnsOnCall[i] = struct {
+		result1 []snapshot.Volume
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Component) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.rebindVolumesMutex.RLock()
+	defer fake.rebindVolumesMutex.RUnlock()
+	fake.scaleReplicasMutex.RLock()
+	defer fake.scaleReplicasMutex.RUnlock()
+	fake.snapshotVolumesMutex.RLock()
+	defer fake.snapshotVolumesMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *Component) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ snapshot.Component = new(Component)
@@ -0,0 +1,525 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"sync"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/kube"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/snapshot"
+	v1 "k8s.io/api/core/v1"
+)
+
+type Kube struct {
+	CreatePVCStub        func(string, *v1.PersistentVolumeClaim) (*v1.PersistentVolumeClaim, error)
+	createPVCMutex       sync.RWMutex
+	createPVCArgsForCall []struct {
+		arg1 string
+		arg2 *v1.PersistentVolumeClaim
+	}
+	createPVCReturns struct {
+		result1 *v1.PersistentVolumeClaim
+		result2 error
+	}
+	createPVCReturnsOnCall map[int]struct {
+		result1 *v1.PersistentVolumeClaim
+		result2 error
+	}
+	CreateVolumeSnapshotStub        func(string, *kube.VolumeSnapshot) (*kube.VolumeSnapshot, error)
+	createVolumeSnapshotMutex       sync.RWMutex
+	createVolumeSnapshotArgsForCall []struct {
+		arg1 string
+		arg2 *kube.VolumeSnapshot
+	}
+	createVolumeSnapshotReturns struct {
+		result1 *kube.VolumeSnapshot
+		result2 error
+	}
+	createVolumeSnapshotReturnsOnCall map[int]struct {
+		result1 *kube.VolumeSnapshot
+		result2 error
+	}
+	GetPVCStub        func(string, string) (*v1.PersistentVolumeClaim, error)
+	getPVCMutex       sync.RWMutex
+	getPVCArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	getPVCReturns struct {
+		result1 *v1.PersistentVolumeClaim
+		result2 error
+	}
+	getPVCReturnsOnCall map[int]struct {
+		result1 *v1.PersistentVolumeClaim
+		result2 error
+	}
+	GetPodsByLabelStub        func(string, string) (*v1.Pod, error)
+	getPodsByLabelMutex       sync.RWMutex
+	getPodsByLabelArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	getPodsByLabelReturns struct {
+		result1 *v1.Pod
+		result2 error
+	}
+	getPodsByLabelReturnsOnCall map[int]struct {
+		result1 *v1.Pod
+		result2 error
+	}
+	GetVolumeSnapshotStub        func(string, string) (*kube.VolumeSnapshot, error)
+	getVolumeSnapshotMutex       sync.RWMutex
+	getVolumeSnapshotArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	getVolumeSnapshotReturns struct {
+		result1 *kube.VolumeSnapshot
+		result2 error
+	}
+	getVolumeSnapshotReturnsOnCall map[int]struct {
+		result1 *kube.VolumeSnapshot
+		result2 error
+	}
+	ListVolumeSnapshotsStub        func(string, string) ([]kube.VolumeSnapshot, error)
+	listVolumeSnapshotsMutex       sync.RWMutex
+	listVolumeSnapshotsArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	listVolumeSnapshotsReturns struct {
+		result1 []kube.VolumeSnapshot
+		result2 error
+	}
+	listVolumeSnapshotsReturnsOnCall map[int]struct {
+		result1 []kube.VolumeSnapshot
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *Kube) CreatePVC(arg1 string, arg2 *v1.PersistentVolumeClaim) (*v1.PersistentVolumeClaim, error) {
+	fake.createPVCMutex.Lock()
+	ret, specificReturn := fake.createPVCReturnsOnCall[len(fake.createPVCArgsForCall)]
+	fake.createPVCArgsForCall = append(fake.createPVCArgsForCall, struct {
+		arg1 string
+		arg2 *v1.PersistentVolumeClaim
+	}{arg1, arg2})
+	stub := fake.CreatePVCStub
+	fakeReturns := fake.createPVCReturns
+	fake.recordInvocation("CreatePVC", []interface{}{arg1, arg2})
+	fake.createPVCMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Kube) CreatePVCCallCount() int {
+	fake.createPVCMutex.RLock()
+	defer fake.createPVCMutex.RUnlock()
+	return len(fake.createPVCArgsForCall)
+}
+
+func (fake *Kube) CreatePVCCalls(stub func(string, *v1.PersistentVolumeClaim) (*v1.PersistentVolumeClaim, error)) {
+	fake.createPVCMutex.Lock()
+	defer fake.createPVCMutex.Unlock()
+	fake.CreatePVCStub = stub
+}
+
+func (fake *Kube) CreatePVCArgsForCall(i int) (string, *v1.PersistentVolumeClaim) {
+	fake.createPVCMutex.RLock()
+	defer fake.createPVCMutex.RUnlock()
+	argsForCall := fake.createPVCArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Kube) CreatePVCReturns(result1 *v1.PersistentVolumeClaim, result2 error) {
+	fake.createPVCMutex.Lock()
+	defer fake.createPVCMutex.Unlock()
+	fake.CreatePVCStub = nil
+	fake.createPVCReturns = struct {
+		result1 *v1.PersistentVolumeClaim
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) CreatePVCReturnsOnCall(i int, result1 *v1.PersistentVolumeClaim, result2 error) {
+	fake.createPVCMutex.Lock()
+	defer fake.createPVCMutex.Unlock()
+	fake.CreatePVCStub = nil
+	if fake.createPVCReturnsOnCall == nil {
+		fake.createPVCReturnsOnCall = make(map[int]struct {
+			result1 *v1.PersistentVolumeClaim
+			result2 error
+		})
+	}
+	fake.createPVCReturnsOnCall[i] = struct {
+		result1 *v1.PersistentVolumeClaim
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) CreateVolumeSnapshot(arg1 string, arg2 *kube.VolumeSnapshot) (*kube.VolumeSnapshot, error) {
+	fake.createVolumeSnapshotMutex.Lock()
+	ret, specificReturn := fake.createVolumeSnapshotReturnsOnCall[len(fake.createVolumeSnapshotArgsForCall)]
+	fake.createVolumeSnapshotArgsForCall = append(fake.createVolumeSnapshotArgsForCall, struct {
+		arg1 string
+		arg2 *kube.VolumeSnapshot
+	}{arg1, arg2})
+	stub := fake.CreateVolumeSnapshotStub
+	fakeReturns := fake.createVolumeSnapshotReturns
+	fake.recordInvocation("CreateVolumeSnapshot", []interface{}{arg1, arg2})
+	fake.createVolumeSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Kube) CreateVolumeSnapshotCallCount() int {
+	fake.createVolumeSnapshotMutex.RLock()
+	defer fake.createVolumeSnapshotMutex.RUnlock()
+	return len(fake.createVolumeSnapshotArgsForCall)
+}
+
+func (fake *Kube) CreateVolumeSnapshotCalls(stub func(string, *kube.VolumeSnapshot) (*kube.VolumeSnapshot, error)) {
+	fake.createVolumeSnapshotMutex.Lock()
+	defer fake.createVolumeSnapshotMutex.Unlock()
+	fake.CreateVolumeSnapshotStub = stub
+}
+
+func (fake *Kube) CreateVolumeSnapshotArgsForCall(i int) (string, *kube.VolumeSnapshot) {
+	fake.createVolumeSnapshotMutex.RLock()
+	defer fake.createVolumeSnapshotMutex.RUnlock()
+	argsForCall := fake.createVolumeSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Kube) CreateVolumeSnapshotReturns(result1 *kube.VolumeSnapshot, result2 error) {
+	fake.createVolumeSnapshotMutex.Lock()
+	defer fake.createVolumeSnapshotMutex.Unlock()
+	fake.CreateVolumeSnapshotStub = nil
+	fake.createVolumeSnapshotReturns = struct {
+		result1 *kube.VolumeSnapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) CreateVolumeSnapshotReturnsOnCall(i int, result1 *kube.VolumeSnapshot, result2 error) {
+	fake.createVolumeSnapshotMutex.Lock()
+	defer fake.createVolumeSnapshotMutex.Unlock()
+	fake.CreateVolumeSnapshotStub = nil
+	if fake.createVolumeSnapshotReturnsOnCall == nil {
+		fake.createVolumeSnapshotReturnsOnCall = make(map[int]struct {
+			result1 *kube.VolumeSnapshot
+			result2 error
+		})
+	}
+	fake.createVolumeSnapshotReturnsOnCall[i] = struct {
+		result1 *kube.VolumeSnapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) GetPVC(arg1 string, arg2 string) (*v1.PersistentVolumeClaim, error) {
+	fake.getPVCMutex.Lock()
+	ret, specificReturn := fake.getPVCReturnsOnCall[len(fake.getPVCArgsForCall)]
+	fake.getPVCArgsForCall = append(fake.getPVCArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetPVCStub
+	fakeReturns := fake.getPVCReturns
+	fake.recordInvocation("GetPVC", []interface{}{arg1, arg2})
+	fake.getPVCMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Kube) GetPVCCallCount() int {
+	fake.getPVCMutex.RLock()
+	defer fake.getPVCMutex.RUnlock()
+	return len(fake.getPVCArgsForCall)
+}
+
+func (fake *Kube) GetPVCCalls(stub func(string, string) (*v1.PersistentVolumeClaim, error)) {
+	fake.getPVCMutex.Lock()
+	defer fake.getPVCMutex.Unlock()
+	fake.GetPVCStub = stub
+}
+
+func (fake *Kube) GetPVCArgsForCall(i int) (string, string) {
+	fake.getPVCMutex.RLock()
+	defer fake.getPVCMutex.RUnlock()
+	argsForCall := fake.getPVCArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Kube) GetPVCReturns(result1 *v1.PersistentVolumeClaim, result2 error) {
+	fake.getPVCMutex.Lock()
+	defer fake.getPVCMutex.Unlock()
+	fake.GetPVCStub = nil
+	fake.getPVCReturns = struct {
+		result1 *v1.PersistentVolumeClaim
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) GetPVCReturnsOnCall(i int, result1 *v1.PersistentVolumeClaim, result2 error) {
+	fake.getPVCMutex.Lock()
+	defer fake.getPVCMutex.Unlock()
+	fake.GetPVCStub = nil
+	if fake.getPVCReturnsOnCall == nil {
+		fake.getPVCReturnsOnCall = make(map[int]struct {
+			result1 *v1.PersistentVolumeClaim
+			result2 error
+		})
+	}
+	fake.getPVCReturnsOnCall[i] = struct {
+		result1 *v1.PersistentVolumeClaim
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) GetPodsByLabel(arg1 string, arg2 string) (*v1.Pod, error) {
+	fake.getPodsByLabelMutex.Lock()
+	ret, specificReturn := fake.getPodsByLabelReturnsOnCall[len(fake.getPodsByLabelArgsForCall)]
+	fake.getPodsByLabelArgsForCall = append(fake.getPodsByLabelArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetPodsByLabelStub
+	fakeReturns := fake.getPodsByLabelReturns
+	fake.recordInvocation("GetPodsByLabel", []interface{}{arg1, arg2})
+	fake.getPodsByLabelMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Kube) GetPodsByLabelCallCount() int {
+	fake.getPodsByLabelMutex.RLock()
+	defer fake.getPodsByLabelMutex.RUnlock()
+	return len(fake.getPodsByLabelArgsForCall)
+}
+
+func (fake *Kube) GetPodsByLabelCalls(stub func(string, string) (*v1.Pod, error)) {
+	fake.getPodsByLabelMutex.Lock()
+	defer fake.getPodsByLabelMutex.Unlock()
+	fake.GetPodsByLabelStub = stub
+}
+
+func (fake *Kube) GetPodsByLabelArgsForCall(i int) (string, string) {
+	fake.getPodsByLabelMutex.RLock()
+	defer fake.getPodsByLabelMutex.RUnlock()
+	argsForCall := fake.getPodsByLabelArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Kube) GetPodsByLabelReturns(result1 *v1.Pod, result2 error) {
+	fake.getPodsByLabelMutex.Lock()
+	defer fake.getPodsByLabelMutex.Unlock()
+	fake.GetPodsByLabelStub = nil
+	fake.getPodsByLabelReturns = struct {
+		result1 *v1.Pod
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) GetPodsByLabelReturnsOnCall(i int, result1 *v1.Pod, result2 error) {
+	fake.getPodsByLabelMutex.Lock()
+	defer fake.getPodsByLabelMutex.Unlock()
+	fake.GetPodsByLabelStub = nil
+	if fake.getPodsByLabelReturnsOnCall == nil {
+		fake.getPodsByLabelReturnsOnCall = make(map[int]struct {
+			result1 *v1.Pod
+			result2 error
+		})
+	}
+	fake.getPodsByLabelReturnsOnCall[i] = struct {
+		result1 *v1.Pod
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) GetVolumeSnapshot(arg1 string, arg2 string) (*kube.VolumeSnapshot, error) {
+	fake.getVolumeSnapshotMutex.Lock()
+	ret, specificReturn := fake.getVolumeSnapshotReturnsOnCall[len(fake.getVolumeSnapshotArgsForCall)]
+	fake.getVolumeSnapshotArgsForCall = append(fake.getVolumeSnapshotArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetVolumeSnapshotStub
+	fakeReturns := fake.getVolumeSnapshotReturns
+	fake.recordInvocation("GetVolumeSnapshot", []interface{}{arg1, arg2})
+	fake.getVolumeSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Kube) GetVolumeSnapshotCallCount() int {
+	fake.getVolumeSnapshotMutex.RLock()
+	defer fake.getVolumeSnapshotMutex.RUnlock()
+	return len(fake.getVolumeSnapshotArgsForCall)
+}
+
+func (fake *Kube) GetVolumeSnapshotCalls(stub func(string, string) (*kube.VolumeSnapshot, error)) {
+	fake.getVolumeSnapshotMutex.Lock()
+	defer fake.getVolumeSnapshotMutex.Unlock()
+	fake.GetVolumeSnapshotStub = stub
+}
+
+func (fake *Kube) GetVolumeSnapshotArgsForCall(i int) (string, string) {
+	fake.getVolumeSnapshotMutex.RLock()
+	defer fake.getVolumeSnapshotMutex.RUnlock()
+	argsForCall := fake.getVolumeSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Kube) GetVolumeSnapshotReturns(result1 *kube.VolumeSnapshot, result2 error) {
+	fake.getVolumeSnapshotMutex.Lock()
+	defer fake.getVolumeSnapshotMutex.Unlock()
+	fake.GetVolumeSnapshotStub = nil
+	fake.getVolumeSnapshotReturns = struct {
+		result1 *kube.VolumeSnapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) GetVolumeSnapshotReturnsOnCall(i int, result1 *kube.VolumeSnapshot, result2 error) {
+	fake.getVolumeSnapshotMutex.Lock()
+	defer fake.getVolumeSnapshotMutex.Unlock()
+	fake.GetVolumeSnapshotStub = nil
+	if fake.getVolumeSnapshotReturnsOnCall == nil {
+		fake.getVolumeSnapshotReturnsOnCall = make(map[int]struct {
+			result1 *kube.VolumeSnapshot
+			result2 error
+		})
+	}
+	fake.getVolumeSnapshotReturnsOnCall[i] = struct {
+		result1 *kube.VolumeSnapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) ListVolumeSnapshots(arg1 string, arg2 string) ([]kube.VolumeSnapshot, error) {
+	fake.listVolumeSnapshotsMutex.Lock()
+	ret, specificReturn := fake.listVolumeSnapshotsReturnsOnCall[len(fake.listVolumeSnapshotsArgsForCall)]
+	fake.listVolumeSnapshotsArgsForCall = append(fake.listVolumeSnapshotsArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ListVolumeSnapshotsStub
+	fakeReturns := fake.listVolumeSnapshotsReturns
+	fake.recordInvocation("ListVolumeSnapshots", []interface{}{arg1, arg2})
+	fake.listVolumeSnapshotsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Kube) ListVolumeSnapshotsCallCount() int {
+	fake.listVolumeSnapshotsMutex.RLock()
+	defer fake.listVolumeSnapshotsMutex.RUnlock()
+	return len(fake.listVolumeSnapshotsArgsForCall)
+}
+
+func (fake *Kube) ListVolumeSnapshotsCalls(stub func(string, string) ([]kube.VolumeSnapshot, error)) {
+	fake.listVolumeSnapshotsMutex.Lock()
+	defer fake.listVolumeSnapshotsMutex.Unlock()
+	fake.ListVolumeSnapshotsStub = stub
+}
+
+func (fake *Kube) ListVolumeSnapshotsArgsForCall(i int) (string, string) {
+	fake.listVolumeSnapshotsMutex.RLock()
+	defer fake.listVolumeSnapshotsMutex.RUnlock()
+	argsForCall := fake.listVolumeSnapshotsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Kube) ListVolumeSnapshotsReturns(result1 []kube.VolumeSnapshot, result2 error) {
+	fake.listVolumeSnapshotsMutex.Lock()
+	defer fake.listVolumeSnapshotsMutex.Unlock()
+	fake.ListVolumeSnapshotsStub = nil
+	fake.listVolumeSnapshotsReturns = struct {
+		result1 []kube.VolumeSnapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) ListVolumeSnapshotsReturnsOnCall(i int, result1 []kube.VolumeSnapshot, result2 error) {
+	fake.listVolumeSnapshotsMutex.Lock()
+	defer fake.listVolumeSnapshotsMutex.Unlock()
+	fake.ListVolumeSnapshotsStub = nil
+	if fake.listVolumeSnapshotsReturnsOnCall == nil {
+		fake.listVolumeSnapshotsReturnsOnCall = make(map[int]struct {
+			result1 []kube.VolumeSnapshot
+			result2 error
+		})
+	}
+	fake.listVolumeSnapshotsReturnsOnCall[i] = struct {
+		result1 []kube.VolumeSnapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.createPVCMutex.RLock()
+	defer fake.createPVCMutex.RUnlock()
+	fake.createVolumeSnapshotMutex.RLock()
+	defer fake.createVolumeSnapshotMutex.RUnlock()
+	fake.getPVCMutex.RLock()
+	defer fake.getPVCMutex.RUnlock()
+	fake.getPodsByLabelMutex.RLock()
+	defer fake.getPodsByLabelMutex.RUnlock()
+	fake.getVolumeSnapshotMutex.RLock()
+	defer fake.getVolumeSnapshotMutex.RUnlock()
+	fake.listVolumeSnapshotsMutex.RLock()
+	defer fake.listVolumeSnapshotsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *Kube) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ snapshot.Kube = new(Kube)
@@ -0,0 +1,542 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package snapshot takes VolumeSnapshots of the ledger volumes of the peers
+// and orderers, and restores them to new volumes.
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/kube"
+)
+
+// Labels of the volume snapshots
+const (
+	LabelComponent     = "fabric-deployer/snapshot-component"
+	LabelComponentType = "fabric-deployer/snapshot-component-type"
+	LabelSnapshot      = "fabric-deployer/snapshot"
+	LabelVolume        = "fabric-deployer/snapshot-volume"
+)
+
+func tracer() trace.Tracer {
+	return otel.Tracer("github.com/IBM-Blockchain/fabric-deployer/deployer/snapshot")
+}
+
+// DefaultPollInterval is how often the pods and volume snapshots are checked
+// while waiting for them
+const DefaultPollInterval = 2 * time.Second
+
+// Volume is a volume of a component, Name is the name of the volume in the
+// storage spec, e.g. statedb
+type Volume struct {
+	Name string
+	PVC  string
+}
+
+// CreateRequest is the body of the requests to take a snapshot, the name is
+// generated if empty and the default volume snapshot class of the cluster is
+// used if VolumeSnapshotClass is empty
+type CreateRequest struct {
+	Name                string `json:"name,omitempty"`
+	VolumeSnapshotClass string `json:"volumeSnapshotClass,omitempty"`
+}
+
+// RestoreRequest is the body of the requests to restore a snapshot
+type RestoreRequest struct {
+	Snapshot string `json:"snapshot"`
+}
+
+// Snapshot is a snapshot of the volumes of a component
+type Snapshot struct {
+	Name          string           `json:"name"`
+	Component     string           `json:"component"`
+	ComponentType string           `json:"componentType"`
+	CreatedAt     *time.Time       `json:"createdAt,omitempty"`
+	ReadyToUse    bool             `json:"readyToUse"`
+	Volumes       []VolumeSnapshot `json:"volumes"`
+}
+
+// VolumeSnapshot is the snapshot of a volume
+type VolumeSnapshot struct {
+	Volume         string `json:"volume"`
+	PVC            string `json:"pvc"`
+	VolumeSnapshot string `json:"volumeSnapshot"`
+	ReadyToUse     bool   `json:"readyToUse"`
+	RestoreSize    string `json:"restoreSize,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// RestoreResponse lists the volumes restored from the snapshot, by volume name
+type RestoreResponse struct {
+	Snapshot string            `json:"snapshot"`
+	PVCs     map[string]string `json:"pvcs"`
+}
+
+//go:generate counterfeiter -o mocks/kube.go -fake-name Kube . Kube
+
+type Kube interface {
+	GetPVC(namespace, name string) (*corev1.PersistentVolumeClaim, error)
+	CreatePVC(namespace string, pvc *corev1.PersistentVolumeClaim) (*corev1.PersistentVolumeClaim, error)
+	CreateVolumeSnapshot(namespace string, snapshot *kube.VolumeSnapshot) (*kube.VolumeSnapshot, error)
+	GetVolumeSnapshot(namespace, name string) (*kube.VolumeSnapshot, error)
+	ListVolumeSnapshots(namespace, labelSelector string) ([]kube.VolumeSnapshot, error)
+	GetPodsByLabel(namespace, name string) (*corev1.Pod, error)
+}
+
+//go:generate counterfeiter -o mocks/component.go -fake-name Component . Component
+
+// Component is a component type with ledger volumes, the peers and orderers
+type Component interface {
+	// SnapshotVolumes returns the volumes of the component
+	SnapshotVolumes(compName, namespace string) ([]Volume, error)
+	// ScaleReplicas sets the replicas of the component and returns the
+	// previous replicas
+	ScaleReplicas(compName, namespace string, replicas *int32) (*int32, error)
+	// RebindVolumes sets the PVCs of the volumes of the component, by volume
+	// name
+	RebindVolumes(compName, namespace string, pvcs map[string]string) error
+}
+
+type Snapshotter struct {
+	Kube   Kube
+	Logger *zap.SugaredLogger
+	// Timeout of the waits for the pods to stop and the snapshots to be
+	// taken
+	Timeout      time.Duration
+	PollInterval time.Duration
+}
+
+func New(logger *zap.Logger, kube Kube, timeout time.Duration) *Snapshotter {
+	return &Snapshotter{
+		Kube:         kube,
+		Logger:       logger.Sugar().Named("Snapshot"),
+		Timeout:      timeout,
+		PollInterval: DefaultPollInterval,
+	}
+}
+
+// Create takes a snapshot of the volumes of the component. The component is
+// scaled to 0 replicas until the snapshots are taken, so that the ledgers
+// are consistent, then scaled back to its previous replicas.
+func (s *Snapshotter) Create(component Component, componentType, compName, namespace string, request *CreateRequest) (snapshot *Snapshot, err error) {
+	name := request.Name
+	if name == "" {
+		name = fmt.Sprintf("%s-%s", compName, time.Now().UTC().Format("20060102150405"))
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return nil, apierror.InvalidField("name", "snapshot name '%s' is not valid: %s", name, strings.Join(errs, ", "))
+	}
+
+	existing, err := s.List(componentType, compName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range existing {
+		if snapshot.Name == name {
+			return nil, apierror.New(apierror.Conflict, apierror.CodeAlreadyExists, "snapshot '%s' already exists", name)
+		}
+	}
+
+	volumes, err := s.existingVolumes(component, compName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Infof("Scaling down '%s' to take snapshot '%s'", compName, name)
+	previous, err := component.ScaleReplicas(compName, namespace, int32Ptr(0))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to scale down '%s'", compName)
+	}
+	defer func() {
+		_, scaleErr := component.ScaleReplicas(compName, namespace, scaleUpReplicas(previous))
+		if scaleErr != nil && err == nil {
+			err = errors.Wrapf(scaleErr, "failed to scale up '%s'", compName)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(s.baseContext(), s.Timeout)
+	defer cancel()
+	err = s.waitForPodsToStop(ctx, compName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := map[string]string{
+		LabelComponent:     compName,
+		LabelComponentType: componentType,
+		LabelSnapshot:      name,
+	}
+	var class *string
+	if request.VolumeSnapshotClass != "" {
+		class = &request.VolumeSnapshotClass
+	}
+	for _, volume := range volumes {
+		volumeLabels := map[string]string{LabelVolume: volume.Name}
+		for k, v := range labels {
+			volumeLabels[k] = v
+		}
+		pvc := volume.PVC
+		_, err = s.Kube.CreateVolumeSnapshot(namespace, &kube.VolumeSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("%s-%s", name, volume.Name),
+				Labels: volumeLabels,
+			},
+			Spec: kube.VolumeSnapshotSpec{
+				Source:                  kube.VolumeSnapshotSource{PersistentVolumeClaimName: &pvc},
+				VolumeSnapshotClassName: class,
+			},
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create volume snapshot of '%s'", volume.PVC)
+		}
+	}
+
+	// the volumes can be used once the snapshots are cut, they do not need
+	// to be ready to use
+	for _, volume := range volumes {
+		err = s.waitForSnapshotToBeCut(ctx, namespace, fmt.Sprintf("%s-%s", name, volume.Name))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	snapshot, err = s.Get(componentType, compName, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// List returns the snapshots of the component, the latest first
+func (s *Snapshotter) List(componentType, compName, namespace string) ([]*Snapshot, error) {
+	selector := fmt.Sprintf("%s=%s,%s=%s", LabelComponent, compName, LabelComponentType, componentType)
+	volumeSnapshots, err := s.Kube.ListVolumeSnapshots(namespace, selector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the volume snapshots of '%s'", compName)
+	}
+
+	snapshots := map[string]*Snapshot{}
+	for _, vs := range volumeSnapshots {
+		name := vs.Labels[LabelSnapshot]
+		snapshot, found := snapshots[name]
+		if !found {
+			snapshot = &Snapshot{
+				Name:          name,
+				Component:     compName,
+				ComponentType: componentType,
+				ReadyToUse:    true,
+			}
+			snapshots[name] = snapshot
+		}
+
+		volume := VolumeSnapshot{
+			Volume:         vs.Labels[LabelVolume],
+			VolumeSnapshot: vs.Name,
+		}
+		if vs.Spec.Source.PersistentVolumeClaimName != nil {
+			volume.PVC = *vs.Spec.Source.PersistentVolumeClaimName
+		}
+		if status := vs.Status; status != nil {
+			volume.ReadyToUse = status.ReadyToUse != nil && *status.ReadyToUse
+			if status.RestoreSize != nil {
+				volume.RestoreSize = status.RestoreSize.String()
+			}
+			if status.Error != nil && status.Error.Message != nil {
+				volume.Error = *status.Error.Message
+			}
+			if status.CreationTime != nil && (snapshot.CreatedAt == nil || status.CreationTime.Time.Before(*snapshot.CreatedAt)) {
+				createdAt := status.CreationTime.Time
+				snapshot.CreatedAt = &createdAt
+			}
+		}
+		snapshot.ReadyToUse = snapshot.ReadyToUse && volume.ReadyToUse
+		snapshot.Volumes = append(snapshot.Volumes, volume)
+	}
+
+	list := []*Snapshot{}
+	for _, snapshot := range snapshots {
+		sort.Slice(snapshot.Volumes, func(i, j int) bool {
+			return snapshot.Volumes[i].Volume < snapshot.Volumes[j].Volume
+		})
+		list = append(list, snapshot)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt == nil || list[j].CreatedAt == nil {
+			return list[i].CreatedAt != nil
+		}
+		return list[i].CreatedAt.After(*list[j].CreatedAt)
+	})
+	return list, nil
+}
+
+// Get returns the snapshot of the component
+func (s *Snapshotter) Get(componentType, compName, namespace, name string) (*Snapshot, error) {
+	snapshots, err := s.List(componentType, compName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Name == name {
+			return snapshot, nil
+		}
+	}
+	return nil, apierror.New(apierror.NotFound, apierror.CodeNotFound, "snapshot '%s' of '%s' not found", name, compName)
+}
+
+// Restore creates new PVCs from the volume snapshots of the snapshot and
+// binds them to the component. The component is scaled to 0 replicas while
+// its volumes are replaced, then scaled back to its previous replicas. The
+// previous PVCs are not deleted.
+func (s *Snapshotter) Restore(component Component, componentType, compName, namespace string, request *RestoreRequest) (response *RestoreResponse, err error) {
+	if request.Snapshot == "" {
+		return nil, apierror.InvalidField("snapshot", "snapshot is required")
+	}
+	snapshot, err := s.Get(componentType, compName, namespace, request.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if !snapshot.ReadyToUse {
+		return nil, apierror.New(apierror.Conflict, apierror.CodeConflict, "snapshot '%s' is not ready to use", snapshot.Name)
+	}
+
+	volumes, err := component.SnapshotVolumes(compName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	current := map[string]string{}
+	for _, volume := range volumes {
+		current[volume.Name] = volume.PVC
+	}
+
+	s.Logger.Infof("Scaling down '%s' to restore snapshot '%s'", compName, snapshot.Name)
+	previous, err := component.ScaleReplicas(compName, namespace, int32Ptr(0))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to scale down '%s'", compName)
+	}
+	defer func() {
+		_, scaleErr := component.ScaleReplicas(compName, namespace, scaleUpReplicas(previous))
+		if scaleErr != nil && err == nil {
+			err = errors.Wrapf(scaleErr, "failed to scale up '%s'", compName)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(s.baseContext(), s.Timeout)
+	defer cancel()
+	err = s.waitForPodsToStop(ctx, compName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	response = &RestoreResponse{Snapshot: snapshot.Name, PVCs: map[string]string{}}
+	suffix := time.Now().UTC().Format("20060102150405")
+	for _, volume := range snapshot.Volumes {
+		pvc, err := s.restorePVC(namespace, current[volume.Volume], volume, suffix)
+		if err != nil {
+			return nil, err
+		}
+		response.PVCs[volume.Volume] = pvc
+	}
+
+	err = component.RebindVolumes(compName, namespace, response.PVCs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to bind the restored volumes to '%s'", compName)
+	}
+
+	return response, nil
+}
+
+// restorePVC creates a PVC from the volume snapshot, with the storage class
+// and access modes of the current PVC of the volume
+func (s *Snapshotter) restorePVC(namespace, currentPVC string, volume VolumeSnapshot, suffix string) (string, error) {
+	source := currentPVC
+	if source == "" {
+		source = volume.PVC
+	}
+	original, err := s.Kube.GetPVC(namespace, source)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get pvc '%s'", source)
+	}
+
+	size := original.Spec.Resources.Requests[corev1.ResourceStorage]
+	if volume.RestoreSize != "" {
+		restoreSize, err := resource.ParseQuantity(volume.RestoreSize)
+		if err == nil && restoreSize.Cmp(size) > 0 {
+			size = restoreSize
+		}
+	}
+
+	apiGroup := kube.VolumeSnapshotGroup
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   fmt.Sprintf("%s-%s", trimSuffix(volume.PVC, suffix), suffix),
+			Labels: original.Labels,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      original.Spec.AccessModes,
+			StorageClassName: original.Spec.StorageClassName,
+			VolumeMode:       original.Spec.VolumeMode,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: &apiGroup,
+				Kind:     "VolumeSnapshot",
+				Name:     volume.VolumeSnapshot,
+			},
+		},
+	}
+
+	created, err := s.Kube.CreatePVC(namespace, pvc)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create pvc from volume snapshot '%s'", volume.VolumeSnapshot)
+	}
+	return created.Name, nil
+}
+
+// existingVolumes returns the volumes of the component that have a PVC,
+// e.g. peers using leveldb have no statedb PVC
+func (s *Snapshotter) existingVolumes(component Component, compName, namespace string) ([]Volume, error) {
+	volumes, err := component.SnapshotVolumes(compName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := []Volume{}
+	for _, volume := range volumes {
+		_, err := s.Kube.GetPVC(namespace, volume.PVC)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to get pvc '%s'", volume.PVC)
+		}
+		existing = append(existing, volume)
+	}
+	if len(existing) == 0 {
+		return nil, apierror.New(apierror.NotFound, apierror.CodeNotFound, "no volumes found for '%s'", compName)
+	}
+	return existing, nil
+}
+
+func (s *Snapshotter) waitForPodsToStop(ctx context.Context, compName, namespace string) error {
+	return s.poll(ctx, fmt.Sprintf("pods of '%s' to stop", compName), func() (bool, error) {
+		_, err := s.Kube.GetPodsByLabel(namespace, compName)
+		if err != nil {
+			if apierror.KindOf(err) == apierror.NotFound || k8serrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		}
+		return false, nil
+	})
+}
+
+func (s *Snapshotter) waitForSnapshotToBeCut(ctx context.Context, namespace, name string) error {
+	return s.poll(ctx, fmt.Sprintf("volume snapshot '%s'", name), func() (bool, error) {
+		vs, err := s.Kube.GetVolumeSnapshot(namespace, name)
+		if err != nil {
+			return false, err
+		}
+		if vs.Status == nil {
+			return false, nil
+		}
+		if vs.Status.Error != nil && vs.Status.Error.Message != nil {
+			return false, errors.Errorf("volume snapshot '%s' failed: %s", name, *vs.Status.Error.Message)
+		}
+		return vs.Status.CreationTime != nil, nil
+	})
+}
+
+func (s *Snapshotter) poll(ctx context.Context, what string, done func() (bool, error)) (err error) {
+	_, span := tracer().Start(ctx, "snapshot.poll", trace.WithAttributes(attribute.String("deployer.wait", what)))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	for {
+		ok, err := done()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return apierror.New(apierror.UpstreamTimeout, apierror.CodeTimeout, "timed out waiting for %s", what)
+		case <-time.After(s.PollInterval):
+		}
+	}
+}
+
+// baseContext returns the context the client is bound to, so that the waits
+// are traced as part of the request
+func (s *Snapshotter) baseContext() context.Context {
+	if k, ok := s.Kube.(*kube.Kube); ok {
+		return k.Context()
+	}
+	return context.Background()
+}
+
+// trimSuffix removes the timestamp suffix of a PVC restored before, so that
+// the names do not grow with each restore
+func trimSuffix(pvc, suffix string) string {
+	if i := strings.LastIndex(pvc, "-"); i > 0 && len(pvc)-i-1 == len(suffix) {
+		if _, err := time.Parse("20060102150405", pvc[i+1:]); err == nil {
+			return pvc[:i]
+		}
+	}
+	return pvc
+}
+
+// scaleUpReplicas returns the replicas to scale back to, the previous replicas
+// or one for components without replicas. Components that were scaled down
+// stay scaled down.
+func scaleUpReplicas(previous *int32) *int32 {
+	if previous == nil {
+		return int32Ptr(1)
+	}
+	return previous
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
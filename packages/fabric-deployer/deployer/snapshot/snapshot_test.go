@@ -0,0 +1,222 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package snapshot_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/kube"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/snapshot"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/snapshot/mocks"
+)
+
+var _ = Describe("Snapshot", func() {
+	var (
+		snapshotter   *snapshot.Snapshotter
+		mockKube      *mocks.Kube
+		mockComponent *mocks.Component
+		created       []*kube.VolumeSnapshot
+		replicas      []int32
+	)
+
+	BeforeEach(func() {
+		mockKube = &mocks.Kube{}
+		mockComponent = &mocks.Component{}
+		snapshotter = snapshot.New(zap.NewNop(), mockKube, time.Second)
+		snapshotter.PollInterval = time.Millisecond
+		created = nil
+		replicas = nil
+
+		mockComponent.SnapshotVolumesReturns([]snapshot.Volume{
+			{Name: "peer", PVC: "peer1-pvc"},
+			{Name: "statedb", PVC: "peer1-statedb-pvc"},
+		}, nil)
+		mockComponent.ScaleReplicasStub = func(compName, namespace string, r *int32) (*int32, error) {
+			replicas = append(replicas, *r)
+			one := int32(1)
+			return &one, nil
+		}
+		mockKube.GetPVCStub = func(namespace, name string) (*corev1.PersistentVolumeClaim, error) {
+			if name == "peer1-statedb-pvc" {
+				return nil, k8serrors.NewNotFound(schema.GroupResource{}, name)
+			}
+			class := "fast"
+			return &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					StorageClassName: &class,
+					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("50Gi")},
+					},
+				},
+			}, nil
+		}
+		mockKube.GetPodsByLabelReturns(nil, apierror.New(apierror.NotFound, apierror.CodeNotFound, "no pods"))
+		mockKube.CreateVolumeSnapshotStub = func(namespace string, vs *kube.VolumeSnapshot) (*kube.VolumeSnapshot, error) {
+			created = append(created, vs)
+			return vs, nil
+		}
+		mockKube.GetVolumeSnapshotStub = func(namespace, name string) (*kube.VolumeSnapshot, error) {
+			return volumeSnapshot(name, "snap1", "peer", "peer1-pvc", true), nil
+		}
+		mockKube.ListVolumeSnapshotsStub = func(namespace, selector string) ([]kube.VolumeSnapshot, error) {
+			var list []kube.VolumeSnapshot
+			for _, vs := range created {
+				list = append(list, *volumeSnapshot(vs.Name, vs.Labels[snapshot.LabelSnapshot], vs.Labels[snapshot.LabelVolume], *vs.Spec.Source.PersistentVolumeClaimName, true))
+			}
+			return list, nil
+		}
+		mockKube.CreatePVCStub = func(namespace string, pvc *corev1.PersistentVolumeClaim) (*corev1.PersistentVolumeClaim, error) {
+			return pvc, nil
+		}
+	})
+
+	Context("create", func() {
+		It("snapshots the existing volumes with the component scaled down", func() {
+			s, err := snapshotter.Create(mockComponent, "peer", "peer1", "ns1", &snapshot.CreateRequest{Name: "snap1", VolumeSnapshotClass: "csi"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(replicas).To(Equal([]int32{0, 1}))
+
+			Expect(created).To(HaveLen(1))
+			Expect(created[0].Name).To(Equal("snap1-peer"))
+			Expect(*created[0].Spec.Source.PersistentVolumeClaimName).To(Equal("peer1-pvc"))
+			Expect(*created[0].Spec.VolumeSnapshotClassName).To(Equal("csi"))
+			Expect(created[0].Labels).To(HaveKeyWithValue(snapshot.LabelComponent, "peer1"))
+			Expect(created[0].Labels).To(HaveKeyWithValue(snapshot.LabelComponentType, "peer"))
+
+			Expect(s.Name).To(Equal("snap1"))
+			Expect(s.ReadyToUse).To(BeTrue())
+			Expect(s.Volumes).To(HaveLen(1))
+		})
+
+		It("keeps components that were scaled down scaled down", func() {
+			mockComponent.ScaleReplicasStub = func(compName, namespace string, r *int32) (*int32, error) {
+				replicas = append(replicas, *r)
+				zero := int32(0)
+				return &zero, nil
+			}
+			_, err := snapshotter.Create(mockComponent, "peer", "peer1", "ns1", &snapshot.CreateRequest{Name: "snap1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(replicas).To(Equal([]int32{0, 0}))
+		})
+
+		It("generates a name if none is given", func() {
+			s, err := snapshotter.Create(mockComponent, "peer", "peer1", "ns1", &snapshot.CreateRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Name).To(HavePrefix("peer1-"))
+		})
+
+		It("rejects invalid names", func() {
+			_, err := snapshotter.Create(mockComponent, "peer", "peer1", "ns1", &snapshot.CreateRequest{Name: "Snap_1"})
+			Expect(apierror.KindOf(err)).To(Equal(apierror.Validation))
+			Expect(mockComponent.ScaleReplicasCallCount()).To(Equal(0))
+		})
+
+		It("rejects names already used", func() {
+			created = []*kube.VolumeSnapshot{volumeSnapshot("snap1-peer", "snap1", "peer", "peer1-pvc", true)}
+			_, err := snapshotter.Create(mockComponent, "peer", "peer1", "ns1", &snapshot.CreateRequest{Name: "snap1"})
+			Expect(apierror.KindOf(err)).To(Equal(apierror.Conflict))
+		})
+
+		It("scales the component back up if the snapshot fails", func() {
+			mockKube.CreateVolumeSnapshotReturns(nil, errors.New("no snapshot class"))
+			_, err := snapshotter.Create(mockComponent, "peer", "peer1", "ns1", &snapshot.CreateRequest{Name: "snap1"})
+			Expect(err).To(MatchError(ContainSubstring("no snapshot class")))
+			Expect(replicas).To(Equal([]int32{0, 1}))
+		})
+
+		It("times out if the pods do not stop", func() {
+			snapshotter.Timeout = 10 * time.Millisecond
+			mockKube.GetPodsByLabelReturns(&corev1.Pod{}, nil)
+			_, err := snapshotter.Create(mockComponent, "peer", "peer1", "ns1", &snapshot.CreateRequest{Name: "snap1"})
+			Expect(apierror.KindOf(err)).To(Equal(apierror.UpstreamTimeout))
+			Expect(created).To(BeEmpty())
+			Expect(replicas).To(Equal([]int32{0, 1}))
+		})
+	})
+
+	Context("restore", func() {
+		BeforeEach(func() {
+			created = []*kube.VolumeSnapshot{volumeSnapshot("snap1-peer", "snap1", "peer", "peer1-pvc", true)}
+		})
+
+		It("binds new volumes restored from the snapshot", func() {
+			resp, err := snapshotter.Restore(mockComponent, "peer", "peer1", "ns1", &snapshot.RestoreRequest{Snapshot: "snap1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(replicas).To(Equal([]int32{0, 1}))
+
+			Expect(mockKube.CreatePVCCallCount()).To(Equal(1))
+			_, pvc := mockKube.CreatePVCArgsForCall(0)
+			Expect(pvc.Name).To(HavePrefix("peer1-pvc-"))
+			Expect(*pvc.Spec.StorageClassName).To(Equal("fast"))
+			Expect(pvc.Spec.DataSource.Kind).To(Equal("VolumeSnapshot"))
+			Expect(pvc.Spec.DataSource.Name).To(Equal("snap1-peer"))
+			Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("50Gi"))
+
+			Expect(mockComponent.RebindVolumesCallCount()).To(Equal(1))
+			_, _, pvcs := mockComponent.RebindVolumesArgsForCall(0)
+			Expect(pvcs).To(Equal(map[string]string{"peer": pvc.Name}))
+			Expect(resp.PVCs).To(Equal(pvcs))
+		})
+
+		It("returns not found for unknown snapshots", func() {
+			_, err := snapshotter.Restore(mockComponent, "peer", "peer1", "ns1", &snapshot.RestoreRequest{Snapshot: "snap2"})
+			Expect(apierror.KindOf(err)).To(Equal(apierror.NotFound))
+		})
+
+		It("rejects snapshots that are not ready to use", func() {
+			mockKube.ListVolumeSnapshotsReturns([]kube.VolumeSnapshot{*volumeSnapshot("snap1-peer", "snap1", "peer", "peer1-pvc", false)}, nil)
+			_, err := snapshotter.Restore(mockComponent, "peer", "peer1", "ns1", &snapshot.RestoreRequest{Snapshot: "snap1"})
+			Expect(apierror.KindOf(err)).To(Equal(apierror.Conflict))
+			Expect(mockComponent.ScaleReplicasCallCount()).To(Equal(0))
+		})
+	})
+})
+
+func volumeSnapshot(name, snapshotName, volume, pvc string, ready bool) *kube.VolumeSnapshot {
+	now := metav1.Now()
+	return &kube.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				snapshot.LabelSnapshot: snapshotName,
+				snapshot.LabelVolume:   volume,
+			},
+		},
+		Spec: kube.VolumeSnapshotSpec{
+			Source: kube.VolumeSnapshotSource{PersistentVolumeClaimName: &pvc},
+		},
+		Status: &kube.VolumeSnapshotStatus{
+			CreationTime: &now,
+			ReadyToUse:   &ready,
+		},
+	}
+}
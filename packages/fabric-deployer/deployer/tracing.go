@@ -0,0 +1,109 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/ibpoperator"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/kube"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer is looked up on every use, so that the spans go to the tracer
+// provider in use
+func tracer() trace.Tracer {
+	return otel.Tracer("github.com/IBM-Blockchain/fabric-deployer/deployer")
+}
+
+// TracingMiddleware starts the server span of the request, as a child of the
+// W3C trace context of the request if any
+func (d *Deployer) TracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if d.Tracing == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer().Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+			),
+		)
+		defer span.End()
+		if id := RequestIDFrom(r.Context()); id != "" {
+			span.SetAttributes(attribute.String("deployer.request_id", id))
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+		if recorder.statusCode == 0 {
+			recorder.statusCode = http.StatusOK
+		}
+
+		route := routeOf(r)
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(
+			attribute.String("http.route", route),
+			attribute.Int("http.response.status_code", recorder.statusCode),
+		)
+		if recorder.statusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.statusCode))
+		}
+	})
+}
+
+// spanContext returns the context the clients are bound to for the request,
+// nil if tracing is disabled. It carries the span of the request but is not
+// canceled with the request, the work started by the request, e.g. an
+// upgrade, goes on once the response is sent.
+func (d *Deployer) spanContext(r *http.Request) context.Context {
+	if d.Tracing == nil || r == nil {
+		return nil
+	}
+	span := trace.SpanFromContext(r.Context())
+	if !span.SpanContext().IsValid() {
+		return nil
+	}
+	return trace.ContextWithSpan(context.Background(), span)
+}
+
+// withContext returns the client bound to ctx, clients other than the
+// Kubernetes and operator clients, e.g. fakes, are returned as is
+func withContext[T any](client T, ctx context.Context) T {
+	switch c := any(client).(type) {
+	case *kube.Kube:
+		if c != nil {
+			return any(c.WithContext(ctx)).(T)
+		}
+	case *ibpoperator.Client:
+		if c != nil {
+			return any(c.WithContext(ctx)).(T)
+		}
+	}
+	return client
+}
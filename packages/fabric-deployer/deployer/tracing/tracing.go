@@ -0,0 +1,205 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/kube"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+)
+
+// ServiceName is the name the deployer reports its spans under
+const ServiceName = "fabric-deployer"
+
+// tracer is looked up on every use, the tracers of the global provider only
+// delegate to the first provider installed
+func tracer() trace.Tracer {
+	return otel.Tracer("github.com/IBM-Blockchain/fabric-deployer/deployer/tracing")
+}
+
+// Tracing exports the OpenTelemetry spans of the deployer, it is installed as
+// the global tracer provider so that the spans of all packages are exported
+type Tracing struct {
+	Logger *zap.SugaredLogger
+
+	provider *sdktrace.TracerProvider
+	// file is closed on shutdown when spans are exported to a file
+	file io.Closer
+}
+
+// New returns the tracing of the configured exporter, nil is returned when
+// tracing is disabled
+func New(logger *zap.Logger, cfg *config.Tracing) (*Tracing, error) {
+	if cfg == nil || cfg.Exporter == "" {
+		return nil, nil
+	}
+
+	ratio := 1.0
+	if cfg.SampleRatio != nil {
+		ratio = *cfg.SampleRatio
+	}
+
+	var (
+		exporter sdktrace.SpanExporter
+		file     io.Closer
+		err      error
+	)
+	switch cfg.Exporter {
+	case config.TracingExporterOTLP:
+		options := []otlptracehttp.Option{}
+		if cfg.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			options = append(options, otlptracehttp.WithHeaders(cfg.Headers))
+		}
+		exporter, err = otlptracehttp.New(context.Background(), options...)
+	case config.TracingExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case config.TracingExporterFile:
+		if cfg.Path == "" {
+			return nil, errors.New("file trace exporter requires a path")
+		}
+		// #nosec G302 G304
+		f, openErr := os.OpenFile(cfg.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if openErr != nil {
+			return nil, errors.Wrapf(openErr, "failed to open trace file '%s'", cfg.Path)
+		}
+		file = f
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		return nil, errors.Errorf("trace exporter '%s' not supported", cfg.Exporter)
+	}
+	if err != nil {
+		if file != nil {
+			_ = file.Close()
+		}
+		return nil, errors.Wrapf(err, "failed to create '%s' trace exporter", cfg.Exporter)
+	}
+
+	t := NewForExporter(logger, sdktrace.NewBatchSpanProcessor(exporter), ratio)
+	t.file = file
+	return t, nil
+}
+
+// NewForExporter returns the tracing exporting the spans through the span
+// processor, ratio is the ratio of the traces started by the deployer that
+// are sampled
+func NewForExporter(logger *zap.Logger, processor sdktrace.SpanProcessor, ratio float64) *Tracing {
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return &Tracing{
+		Logger:   logger.Sugar().Named("Tracing"),
+		provider: provider,
+	}
+}
+
+// Shutdown exports the pending spans and stops the exporter
+func (t *Tracing) Shutdown(ctx context.Context) {
+	if err := t.provider.Shutdown(ctx); err != nil {
+		t.Logger.Warnf("Failed to shut down tracing: %s", err)
+	}
+	if t.file != nil {
+		_ = t.file.Close()
+	}
+}
+
+// InstrumentConfig returns a copy of the config whose clients record a span
+// for each of their calls to the Kubernetes API of the cluster, as a child of
+// the span of the context of the call. The trace context is propagated to the
+// API server. client names the client using the config, e.g. kube or
+// ibpoperator.
+func (t *Tracing) InstrumentConfig(cfg *rest.Config, cluster, client string) *rest.Config {
+	cfg = rest.CopyConfig(cfg)
+	cfg.WrapTransport = transport.Wrappers(cfg.WrapTransport, func(rt http.RoundTripper) http.RoundTripper {
+		return &tracedTransport{next: rt, cluster: cluster, client: client}
+	})
+	return cfg
+}
+
+type tracedTransport struct {
+	next    http.RoundTripper
+	cluster string
+	client  string
+}
+
+func (t *tracedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	verb := kube.VerbOf(req)
+	// watches last as long as the informers, they are not part of a request
+	if verb == "WATCH" {
+		return t.next.RoundTrip(req)
+	}
+
+	resource := kube.ResourceOf(req.URL.Path)
+	ctx, span := tracer().Start(req.Context(), verb+" "+resource,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("deployer.cluster", t.cluster),
+			attribute.String("deployer.client", t.client),
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.path", req.URL.Path),
+			attribute.String("k8s.resource", resource),
+		),
+	)
+	defer span.End()
+
+	// the request must not be modified by a round tripper
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"k8s.io/client-go/rest"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/kube"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/tracing"
+)
+
+var _ = Describe("Tracing", func() {
+	Context("new", func() {
+		It("is disabled without an exporter", func() {
+			t, err := tracing.New(zap.NewNop(), &config.Tracing{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(t).To(BeNil())
+		})
+
+		It("returns an error for an unknown exporter", func() {
+			_, err := tracing.New(zap.NewNop(), &config.Tracing{Exporter: "jaeger"})
+			Expect(err).To(MatchError("trace exporter 'jaeger' not supported"))
+		})
+
+		It("returns an error for the file exporter without a path", func() {
+			_, err := tracing.New(zap.NewNop(), &config.Tracing{Exporter: config.TracingExporterFile})
+			Expect(err).To(MatchError("file trace exporter requires a path"))
+		})
+
+		It("exports the spans to the file", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "traces.json")
+			t, err := tracing.New(zap.NewNop(), &config.Tracing{Exporter: config.TracingExporterFile, Path: path})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, span := otel.Tracer("test").Start(context.Background(), "test span")
+			span.End()
+			t.Shutdown(context.Background())
+
+			data, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring(`"Name":"test span"`))
+			Expect(string(data)).To(ContainSubstring(tracing.ServiceName))
+		})
+	})
+
+	Context("kubernetes calls", func() {
+		var (
+			t        *tracing.Tracing
+			exporter *tracetest.InMemoryExporter
+			server   *httptest.Server
+			headers  chan http.Header
+		)
+
+		BeforeEach(func() {
+			exporter = tracetest.NewInMemoryExporter()
+			t = tracing.NewForExporter(zap.NewNop(), sdktrace.NewSimpleSpanProcessor(exporter), 1)
+
+			headers = make(chan http.Header, 1)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				headers <- r.Header.Clone()
+				w.WriteHeader(http.StatusNotFound)
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+			t.Shutdown(context.Background())
+		})
+
+		It("records the calls as children of the span of the bound context", func() {
+			client, err := kube.NewForConfig(t.InstrumentConfig(&rest.Config{Host: server.URL}, "local", "kube"))
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+			_, err = client.WithContext(ctx).GetConfigMap("ns1", "cm1")
+			Expect(err).To(HaveOccurred())
+			parent.End()
+
+			spans := exporter.GetSpans()
+			Expect(spans).To(HaveLen(2))
+			call := spans[0]
+			Expect(call.Name).To(Equal("GET configmaps"))
+			Expect(call.SpanKind).To(Equal(trace.SpanKindClient))
+			Expect(call.Parent.SpanID()).To(Equal(parent.SpanContext().SpanID()))
+
+			// the trace context is propagated to the API server
+			Expect((<-headers).Get("traceparent")).To(ContainSubstring(parent.SpanContext().TraceID().String()))
+		})
+
+		It("records the calls of unbound clients as root spans", func() {
+			client, err := kube.NewForConfig(t.InstrumentConfig(&rest.Config{Host: server.URL}, "local", "kube"))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = client.GetConfigMap("ns1", "cm1")
+			Expect(err).To(HaveOccurred())
+
+			spans := exporter.GetSpans()
+			Expect(spans).To(HaveLen(1))
+			Expect(spans[0].Parent.IsValid()).To(BeFalse())
+		})
+	})
+})
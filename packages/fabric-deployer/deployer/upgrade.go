@@ -0,0 +1,340 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deployer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/ca"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/orderer"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/peer"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/operations"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/upgrade"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+)
+
+// upgradeRun is an upgrade that is running or paused
+type upgradeRun struct {
+	op        *operations.Operation
+	namespace string
+	upgrader  *upgrade.Upgrader
+	plan      *upgrade.Response
+	cancel    context.CancelFunc
+}
+
+func (d *Deployer) UpgradeEndpoint() func(http.ResponseWriter, *http.Request) {
+	return NewEndpoint(d.Upgrade, d.LocalConfig.Logger).ServeHTTP
+}
+
+func (d *Deployer) ResumeUpgradeEndpoint() func(http.ResponseWriter, *http.Request) {
+	return NewEndpoint(d.ResumeUpgrade, d.LocalConfig.Logger).ServeHTTP
+}
+
+func (d *Deployer) AbortUpgradeEndpoint() func(http.ResponseWriter, *http.Request) {
+	return NewEndpoint(d.AbortUpgrade, d.LocalConfig.Logger).ServeHTTP
+}
+
+// Upgrade moves the components of the service instance to the versions of
+// the request. Upgrades always run as operations, their progress is the
+// progress of the operation. On dry runs the plan is returned.
+func (d *Deployer) Upgrade(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	sID := chi.URLParam(r, "serviceInstanceID")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, 0, errors.New("failed to ready request body")
+	}
+
+	request := &upgrade.Request{}
+	err = json.Unmarshal(body, request)
+	if err != nil {
+		return nil, 0, apierror.Wrap(err, apierror.Validation, apierror.CodeInvalidRequest, "failed to unmarshal upgrade request")
+	}
+
+	namespace := d.namespace(r)
+	upgrader := d.target(r).Upgrader
+	plan, err := upgrader.Plan(sID, namespace, request)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if isDryRun(r) {
+		plan.DryRun = true
+		return plan, http.StatusOK, nil
+	}
+
+	d.upgradesMutex.Lock()
+	for _, run := range d.upgrades {
+		if run.op.ServiceInstanceID == sID {
+			d.upgradesMutex.Unlock()
+			return nil, 0, apierror.New(apierror.Conflict, apierror.CodeConflict, "upgrade '%s' is not finished, resume or abort it first", run.op.ID)
+		}
+	}
+	run := &upgradeRun{
+		op:        d.Operations.Start(sID, operations.UPGRADE, "instance", ""),
+		namespace: namespace,
+		upgrader:  upgrader,
+		plan:      plan,
+	}
+	if d.upgrades == nil {
+		d.upgrades = map[string]*upgradeRun{}
+	}
+	d.upgrades[run.op.ID] = run
+	d.startUpgrade(r, run)
+	d.upgradesMutex.Unlock()
+
+	return d.acceptUpgrade(w, sID, run)
+}
+
+// ResumeUpgrade runs a paused upgrade again, starting with the nodes that
+// failed
+func (d *Deployer) ResumeUpgrade(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	sID := chi.URLParam(r, "serviceInstanceID")
+
+	d.upgradesMutex.Lock()
+	defer d.upgradesMutex.Unlock()
+
+	run, err := d.upgradeRun(sID, chi.URLParam(r, "operationID"))
+	if err != nil {
+		return nil, 0, err
+	}
+	if !run.op.Resume() {
+		return nil, 0, apierror.New(apierror.Conflict, apierror.CodeConflict, "upgrade '%s' is not paused", run.op.ID)
+	}
+	d.startUpgrade(r, run)
+
+	return d.acceptUpgrade(w, sID, run)
+}
+
+// AbortUpgrade stops a running upgrade once the nodes being upgraded are
+// deployed, or finishes a paused upgrade
+func (d *Deployer) AbortUpgrade(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	sID := chi.URLParam(r, "serviceInstanceID")
+
+	d.upgradesMutex.Lock()
+	defer d.upgradesMutex.Unlock()
+
+	run, err := d.upgradeRun(sID, chi.URLParam(r, "operationID"))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if run.op.Snapshot().Status == operations.StatusPaused {
+		run.op.Finish(nil, 0, apierror.New(apierror.Conflict, apierror.CodeConflict, "upgrade aborted"))
+		delete(d.upgrades, run.op.ID)
+		return run.op.Snapshot(), http.StatusOK, nil
+	}
+
+	run.cancel()
+	return d.acceptUpgrade(w, sID, run)
+}
+
+// upgradeRun returns the unfinished upgrade, must be called with the
+// upgrades mutex held
+func (d *Deployer) upgradeRun(sID, opID string) (*upgradeRun, error) {
+	run, found := d.upgrades[opID]
+	if !found || run.op.ServiceInstanceID != sID {
+		if _, found := d.Operations.Get(sID, opID); found {
+			return nil, apierror.New(apierror.Conflict, apierror.CodeConflict, "operation '%s' is not an unfinished upgrade", opID)
+		}
+		return nil, apierror.New(apierror.NotFound, apierror.CodeNotFound, "operation '%s' not found", opID)
+	}
+	return run, nil
+}
+
+// startUpgrade executes the plan in the background, as part of the trace of
+// the request if tracing is enabled. It must be called with the upgrades mutex
+// held.
+func (d *Deployer) startUpgrade(r *http.Request, run *upgradeRun) {
+	base := d.spanContext(r)
+	if base == nil {
+		base = context.Background()
+	}
+	ctx, cancel := context.WithCancel(base)
+	run.cancel = cancel
+
+	go func() {
+		defer cancel()
+		err := run.upgrader.Execute(ctx, run.op.ServiceInstanceID, run.namespace, run.plan, run.op.UpdateNode)
+
+		d.upgradesMutex.Lock()
+		defer d.upgradesMutex.Unlock()
+		if err != nil {
+			d.Logger.Errorf("Operation '%s' to upgrade failed: %s", run.op.ID, err)
+			if ctx.Err() == nil && run.plan.OnFailure == upgrade.OnFailurePause {
+				run.op.Pause(run.plan.Copy(), err)
+				return
+			}
+		}
+		run.op.Finish(run.plan.Copy(), http.StatusOK, err)
+		delete(d.upgrades, run.op.ID)
+	}()
+}
+
+func (d *Deployer) acceptUpgrade(w http.ResponseWriter, sID string, run *upgradeRun) (interface{}, int, error) {
+	w.Header().Set("Location", fmt.Sprintf("/api/v3/instance/%s/operations/%s", sID, run.op.ID))
+	return run.op.Snapshot(), http.StatusAccepted, nil
+}
+
+// upgradeTargets returns the targets of the upgrades in the cluster, they
+// look up the components on every call so that running upgrades use a
+// reloaded configuration
+func (d *Deployer) upgradeTargets(cluster string) map[string]upgrade.Target {
+	return map[string]upgrade.Target{
+		upgrade.TypeCA:      &caUpgradeTarget{d: d, cluster: cluster},
+		upgrade.TypePeer:    &peerUpgradeTarget{d: d, cluster: cluster},
+		upgrade.TypeOrderer: &ordererUpgradeTarget{d: d, cluster: cluster},
+	}
+}
+
+// versionBody returns the body of the request to update the version section
+func versionBody(version string) []byte {
+	body, _ := json.Marshal(map[string]string{"version": version})
+	return body
+}
+
+type caUpgradeTarget struct {
+	d       *Deployer
+	cluster string
+}
+
+func (t *caUpgradeTarget) Nodes(sID, namespace string) ([]upgrade.Node, error) {
+	cas, _, err := t.d.Components().For(t.cluster).CA.GetAllCR(sID, namespace)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []upgrade.Node{}
+	for _, ca := range cas {
+		nodes = append(nodes, upgrade.Node{Name: ca.Name, FromVersion: ca.Version})
+	}
+	return nodes, nil
+}
+
+func (t *caUpgradeTarget) ValidVersion(version string) error {
+	if !util.IsValidVersion("ca", version, t.d.Components().For(t.cluster).Config.Versions) {
+		return apierror.InvalidField("versions.ca", "version '%s' not valid", version)
+	}
+	return nil
+}
+
+func (t *caUpgradeTarget) CheckTransition(from, to string) error {
+	return util.CheckVersionTransition("ca", from, to, false, t.d.Components().For(t.cluster).Config.Versions)
+}
+
+func (t *caUpgradeTarget) Upgrade(sID, namespace, name, version string) error {
+	_, _, err := t.d.Components().For(t.cluster).CA.UpdateCR(ca.VERSION, name, namespace, sID, versionBody(version))
+	return err
+}
+
+func (t *caUpgradeTarget) WaitForStatus(ctx context.Context, namespace, name string, cond func(status *current.CRStatus) (bool, error)) (*current.CRStatus, error) {
+	return t.d.Components().For(t.cluster).CA.IBPOperatorClient.WaitForCRStatus(ctx, namespace, "ibpcas", name, cond)
+}
+
+type peerUpgradeTarget struct {
+	d       *Deployer
+	cluster string
+}
+
+func (t *peerUpgradeTarget) Nodes(sID, namespace string) ([]upgrade.Node, error) {
+	peers, _, err := t.d.Components().For(t.cluster).Peer.GetAllCR(sID, namespace)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []upgrade.Node{}
+	for _, peer := range peers {
+		nodes = append(nodes, upgrade.Node{Name: peer.Name, FromVersion: peer.Version})
+	}
+	return nodes, nil
+}
+
+func (t *peerUpgradeTarget) ValidVersion(version string) error {
+	if !util.IsValidVersion("peer", version, t.d.Components().For(t.cluster).Config.Versions) {
+		return apierror.InvalidField("versions.peer", "version '%s' not valid", version)
+	}
+	return nil
+}
+
+func (t *peerUpgradeTarget) CheckTransition(from, to string) error {
+	return util.CheckVersionTransition("peer", from, to, false, t.d.Components().For(t.cluster).Config.Versions)
+}
+
+func (t *peerUpgradeTarget) Upgrade(sID, namespace, name, version string) error {
+	_, _, err := t.d.Components().For(t.cluster).Peer.UpdateCR(peer.VERSION, name, namespace, sID, versionBody(version))
+	return err
+}
+
+func (t *peerUpgradeTarget) WaitForStatus(ctx context.Context, namespace, name string, cond func(status *current.CRStatus) (bool, error)) (*current.CRStatus, error) {
+	return t.d.Components().For(t.cluster).Peer.IBPOperatorClient.WaitForCRStatus(ctx, namespace, "ibppeers", name, cond)
+}
+
+// ordererUpgradeTarget upgrades the orderer nodes, the clusters that have
+// nodes are not upgraded themselves
+type ordererUpgradeTarget struct {
+	d       *Deployer
+	cluster string
+}
+
+func (t *ordererUpgradeTarget) Nodes(sID, namespace string) ([]upgrade.Node, error) {
+	orderers, _, err := t.d.Components().For(t.cluster).Orderer.GetAllCR(sID, namespace)
+	if err != nil {
+		return nil, err
+	}
+	clusters := map[string]bool{}
+	for _, orderer := range orderers {
+		if orderer.Parent != nil {
+			clusters[orderer.Parent.Name] = true
+		}
+	}
+	nodes := []upgrade.Node{}
+	for _, orderer := range orderers {
+		if !clusters[orderer.Name] {
+			nodes = append(nodes, upgrade.Node{Name: orderer.Name, FromVersion: orderer.Version})
+		}
+	}
+	return nodes, nil
+}
+
+func (t *ordererUpgradeTarget) ValidVersion(version string) error {
+	if !util.IsValidVersion("orderer", version, t.d.Components().For(t.cluster).Config.Versions) {
+		return apierror.InvalidField("versions.orderer", "version '%s' not valid", version)
+	}
+	return nil
+}
+
+func (t *ordererUpgradeTarget) CheckTransition(from, to string) error {
+	return util.CheckVersionTransition("orderer", from, to, false, t.d.Components().For(t.cluster).Config.Versions)
+}
+
+func (t *ordererUpgradeTarget) Upgrade(sID, namespace, name, version string) error {
+	_, _, err := t.d.Components().For(t.cluster).Orderer.UpdateCR(orderer.VERSION, name, namespace, sID, versionBody(version))
+	return err
+}
+
+func (t *ordererUpgradeTarget) WaitForStatus(ctx context.Context, namespace, name string, cond func(status *current.CRStatus) (bool, error)) (*current.CRStatus, error) {
+	return t.d.Components().For(t.cluster).Orderer.IBPOperatorClient.WaitForCRStatus(ctx, namespace, "ibporderers", name, cond)
+}
@@ -0,0 +1,119 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"sync"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/upgrade"
+	v1 "k8s.io/api/core/v1"
+)
+
+type Kube struct {
+	GetPodsByLabelStub        func(string, string) (*v1.Pod, error)
+	getPodsByLabelMutex       sync.RWMutex
+	getPodsByLabelArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	getPodsByLabelReturns struct {
+		result1 *v1.Pod
+		result2 error
+	}
+	getPodsByLabelReturnsOnCall map[int]struct {
+		result1 *v1.Pod
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *Kube) GetPodsByLabel(arg1 string, arg2 string) (*v1.Pod, error) {
+	fake.getPodsByLabelMutex.Lock()
+	ret, specificReturn := fake.getPodsByLabelReturnsOnCall[len(fake.getPodsByLabelArgsForCall)]
+	fake.getPodsByLabelArgsForCall = append(fake.getPodsByLabelArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetPodsByLabelStub
+	fakeReturns := fake.getPodsByLabelReturns
+	fake.recordInvocation("GetPodsByLabel", []interface{}{arg1, arg2})
+	fake.getPodsByLabelMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Kube) GetPodsByLabelCallCount() int {
+	fake.getPodsByLabelMutex.RLock()
+	defer fake.getPodsByLabelMutex.RUnlock()
+	return len(fake.getPodsByLabelArgsForCall)
+}
+
+func (fake *Kube) GetPodsByLabelCalls(stub func(string, string) (*v1.Pod, error)) {
+	fake.getPodsByLabelMutex.Lock()
+	defer fake.getPodsByLabelMutex.Unlock()
+	fake.GetPodsByLabelStub = stub
+}
+
+func (fake *Kube) GetPodsByLabelArgsForCall(i int) (string, string) {
+	fake.getPodsByLabelMutex.RLock()
+	defer fake.getPodsByLabelMutex.RUnlock()
+	argsForCall := fake.getPodsByLabelArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Kube) GetPodsByLabelReturns(result1 *v1.Pod, result2 error) {
+	fake.getPodsByLabelMutex.Lock()
+	defer fake.getPodsByLabelMutex.Unlock()
+	fake.GetPodsByLabelStub = nil
+	fake.getPodsByLabelReturns = struct {
+		result1 *v1.Pod
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) GetPodsByLabelReturnsOnCall(i int, result1 *v1.Pod, result2 error) {
+	fake.getPodsByLabelMutex.Lock()
+	defer fake.getPodsByLabelMutex.Unlock()
+	fake.GetPodsByLabelStub = nil
+	if fake.getPodsByLabelReturnsOnCall == nil {
+		fake.getPodsByLabelReturnsOnCall = make(map[int]struct {
+			result1 *v1.Pod
+			result2 error
+		})
+	}
+	fake.getPodsByLabelReturnsOnCall[i] = struct {
+		result1 *v1.Pod
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Kube) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getPodsByLabelMutex.RLock()
+	defer fake.getPodsByLabelMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *Kube) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ upgrade.Kube = new(Kube)
@@ -0,0 +1,435 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/upgrade"
+	"github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+)
+
+type Target struct {
+	CheckTransitionStub        func(string, string) error
+	checkTransitionMutex       sync.RWMutex
+	checkTransitionArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	checkTransitionReturns struct {
+		result1 error
+	}
+	checkTransitionReturnsOnCall map[int]struct {
+		result1 error
+	}
+	NodesStub        func(string, string) ([]upgrade.Node, error)
+	nodesMutex       sync.RWMutex
+	nodesArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	nodesReturns struct {
+		result1 []upgrade.Node
+		result2 error
+	}
+	nodesReturnsOnCall map[int]struct {
+		result1 []upgrade.Node
+		result2 error
+	}
+	UpgradeStub        func(string, string, string, string) error
+	upgradeMutex       sync.RWMutex
+	upgradeArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+	}
+	upgradeReturns struct {
+		result1 error
+	}
+	upgradeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ValidVersionStub        func(string) error
+	validVersionMutex       sync.RWMutex
+	validVersionArgsForCall []struct {
+		arg1 string
+	}
+	validVersionReturns struct {
+		result1 error
+	}
+	validVersionReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WaitForStatusStub        func(context.Context, string, string, func(status *v1beta1.CRStatus) (bool, error)) (*v1beta1.CRStatus, error)
+	waitForStatusMutex       sync.RWMutex
+	waitForStatusArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 func(status *v1beta1.CRStatus) (bool, error)
+	}
+	waitForStatusReturns struct {
+		result1 *v1beta1.CRStatus
+		result2 error
+	}
+	waitForStatusReturnsOnCall map[int]struct {
+		result1 *v1beta1.CRStatus
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *Target) CheckTransition(arg1 string, arg2 string) error {
+	fake.checkTransitionMutex.Lock()
+	ret, specificReturn := fake.checkTransitionReturnsOnCall[len(fake.checkTransitionArgsForCall)]
+	fake.checkTransitionArgsForCall = append(fake.checkTransitionArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.CheckTransitionStub
+	fakeReturns := fake.checkTransitionReturns
+	fake.recordInvocation("CheckTransition", []interface{}{arg1, arg2})
+	fake.checkTransitionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Target) CheckTransitionCallCount() int {
+	fake.checkTransitionMutex.RLock()
+	defer fake.checkTransitionMutex.RUnlock()
+	return len(fake.checkTransitionArgsForCall)
+}
+
+func (fake *Target) CheckTransitionCalls(stub func(string, string) error) {
+	fake.checkTransitionMutex.Lock()
+	defer fake.checkTransitionMutex.Unlock()
+	fake.CheckTransitionStub = stub
+}
+
+func (fake *Target) CheckTransitionArgsForCall(i int) (string, string) {
+	fake.checkTransitionMutex.RLock()
+	defer fake.checkTransitionMutex.RUnlock()
+	argsForCall := fake.checkTransitionArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Target) CheckTransitionReturns(result1 error) {
+	fake.checkTransitionMutex.Lock()
+	defer fake.checkTransitionMutex.Unlock()
+	fake.CheckTransitionStub = nil
+	fake.checkTransitionReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Target) CheckTransitionReturnsOnCall(i int, result1 error) {
+	fake.checkTransitionMutex.Lock()
+	defer fake.checkTransitionMutex.Unlock()
+	fake.CheckTransitionStub = nil
+	if fake.checkTransitionReturnsOnCall == nil {
+		fake.checkTransitionReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.checkTransitionReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Target) Nodes(arg1 string, arg2 string) ([]upgrade.Node, error) {
+	fake.nodesMutex.Lock()
+	ret, specificReturn := fake.nodesReturnsOnCall[len(fake.nodesArgsForCall)]
+	fake.nodesArgsForCall = append(fake.nodesArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.NodesStub
+	fakeReturns := fake.nodesReturns
+	fake.recordInvocation("Nodes", []interface{}{arg1, arg2})
+	fake.nodesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Target) NodesCallCount() int {
+	fake.nodesMutex.RLock()
+	defer fake.nodesMutex.RUnlock()
+	return len(fake.nodesArgsForCall)
+}
+
+func (fake *Target) NodesCalls(stub func(string, string) ([]upgrade.Node, error)) {
+	fake.nodesMutex.Lock()
+	defer fake.nodesMutex.Unlock()
+	fake.NodesStub = stub
+}
+
+func (fake *Target) NodesArgsForCall(i int) (string, string) {
+	fake.nodesMutex.RLock()
+	defer fake.nodesMutex.RUnlock()
+	argsForCall := fake.nodesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Target) NodesReturns(result1 []upgrade.Node, result2 error) {
+	fake.nodesMutex.Lock()
+	defer fake.nodesMutex.Unlock()
+	fake.NodesStub = nil
+	fake.nodesReturns = struct {
+		result1 []upgrade.Node
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Target) NodesReturnsOnCall(i int, result1 []upgrade.Node, result2 error) {
+	fake.nodesMutex.Lock()
+	defer fake.nodesMutex.Unlock()
+	fake.NodesStub = nil
+	if fake.nodesReturnsOnCall == nil {
+		fake.nodesReturnsOnCall = make(map[int]struct {
+			result1 []upgrade.Node
+			result2 error
+		})
+	}
+	fake.nodesReturnsOnCall[i] = struct {
+		result1 []upgrade.Node
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Target) Upgrade(arg1 string, arg2 string, arg3 string, arg4 string) error {
+	fake.upgradeMutex.Lock()
+	ret, specificReturn := fake.upgradeReturnsOnCall[len(fake.upgradeArgsForCall)]
+	fake.upgradeArgsForCall = append(fake.upgradeArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.UpgradeStub
+	fakeReturns := fake.upgradeReturns
+	fake.recordInvocation("Upgrade", []interface{}{arg1, arg2, arg3, arg4})
+	fake.upgradeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Target) UpgradeCallCount() int {
+	fake.upgradeMutex.RLock()
+	defer fake.upgradeMutex.RUnlock()
+	return len(fake.upgradeArgsForCall)
+}
+
+func (fake *Target) UpgradeCalls(stub func(string, string, string, string) error) {
+	fake.upgradeMutex.Lock()
+	defer fake.upgradeMutex.Unlock()
+	fake.UpgradeStub = stub
+}
+
+func (fake *Target) UpgradeArgsForCall(i int) (string, string, string, string) {
+	fake.upgradeMutex.RLock()
+	defer fake.upgradeMutex.RUnlock()
+	argsForCall := fake.upgradeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *Target) UpgradeReturns(result1 error) {
+	fake.upgradeMutex.Lock()
+	defer fake.upgradeMutex.Unlock()
+	fake.UpgradeStub = nil
+	fake.upgradeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Target) UpgradeReturnsOnCall(i int, result1 error) {
+	fake.upgradeMutex.Lock()
+	defer fake.upgradeMutex.Unlock()
+	fake.UpgradeStub = nil
+	if fake.upgradeReturnsOnCall == nil {
+		fake.upgradeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.upgradeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Target) ValidVersion(arg1 string) error {
+	fake.validVersionMutex.Lock()
+	ret, specificReturn := fake.validVersionReturnsOnCall[len(fake.validVersionArgsForCall)]
+	fake.validVersionArgsForCall = append(fake.validVersionArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ValidVersionStub
+	fakeReturns := fake.validVersionReturns
+	fake.recordInvocation("ValidVersion", []interface{}{arg1})
+	fake.validVersionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Target) ValidVersionCallCount() int {
+	fake.validVersionMutex.RLock()
+	defer fake.validVersionMutex.RUnlock()
+	return len(fake.validVersionArgsForCall)
+}
+
+func (fake *Target) ValidVersionCalls(stub func(string) error) {
+	fake.validVersionMutex.Lock()
+	defer fake.validVersionMutex.Unlock()
+	fake.ValidVersionStub = stub
+}
+
+func (fake *Target) ValidVersionArgsForCall(i int) string {
+	fake.validVersionMutex.RLock()
+	defer fake.validVersionMutex.RUnlock()
+	argsForCall := fake.validVersionArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Target) ValidVersionReturns(result1 error) {
+	fake.validVersionMutex.Lock()
+	defer fake.validVersionMutex.Unlock()
+	fake.ValidVersionStub = nil
+	fake.validVersionReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Target) ValidVersionReturnsOnCall(i int, result1 error) {
+	fake.validVersionMutex.Lock()
+	defer fake.validVersionMutex.Unlock()
+	fake.ValidVersionStub = nil
+	if fake.validVersionReturnsOnCall == nil {
+		fake.validVersionReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.validVersionReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Target) WaitForStatus(arg1 context.Context, arg2 string, arg3 string, arg4 func(status *v1beta1.CRStatus) (bool, error)) (*v1beta1.CRStatus, error) {
+	fake.waitForStatusMutex.Lock()
+	ret, specificReturn := fake.waitForStatusReturnsOnCall[len(fake.waitForStatusArgsForCall)]
+	fake.waitForStatusArgsForCall = append(fake.waitForStatusArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 func(status *v1beta1.CRStatus) (bool, error)
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.WaitForStatusStub
+	fakeReturns := fake.waitForStatusReturns
+	fake.recordInvocation("WaitForStatus", []interface{}{arg1, arg2, arg3, arg4})
+	fake.waitForStatusMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Target) WaitForStatusCallCount() int {
+	fake.waitForStatusMutex.RLock()
+	defer fake.waitForStatusMutex.RUnlock()
+	return len(fake.waitForStatusArgsForCall)
+}
+
+func (fake *Target) WaitForStatusCalls(stub func(context.Context, string, string, func(status *v1beta1.CRStatus) (bool, error)) (*v1beta1.CRStatus, error)) {
+	fake.waitForStatusMutex.Lock()
+	defer fake.waitForStatusMutex.Unlock()
+	fake.WaitForStatusStub = stub
+}
+
+func (fake *Target) WaitForStatusArgsForCall(i int) (context.Context, string, string, func(status *v1beta1.CRStatus) (bool, error)) {
+	fake.waitForStatusMutex.RLock()
+	defer fake.waitForStatusMutex.RUnlock()
+	argsForCall := fake.waitForStatusArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *Target) WaitForStatusReturns(result1 *v1beta1.CRStatus, result2 error) {
+	fake.waitForStatusMutex.Lock()
+	defer fake.waitForStatusMutex.Unlock()
+	fake.WaitForStatusStub = nil
+	fake.waitForStatusReturns = struct {
+		result1 *v1beta1.CRStatus
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Target) WaitForStatusReturnsOnCall(i int, result1 *v1beta1.CRStatus, result2 error) {
+	fake.waitForStatusMutex.Lock()
+	defer fake.waitForStatusMutex.Unlock()
+	fake.WaitForStatusStub = nil
+	if fake.waitForStatusReturnsOnCall == nil {
+		fake.waitForStatusReturnsOnCall = make(map[int]struct {
+			result1 *v1beta1.CRStatus
+			result2 error
+		})
+	}
+	fake.waitForStatusReturnsOnCall[i] = struct {
+		result1 *v1beta1.CRStatus
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Target) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.checkTransitionMutex.RLock()
+	defer fake.checkTransitionMutex.RUnlock()
+	fake.nodesMutex.RLock()
+	defer fake.nodesMutex.RUnlock()
+	fake.upgradeMutex.RLock()
+	defer fake.upgradeMutex.RUnlock()
+	fake.validVersionMutex.RLock()
+	defer fake.validVersionMutex.RUnlock()
+	fake.waitForStatusMutex.RLock()
+	defer fake.waitForStatusMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *Target) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ upgrade.Target = new(Target)
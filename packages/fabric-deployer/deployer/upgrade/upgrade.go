@@ -0,0 +1,436 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package upgrade moves the components of a service instance to new Fabric
+// versions, one component type at a time: the CAs, then the orderer nodes one
+// at a time, then the peers in batches.
+package upgrade
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/components/common"
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+)
+
+// Component types, in the order they are upgraded
+const (
+	TypeCA      = "ca"
+	TypeOrderer = "orderer"
+	TypePeer    = "peer"
+)
+
+var typeOrder = []string{TypeCA, TypeOrderer, TypePeer}
+
+// DefaultRecheckInterval is how often the pod of a node is checked again
+// while its CR does not change
+const DefaultRecheckInterval = 5 * time.Second
+
+func tracer() trace.Tracer {
+	return otel.Tracer("github.com/IBM-Blockchain/fabric-deployer/deployer/upgrade")
+}
+
+// What to do when a node fails to upgrade
+const (
+	OnFailureAbort = "abort"
+	OnFailurePause = "pause"
+)
+
+// Statuses of the nodes
+const (
+	StatusPending   = "pending"
+	StatusUpgrading = "upgrading"
+	StatusUpgraded  = "upgraded"
+	StatusFailed    = "failed"
+	// StatusSkipped nodes already run the target version
+	StatusSkipped = "skipped"
+)
+
+// Request is the target version of each component type, the component types
+// without a version are not upgraded
+type Request struct {
+	Versions Versions `json:"versions"`
+	// PeerBatchSize is the number of peers upgraded at the same time,
+	// defaults to 1
+	PeerBatchSize int `json:"peerBatchSize,omitempty"`
+	// OnFailure is abort, the default, or pause. Paused upgrades can be
+	// resumed, starting with the node that failed.
+	OnFailure string `json:"onFailure,omitempty"`
+}
+
+type Versions struct {
+	CA      string `json:"ca,omitempty"`
+	Peer    string `json:"peer,omitempty"`
+	Orderer string `json:"orderer,omitempty"`
+}
+
+func (v Versions) of(componentType string) string {
+	switch componentType {
+	case TypeCA:
+		return v.CA
+	case TypePeer:
+		return v.Peer
+	case TypeOrderer:
+		return v.Orderer
+	}
+	return ""
+}
+
+// Node is a component to upgrade and its result
+type Node struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	FromVersion string `json:"fromVersion"`
+	ToVersion   string `json:"toVersion"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+}
+
+// ID is the name of the node in the progress of the operation, e.g.
+// peer/org1peer1
+func (n *Node) ID() string {
+	return n.Type + "/" + n.Name
+}
+
+// Response is the plan of an upgrade with the status of its nodes, the nodes
+// are not upgraded on dry runs
+type Response struct {
+	DryRun        bool    `json:"dryRun,omitempty"`
+	PeerBatchSize int     `json:"peerBatchSize"`
+	OnFailure     string  `json:"onFailure"`
+	Nodes         []*Node `json:"nodes"`
+}
+
+// Copy returns a copy of the response that is not changed by the execution
+// of the plan
+func (r *Response) Copy() *Response {
+	c := *r
+	c.Nodes = make([]*Node, len(r.Nodes))
+	for i, node := range r.Nodes {
+		n := *node
+		c.Nodes[i] = &n
+	}
+	return &c
+}
+
+// remaining returns the number of nodes that are not upgraded yet
+func (r *Response) remaining() int {
+	remaining := 0
+	for _, node := range r.Nodes {
+		if node.Status == StatusPending || node.Status == StatusFailed {
+			remaining++
+		}
+	}
+	return remaining
+}
+
+//go:generate counterfeiter -o mocks/target.go -fake-name Target . Target
+
+// Target manages the nodes of a component type
+type Target interface {
+	// Nodes returns the nodes of the service instance that run a version,
+	// e.g. the nodes of the orderer clusters but not the clusters
+	Nodes(sID, namespace string) ([]Node, error)
+	// ValidVersion returns an error if the nodes cannot be upgraded to the
+	// version
+	ValidVersion(version string) error
+	// CheckTransition returns an error if the transition policy does not
+	// allow a node to move from a version to the other
+	CheckTransition(from, to string) error
+	Upgrade(sID, namespace, name, version string) error
+	// WaitForStatus blocks until cond returns true or an error for the
+	// status of the CR of the node, or until ctx is done. cond is evaluated
+	// every time the CR changes and is passed a nil status while the CR
+	// does not exist.
+	WaitForStatus(ctx context.Context, namespace, name string, cond func(status *current.CRStatus) (bool, error)) (*current.CRStatus, error)
+}
+
+//go:generate counterfeiter -o mocks/kube.go -fake-name Kube . Kube
+
+type Kube interface {
+	GetPodsByLabel(namespace, name string) (*corev1.Pod, error)
+}
+
+type Upgrader struct {
+	Logger  *zap.SugaredLogger
+	Kube    Kube
+	Targets map[string]Target
+	// Timeout of the wait for each batch of nodes to be deployed
+	Timeout time.Duration
+	// RecheckInterval is how often the pod of a node is checked again while
+	// its CR does not change, the pods are not watched
+	RecheckInterval time.Duration
+}
+
+func New(logger *zap.Logger, kube Kube, targets map[string]Target, timeout time.Duration) *Upgrader {
+	return &Upgrader{
+		Logger:          logger.Sugar().Named("Upgrade"),
+		Kube:            kube,
+		Targets:         targets,
+		Timeout:         timeout,
+		RecheckInterval: DefaultRecheckInterval,
+	}
+}
+
+// Plan returns the nodes to upgrade in the order they are upgraded. The
+// nodes that already run the target version are skipped.
+func (u *Upgrader) Plan(sID, namespace string, request *Request) (*Response, error) {
+	response := &Response{
+		PeerBatchSize: request.PeerBatchSize,
+		OnFailure:     request.OnFailure,
+		Nodes:         []*Node{},
+	}
+	if response.PeerBatchSize == 0 {
+		response.PeerBatchSize = 1
+	}
+	if response.PeerBatchSize < 0 {
+		return nil, apierror.InvalidField("peerBatchSize", "peerBatchSize must be at least 1")
+	}
+	if response.OnFailure == "" {
+		response.OnFailure = OnFailureAbort
+	}
+	if response.OnFailure != OnFailureAbort && response.OnFailure != OnFailurePause {
+		return nil, apierror.InvalidField("onFailure", "onFailure must be '%s' or '%s'", OnFailureAbort, OnFailurePause)
+	}
+
+	versions := 0
+	for _, componentType := range typeOrder {
+		version := request.Versions.of(componentType)
+		if version == "" {
+			continue
+		}
+		versions++
+
+		target, found := u.Targets[componentType]
+		if !found {
+			return nil, apierror.UnsupportedComponentType(componentType)
+		}
+		err := target.ValidVersion(version)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes, err := target.Nodes(sID, namespace)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list the %s nodes", componentType)
+		}
+		for i := range nodes {
+			node := nodes[i]
+			node.Type = componentType
+			node.ToVersion = version
+			node.Status = StatusPending
+			if node.FromVersion == version {
+				node.Status = StatusSkipped
+			} else if err := target.CheckTransition(node.FromVersion, version); err != nil {
+				return nil, apierror.InvalidField("versions."+componentType, "%s '%s': %s", componentType, node.Name, err.Error())
+			}
+			response.Nodes = append(response.Nodes, &node)
+		}
+	}
+	if versions == 0 {
+		return nil, apierror.InvalidField("versions", "a version of at least one component type is required")
+	}
+
+	return response, nil
+}
+
+// Execute upgrades the nodes of the plan that are pending, or failed on a
+// previous execution. Each batch of nodes must be deployed with the new
+// version before the next batch is upgraded, the execution stops on the first
+// failure. Canceling ctx stops the execution before the next batch.
+func (u *Upgrader) Execute(ctx context.Context, sID, namespace string, plan *Response, progress common.NodeProgressFunc) (err error) {
+	ctx, span := tracer().Start(ctx, "upgrade.Execute", trace.WithAttributes(
+		attribute.String("deployer.service_instance", sID),
+		attribute.String("k8s.namespace.name", namespace),
+	))
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	total := plan.remaining()
+	if progress == nil {
+		progress = func(string, int, string, error) {}
+	}
+
+	for _, batch := range plan.batches() {
+		select {
+		case <-ctx.Done():
+			return apierror.New(apierror.Conflict, apierror.CodeConflict, "upgrade aborted")
+		default:
+		}
+
+		err := u.upgradeBatch(ctx, sID, namespace, batch, total, progress)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// batches returns the nodes to upgrade grouped by the nodes upgraded at the
+// same time, the CAs and orderers are upgraded one at a time
+func (r *Response) batches() [][]*Node {
+	batches := [][]*Node{}
+	var batch []*Node
+	for _, node := range r.Nodes {
+		if node.Status != StatusPending && node.Status != StatusFailed {
+			continue
+		}
+
+		size := 1
+		if node.Type == TypePeer {
+			size = r.PeerBatchSize
+		}
+		if len(batch) > 0 && (len(batch) >= size || batch[0].Type != node.Type) {
+			batches = append(batches, batch)
+			batch = nil
+		}
+		batch = append(batch, node)
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// upgradeBatch upgrades the nodes of the batch and waits for them to be
+// deployed, the first error is returned once the whole batch is done
+func (u *Upgrader) upgradeBatch(ctx context.Context, sID, namespace string, batch []*Node, total int, progress common.NodeProgressFunc) error {
+	since := time.Now()
+	upgraded := []*Node{}
+	var firstErr error
+	fail := func(node *Node, err error) {
+		node.Status = StatusFailed
+		node.Error = err.Error()
+		progress(node.ID(), total, common.NodeStateFailed, err)
+		if firstErr == nil {
+			firstErr = errors.Wrapf(err, "failed to upgrade %s '%s'", node.Type, node.Name)
+		}
+	}
+
+	for _, node := range batch {
+		u.Logger.Infof("Upgrading %s '%s' from '%s' to '%s'", node.Type, node.Name, node.FromVersion, node.ToVersion)
+		node.Status = StatusUpgrading
+		node.Error = ""
+		progress(node.ID(), total, common.NodeStateDeploying, nil)
+
+		err := u.Targets[node.Type].Upgrade(sID, namespace, node.Name, node.ToVersion)
+		if err != nil {
+			fail(node, err)
+			continue
+		}
+		upgraded = append(upgraded, node)
+	}
+
+	// the wait is not canceled with ctx, an aborted upgrade stops once the
+	// nodes being upgraded are deployed
+	waitCtx, cancel := context.WithTimeout(trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx)), u.Timeout)
+	defer cancel()
+	for _, node := range upgraded {
+		err := u.waitForDeployed(waitCtx, namespace, node, since)
+		if err != nil {
+			fail(node, err)
+			continue
+		}
+		node.Status = StatusUpgraded
+		progress(node.ID(), total, common.NodeStateDeployed, nil)
+	}
+
+	return firstErr
+}
+
+// waitForDeployed waits for the operator to deploy the node again, with a
+// pod started after the upgrade that is ready. The CR of the node is watched
+// and the pod is checked every time the CR changes, and every recheck
+// interval since the pod can become ready after the last change of the CR.
+func (u *Upgrader) waitForDeployed(ctx context.Context, namespace string, node *Node, since time.Time) (err error) {
+	ctx, span := tracer().Start(ctx, "upgrade.waitForDeployed", trace.WithAttributes(
+		attribute.String("deployer.component_type", node.Type),
+		attribute.String("k8s.object.name", node.Name),
+	))
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	for {
+		recheckCtx, cancel := context.WithTimeout(ctx, u.RecheckInterval)
+		_, err = u.Targets[node.Type].WaitForStatus(recheckCtx, namespace, node.Name, func(status *current.CRStatus) (bool, error) {
+			if status == nil {
+				return false, apierror.New(apierror.NotFound, apierror.CodeNotFound, "%s '%s' not found", node.Type, node.Name)
+			}
+			return u.deployed(namespace, node, status, since)
+		})
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return apierror.New(apierror.UpstreamTimeout, apierror.CodeTimeout, "timed out waiting for %s '%s' to be deployed", node.Type, node.Name)
+		}
+	}
+}
+
+func (u *Upgrader) deployed(namespace string, node *Node, status *current.CRStatus, since time.Time) (bool, error) {
+	if status.Type == current.Error {
+		return false, errors.Errorf("cr status is set to error: %s", status.Message)
+	}
+	if status.Type != current.Deployed {
+		return false, nil
+	}
+
+	pod, err := u.Kube.GetPodsByLabel(namespace, node.Name)
+	if err != nil {
+		if apierror.KindOf(err) == apierror.NotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	// the pod must have been recreated with the images of the new version
+	if pod.DeletionTimestamp != nil || pod.CreationTimestamp.Time.Before(since.Truncate(time.Second)) {
+		return false, nil
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue, nil
+		}
+	}
+	return false, nil
+}
+
+// endSpan ends the span, with the error if any
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
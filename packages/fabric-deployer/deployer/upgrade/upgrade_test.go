@@ -0,0 +1,307 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upgrade_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/apierror"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/upgrade"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/upgrade/mocks"
+	current "github.com/IBM-Blockchain/fabric-operator/api/v1beta1"
+)
+
+var _ = Describe("Upgrade", func() {
+	var (
+		upgrader *upgrade.Upgrader
+		mockKube *mocks.Kube
+		cas      *mocks.Target
+		peers    *mocks.Target
+		orderers *mocks.Target
+		upgraded []string
+		request  *upgrade.Request
+	)
+
+	// watch evaluates the condition against each of the statuses in turn, as
+	// the watch of the CR would for every change, then waits for ctx
+	watch := func(statuses ...*current.CRStatus) func(context.Context, string, string, func(*current.CRStatus) (bool, error)) (*current.CRStatus, error) {
+		return func(ctx context.Context, namespace, name string, cond func(*current.CRStatus) (bool, error)) (*current.CRStatus, error) {
+			var last *current.CRStatus
+			for _, status := range statuses {
+				last = status
+				done, err := cond(status)
+				if err != nil || done {
+					return last, err
+				}
+			}
+			<-ctx.Done()
+			return last, ctx.Err()
+		}
+	}
+
+	newTarget := func(componentType string, nodes ...upgrade.Node) *mocks.Target {
+		target := &mocks.Target{}
+		target.NodesReturns(nodes, nil)
+		target.UpgradeStub = func(sID, namespace, name, version string) error {
+			upgraded = append(upgraded, componentType+"/"+name)
+			return nil
+		}
+		target.WaitForStatusStub = watch(&current.CRStatus{Type: current.Deployed})
+		return target
+	}
+
+	BeforeEach(func() {
+		upgraded = nil
+		mockKube = &mocks.Kube{}
+		mockKube.GetPodsByLabelStub = func(namespace, name string) (*corev1.Pod, error) {
+			return &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(time.Now().Add(time.Second))},
+				Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+					{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+				}},
+			}, nil
+		}
+		cas = newTarget("ca", upgrade.Node{Name: "ca1", FromVersion: "1.5.5"})
+		peers = newTarget("peer",
+			upgrade.Node{Name: "peer1", FromVersion: "2.2.10"},
+			upgrade.Node{Name: "peer2", FromVersion: "2.2.10"},
+			upgrade.Node{Name: "peer3", FromVersion: "2.5.4"},
+			upgrade.Node{Name: "peer4", FromVersion: "2.2.10"},
+		)
+		orderers = newTarget("orderer",
+			upgrade.Node{Name: "os1node1", FromVersion: "2.2.10"},
+			upgrade.Node{Name: "os1node2", FromVersion: "2.2.10"},
+		)
+		upgrader = upgrade.New(zap.NewNop(), mockKube, map[string]upgrade.Target{
+			upgrade.TypeCA:      cas,
+			upgrade.TypePeer:    peers,
+			upgrade.TypeOrderer: orderers,
+		}, time.Second)
+
+		request = &upgrade.Request{
+			Versions:      upgrade.Versions{CA: "1.5.7", Peer: "2.5.4", Orderer: "2.5.4"},
+			PeerBatchSize: 2,
+		}
+	})
+
+	Context("plan", func() {
+		It("lists the cas, orderers and peers in order", func() {
+			plan, err := upgrader.Plan("sID", "ns1", request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(plan.OnFailure).To(Equal(upgrade.OnFailureAbort))
+
+			ids := []string{}
+			for _, node := range plan.Nodes {
+				ids = append(ids, node.ID()+":"+node.Status)
+			}
+			Expect(ids).To(Equal([]string{
+				"ca/ca1:pending",
+				"orderer/os1node1:pending",
+				"orderer/os1node2:pending",
+				"peer/peer1:pending",
+				"peer/peer2:pending",
+				"peer/peer3:skipped",
+				"peer/peer4:pending",
+			}))
+			sID, namespace := peers.NodesArgsForCall(0)
+			Expect(sID).To(Equal("sID"))
+			Expect(namespace).To(Equal("ns1"))
+		})
+
+		It("only lists the component types with a version", func() {
+			request.Versions = upgrade.Versions{CA: "1.5.7"}
+			plan, err := upgrader.Plan("sID", "ns1", request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(plan.Nodes).To(HaveLen(1))
+			Expect(peers.NodesCallCount()).To(Equal(0))
+		})
+
+		It("requires a version", func() {
+			_, err := upgrader.Plan("sID", "ns1", &upgrade.Request{})
+			Expect(apierror.KindOf(err)).To(Equal(apierror.Validation))
+		})
+
+		It("rejects invalid versions", func() {
+			peers.ValidVersionReturns(apierror.InvalidField("versions.peer", "version not valid"))
+			_, err := upgrader.Plan("sID", "ns1", request)
+			Expect(err).To(MatchError("version not valid"))
+		})
+
+		It("rejects transitions the policy does not allow", func() {
+			orderers.CheckTransitionReturns(errors.New("cannot upgrade orderer"))
+			_, err := upgrader.Plan("sID", "ns1", request)
+			Expect(err).To(MatchError("orderer 'os1node1': cannot upgrade orderer"))
+			Expect(apierror.KindOf(err)).To(Equal(apierror.Validation))
+		})
+
+		It("rejects unknown failure modes", func() {
+			request.OnFailure = "retry"
+			_, err := upgrader.Plan("sID", "ns1", request)
+			Expect(apierror.KindOf(err)).To(Equal(apierror.Validation))
+		})
+	})
+
+	Context("execute", func() {
+		var plan *upgrade.Response
+
+		BeforeEach(func() {
+			var err error
+			plan, err = upgrader.Plan("sID", "ns1", request)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("upgrades the nodes in order and reports their progress", func() {
+			var progress []string
+			err := upgrader.Execute(context.Background(), "sID", "ns1", plan, func(node string, total int, state string, err error) {
+				progress = append(progress, node+":"+state)
+				Expect(total).To(Equal(6))
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(upgraded).To(Equal([]string{"ca/ca1", "orderer/os1node1", "orderer/os1node2", "peer/peer1", "peer/peer2", "peer/peer4"}))
+			Expect(progress).To(ContainElements("orderer/os1node1:deployed", "peer/peer4:deployed"))
+
+			for _, node := range plan.Nodes {
+				Expect(node.Status).To(BeElementOf(upgrade.StatusUpgraded, upgrade.StatusSkipped))
+			}
+			_, namespace, _, _ := cas.UpgradeArgsForCall(0)
+			Expect(namespace).To(Equal("ns1"))
+		})
+
+		It("waits for each orderer node to be deployed before the next", func() {
+			deployed := map[string]bool{}
+			orderers.WaitForStatusStub = func(ctx context.Context, namespace, name string, cond func(*current.CRStatus) (bool, error)) (*current.CRStatus, error) {
+				deployed[name] = true
+				return watch(&current.CRStatus{Type: current.Deployed})(ctx, namespace, name, cond)
+			}
+			orderers.UpgradeStub = func(sID, namespace, name, version string) error {
+				if name == "os1node2" {
+					Expect(deployed).To(HaveKey("os1node1"))
+				}
+				return nil
+			}
+			Expect(upgrader.Execute(context.Background(), "sID", "ns1", plan, nil)).To(Succeed())
+		})
+
+		It("stops on the first failure", func() {
+			orderers.UpgradeStub = func(sID, namespace, name, version string) error {
+				if name == "os1node1" {
+					return errors.New("update failed")
+				}
+				return nil
+			}
+			err := upgrader.Execute(context.Background(), "sID", "ns1", plan, nil)
+			Expect(err).To(MatchError("failed to upgrade orderer 'os1node1': update failed"))
+			Expect(upgraded).To(Equal([]string{"ca/ca1"}))
+			Expect(plan.Nodes[1].Status).To(Equal(upgrade.StatusFailed))
+			Expect(plan.Nodes[2].Status).To(Equal(upgrade.StatusPending))
+
+			By("retrying the failed node when executed again", func() {
+				orderers.UpgradeReturns(nil)
+				Expect(upgrader.Execute(context.Background(), "sID", "ns1", plan, nil)).To(Succeed())
+				Expect(orderers.UpgradeCallCount()).To(Equal(3))
+				Expect(cas.UpgradeCallCount()).To(Equal(1))
+			})
+		})
+
+		It("fails nodes whose cr is set to error", func() {
+			cas.WaitForStatusStub = watch(&current.CRStatus{Type: current.Deploying}, &current.CRStatus{Type: current.Error, Message: "image pull failed"})
+			err := upgrader.Execute(context.Background(), "sID", "ns1", plan, nil)
+			Expect(err).To(MatchError(ContainSubstring("image pull failed")))
+		})
+
+		It("fails nodes whose cr is deleted", func() {
+			cas.WaitForStatusStub = watch(nil)
+			err := upgrader.Execute(context.Background(), "sID", "ns1", plan, nil)
+			Expect(err).To(MatchError(ContainSubstring("ca 'ca1' not found")))
+		})
+
+		It("checks the pod again when the cr changes", func() {
+			recreated := false
+			mockKube.GetPodsByLabelStub = func(namespace, name string) (*corev1.Pod, error) {
+				created := time.Now().Add(-time.Hour)
+				if recreated {
+					created = time.Now().Add(time.Second)
+				}
+				recreated = true
+				return &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+					Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+						{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+					}},
+				}, nil
+			}
+			cas.WaitForStatusStub = watch(&current.CRStatus{Type: current.Deployed}, &current.CRStatus{Type: current.Deployed})
+			request.Versions = upgrade.Versions{CA: "1.5.7"}
+			plan, err := upgrader.Plan("sID", "ns1", request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(upgrader.Execute(context.Background(), "sID", "ns1", plan, nil)).To(Succeed())
+			Expect(mockKube.GetPodsByLabelCallCount()).To(Equal(2))
+		})
+
+		It("checks the pod again when it becomes ready after the last change of the cr", func() {
+			upgrader.RecheckInterval = 10 * time.Millisecond
+			ready := corev1.ConditionFalse
+			mockKube.GetPodsByLabelStub = func(namespace, name string) (*corev1.Pod, error) {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(time.Now().Add(time.Second))},
+					Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+						{Type: corev1.PodReady, Status: ready},
+					}},
+				}
+				ready = corev1.ConditionTrue
+				return pod, nil
+			}
+			request.Versions = upgrade.Versions{CA: "1.5.7"}
+			plan, err := upgrader.Plan("sID", "ns1", request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(upgrader.Execute(context.Background(), "sID", "ns1", plan, nil)).To(Succeed())
+			Expect(mockKube.GetPodsByLabelCallCount()).To(Equal(2))
+			Expect(cas.WaitForStatusCallCount()).To(Equal(2))
+		})
+
+		It("times out if the pod is not recreated", func() {
+			upgrader.Timeout = 10 * time.Millisecond
+			mockKube.GetPodsByLabelReturns(&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))},
+			}, nil)
+			err := upgrader.Execute(context.Background(), "sID", "ns1", plan, nil)
+			Expect(apierror.KindOf(err)).To(Equal(apierror.UpstreamTimeout))
+		})
+
+		It("stops before the next node when aborted", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cas.UpgradeStub = func(sID, namespace, name, version string) error {
+				cancel()
+				return nil
+			}
+			err := upgrader.Execute(ctx, "sID", "ns1", plan, nil)
+			Expect(err).To(MatchError("upgrade aborted"))
+			Expect(plan.Nodes[0].Status).To(Equal(upgrade.StatusUpgraded))
+			Expect(orderers.UpgradeCallCount()).To(Equal(0))
+		})
+	})
+})
@@ -20,9 +20,9 @@ package util
 
 import (
 	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"math/big"
-	"net/http"
 	"strconv"
 	"strings"
 
@@ -37,6 +37,15 @@ func GenRandomName(prefix string) string {
 	return prefix + num.String()
 }
 
+// NewID returns a random 128 bit ID in hex, e.g. of operations, audit
+// entries or requests
+func NewID() string {
+	b := make([]byte, 16)
+	// crypto/rand.Read does not fail on supported platforms
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 func IgnoreAlreadyExistError(err error) error {
 	if !strings.Contains(err.Error(), "already exists") {
 		return err
@@ -44,26 +53,6 @@ func IgnoreAlreadyExistError(err error) error {
 	return nil
 }
 
-func GetErrorStatusCode(err error) int {
-	derr := strings.ToLower(err.Error())
-	if strings.Contains(derr, "bad request") {
-		return http.StatusBadRequest
-	}
-	if strings.Contains(derr, "unauthorized") {
-		return http.StatusUnauthorized
-	}
-	if strings.Contains(derr, "forbidden") {
-		return http.StatusForbidden
-	}
-	if strings.Contains(derr, "not found") {
-		return http.StatusNotFound
-	}
-	if strings.Contains(derr, "already exists") {
-		return http.StatusConflict
-	}
-	return http.StatusInternalServerError
-}
-
 func GetDefaultVersion(comp string, versions *config.Versions) string {
 	comp = strings.ToLower(comp)
 
@@ -123,9 +112,13 @@ func IsValidVersion(comp string, version string, versions *config.Versions) bool
 	return false
 }
 
+// ResourceReturn is the total of the resources of the containers of a
+// component, CPU in millicores and memory in megabytes, and the resources of
+// each container role
 type ResourceReturn struct {
-	Requests Resource `json:"requests"`
-	Limits   Resource `json:"limits"`
+	Requests Resource                   `json:"requests"`
+	Limits   Resource                   `json:"limits"`
+	Roles    map[string]*ResourceReturn `json:"roles,omitempty"`
 }
 
 type Resource struct {
@@ -133,81 +126,185 @@ type Resource struct {
 	Memory string `json:"memory"`
 }
 
-func GetTotalDeploymentResources(resources []*corev1.ResourceRequirements) *ResourceReturn {
-	var requestCPU int
-	var requestMemory int
-	var limitCPU int
-	var limitMemory int
+// Container roles of the components, the json names of the fields of the
+// resources of the CRs
+const (
+	RoleCA                = "ca"
+	RolePeer              = "peer"
+	RoleOrderer           = "orderer"
+	RoleCouchDB           = "couchdb"
+	RoleProxy             = "proxy"
+	RoleInit              = "init"
+	RoleEnroller          = "enroller"
+	RoleHSMDaemon         = "hsmdaemon"
+	RoleChaincodeLauncher = "chaincodelauncher"
+)
+
+// ComponentResources are the resources of all the replicas of a component,
+// Roles are the resources of a replica by container role
+type ComponentResources struct {
+	Type      string                                  `json:"type"`
+	Name      string                                  `json:"name"`
+	Replicas  int32                                   `json:"replicas"`
+	Resources *ResourceReturn                         `json:"resources"`
+	Roles     map[string]*corev1.ResourceRequirements `json:"-"`
+}
+
+// InitRoles are the roles of the containers that run before the others, a
+// pod needs the largest of them or the sum of the other containers
+var InitRoles = map[string]bool{
+	RoleInit:     true,
+	RoleEnroller: true,
+}
+
+// NewComponentResources returns the resources of the replicas of a component
+func NewComponentResources(typeOfComponent, name string, replicas *int32, roles map[string]*corev1.ResourceRequirements) ComponentResources {
+	count := int32(1)
+	if replicas != nil {
+		count = *replicas
+	}
+	total := NewTotals()
+	total.Add(roles, count)
+	return ComponentResources{
+		Type:      typeOfComponent,
+		Name:      name,
+		Replicas:  count,
+		Resources: total.ResourceReturn(),
+		Roles:     roles,
+	}
+}
 
+func GetTotalDeploymentResources(resources []*corev1.ResourceRequirements) *ResourceReturn {
+	requests := corev1.ResourceList{}
+	limits := corev1.ResourceList{}
 	for _, resource := range resources {
-		requestCPU += ConvertCPUToNum(resource.Requests.Cpu())
-		requestMemory += ConvertMemToNum(resource.Requests.Memory())
-		limitCPU += ConvertCPUToNum(resource.Limits.Cpu())
-		limitMemory += ConvertMemToNum(resource.Limits.Memory())
+		if resource == nil {
+			continue
+		}
+		addResourceList(requests, resource.Requests, 1)
+		addResourceList(limits, resource.Limits, 1)
+	}
+	return newResourceReturn(requests, limits)
+}
+
+// GetResourcesByRole returns the resources of a pod with the container roles
+// and the resources of each role, roles without resources are left out
+func GetResourcesByRole(roles map[string]*corev1.ResourceRequirements) *ResourceReturn {
+	total := NewTotals()
+	total.Add(roles, 1)
+	return total.ResourceReturn()
+}
+
+// EffectiveResources returns the resources of a pod with the containers of
+// the roles following the rules of the scheduler, the sum of the containers
+// or the largest init container
+func EffectiveResources(roles map[string]*corev1.ResourceRequirements) (requests, limits corev1.ResourceList) {
+	requests = corev1.ResourceList{}
+	limits = corev1.ResourceList{}
+	initRequests := corev1.ResourceList{}
+	initLimits := corev1.ResourceList{}
+	for role, requirements := range roles {
+		if requirements == nil {
+			continue
+		}
+		if InitRoles[role] {
+			raiseResourceList(initRequests, requirements.Requests)
+			raiseResourceList(initLimits, requirements.Limits)
+			continue
+		}
+		addResourceList(requests, requirements.Requests, 1)
+		addResourceList(limits, requirements.Limits, 1)
+	}
+	raiseResourceList(requests, initRequests)
+	raiseResourceList(limits, initLimits)
+	return requests, limits
+}
+
+// Totals adds up the resources of the replicas of components, the total of
+// their effective pods and the total of each container role
+type Totals struct {
+	requests corev1.ResourceList
+	limits   corev1.ResourceList
+	roles    map[string]*corev1.ResourceRequirements
+}
+
+func NewTotals() *Totals {
+	return &Totals{
+		requests: corev1.ResourceList{},
+		limits:   corev1.ResourceList{},
+		roles:    map[string]*corev1.ResourceRequirements{},
+	}
+}
+
+// Add adds the replicas of a pod with the container roles
+func (t *Totals) Add(roles map[string]*corev1.ResourceRequirements, replicas int32) {
+	requests, limits := EffectiveResources(roles)
+	addResourceList(t.requests, requests, int64(replicas))
+	addResourceList(t.limits, limits, int64(replicas))
+
+	for role, requirements := range roles {
+		if requirements == nil {
+			continue
+		}
+		if t.roles[role] == nil {
+			t.roles[role] = &corev1.ResourceRequirements{Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{}}
+		}
+		addResourceList(t.roles[role].Requests, requirements.Requests, int64(replicas))
+		addResourceList(t.roles[role].Limits, requirements.Limits, int64(replicas))
+	}
+}
+
+// ResourceReturn returns the totals, roles without resources are left out
+func (t *Totals) ResourceReturn() *ResourceReturn {
+	total := newResourceReturn(t.requests, t.limits)
+	total.Roles = map[string]*ResourceReturn{}
+	for role, requirements := range t.roles {
+		total.Roles[role] = newResourceReturn(requirements.Requests, requirements.Limits)
+	}
+	return total
+}
+
+func addResourceList(total, list corev1.ResourceList, times int64) {
+	for name, quantity := range list {
+		value := total[name]
+		for i := int64(0); i < times; i++ {
+			value.Add(quantity)
+		}
+		total[name] = value
+	}
+}
+
+// raiseResourceList sets each resource of total to the larger of total and
+// list
+func raiseResourceList(total, list corev1.ResourceList) {
+	for name, quantity := range list {
+		if value, ok := total[name]; !ok || quantity.Cmp(value) > 0 {
+			total[name] = quantity.DeepCopy()
+		}
 	}
+}
 
+func newResourceReturn(requests, limits corev1.ResourceList) *ResourceReturn {
 	return &ResourceReturn{
 		Requests: Resource{
-			CPU:    strconv.Itoa(requestCPU) + "m",
-			Memory: strconv.Itoa(requestMemory) + "M",
+			CPU:    strconv.Itoa(ConvertCPUToNum(requests.Cpu())) + "m",
+			Memory: strconv.Itoa(ConvertMemToNum(requests.Memory())) + "M",
 		},
 		Limits: Resource{
-			CPU:    strconv.Itoa(limitCPU) + "m",
-			Memory: strconv.Itoa(limitMemory) + "M",
+			CPU:    strconv.Itoa(ConvertCPUToNum(limits.Cpu())) + "m",
+			Memory: strconv.Itoa(ConvertMemToNum(limits.Memory())) + "M",
 		},
 	}
 }
 
-const (
-	kibibyte = 1024
-	kilobyte = 1000
-	mebibyte = kibibyte ^ 2
-	megabyte = kilobyte ^ 2
-	gibibyte = mebibyte * kibibyte
-	gigabyte = megabyte * kilobyte
-)
-
+// ConvertCPUToNum returns the CPU in millicores
 func ConvertCPUToNum(cpu *resource.Quantity) int {
-	str := cpu.String()
-
-	var num int
-	if strings.HasSuffix(str, "m") {
-		num = atoi(strings.Split(str, "m")[0])
-	} else {
-		num = atoi(str)
-		num = num * 1000
-	}
-
-	return num
+	return int(cpu.MilliValue())
 }
 
+// ConvertMemToNum returns the memory in megabytes, rounded up
 func ConvertMemToNum(mem *resource.Quantity) int {
-	str := mem.String()
-
-	var num int
-	if strings.Contains(str, "Ki") {
-		num = atoi(strings.Split(str, "Ki")[0])
-		num = num * kibibyte
-	} else if strings.Contains(str, "K") {
-		num = atoi(strings.Split(str, "K")[0])
-		num = num * kilobyte
-	} else if strings.Contains(str, "Mi") {
-		num = atoi(strings.Split(str, "Mi")[0])
-		num = num * mebibyte
-	} else if strings.Contains(str, "M") {
-		num = atoi(strings.Split(str, "M")[0])
-		num = num * megabyte
-	} else if strings.Contains(str, "Gi") {
-		num = atoi(strings.Split(str, "Gi")[0])
-		num = num * gibibyte
-	} else if strings.Contains(str, "G") {
-		num = atoi(strings.Split(str, "G")[0])
-		num = num * gigabyte
-	} else {
-		num = atoi(str)
-	}
-
-	return num / megabyte
+	return int(mem.ScaledValue(resource.Mega))
 }
 
 func GetServiceName(compName string) string {
@@ -35,6 +35,12 @@ var _ = Describe("Util", func() {
 		Expect(name).Should(MatchRegexp(`random\d`))
 	})
 
+	It("generates random IDs", func() {
+		id := util.NewID()
+		Expect(id).To(MatchRegexp(`^[0-9a-f]{32}$`))
+		Expect(util.NewID()).NotTo(Equal(id))
+	})
+
 	Context("already exists error", func() {
 		It("returns error if it is not an already exists error", func() {
 			err := util.IgnoreAlreadyExistError(errors.New("failed to create resource"))
@@ -76,6 +82,53 @@ var _ = Describe("Util", func() {
 			num := util.ConvertMemToNum(&mem)
 			Expect(num).To(Equal(2000))
 		})
+
+		It("converts binary units", func() {
+			mem := resource.MustParse("2Mi")
+			Expect(util.ConvertMemToNum(&mem)).To(Equal(3))
+			mem = resource.MustParse("1Gi")
+			Expect(util.ConvertMemToNum(&mem)).To(Equal(1074))
+		})
+	})
+
+	Context("ConvertCPUToNum", func() {
+		It("converts fractional cpu", func() {
+			cpu := resource.MustParse("1.5")
+			Expect(util.ConvertCPUToNum(&cpu)).To(Equal(1500))
+			cpu = resource.MustParse("250m")
+			Expect(util.ConvertCPUToNum(&cpu)).To(Equal(250))
+		})
+	})
+
+	Context("GetResourcesByRole", func() {
+		It("returns the totals and the resources of each role", func() {
+			roles := map[string]*corev1.ResourceRequirements{
+				util.RolePeer:    {Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+				util.RoleCouchDB: {Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}},
+				util.RoleInit:    nil,
+			}
+			total := util.NewTotals()
+			total.Add(roles, 2)
+
+			resources := total.ResourceReturn()
+			Expect(resources.Requests.CPU).To(Equal("3000m"))
+			Expect(resources.Roles).To(HaveLen(2))
+			Expect(resources.Roles[util.RoleCouchDB].Requests.CPU).To(Equal("1000m"))
+		})
+
+		It("does not add the init containers to the other containers", func() {
+			roles := map[string]*corev1.ResourceRequirements{
+				util.RolePeer:     {Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("100M")}},
+				util.RoleInit:     {Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("200M")}},
+				util.RoleEnroller: {Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}},
+			}
+
+			resources := util.GetResourcesByRole(roles)
+			Expect(resources.Requests.CPU).To(Equal("2000m"))
+			Expect(resources.Requests.Memory).To(Equal("200M"))
+			Expect(resources.Roles).To(HaveLen(3))
+			Expect(resources.Roles[util.RoleInit].Requests.CPU).To(Equal("500m"))
+		})
 	})
 
 	Context("GetZoneAndRegion", func() {
@@ -0,0 +1,146 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+)
+
+// CompareVersions compares two Fabric versions, e.g. 2.5.4 and 2.5.4-1, and
+// returns -1, 0 or 1. Missing parts are zero.
+func CompareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionParts returns the numbers of the version, the release number after
+// the dash is the last one
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(version, "v")
+	release := ""
+	if i := strings.Index(version, "-"); i >= 0 {
+		version, release = version[:i], version[i+1:]
+	}
+
+	parts := []int{}
+	for _, part := range strings.Split(version, ".") {
+		n, _ := strconv.Atoi(part)
+		parts = append(parts, n)
+	}
+	for len(parts) < 3 {
+		parts = append(parts, 0)
+	}
+	n, _ := strconv.Atoi(release)
+	return append(parts, n)
+}
+
+func majorVersion(version string) int {
+	return versionParts(version)[0]
+}
+
+// matchesVersion returns true if the version is the prefix or starts with it
+func matchesVersion(version, prefix string) bool {
+	return version == prefix || strings.HasPrefix(version, prefix+".") || strings.HasPrefix(version, prefix+"-")
+}
+
+// CheckVersionTransition returns an error explaining why a component cannot
+// be moved from a version to another under the transition policy of its
+// type. Forced transitions are only allowed to downgrade.
+func CheckVersionTransition(comp, from, to string, force bool, versions *config.Versions) error {
+	if from == "" || from == to {
+		return nil
+	}
+
+	policy := &config.TransitionPolicy{}
+	if versions != nil && versions.Transitions != nil {
+		var p *config.TransitionPolicy
+		switch strings.ToLower(comp) {
+		case "ca":
+			p = versions.Transitions.CA
+		case "peer":
+			p = versions.Transitions.Peer
+		case "orderer":
+			p = versions.Transitions.Orderer
+		}
+		if p != nil {
+			policy = p
+		}
+	}
+
+	if CompareVersions(to, from) < 0 {
+		if policy.AllowDowngrade || force {
+			return nil
+		}
+		return errors.Errorf("cannot downgrade %s from '%s' to '%s', downgrades must be forced", comp, from, to)
+	}
+
+	fromMajor, toMajor := majorVersion(from), majorVersion(to)
+	if toMajor > fromMajor+1 && !policy.AllowMajorSkip {
+		return errors.Errorf("cannot upgrade %s from '%s' to '%s', upgrade to %d.x first", comp, from, to, fromMajor+1)
+	}
+	if toMajor > fromMajor {
+		gate, found := policy.MajorGates[strconv.Itoa(toMajor)]
+		if found && CompareVersions(from, gate) < 0 {
+			return errors.Errorf("cannot upgrade %s from '%s' to '%s', upgrading to %d.x requires version '%s' or later", comp, from, to, toMajor, gate)
+		}
+	}
+
+	for _, path := range policy.Paths {
+		if !matchesVersion(from, path.From) {
+			continue
+		}
+		for _, allowed := range path.To {
+			if matchesVersion(to, allowed) {
+				return nil
+			}
+		}
+		return errors.Errorf("cannot upgrade %s from '%s' to '%s', '%s' can only be upgraded to %s", comp, from, to, path.From, quoteVersions(path.To))
+	}
+
+	return nil
+}
+
+func quoteVersions(versions []string) string {
+	quoted := []string{}
+	for _, version := range versions {
+		quoted = append(quoted, fmt.Sprintf("'%s'", version))
+	}
+	return strings.Join(quoted, ", ")
+}
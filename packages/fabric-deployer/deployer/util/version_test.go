@@ -0,0 +1,95 @@
+/*
+ * Copyright contributors to the Hyperledger Fabric Operations Console project
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at:
+ *
+ * 	  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/IBM-Blockchain/fabric-deployer/config"
+	"github.com/IBM-Blockchain/fabric-deployer/deployer/util"
+)
+
+var _ = Describe("Version", func() {
+	DescribeTable("compares versions",
+		func(a, b string, expected int) {
+			Expect(util.CompareVersions(a, b)).To(Equal(expected))
+		},
+		Entry("equal", "2.5.4", "2.5.4", 0),
+		Entry("patch", "2.5.4", "2.5.10", -1),
+		Entry("minor", "2.5.0", "2.2.10", 1),
+		Entry("release", "1.4.1-1", "1.4.1", 1),
+		Entry("missing parts", "2.5", "2.5.0", 0),
+	)
+
+	Context("transition policy", func() {
+		var versions *config.Versions
+
+		BeforeEach(func() {
+			versions = &config.Versions{
+				Transitions: &config.VersionTransitions{
+					Orderer: &config.TransitionPolicy{
+						Paths: []config.VersionPath{
+							{From: "2.2", To: []string{"2.5"}},
+						},
+						MajorGates: map[string]string{"2": "1.4.9"},
+					},
+				},
+			}
+		})
+
+		It("allows upgrades without a policy", func() {
+			Expect(util.CheckVersionTransition("peer", "2.2.10", "2.5.4", false, versions)).To(Succeed())
+			Expect(util.CheckVersionTransition("peer", "2.2.10", "2.5.4", false, nil)).To(Succeed())
+		})
+
+		It("allows components without a version", func() {
+			Expect(util.CheckVersionTransition("orderer", "", "1.4.1", false, versions)).To(Succeed())
+		})
+
+		It("rejects downgrades unless forced", func() {
+			err := util.CheckVersionTransition("orderer", "2.2.10", "1.4.12", false, versions)
+			Expect(err).To(MatchError("cannot downgrade orderer from '2.2.10' to '1.4.12', downgrades must be forced"))
+			Expect(util.CheckVersionTransition("orderer", "2.2.10", "1.4.12", true, versions)).To(Succeed())
+		})
+
+		It("allows downgrades if the policy does", func() {
+			versions.Transitions.Orderer.AllowDowngrade = true
+			Expect(util.CheckVersionTransition("orderer", "2.2.10", "2.2.9", false, versions)).To(Succeed())
+		})
+
+		It("rejects upgrades that skip a major version", func() {
+			err := util.CheckVersionTransition("peer", "1.4.12", "3.0.0", false, versions)
+			Expect(err).To(MatchError("cannot upgrade peer from '1.4.12' to '3.0.0', upgrade to 2.x first"))
+		})
+
+		It("rejects upgrades to a major version from before its gate", func() {
+			err := util.CheckVersionTransition("orderer", "1.4.8", "2.2.10", false, versions)
+			Expect(err).To(MatchError("cannot upgrade orderer from '1.4.8' to '2.2.10', upgrading to 2.x requires version '1.4.9' or later"))
+			Expect(util.CheckVersionTransition("orderer", "1.4.12", "2.2.10", false, versions)).To(Succeed())
+		})
+
+		It("only allows the upgrade paths of the version", func() {
+			err := util.CheckVersionTransition("orderer", "2.2.10", "2.4.9", false, versions)
+			Expect(err).To(MatchError("cannot upgrade orderer from '2.2.10' to '2.4.9', '2.2' can only be upgraded to '2.5'"))
+			Expect(util.CheckVersionTransition("orderer", "2.2.10", "2.5.4-1", false, versions)).To(Succeed())
+			Expect(util.CheckVersionTransition("orderer", "2.4.9", "2.5.4", false, versions)).To(Succeed())
+		})
+	})
+})